@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"com.perkunas/pkg/wallet"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var accountCmd = &cobra.Command{
+	Use:   "account",
+	Short: "Manage keystore accounts",
+	Long:  "Create, list and import password protected accounts in the local keystore",
+}
+
+var accountNewCmd = &cobra.Command{
+	Use:   "new",
+	Short: "Create a new account",
+	Long:  "Generate a new key and store it encrypted in the keystore",
+	Run:   accountNew,
+}
+
+var accountListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List accounts",
+	Long:  "List the addresses of all accounts in the keystore",
+	Run:   accountList,
+}
+
+var accountImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import a private key",
+	Long:  "Encrypt an existing hex private key, read from a file or prompt, into the keystore",
+	Run:   accountImport,
+}
+
+var accountMnemonicCmd = &cobra.Command{
+	Use:   "mnemonic",
+	Short: "Generate a mnemonic phrase",
+	Long:  "Generate a BIP-39 phrase from which all HD accounts can be derived and restored",
+	Run:   accountMnemonic,
+}
+
+var accountDeriveCmd = &cobra.Command{
+	Use:   "derive",
+	Short: "Derive HD accounts from a mnemonic",
+	Long:  "Derive the addresses at m/44'/60'/0'/0/<index> from a mnemonic, optionally saving them to the keystore",
+	Run:   accountDerive,
+}
+
+// Keystore flags
+var (
+	keystoreDir  string
+	passwordFile string
+	keyFile      string
+	lightKDF     bool
+)
+
+// HD flags
+var (
+	mnemonicFile   string
+	passphraseFile string
+	deriveIndex    uint32
+	deriveCount    uint32
+	saveDerived    bool
+)
+
+func init() {
+	accountCmd.PersistentFlags().StringVar(&keystoreDir, "keystore-dir", defaultKeystoreDir(), "Keystore directory")
+	accountCmd.PersistentFlags().StringVar(&passwordFile, "password-file", "", "File containing the keystore password (prompted if omitted)")
+	accountNewCmd.Flags().BoolVar(&lightKDF, "light-kdf", false, "Use cheaper key derivation (less secure)")
+	accountImportCmd.Flags().BoolVar(&lightKDF, "light-kdf", false, "Use cheaper key derivation (less secure)")
+	accountImportCmd.Flags().StringVar(&keyFile, "key-file", "", "File containing the hex private key (prompted if omitted)")
+	accountDeriveCmd.Flags().BoolVar(&lightKDF, "light-kdf", false, "Use cheaper key derivation (less secure)")
+	accountDeriveCmd.Flags().StringVar(&mnemonicFile, "mnemonic-file", "", "File containing the mnemonic (prompted if omitted)")
+	accountDeriveCmd.Flags().StringVar(&passphraseFile, "passphrase-file", "", "File containing the optional BIP-39 passphrase")
+	accountDeriveCmd.Flags().Uint32Var(&deriveIndex, "index", 0, "Index of the first address to derive")
+	accountDeriveCmd.Flags().Uint32Var(&deriveCount, "count", 1, "Number of consecutive addresses to derive")
+	accountDeriveCmd.Flags().BoolVar(&saveDerived, "save", false, "Encrypt the derived keys into the keystore")
+
+	accountCmd.AddCommand(accountNewCmd)
+	accountCmd.AddCommand(accountListCmd)
+	accountCmd.AddCommand(accountImportCmd)
+	accountCmd.AddCommand(accountMnemonicCmd)
+	accountCmd.AddCommand(accountDeriveCmd)
+	rootCmd.AddCommand(accountCmd)
+}
+
+func accountNew(cmd *cobra.Command, args []string) {
+	w, err := wallet.New()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to generate wallet: %v\n", err)
+		os.Exit(1)
+	}
+
+	saveAccount(w)
+}
+
+func accountImport(cmd *cobra.Command, args []string) {
+	var keyHex string
+	if keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read key file: %v\n", err)
+			os.Exit(1)
+		}
+		keyHex = string(data)
+	} else {
+		secret, err := promptSecret("Private key: ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read private key: %v\n", err)
+			os.Exit(1)
+		}
+		keyHex = secret
+	}
+
+	w, err := wallet.FromPrivateKey(strings.TrimPrefix(strings.TrimSpace(keyHex), "0x"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	saveAccount(w)
+}
+
+func saveAccount(w *wallet.Wallet) {
+	password, err := newPassword()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	kdf := wallet.StandardKDF
+	if lightKDF {
+		kdf = wallet.LightKDF
+	}
+
+	path, err := wallet.Save(keystoreDir, w, password, kdf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to save key: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Address:  %s\n", w.Address)
+	fmt.Printf("Keystore: %s\n", path)
+}
+
+func accountList(cmd *cobra.Command, args []string) {
+	keys, err := wallet.List(keystoreDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to list keystore: %v\n", err)
+		os.Exit(1)
+	}
+
+	for i, k := range keys {
+		fmt.Printf("#%d: %s %s\n", i, k.Address, k.Path)
+	}
+}
+
+func accountMnemonic(cmd *cobra.Command, args []string) {
+	mnemonic, err := wallet.NewMnemonic()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to generate mnemonic: %v\n", err)
+		os.Exit(1)
+	}
+
+	w, err := wallet.FromMnemonic(mnemonic, "", 0)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Mnemonic: %s\n", mnemonic)
+	fmt.Printf("Address:  %s (%s)\n", w.Address, wallet.AccountPath(0))
+	fmt.Fprintln(os.Stderr, "Write the mnemonic down and keep it offline, anyone with it controls every derived account.")
+}
+
+func accountDerive(cmd *cobra.Command, args []string) {
+	mnemonic, err := readSecretFile(mnemonicFile, "Mnemonic: ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to read mnemonic: %v\n", err)
+		os.Exit(1)
+	}
+
+	var passphrase string
+	if passphraseFile != "" {
+		if passphrase, err = readSecretFile(passphraseFile, ""); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read passphrase: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	master, err := wallet.NewMasterKey(mnemonic, passphrase)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var password string
+	if saveDerived {
+		if password, err = newPassword(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	kdf := wallet.StandardKDF
+	if lightKDF {
+		kdf = wallet.LightKDF
+	}
+
+	for i := deriveIndex; i < deriveIndex+deriveCount; i++ {
+		path := wallet.AccountPath(i)
+		key, err := master.Derive(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to derive %s: %v\n", path, err)
+			os.Exit(1)
+		}
+
+		w, err := key.Wallet()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !saveDerived {
+			fmt.Printf("%s %s\n", path, w.Address)
+			continue
+		}
+
+		file, err := wallet.Save(keystoreDir, w, password, kdf)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to save key: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s %s %s\n", path, w.Address, file)
+	}
+}
+
+// loadKeystoreWallet unlocks the key at path, or the key of address when path
+// is a keystore directory.
+func loadKeystoreWallet(path, address string) (*wallet.Wallet, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		if address == "" {
+			return nil, errors.New("an address is required to pick a key from a keystore directory")
+		}
+
+		if path, err = wallet.Find(path, address); err != nil {
+			return nil, err
+		}
+	}
+
+	password, err := readPassword("Password: ")
+	if err != nil {
+		return nil, err
+	}
+
+	return wallet.Load(path, password)
+}
+
+func defaultKeystoreDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "keystore"
+	}
+
+	return filepath.Join(home, ".perkunas", "keystore")
+}
+
+// readPassword returns the contents of --password-file or prompts for it.
+func readPassword(prompt string) (string, error) {
+	if passwordFile != "" {
+		data, err := os.ReadFile(passwordFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	return promptSecret(prompt)
+}
+
+// readSecretFile returns the trimmed contents of path or prompts when path is empty.
+func readSecretFile(path, prompt string) (string, error) {
+	if path == "" {
+		return promptSecret(prompt)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+func newPassword() (string, error) {
+	password, err := readPassword("Password: ")
+	if err != nil || passwordFile != "" {
+		return password, err
+	}
+
+	confirm, err := promptSecret("Repeat password: ")
+	if err != nil {
+		return "", err
+	}
+
+	if password != confirm {
+		return "", errors.New("passwords do not match")
+	}
+
+	return password, nil
+}
+
+func promptSecret(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	defer fmt.Fprintln(os.Stderr)
+
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		secret, err := term.ReadPassword(fd)
+		return string(secret), err
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
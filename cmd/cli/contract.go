@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"com.perkunas/internal/models/contract"
+	"com.perkunas/internal/models/eventlog"
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/internal/vm"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+var contractCmd = &cobra.Command{
+	Use:   "contract",
+	Short: "Deploy and call contracts",
+	Long: `Contracts are code run by a small stack machine on every call. The fee of
+a contract transaction is its gas limit: execution stops when it runs out,
+and the gas not used is refunded. A failed call is still mined, it keeps the
+value with the caller and discards the storage writes and logs.
+
+Code is given as hex or as assembly, see the vm package for the instructions:
+
+  PUSH 0 SLOAD PUSH 1 ADD   ; counter + 1
+  DUP1 PUSH 0 SSTORE
+  RETURN`,
+}
+
+var contractDeployCmd = &cobra.Command{
+	Use:   "deploy",
+	Short: "Deploy a contract",
+	Run:   contractDeploy,
+}
+
+var contractCallCmd = &cobra.Command{
+	Use:   "call <contract>",
+	Short: "Call a contract",
+	Args:  cobra.ExactArgs(1),
+	Run:   contractCall,
+}
+
+var contractInfoCmd = &cobra.Command{
+	Use:   "info <contract>",
+	Short: "Show a contract and its disassembled code",
+	Args:  cobra.ExactArgs(1),
+	Run:   contractInfo,
+}
+
+var contractStorageCmd = &cobra.Command{
+	Use:   "storage <contract> <key>",
+	Short: "Read a storage word of a contract, the key is decimal or 0x hex",
+	Args:  cobra.ExactArgs(2),
+	Run:   contractStorage,
+}
+
+var contractLogsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "List contract logs",
+	Long: `List the logs contracts emitted, oldest first. --topic is given once per
+topic position with comma separated alternatives, * accepts any value:
+
+  contract logs --address <contract> --topic '*' --topic 0x0a,0x0b`,
+	Run: contractLogs,
+}
+
+// Contract flags
+var (
+	codeHex   string
+	asmFile   string
+	inputHex  string
+	logFilter eventlog.Filter
+	topics    []string
+)
+
+func init() {
+	contractDeployCmd.Flags().StringVar(&codeHex, "code", "", "0x prefixed hex code")
+	contractDeployCmd.Flags().StringVar(&asmFile, "asm", "", "File with the code as assembly")
+	contractDeployCmd.Flags().Int64Var(&amount, "value", 0, "Amount to send to the contract")
+	contractDeployCmd.MarkFlagsOneRequired("code", "asm")
+	contractDeployCmd.MarkFlagsMutuallyExclusive("code", "asm")
+	addSigningFlags(contractDeployCmd)
+
+	contractCallCmd.Flags().StringVar(&inputHex, "input", "", "0x prefixed hex calldata")
+	contractCallCmd.Flags().Int64Var(&amount, "value", 0, "Amount to send to the contract")
+	addSigningFlags(contractCallCmd)
+
+	contractCmd.AddCommand(contractDeployCmd)
+	contractCmd.AddCommand(contractCallCmd)
+	contractCmd.AddCommand(contractInfoCmd)
+	contractLogsCmd.Flags().StringArrayVar(&logFilter.Addresses, "address", nil, "Only logs of this contract, may repeat")
+	contractLogsCmd.Flags().StringArrayVar(&topics, "topic", nil, "Accepted values of the next topic position, comma separated or *")
+	contractLogsCmd.Flags().Uint64Var(&logFilter.FromHeight, "from-block", 0, "First block height")
+	contractLogsCmd.Flags().Uint64Var(&logFilter.ToHeight, "to-block", 0, "Last block height, the latest block by default")
+	contractLogsCmd.Flags().IntVar(&logFilter.Limit, "limit", 0, "Most logs to list")
+
+	contractCmd.AddCommand(contractStorageCmd)
+	contractCmd.AddCommand(contractLogsCmd)
+	rootCmd.AddCommand(contractCmd)
+}
+
+func contractDeploy(cmd *cobra.Command, args []string) {
+	code, err := contractCode()
+	if err != nil {
+		fail("invalid code", err)
+	}
+
+	tx, hash := signAndSubmit(cmd, func(nonce uint64) (*transaction.Transaction, error) {
+		return contract.DeployTransaction(from, code, amount, fee, nonce)
+	})
+
+	printResult(map[string]any{"contract": tx.To, "hash": hash}, func(out io.Writer) {
+		fmt.Fprintf(out, "Contract:\t%s\n", tx.To)
+		fmt.Fprintf(out, "Hash:\t%s\n", hash)
+	})
+}
+
+func contractCode() ([]byte, error) {
+	if asmFile == "" {
+		return hexutil.Decode(codeHex)
+	}
+
+	src, err := os.ReadFile(asmFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return vm.Assemble(string(src))
+}
+
+func contractCall(cmd *cobra.Command, args []string) {
+	var input []byte
+	if inputHex != "" {
+		var err error
+		if input, err = hexutil.Decode(inputHex); err != nil {
+			fail("invalid input", err)
+		}
+	}
+
+	_, hash := signAndSubmit(cmd, func(nonce uint64) (*transaction.Transaction, error) {
+		return contract.CallTransaction(from, args[0], input, amount, fee, nonce), nil
+	})
+
+	printHash(hash)
+}
+
+func contractInfo(cmd *cobra.Command, args []string) {
+	c, err := nodeClient().Contract(cmd.Context(), args[0])
+	if err != nil {
+		fail("failed to get contract", err)
+	}
+
+	printResult(c, func(out io.Writer) {
+		fmt.Fprintf(out, "Address:\t%s\n", c.Address)
+		fmt.Fprintf(out, "Creator:\t%s\n", c.Creator)
+		fmt.Fprintf(out, "Code:\t%s\n\n", c.Code)
+		fmt.Fprint(out, vm.Disassemble(c.CodeBytes()))
+	})
+}
+
+func contractStorage(cmd *cobra.Command, args []string) {
+	value, err := nodeClient().ContractStorage(cmd.Context(), args[0], args[1])
+	if err != nil {
+		fail("failed to get contract storage", err)
+	}
+
+	printResult(map[string]any{"value": value}, func(out io.Writer) {
+		fmt.Fprintln(out, value)
+	})
+}
+
+func contractLogs(cmd *cobra.Command, args []string) {
+	for _, t := range topics {
+		if t == "*" {
+			logFilter.Topics = append(logFilter.Topics, nil)
+			continue
+		}
+		logFilter.Topics = append(logFilter.Topics, strings.Split(t, ","))
+	}
+
+	logs, err := nodeClient().Logs(cmd.Context(), logFilter)
+	if err != nil {
+		fail("failed to get logs", err)
+	}
+
+	printResult(logs, func(out io.Writer) {
+		fmt.Fprintln(out, "HEIGHT\tTX\tCONTRACT\tTOPICS\tDATA")
+		for _, l := range logs {
+			fmt.Fprintf(out, "%d\t%s\t%s\t%s\t%s\n", l.BlockHeight, l.TxHash, l.Address, strings.Join(l.Topics, ","), l.Data)
+		}
+	})
+}
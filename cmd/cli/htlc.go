@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"com.perkunas/internal/models/htlc"
+	"com.perkunas/internal/models/transaction"
+	"github.com/spf13/cobra"
+)
+
+var htlcCmd = &cobra.Command{
+	Use:   "htlc",
+	Short: "Lock funds to a hash and a timeout",
+	Long: `Hash time-locked contracts hold funds until the recipient claims them with
+the secret whose SHA-256 hash they are locked to, or until the timeout passes
+and the sender takes them back. Claiming reveals the secret on-chain, which
+makes atomic swaps between two chains possible:
+
+  1. Alice runs htlc secret and locks funds for Bob on chain A
+  2. Bob locks funds for Alice on chain B with the same hash lock and an
+     earlier timeout
+  3. Alice claims on chain B, revealing the secret
+  4. Bob reads the secret with htlc info and claims on chain A`,
+}
+
+var htlcSecretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Generate a secret and its hash lock",
+	Run:   htlcSecret,
+}
+
+var htlcLockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Lock funds for a recipient",
+	Run:   htlcLock,
+}
+
+var htlcClaimCmd = &cobra.Command{
+	Use:   "claim <htlc>",
+	Short: "Claim locked funds with the secret, before the timeout",
+	Args:  cobra.ExactArgs(1),
+	Run:   htlcClaim,
+}
+
+var htlcRefundCmd = &cobra.Command{
+	Use:   "refund <htlc>",
+	Short: "Take locked funds back, after the timeout",
+	Args:  cobra.ExactArgs(1),
+	Run:   htlcRefund,
+}
+
+var htlcInfoCmd = &cobra.Command{
+	Use:   "info <htlc>",
+	Short: "Show a hash time-locked contract",
+	Args:  cobra.ExactArgs(1),
+	Run:   htlcInfo,
+}
+
+// HTLC flags
+var (
+	hashLock    string
+	htlcTimeout string
+	preimage    string
+)
+
+func init() {
+	htlcLockCmd.Flags().StringVarP(&to, "to", "t", "", "Recipient address (required)")
+	htlcLockCmd.Flags().Int64VarP(&amount, "amount", "a", 0, "Amount to lock (required)")
+	htlcLockCmd.Flags().StringVar(&hashLock, "hash-lock", "", "Hex encoded SHA-256 hash of the secret (required)")
+	htlcLockCmd.Flags().StringVar(&htlcTimeout, "timeout", "", "When the sender may take the funds back, a duration from now, RFC 3339 time or unix time (required)")
+	htlcLockCmd.MarkFlagRequired("to")
+	htlcLockCmd.MarkFlagRequired("amount")
+	htlcLockCmd.MarkFlagRequired("hash-lock")
+	htlcLockCmd.MarkFlagRequired("timeout")
+	addSigningFlags(htlcLockCmd)
+
+	htlcClaimCmd.Flags().StringVar(&preimage, "secret", "", "Hex encoded secret (required)")
+	htlcClaimCmd.MarkFlagRequired("secret")
+	addSigningFlags(htlcClaimCmd)
+
+	addSigningFlags(htlcRefundCmd)
+
+	htlcCmd.AddCommand(htlcSecretCmd)
+	htlcCmd.AddCommand(htlcLockCmd)
+	htlcCmd.AddCommand(htlcClaimCmd)
+	htlcCmd.AddCommand(htlcRefundCmd)
+	htlcCmd.AddCommand(htlcInfoCmd)
+	rootCmd.AddCommand(htlcCmd)
+}
+
+func htlcSecret(cmd *cobra.Command, args []string) {
+	secret, lock, err := htlc.NewSecret()
+	if err != nil {
+		fail("failed to generate secret", err)
+	}
+
+	printResult(map[string]any{"secret": secret, "hash_lock": lock}, func(out io.Writer) {
+		fmt.Fprintf(out, "Secret:\t%s\n", secret)
+		fmt.Fprintf(out, "Hash lock:\t%s\n", lock)
+	})
+}
+
+func htlcLock(cmd *cobra.Command, args []string) {
+	timeout, err := parseTime(htlcTimeout)
+	if err != nil {
+		fail("invalid timeout", err)
+	}
+
+	lock := htlc.Lock{Recipient: to, HashLock: hashLock, Timeout: timeout}
+	tx, hash := signAndSubmit(cmd, func(nonce uint64) (*transaction.Transaction, error) {
+		return htlc.LockTransaction(from, lock, amount, fee, nonce)
+	})
+
+	printResult(map[string]any{"htlc": tx.To, "hash": hash}, func(out io.Writer) {
+		fmt.Fprintf(out, "HTLC:\t%s\n", tx.To)
+		fmt.Fprintf(out, "Hash:\t%s\n", hash)
+	})
+}
+
+func htlcClaim(cmd *cobra.Command, args []string) {
+	h := getHTLC(cmd, args[0])
+	_, hash := signAndSubmit(cmd, func(nonce uint64) (*transaction.Transaction, error) {
+		return h.ClaimTransaction(preimage, fee, nonce)
+	})
+
+	printHash(hash)
+}
+
+func htlcRefund(cmd *cobra.Command, args []string) {
+	h := getHTLC(cmd, args[0])
+	_, hash := signAndSubmit(cmd, func(nonce uint64) (*transaction.Transaction, error) {
+		return h.RefundTransaction(fee, nonce), nil
+	})
+
+	printHash(hash)
+}
+
+func htlcInfo(cmd *cobra.Command, args []string) {
+	h := getHTLC(cmd, args[0])
+
+	printResult(h, func(out io.Writer) {
+		fmt.Fprintf(out, "Address:\t%s\n", h.Address)
+		fmt.Fprintf(out, "Sender:\t%s\n", h.Sender)
+		fmt.Fprintf(out, "Recipient:\t%s\n", h.Recipient)
+		fmt.Fprintf(out, "Amount:\t%d\n", h.Amount)
+		fmt.Fprintf(out, "Hash lock:\t%s\n", h.HashLock)
+		fmt.Fprintf(out, "Timeout:\t%s\n", time.Unix(h.Timeout, 0).UTC().Format(time.RFC3339))
+		fmt.Fprintf(out, "Status:\t%s\n", h.Status)
+		if h.Preimage != "" {
+			fmt.Fprintf(out, "Secret:\t%s\n", h.Preimage)
+		}
+	})
+}
+
+func getHTLC(cmd *cobra.Command, addr string) *htlc.HTLC {
+	h, err := nodeClient().HTLC(cmd.Context(), addr)
+	if err != nil {
+		fail("failed to get htlc", err)
+	}
+
+	return h
+}
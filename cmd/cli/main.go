@@ -2,11 +2,14 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
 	"com.perkunas/internal/models/transaction"
+	"com.perkunas/pkg/address"
 	"com.perkunas/pkg/wallet"
 	"github.com/spf13/cobra"
 )
@@ -15,6 +18,8 @@ var rootCmd = &cobra.Command{
 	Use:   "perkunas-cli",
 	Short: "Perkunas chain CLI tool",
 	Long:  "A command line interface for interacting with the Perkunas chain",
+
+	PersistentPreRunE: normalizeAddressFlags,
 }
 
 var signTxCmd = &cobra.Command{
@@ -39,7 +44,9 @@ var (
 	fee        int64
 	nonce      uint64
 	privateKey string
+	keystore   string
 	data       string
+	lockTime   string
 )
 
 func init() {
@@ -48,17 +55,20 @@ func init() {
 	signTxCmd.Flags().StringVarP(&to, "to", "t", "", "Recipient address (required)")
 	signTxCmd.Flags().Int64VarP(&amount, "amount", "a", 0, "Amount to transfer (required)")
 	signTxCmd.Flags().Int64Var(&fee, "fee", 0, "Transaction fee (required)")
-	signTxCmd.Flags().Uint64VarP(&nonce, "nonce", "n", 0, "Transaction nonce (required)")
-	signTxCmd.Flags().StringVarP(&privateKey, "private-key", "k", "", "Private key for signing (hex format, required)")
+	signTxCmd.Flags().Uint64VarP(&nonce, "nonce", "n", 0, "Transaction nonce (fetched from --node if omitted)")
+	signTxCmd.Flags().StringVarP(&privateKey, "private-key", "k", "", "Private key for signing (hex format, ends up in shell history, prefer --keystore)")
+	signTxCmd.Flags().StringVar(&keystore, "keystore", "", "Keystore file, or keystore directory holding the --from key")
+	signTxCmd.Flags().StringVar(&passwordFile, "password-file", "", "File containing the keystore password (prompted if omitted)")
 	signTxCmd.Flags().StringVarP(&data, "data", "d", "", "Additional transaction data (optional)")
+	signTxCmd.Flags().StringVar(&lockTime, "lock-time", "", "Hold the transaction until this time, a duration from now, RFC 3339 time or unix time (optional)")
 
 	// Mark required flags
 	signTxCmd.MarkFlagRequired("from")
 	signTxCmd.MarkFlagRequired("to")
 	signTxCmd.MarkFlagRequired("amount")
 	signTxCmd.MarkFlagRequired("fee")
-	signTxCmd.MarkFlagRequired("nonce")
-	signTxCmd.MarkFlagRequired("private-key")
+	signTxCmd.MarkFlagsOneRequired("private-key", "keystore")
+	signTxCmd.MarkFlagsMutuallyExclusive("private-key", "keystore")
 
 	// Add commands to root
 	rootCmd.AddCommand(signTxCmd)
@@ -66,64 +76,142 @@ func init() {
 }
 
 func signTransaction(cmd *cobra.Command, args []string) {
-	// Validate inputs
-	if amount < 0 {
-		fmt.Fprintf(os.Stderr, "Error: amount cannot be negative\n")
+	w, err := signingWallet()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	if fee < 0 {
-		fmt.Fprintf(os.Stderr, "Error: fee cannot be negative\n")
-		os.Exit(1)
+	txNonce := nonce
+	if !cmd.Flags().Changed("nonce") {
+		if txNonce, err = wallet.NewNonceManager(nodeClient()).Next(cmd.Context(), from); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to get next nonce from node, pass --nonce to sign offline: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Using nonce %d\n", txNonce)
 	}
 
-	// Create wallet from private key
-	w, err := wallet.FromPrivateKey(privateKey)
+	tx, err := newSignedTransaction(w, txNonce)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Output signed transaction as JSON
+	jsonOutput, err := json.MarshalIndent(tx, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to marshal transaction to JSON: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(string(jsonOutput))
+}
+
+// newSignedTransaction builds a transaction from the command line flags and
+// signs it with w.
+func newSignedTransaction(w *wallet.Wallet, nonce uint64) (*transaction.Transaction, error) {
+	// Validate inputs
+	if amount < 0 {
+		return nil, errors.New("amount cannot be negative")
+	}
+
+	if fee < 0 {
+		return nil, errors.New("fee cannot be negative")
+	}
+
 	// Verify that the from address matches the wallet address
 	if from != w.Address {
-		fmt.Fprintf(os.Stderr, "Error: from address %s does not match wallet address %s\n", from, w.Address)
-		os.Exit(1)
+		return nil, fmt.Errorf("from address %s does not match wallet address %s", from, w.Address)
+	}
+
+	locked, err := parseTime(lockTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lock time: %w", err)
 	}
 
 	tx := &transaction.Transaction{
-		From:      from,
-		To:        to,
-		Amount:    amount,
-		Fee:       fee,
-		Nonce:     nonce,
-		Data:      data,
-		Timestamp: time.Now().Unix(),
-		Expires:   time.Now().Add(24 * time.Hour).Unix(), // Default 24 hour expiry
+		From:     from,
+		To:       to,
+		Amount:   amount,
+		Fee:      fee,
+		Nonce:    nonce,
+		Data:     data,
+		LockTime: locked,
 	}
+	stampTransaction(tx)
 
 	// Set transaction hash
 	tx.SetHash()
 
 	// Sign transaction
 	if err := w.SignTransaction(tx); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to sign transaction: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
 	}
 
 	// Verify signature (optional check)
-	if err := tx.Verify(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: transaction verification failed: %v\n", err)
-		os.Exit(1)
+	if err := tx.Verify(chainID); err != nil {
+		return nil, fmt.Errorf("transaction verification failed: %w", err)
 	}
 
-	// Output signed transaction as JSON
-	jsonOutput, err := json.MarshalIndent(tx, "", "  ")
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to marshal transaction to JSON: %v\n", err)
-		os.Exit(1)
+	return tx, nil
+}
+
+// parseTime reads a point in time given as a duration from now, an RFC 3339
+// time or a unix time. Empty input is the zero time.
+func parseTime(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
 	}
 
-	fmt.Println(string(jsonOutput))
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(d).Unix(), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.Unix(), nil
+	}
+
+	if unix, err := strconv.ParseInt(s, 10, 64); err == nil && unix >= 0 {
+		return unix, nil
+	}
+
+	return 0, fmt.Errorf("%q is neither a duration, an RFC 3339 time nor a unix time", s)
+}
+
+// addressFlags are the flags that take an address, whichever variable a
+// command binds them to.
+var addressFlags = []string{"from", "to", "multisig", "token", "owner", "spender"}
+
+// normalizeAddressFlags rejects malformed address flags before a command runs
+// and rewrites them into the checksummed form the chain expects.
+func normalizeAddressFlags(cmd *cobra.Command, args []string) error {
+	for _, name := range addressFlags {
+		f := cmd.Flags().Lookup(name)
+		if f == nil || !f.Changed {
+			continue
+		}
+
+		addr, err := address.Parse(f.Value.String())
+		if err == nil {
+			err = f.Value.Set(addr)
+		}
+		if err != nil {
+			// main prints the error
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+			return fmt.Errorf("--%s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func signingWallet() (*wallet.Wallet, error) {
+	if keystore != "" {
+		return loadKeystoreWallet(keystore, from)
+	}
+
+	return wallet.FromPrivateKey(privateKey)
 }
 
 func generateKeys(cmd *cobra.Command, args []string) {
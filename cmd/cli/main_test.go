@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"com.perkunas/internal/models/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeAddressFlags(t *testing.T) {
+	const checksummed = "0xE07cD67682C4b43bEF6b399bb7C180D975571aaa"
+	from, pendingFrom = "", ""
+
+	// mempool binds its own --from
+	assert.NoError(t, mempoolCmd.ParseFlags([]string{"--from", strings.ToLower(checksummed)}))
+	assert.NoError(t, normalizeAddressFlags(mempoolCmd, nil))
+	assert.Equal(t, checksummed, pendingFrom)
+	assert.Empty(t, from)
+
+	assert.NoError(t, sendCmd.ParseFlags([]string{"--to", strings.ToLower(checksummed)}))
+	assert.NoError(t, normalizeAddressFlags(sendCmd, nil))
+	assert.Equal(t, checksummed, to)
+
+	assert.NoError(t, sendCmd.ParseFlags([]string{"--from", "0x1234"}))
+	assert.ErrorContains(t, normalizeAddressFlags(sendCmd, nil), "--from")
+}
+
+func TestTxChainID(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"chain_id":77}`))
+	}))
+	defer srv.Close()
+
+	nodeURL, outputFormat, chainID = srv.URL, outputTable, 0
+	t.Cleanup(func() { chainID = 0 })
+	ctx := context.Background()
+
+	// native transactions are not signed for a chain
+	id, err := txChainID(ctx, &transaction.Transaction{})
+	assert.NoError(t, err)
+	assert.Zero(t, id)
+	assert.Zero(t, calls)
+
+	id, err = txChainID(ctx, &transaction.Transaction{Raw: "0x01"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(77), id)
+
+	// --chain-id wins over the node
+	chainID = 5
+	id, err = txChainID(ctx, &transaction.Transaction{Raw: "0x01"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), id)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"com.perkunas/pkg/wallet"
+	"github.com/spf13/cobra"
+)
+
+var signMessageCmd = &cobra.Command{
+	Use:   "sign-message",
+	Short: "Sign a message",
+	Long:  "Sign an arbitrary message to prove ownership of an address. The signature is not valid for any transaction",
+	Run:   signMessage,
+}
+
+var verifyMessageCmd = &cobra.Command{
+	Use:   "verify-message",
+	Short: "Verify a signed message",
+	Long:  "Check that a message was signed by the key of an address, exits with status 1 when it was not",
+	Run:   verifyMessage,
+}
+
+// Message flags
+var (
+	message     string
+	messageFile string
+	signature   string
+	signerAddr  string
+)
+
+func init() {
+	signMessageCmd.Flags().StringVarP(&message, "message", "m", "", "Message to sign")
+	signMessageCmd.Flags().StringVar(&messageFile, "message-file", "", "File containing the message to sign")
+	signMessageCmd.Flags().StringVarP(&from, "from", "f", "", "Signer address, picks the key from a keystore directory")
+	signMessageCmd.Flags().StringVarP(&privateKey, "private-key", "k", "", "Private key for signing (hex format, ends up in shell history, prefer --keystore)")
+	signMessageCmd.Flags().StringVar(&keystore, "keystore", "", "Keystore file, or keystore directory holding the --from key")
+	signMessageCmd.Flags().StringVar(&passwordFile, "password-file", "", "File containing the keystore password (prompted if omitted)")
+	signMessageCmd.MarkFlagsOneRequired("message", "message-file")
+	signMessageCmd.MarkFlagsMutuallyExclusive("message", "message-file")
+	signMessageCmd.MarkFlagsOneRequired("private-key", "keystore")
+	signMessageCmd.MarkFlagsMutuallyExclusive("private-key", "keystore")
+
+	verifyMessageCmd.Flags().StringVarP(&message, "message", "m", "", "Signed message")
+	verifyMessageCmd.Flags().StringVar(&messageFile, "message-file", "", "File containing the signed message")
+	verifyMessageCmd.Flags().StringVar(&signerAddr, "address", "", "Expected signer address (required)")
+	verifyMessageCmd.Flags().StringVarP(&signature, "signature", "s", "", "Hex signature (required)")
+	verifyMessageCmd.MarkFlagRequired("address")
+	verifyMessageCmd.MarkFlagRequired("signature")
+	verifyMessageCmd.MarkFlagsOneRequired("message", "message-file")
+	verifyMessageCmd.MarkFlagsMutuallyExclusive("message", "message-file")
+
+	rootCmd.AddCommand(signMessageCmd)
+	rootCmd.AddCommand(verifyMessageCmd)
+}
+
+func signMessage(cmd *cobra.Command, args []string) {
+	msg, err := readMessage()
+	if err != nil {
+		fail("failed to read message", err)
+	}
+
+	w, err := signingWallet()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	sig, err := w.SignMessage(msg)
+	if err != nil {
+		fail("failed to sign message", err)
+	}
+
+	printResult(map[string]string{"address": w.Address, "signature": sig}, func(out io.Writer) {
+		fmt.Fprintf(out, "Address:\t%s\n", w.Address)
+		fmt.Fprintf(out, "Signature:\t%s\n", sig)
+	})
+}
+
+func verifyMessage(cmd *cobra.Command, args []string) {
+	msg, err := readMessage()
+	if err != nil {
+		fail("failed to read message", err)
+	}
+
+	signer, err := wallet.RecoverMessageSigner(msg, signature)
+	if err == nil {
+		err = wallet.VerifyMessage(signerAddr, msg, signature)
+	}
+
+	valid := err == nil
+	printResult(map[string]any{"valid": valid, "signer": signer}, func(out io.Writer) {
+		fmt.Fprintf(out, "Valid:\t%t\n", valid)
+		fmt.Fprintf(out, "Signer:\t%s\n", signer)
+	})
+
+	if !valid {
+		os.Exit(1)
+	}
+}
+
+func readMessage() ([]byte, error) {
+	if messageFile == "" {
+		if message == "" {
+			return nil, errors.New("message is empty")
+		}
+		return []byte(message), nil
+	}
+
+	return os.ReadFile(messageFile)
+}
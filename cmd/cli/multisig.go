@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"com.perkunas/internal/models/multisig"
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/pkg/txfile"
+	"com.perkunas/pkg/wallet"
+	"github.com/spf13/cobra"
+)
+
+var multisigCmd = &cobra.Command{
+	Use:   "multisig",
+	Short: "Manage M-of-N multisig accounts",
+	Long: `Create multisig accounts and spend from them. A spend is proposed into a
+file, passed between owners who each add their signature, and submitted once
+enough owners signed.`,
+}
+
+var multisigAddressCmd = &cobra.Command{
+	Use:   "address",
+	Short: "Compute the address of a multisig account",
+	Run:   multisigAddress,
+}
+
+var multisigCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create and fund a multisig account on-chain",
+	Run:   multisigCreate,
+}
+
+var multisigInfoCmd = &cobra.Command{
+	Use:   "info <address>",
+	Short: "Show the owners and threshold of a multisig account",
+	Args:  cobra.ExactArgs(1),
+	Run:   multisigInfo,
+}
+
+var multisigProposeCmd = &cobra.Command{
+	Use:   "propose",
+	Short: "Write an unsigned spend from a multisig account to a file",
+	Run:   multisigPropose,
+}
+
+var multisigSignCmd = &cobra.Command{
+	Use:   "sign",
+	Short: "Add an owner signature to a proposed spend",
+	Run:   multisigSign,
+}
+
+var multisigSubmitCmd = &cobra.Command{
+	Use:   "submit",
+	Short: "Submit a spend signed by enough owners",
+	Run:   txBroadcast,
+}
+
+// Multisig flags
+var (
+	owners       []string
+	threshold    int
+	multisigAddr string
+	inFile       string
+	outFile      string
+)
+
+func init() {
+	multisigAddressCmd.Flags().StringSliceVar(&owners, "owners", nil, "Comma separated owner addresses (required)")
+	multisigAddressCmd.Flags().IntVar(&threshold, "threshold", 0, "Number of owner signatures a spend needs (required)")
+	multisigAddressCmd.MarkFlagRequired("owners")
+	multisigAddressCmd.MarkFlagRequired("threshold")
+
+	multisigCreateCmd.Flags().StringSliceVar(&owners, "owners", nil, "Comma separated owner addresses (required)")
+	multisigCreateCmd.Flags().IntVar(&threshold, "threshold", 0, "Number of owner signatures a spend needs (required)")
+	multisigCreateCmd.Flags().StringVarP(&from, "from", "f", "", "Creator address paying the fee (required)")
+	multisigCreateCmd.Flags().Int64VarP(&amount, "amount", "a", 0, "Initial funding sent to the multisig account")
+	multisigCreateCmd.Flags().Int64Var(&fee, "fee", 0, "Transaction fee (required)")
+	multisigCreateCmd.Flags().StringVarP(&privateKey, "private-key", "k", "", "Private key of the creator (hex format, prefer --keystore)")
+	multisigCreateCmd.Flags().StringVar(&keystore, "keystore", "", "Keystore file, or keystore directory holding the --from key")
+	multisigCreateCmd.Flags().StringVar(&passwordFile, "password-file", "", "File containing the keystore password (prompted if omitted)")
+	multisigCreateCmd.MarkFlagRequired("owners")
+	multisigCreateCmd.MarkFlagRequired("threshold")
+	multisigCreateCmd.MarkFlagRequired("from")
+	multisigCreateCmd.MarkFlagRequired("fee")
+	multisigCreateCmd.MarkFlagsOneRequired("private-key", "keystore")
+	multisigCreateCmd.MarkFlagsMutuallyExclusive("private-key", "keystore")
+
+	multisigProposeCmd.Flags().StringVar(&multisigAddr, "multisig", "", "Multisig account to spend from (required)")
+	multisigProposeCmd.Flags().StringVarP(&to, "to", "t", "", "Recipient address (required)")
+	multisigProposeCmd.Flags().Int64VarP(&amount, "amount", "a", 0, "Amount to transfer (required)")
+	multisigProposeCmd.Flags().Int64Var(&fee, "fee", 0, "Transaction fee (required)")
+	multisigProposeCmd.Flags().Uint64VarP(&nonce, "nonce", "n", 0, "Transaction nonce (next free nonce if omitted)")
+	multisigProposeCmd.Flags().StringVarP(&data, "data", "d", "", "Additional transaction data (optional)")
+	multisigProposeCmd.Flags().StringVar(&outFile, "out", "", "File to write the proposed transaction to (required)")
+	multisigProposeCmd.MarkFlagRequired("multisig")
+	multisigProposeCmd.MarkFlagRequired("to")
+	multisigProposeCmd.MarkFlagRequired("amount")
+	multisigProposeCmd.MarkFlagRequired("fee")
+	multisigProposeCmd.MarkFlagRequired("out")
+
+	multisigSignCmd.Flags().StringVar(&inFile, "in", "", "Proposed transaction file (required)")
+	multisigSignCmd.Flags().StringVar(&outFile, "out", "", "File to write the signed transaction to (defaults to --in)")
+	multisigSignCmd.Flags().StringVarP(&from, "from", "f", "", "Owner address, picks the key from a keystore directory")
+	multisigSignCmd.Flags().StringVarP(&privateKey, "private-key", "k", "", "Private key of the owner (hex format, prefer --keystore)")
+	multisigSignCmd.Flags().StringVar(&keystore, "keystore", "", "Keystore file, or keystore directory holding the --from key")
+	multisigSignCmd.Flags().StringVar(&passwordFile, "password-file", "", "File containing the keystore password (prompted if omitted)")
+	multisigSignCmd.MarkFlagRequired("in")
+	multisigSignCmd.MarkFlagsOneRequired("private-key", "keystore")
+	multisigSignCmd.MarkFlagsMutuallyExclusive("private-key", "keystore")
+
+	multisigSubmitCmd.Flags().StringVar(&inFile, "in", "", "Signed transaction file (required)")
+	multisigSubmitCmd.Flags().BoolVarP(&wait, "wait", "w", false, "Wait until the transaction is included in a block")
+	multisigSubmitCmd.Flags().DurationVar(&waitTimeout, "timeout", 2*time.Minute, "How long to wait for inclusion")
+	multisigSubmitCmd.MarkFlagRequired("in")
+
+	multisigCmd.AddCommand(multisigAddressCmd)
+	multisigCmd.AddCommand(multisigCreateCmd)
+	multisigCmd.AddCommand(multisigInfoCmd)
+	multisigCmd.AddCommand(multisigProposeCmd)
+	multisigCmd.AddCommand(multisigSignCmd)
+	multisigCmd.AddCommand(multisigSubmitCmd)
+	rootCmd.AddCommand(multisigCmd)
+}
+
+func multisigAddress(cmd *cobra.Command, args []string) {
+	m, err := multisig.New(threshold, owners)
+	if err != nil {
+		fail("invalid multisig", err)
+	}
+
+	printMultisig(m)
+}
+
+func multisigCreate(cmd *cobra.Command, args []string) {
+	m, err := multisig.New(threshold, owners)
+	if err != nil {
+		fail("invalid multisig", err)
+	}
+
+	_, hash := signAndSubmit(cmd, func(nonce uint64) (*transaction.Transaction, error) {
+		return m.CreateTransaction(from, amount, fee, nonce)
+	})
+
+	printResult(map[string]any{"address": m.Address, "hash": hash}, func(out io.Writer) {
+		fmt.Fprintf(out, "Address:\t%s\n", m.Address)
+		fmt.Fprintf(out, "Hash:\t%s\n", hash)
+	})
+}
+
+func multisigInfo(cmd *cobra.Command, args []string) {
+	m, err := nodeClient().Multisig(cmd.Context(), args[0])
+	if err != nil {
+		fail("failed to get multisig account", err)
+	}
+
+	printMultisig(m)
+}
+
+func multisigPropose(cmd *cobra.Command, args []string) {
+	c := nodeClient()
+	ctx := cmd.Context()
+
+	m, err := c.Multisig(ctx, multisigAddr)
+	if err != nil {
+		fail("failed to get multisig account", err)
+	}
+
+	txNonce := nonce
+	if !cmd.Flags().Changed("nonce") {
+		if txNonce, err = wallet.NewNonceManager(c).Next(ctx, m.Address); err != nil {
+			fail("failed to get next nonce", err)
+		}
+	}
+
+	tx := &transaction.Transaction{
+		From:   m.Address,
+		To:     to,
+		Amount: amount,
+		Fee:    fee,
+		Nonce:  txNonce,
+		Data:   data,
+	}
+	stampTransaction(tx)
+	tx.SetHash()
+
+	if err := txfile.Write(outFile, txfile.New(tx, nil)); err != nil {
+		fail("failed to write transaction", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote %s, it needs %d of %d owner signatures\n", outFile, m.Threshold, len(m.Owners))
+}
+
+func multisigSign(cmd *cobra.Command, args []string) {
+	f, err := txfile.Read(inFile)
+	if err != nil {
+		fail("failed to read transaction", err)
+	}
+
+	if err := f.Check(); err != nil {
+		fail("refusing to sign", err)
+	}
+
+	tx := &f.Transaction
+
+	w, err := signingWallet()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := w.CoSign(tx); err != nil {
+		fail("failed to sign transaction", err)
+	}
+
+	if outFile == "" {
+		outFile = inFile
+	}
+
+	if err := txfile.Write(outFile, f); err != nil {
+		fail("failed to write transaction", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Signed by %s, %d signature(s) collected\n", w.Address, len(tx.Signatures))
+}
+
+func printMultisig(m *multisig.Multisig) {
+	printResult(m, func(out io.Writer) {
+		fmt.Fprintf(out, "Address:\t%s\n", m.Address)
+		fmt.Fprintf(out, "Threshold:\t%d of %d\n", m.Threshold, len(m.Owners))
+		fmt.Fprintf(out, "Owners:\t%s\n", strings.Join(m.Owners, ", "))
+	})
+}
+
+// stampTransaction sets the default timestamp and expiry used by sign-tx. A
+// transaction with a lock time expires a day after it unlocks.
+func stampTransaction(tx *transaction.Transaction) {
+	tx.Timestamp = time.Now().Unix()
+	if tx.Expires == 0 {
+		tx.Expires = time.Unix(max(tx.Timestamp, tx.LockTime), 0).Add(24 * time.Hour).Unix()
+	}
+}
@@ -0,0 +1,396 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/pkg/client"
+	"com.perkunas/pkg/wallet"
+	"github.com/spf13/cobra"
+)
+
+var sendCmd = &cobra.Command{
+	Use:   "send",
+	Short: "Sign and submit a transaction",
+	Long:  "Sign a transaction with the next free nonce of the sender, counting its pending transactions, and submit it to the node",
+	Run:   send,
+}
+
+var balanceCmd = &cobra.Command{
+	Use:   "balance <address>",
+	Short: "Show an account balance",
+	Args:  cobra.ExactArgs(1),
+	Run:   balance,
+}
+
+var nonceCmd = &cobra.Command{
+	Use:   "nonce <address>",
+	Short: "Show the confirmed and next nonce of an account",
+	Args:  cobra.ExactArgs(1),
+	Run:   accountNonce,
+}
+
+var txCmd = &cobra.Command{
+	Use:   "tx <hash>",
+	Short: "Show a transaction",
+	Long: `Show a transaction and the block it was included in, or PENDING while it is
+in the mempool. The subcommands build, sign and broadcast transaction files so
+keys can stay on an offline machine`,
+	Args: cobra.ExactArgs(1),
+	Run:  showTransaction,
+}
+
+var blockCmd = &cobra.Command{
+	Use:   "block [height]",
+	Short: "Show a block",
+	Long:  "Show the block at height, or the latest block when height is omitted",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   showBlock,
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the chain tip",
+	Run:   showStatus,
+}
+
+var mempoolCmd = &cobra.Command{
+	Use:   "mempool",
+	Short: "List pending transactions",
+	Run:   showMempool,
+}
+
+// Node flags
+var (
+	nodeURL      string
+	outputFormat string
+	wait         bool
+	waitTimeout  time.Duration
+	pendingFrom  string
+	feePriority  string
+	chainID      uint64
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+func init() {
+	defaultNode := os.Getenv("PERKUNAS_NODE")
+	if defaultNode == "" {
+		defaultNode = "http://localhost:8080"
+	}
+
+	rootCmd.PersistentFlags().StringVar(&nodeURL, "node", defaultNode, "Node API URL (env PERKUNAS_NODE)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputTable, "Output format: table or json")
+
+	var defaultChainID uint64
+	if v := os.Getenv("PERKUNAS_CHAIN_ID"); v != "" {
+		if id, err := strconv.ParseUint(v, 10, 64); err == nil {
+			defaultChainID = id
+		}
+	}
+	rootCmd.PersistentFlags().Uint64Var(&chainID, "chain-id", defaultChainID, "Chain id Ethereum transactions are verified against, asked from the node if unset (env PERKUNAS_CHAIN_ID)")
+
+	sendCmd.Flags().StringVarP(&from, "from", "f", "", "Sender address (required)")
+	sendCmd.Flags().StringVarP(&to, "to", "t", "", "Recipient address (required)")
+	sendCmd.Flags().Int64VarP(&amount, "amount", "a", 0, "Amount to transfer (required)")
+	sendCmd.Flags().Int64Var(&fee, "fee", 0, "Transaction fee (estimated by the node if omitted)")
+	sendCmd.Flags().StringVar(&feePriority, "priority", "medium", "Estimated fee to pay when --fee is omitted: low, medium or high")
+	sendCmd.Flags().StringVarP(&privateKey, "private-key", "k", "", "Private key for signing (hex format, ends up in shell history, prefer --keystore)")
+	sendCmd.Flags().StringVar(&keystore, "keystore", "", "Keystore file, or keystore directory holding the --from key")
+	sendCmd.Flags().StringVar(&passwordFile, "password-file", "", "File containing the keystore password (prompted if omitted)")
+	sendCmd.Flags().StringVarP(&data, "data", "d", "", "Additional transaction data (optional)")
+	sendCmd.Flags().Uint64VarP(&nonce, "nonce", "n", 0, "Transaction nonce (next free nonce if omitted)")
+	sendCmd.Flags().StringVar(&lockTime, "lock-time", "", "Hold the transaction until this time, a duration from now, RFC 3339 time or unix time (optional)")
+	sendCmd.Flags().BoolVarP(&wait, "wait", "w", false, "Wait until the transaction is included in a block")
+	sendCmd.Flags().DurationVar(&waitTimeout, "timeout", 2*time.Minute, "How long to wait for inclusion")
+
+	sendCmd.MarkFlagRequired("from")
+	sendCmd.MarkFlagRequired("to")
+	sendCmd.MarkFlagRequired("amount")
+	sendCmd.MarkFlagsOneRequired("private-key", "keystore")
+	sendCmd.MarkFlagsMutuallyExclusive("private-key", "keystore")
+
+	mempoolCmd.Flags().StringVar(&pendingFrom, "from", "", "Only list transactions sent by this address")
+
+	rootCmd.AddCommand(sendCmd)
+	rootCmd.AddCommand(balanceCmd)
+	rootCmd.AddCommand(nonceCmd)
+	rootCmd.AddCommand(txCmd)
+	rootCmd.AddCommand(blockCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(mempoolCmd)
+}
+
+func send(cmd *cobra.Command, args []string) {
+	c := nodeClient()
+	ctx := cmd.Context()
+
+	w, err := signingWallet()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !cmd.Flags().Changed("fee") {
+		if fee, err = estimatedFee(ctx, c, feePriority); err != nil {
+			fail("failed to estimate fee, pass --fee to set it", err)
+		}
+		fmt.Fprintf(os.Stderr, "Using fee %d\n", fee)
+	}
+
+	nonces := wallet.NewNonceManager(c)
+	tx, hash, err := submit(ctx, c, nonces, w, cmd.Flags().Changed("nonce"))
+	if err != nil && !cmd.Flags().Changed("nonce") && errors.Is(err, client.ErrConflict) {
+		// the node's view moved on, e.g. a pending transaction expired,
+		// so resync and try once more with a fresh nonce
+		fmt.Fprintf(os.Stderr, "Nonce rejected (%v), retrying with a fresh nonce\n", err)
+		nonces.Reset(from)
+		tx, hash, err = submit(ctx, c, nonces, w, false)
+	}
+	if err != nil {
+		fail("failed to submit transaction", err)
+	}
+
+	if !wait {
+		printResult(map[string]any{"hash": hash, "nonce": tx.Nonce}, func(w io.Writer) {
+			fmt.Fprintf(w, "Hash:\t%s\n", hash)
+			fmt.Fprintf(w, "Nonce:\t%d\n", tx.Nonce)
+		})
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Submitted %s, waiting for inclusion...\n", hash)
+	waitCtx, cancel := context.WithTimeout(ctx, waitTimeout)
+	defer cancel()
+
+	res, err := c.WaitForTransaction(waitCtx, hash, time.Second)
+	if err != nil {
+		fail("transaction was not included", err)
+	}
+
+	printTxResult(res)
+}
+
+// estimatedFee returns the node's fee estimate for the priority.
+func estimatedFee(ctx context.Context, c *client.Client, priority string) (int64, error) {
+	e, err := c.EstimateFee(ctx, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	switch priority {
+	case "low":
+		return e.Low, nil
+	case "medium":
+		return e.Medium, nil
+	case "high":
+		return e.High, nil
+	default:
+		return 0, fmt.Errorf("unknown priority %q", priority)
+	}
+}
+
+// submit signs a transaction with the --nonce flag or the next nonce of the
+// sender and sends it, giving the nonce back when the node rejects it.
+func submit(ctx context.Context, c *client.Client, nonces *wallet.NonceManager, w *wallet.Wallet, fixedNonce bool) (*transaction.Transaction, string, error) {
+	txNonce := nonce
+	if !fixedNonce {
+		var err error
+		if txNonce, err = nonces.Next(ctx, from); err != nil {
+			return nil, "", fmt.Errorf("failed to get next nonce: %w", err)
+		}
+	}
+
+	tx, err := newSignedTransaction(w, txNonce)
+	if err != nil {
+		return nil, "", err
+	}
+
+	hash, err := c.SendTransaction(ctx, tx)
+	if err != nil {
+		nonces.Release(from, txNonce)
+		return nil, "", err
+	}
+
+	return tx, hash, nil
+}
+
+func balance(cmd *cobra.Command, args []string) {
+	acc, err := nodeClient().Account(cmd.Context(), args[0])
+	if err != nil {
+		fail("failed to get account", err)
+	}
+
+	printResult(acc, func(w io.Writer) {
+		fmt.Fprintf(w, "Address:\t%s\n", args[0])
+		fmt.Fprintf(w, "Balance:\t%d\n", acc.Balance)
+	})
+}
+
+func accountNonce(cmd *cobra.Command, args []string) {
+	c := nodeClient()
+
+	confirmed, err := c.ConfirmedNonce(cmd.Context(), args[0])
+	if err != nil {
+		fail("failed to get account", err)
+	}
+
+	pending, err := c.PendingNonces(cmd.Context(), args[0])
+	if err != nil {
+		fail("failed to get pending transactions", err)
+	}
+
+	next := wallet.NextNonce(confirmed, pending)
+	printResult(map[string]any{"address": args[0], "nonce": confirmed, "pending": len(pending), "next": next}, func(w io.Writer) {
+		fmt.Fprintf(w, "Address:\t%s\n", args[0])
+		fmt.Fprintf(w, "Nonce:\t%d\n", confirmed)
+		fmt.Fprintf(w, "Pending:\t%d\n", len(pending))
+		fmt.Fprintf(w, "Next:\t%d\n", next)
+	})
+}
+
+func showTransaction(cmd *cobra.Command, args []string) {
+	res, err := nodeClient().Transaction(cmd.Context(), args[0])
+	if err != nil {
+		fail("failed to get transaction", err)
+	}
+
+	printTxResult(res)
+}
+
+func showBlock(cmd *cobra.Command, args []string) {
+	c := nodeClient()
+	ctx := cmd.Context()
+
+	var height uint64
+	if len(args) == 1 {
+		h, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			fail("invalid height", err)
+		}
+		height = h
+	} else {
+		tip, err := c.Status(ctx)
+		if err != nil {
+			fail("failed to get latest block", err)
+		}
+		height = tip.Height
+	}
+
+	b, err := c.Block(ctx, height)
+	if err != nil {
+		fail("failed to get block", err)
+	}
+
+	printResult(b, func(w io.Writer) {
+		fmt.Fprintf(w, "Height:\t%d\n", b.Height)
+		fmt.Fprintf(w, "Hash:\t%s\n", b.Hash)
+		fmt.Fprintf(w, "Parent:\t%s\n", b.PrevHash)
+		fmt.Fprintf(w, "Time:\t%s\n", time.Unix(b.Timestamp, 0).UTC().Format(time.RFC3339))
+		fmt.Fprintf(w, "Miner:\t%s\n", b.Miner)
+		fmt.Fprintf(w, "State root:\t%s\n", b.StateRoot)
+		fmt.Fprintf(w, "Transactions:\t%d\n", len(b.Transactions))
+		if len(b.Transactions) > 0 {
+			fmt.Fprintln(w)
+			printTxTable(w, b.Transactions)
+		}
+	})
+}
+
+func showStatus(cmd *cobra.Command, args []string) {
+	tip, err := nodeClient().Status(cmd.Context())
+	if err != nil {
+		fail("failed to get status", err)
+	}
+
+	printResult(tip, func(w io.Writer) {
+		fmt.Fprintf(w, "Node:\t%s\n", nodeURL)
+		fmt.Fprintf(w, "Height:\t%d\n", tip.Height)
+		fmt.Fprintf(w, "Hash:\t%s\n", tip.Hash)
+		fmt.Fprintf(w, "Time:\t%s\n", time.Unix(tip.Timestamp, 0).UTC().Format(time.RFC3339))
+	})
+}
+
+func showMempool(cmd *cobra.Command, args []string) {
+	txs, err := nodeClient().Mempool(cmd.Context(), pendingFrom)
+	if err != nil {
+		fail("failed to get mempool", err)
+	}
+
+	printResult(txs, func(w io.Writer) {
+		printTxTable(w, txs)
+	})
+}
+
+func printTxResult(res *client.TxResult) {
+	printResult(res, func(w io.Writer) {
+		tx := res.Transaction
+		fmt.Fprintf(w, "Hash:\t%s\n", tx.Hash)
+		fmt.Fprintf(w, "Status:\t%s\n", res.Status)
+		if res.Error != "" {
+			fmt.Fprintf(w, "Error:\t%s\n", res.Error)
+		}
+		if res.Status != client.StatusPending {
+			fmt.Fprintf(w, "Block:\t%d %s\n", res.Height, res.BlockHash)
+		}
+		fmt.Fprintf(w, "From:\t%s\n", tx.From)
+		fmt.Fprintf(w, "To:\t%s\n", tx.To)
+		fmt.Fprintf(w, "Amount:\t%d\n", tx.Amount)
+		fmt.Fprintf(w, "Fee:\t%d\n", tx.Fee)
+		fmt.Fprintf(w, "Nonce:\t%d\n", tx.Nonce)
+		if res.GasUsed > 0 {
+			fmt.Fprintf(w, "Gas used:\t%d\n", res.GasUsed)
+		}
+		for i, l := range res.Logs {
+			fmt.Fprintf(w, "Log %d:\t%s topics %v data %s\n", i, l.Address, l.Topics, l.Data)
+		}
+	})
+}
+
+func printTxTable(w io.Writer, txs []*transaction.Transaction) {
+	fmt.Fprintln(w, "HASH\tFROM\tTO\tAMOUNT\tFEE\tNONCE")
+	for _, tx := range txs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\n", tx.Hash, tx.From, tx.To, tx.Amount, tx.Fee, tx.Nonce)
+	}
+}
+
+func nodeClient() *client.Client {
+	if outputFormat != outputTable && outputFormat != outputJSON {
+		fmt.Fprintf(os.Stderr, "Error: unknown output format %q, use table or json\n", outputFormat)
+		os.Exit(1)
+	}
+
+	return client.New(nodeURL)
+}
+
+// printResult writes v as JSON or, in table mode, whatever table writes.
+func printResult(v any, table func(w io.Writer)) {
+	if outputFormat == outputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(v); err != nil {
+			fail("failed to encode output", err)
+		}
+		return
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	table(tw)
+	tw.Flush()
+}
+
+func fail(msg string, err error) {
+	fmt.Fprintf(os.Stderr, "Error: %s: %v\n", msg, err)
+	os.Exit(1)
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"com.perkunas/internal/models/token"
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/pkg/wallet"
+	"github.com/spf13/cobra"
+)
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Issue and transfer fungible tokens",
+	Long: `Issue tokens with a symbol, decimals and fixed supply, transfer them and
+allow other addresses to transfer them on your behalf. Amounts are given in
+whole tokens, e.g. 12.5 of a token with 2 decimals.`,
+}
+
+var tokenCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Issue a token, the whole supply goes to the creator",
+	Run:   tokenCreate,
+}
+
+var tokenTransferCmd = &cobra.Command{
+	Use:   "transfer",
+	Short: "Transfer tokens, from an owner who approved you with --owner",
+	Run:   tokenTransfer,
+}
+
+var tokenApproveCmd = &cobra.Command{
+	Use:   "approve",
+	Short: "Allow a spender to transfer up to an amount of your tokens",
+	Run:   tokenApprove,
+}
+
+var tokenInfoCmd = &cobra.Command{
+	Use:   "info <token>",
+	Short: "Show a token",
+	Args:  cobra.ExactArgs(1),
+	Run:   tokenInfo,
+}
+
+var tokenBalanceCmd = &cobra.Command{
+	Use:   "balance <address>",
+	Short: "Show the token balances of an address",
+	Args:  cobra.ExactArgs(1),
+	Run:   tokenBalance,
+}
+
+var tokenAllowanceCmd = &cobra.Command{
+	Use:   "allowance",
+	Short: "Show how many tokens a spender may transfer for an owner",
+	Run:   tokenAllowance,
+}
+
+// Token flags
+var (
+	tokenAddr   string
+	tokenAmount string
+	symbol      string
+	tokenName   string
+	decimals    uint8
+	supply      string
+	owner       string
+	spender     string
+)
+
+func init() {
+	tokenCreateCmd.Flags().StringVar(&symbol, "symbol", "", "Token symbol, 1 to 12 upper case letters or digits (required)")
+	tokenCreateCmd.Flags().StringVar(&tokenName, "name", "", "Token name")
+	tokenCreateCmd.Flags().Uint8Var(&decimals, "decimals", 0, "Number of decimals")
+	tokenCreateCmd.Flags().StringVar(&supply, "supply", "", "Total supply in whole tokens (required)")
+	tokenCreateCmd.MarkFlagRequired("symbol")
+	tokenCreateCmd.MarkFlagRequired("supply")
+	addSigningFlags(tokenCreateCmd)
+
+	tokenTransferCmd.Flags().StringVar(&tokenAddr, "token", "", "Token address (required)")
+	tokenTransferCmd.Flags().StringVarP(&to, "to", "t", "", "Recipient address (required)")
+	tokenTransferCmd.Flags().StringVarP(&tokenAmount, "amount", "a", "", "Amount in whole tokens (required)")
+	tokenTransferCmd.Flags().StringVar(&owner, "owner", "", "Transfer tokens of this owner using its allowance")
+	tokenTransferCmd.MarkFlagRequired("token")
+	tokenTransferCmd.MarkFlagRequired("to")
+	tokenTransferCmd.MarkFlagRequired("amount")
+	addSigningFlags(tokenTransferCmd)
+
+	tokenApproveCmd.Flags().StringVar(&tokenAddr, "token", "", "Token address (required)")
+	tokenApproveCmd.Flags().StringVar(&spender, "spender", "", "Address allowed to transfer your tokens (required)")
+	tokenApproveCmd.Flags().StringVarP(&tokenAmount, "amount", "a", "", "Allowance in whole tokens, 0 revokes it (required)")
+	tokenApproveCmd.MarkFlagRequired("token")
+	tokenApproveCmd.MarkFlagRequired("spender")
+	tokenApproveCmd.MarkFlagRequired("amount")
+	addSigningFlags(tokenApproveCmd)
+
+	tokenBalanceCmd.Flags().StringVar(&tokenAddr, "token", "", "Only show this token")
+
+	tokenAllowanceCmd.Flags().StringVar(&tokenAddr, "token", "", "Token address (required)")
+	tokenAllowanceCmd.Flags().StringVar(&owner, "owner", "", "Token owner (required)")
+	tokenAllowanceCmd.Flags().StringVar(&spender, "spender", "", "Spender (required)")
+	tokenAllowanceCmd.MarkFlagRequired("token")
+	tokenAllowanceCmd.MarkFlagRequired("owner")
+	tokenAllowanceCmd.MarkFlagRequired("spender")
+
+	tokenCmd.AddCommand(tokenCreateCmd)
+	tokenCmd.AddCommand(tokenTransferCmd)
+	tokenCmd.AddCommand(tokenApproveCmd)
+	tokenCmd.AddCommand(tokenInfoCmd)
+	tokenCmd.AddCommand(tokenBalanceCmd)
+	tokenCmd.AddCommand(tokenAllowanceCmd)
+	rootCmd.AddCommand(tokenCmd)
+}
+
+func tokenCreate(cmd *cobra.Command, args []string) {
+	total, err := token.ParseAmount(supply, decimals)
+	if err != nil {
+		fail("invalid supply", err)
+	}
+
+	def := token.Definition{Symbol: symbol, Name: tokenName, Decimals: decimals, Supply: total}
+	tx, hash := signAndSubmit(cmd, func(nonce uint64) (*transaction.Transaction, error) {
+		return token.CreateTransaction(from, def, fee, nonce)
+	})
+
+	printResult(map[string]any{"token": tx.To, "hash": hash}, func(out io.Writer) {
+		fmt.Fprintf(out, "Token:\t%s\n", tx.To)
+		fmt.Fprintf(out, "Hash:\t%s\n", hash)
+	})
+}
+
+func tokenTransfer(cmd *cobra.Command, args []string) {
+	units := tokenUnits(cmd)
+	_, hash := signAndSubmit(cmd, func(nonce uint64) (*transaction.Transaction, error) {
+		return token.TransferTransaction(from, to, token.Transfer{Token: tokenAddr, Amount: units, Owner: owner}, fee, nonce)
+	})
+
+	printHash(hash)
+}
+
+func tokenApprove(cmd *cobra.Command, args []string) {
+	units := tokenUnits(cmd)
+	_, hash := signAndSubmit(cmd, func(nonce uint64) (*transaction.Transaction, error) {
+		return token.ApproveTransaction(from, spender, token.Transfer{Token: tokenAddr, Amount: units}, fee, nonce)
+	})
+
+	printHash(hash)
+}
+
+func tokenInfo(cmd *cobra.Command, args []string) {
+	t, err := nodeClient().Token(cmd.Context(), args[0])
+	if err != nil {
+		fail("failed to get token", err)
+	}
+
+	printResult(t, func(out io.Writer) {
+		fmt.Fprintf(out, "Address:\t%s\n", t.Address)
+		fmt.Fprintf(out, "Symbol:\t%s\n", t.Symbol)
+		fmt.Fprintf(out, "Name:\t%s\n", t.Name)
+		fmt.Fprintf(out, "Decimals:\t%d\n", t.Decimals)
+		fmt.Fprintf(out, "Supply:\t%s\n", token.FormatAmount(t.Supply, t.Decimals))
+		fmt.Fprintf(out, "Creator:\t%s\n", t.Creator)
+	})
+}
+
+func tokenBalance(cmd *cobra.Command, args []string) {
+	c := nodeClient()
+
+	var balances []token.Balance
+	if tokenAddr != "" {
+		b, err := c.TokenBalance(cmd.Context(), tokenAddr, args[0])
+		if err != nil {
+			fail("failed to get token balance", err)
+		}
+		balances = []token.Balance{*b}
+	} else {
+		var err error
+		if balances, err = c.TokenBalances(cmd.Context(), args[0]); err != nil {
+			fail("failed to get token balances", err)
+		}
+	}
+
+	printResult(balances, func(out io.Writer) {
+		fmt.Fprintln(out, "TOKEN\tSYMBOL\tBALANCE")
+		for _, b := range balances {
+			fmt.Fprintf(out, "%s\t%s\t%s\n", b.Token, b.Symbol, token.FormatAmount(b.Balance, b.Decimals))
+		}
+	})
+}
+
+func tokenAllowance(cmd *cobra.Command, args []string) {
+	c := nodeClient()
+
+	t, err := c.Token(cmd.Context(), tokenAddr)
+	if err != nil {
+		fail("failed to get token", err)
+	}
+
+	amount, err := c.TokenAllowance(cmd.Context(), tokenAddr, owner, spender)
+	if err != nil {
+		fail("failed to get token allowance", err)
+	}
+
+	printResult(map[string]any{"token": tokenAddr, "owner": owner, "spender": spender, "amount": amount}, func(out io.Writer) {
+		fmt.Fprintf(out, "Allowance:\t%s %s\n", token.FormatAmount(amount, t.Decimals), t.Symbol)
+	})
+}
+
+// addSigningFlags adds the flags signAndSubmit needs.
+func addSigningFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&from, "from", "f", "", "Sender address (required)")
+	cmd.Flags().Int64Var(&fee, "fee", 0, "Transaction fee (required)")
+	cmd.Flags().StringVarP(&privateKey, "private-key", "k", "", "Private key of the sender (hex format, prefer --keystore)")
+	cmd.Flags().StringVar(&keystore, "keystore", "", "Keystore file, or keystore directory holding the --from key")
+	cmd.Flags().StringVar(&passwordFile, "password-file", "", "File containing the keystore password (prompted if omitted)")
+	cmd.MarkFlagRequired("from")
+	cmd.MarkFlagRequired("fee")
+	cmd.MarkFlagsOneRequired("private-key", "keystore")
+	cmd.MarkFlagsMutuallyExclusive("private-key", "keystore")
+}
+
+// tokenUnits converts the --amount flag into base units of the --token.
+func tokenUnits(cmd *cobra.Command) int64 {
+	t, err := nodeClient().Token(cmd.Context(), tokenAddr)
+	if err != nil {
+		fail("failed to get token", err)
+	}
+
+	units, err := token.ParseAmount(tokenAmount, t.Decimals)
+	if err != nil {
+		fail("invalid amount", err)
+	}
+
+	return units
+}
+
+// signAndSubmit signs the transaction build returns for the next nonce of
+// the --from account and submits it.
+func signAndSubmit(cmd *cobra.Command, build func(nonce uint64) (*transaction.Transaction, error)) (*transaction.Transaction, string) {
+	w, err := signingWallet()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	c := nodeClient()
+	ctx := cmd.Context()
+	nonces := wallet.NewNonceManager(c)
+
+	txNonce, err := nonces.Next(ctx, w.Address)
+	if err != nil {
+		fail("failed to get next nonce", err)
+	}
+
+	tx, err := build(txNonce)
+	if err != nil {
+		fail("failed to build transaction", err)
+	}
+	stampTransaction(tx)
+
+	if err := w.SignTransaction(tx); err != nil {
+		fail("failed to sign transaction", err)
+	}
+
+	hash, err := c.SendTransaction(ctx, tx)
+	if err != nil {
+		nonces.Release(w.Address, txNonce)
+		fail("failed to submit transaction", err)
+	}
+
+	return tx, hash
+}
+
+func printHash(hash string) {
+	printResult(map[string]any{"hash": hash}, func(out io.Writer) {
+		fmt.Fprintf(out, "Hash:\t%s\n", hash)
+	})
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/pkg/txfile"
+	"com.perkunas/pkg/wallet"
+	"github.com/spf13/cobra"
+)
+
+var txBuildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Build an unsigned transaction file",
+	Long: `Build an unsigned transaction with the next free nonce of the sender and the
+fee estimated by the node, and write it to a file for an offline signer`,
+	Run: txBuild,
+}
+
+var txSignCmd = &cobra.Command{
+	Use:   "sign",
+	Short: "Sign a transaction file offline",
+	Long: `Sign a transaction file without contacting a node. Spends from a multisig
+account get an owner signature added instead`,
+	Run: txSign,
+}
+
+var txInspectCmd = &cobra.Command{
+	Use:   "inspect <file>",
+	Short: "Show the contents and signatures of a transaction file",
+	Args:  cobra.ExactArgs(1),
+	Run:   txInspect,
+}
+
+var txBroadcastCmd = &cobra.Command{
+	Use:   "broadcast",
+	Short: "Submit a signed transaction file",
+	Run:   txBroadcast,
+}
+
+// Offline signing flags
+var expiresIn time.Duration
+
+func init() {
+	txBuildCmd.Flags().StringVarP(&from, "from", "f", "", "Sender address (required)")
+	txBuildCmd.Flags().StringVarP(&to, "to", "t", "", "Recipient address (required)")
+	txBuildCmd.Flags().Int64VarP(&amount, "amount", "a", 0, "Amount to transfer (required)")
+	txBuildCmd.Flags().Int64Var(&fee, "fee", 0, "Transaction fee (estimated by the node if omitted)")
+	txBuildCmd.Flags().StringVar(&feePriority, "priority", "medium", "Estimated fee to pay when --fee is omitted: low, medium or high")
+	txBuildCmd.Flags().Uint64VarP(&nonce, "nonce", "n", 0, "Transaction nonce (next free nonce if omitted)")
+	txBuildCmd.Flags().StringVarP(&data, "data", "d", "", "Additional transaction data (optional)")
+	txBuildCmd.Flags().DurationVar(&expiresIn, "expires", 24*time.Hour, "How long the transaction stays valid, counted from its lock time")
+	txBuildCmd.Flags().StringVar(&lockTime, "lock-time", "", "Hold the transaction until this time, a duration from now, RFC 3339 time or unix time (optional)")
+	txBuildCmd.Flags().StringVar(&outFile, "out", "", "File to write the transaction to (stdout if omitted)")
+	txBuildCmd.MarkFlagRequired("from")
+	txBuildCmd.MarkFlagRequired("to")
+	txBuildCmd.MarkFlagRequired("amount")
+
+	txSignCmd.Flags().StringVar(&inFile, "in", "", "Transaction file (required)")
+	txSignCmd.Flags().StringVar(&outFile, "out", "", "File to write the signed transaction to (defaults to --in)")
+	txSignCmd.Flags().StringVarP(&from, "from", "f", "", "Signer address, picks the key from a keystore directory (defaults to the sender)")
+	txSignCmd.Flags().StringVarP(&privateKey, "private-key", "k", "", "Private key for signing (hex format, prefer --keystore)")
+	txSignCmd.Flags().StringVar(&keystore, "keystore", "", "Keystore file, or keystore directory holding the signer key")
+	txSignCmd.Flags().StringVar(&passwordFile, "password-file", "", "File containing the keystore password (prompted if omitted)")
+	txSignCmd.MarkFlagRequired("in")
+	txSignCmd.MarkFlagsOneRequired("private-key", "keystore")
+	txSignCmd.MarkFlagsMutuallyExclusive("private-key", "keystore")
+
+	txBroadcastCmd.Flags().StringVar(&inFile, "in", "", "Signed transaction file (required)")
+	txBroadcastCmd.Flags().BoolVarP(&wait, "wait", "w", false, "Wait until the transaction is included in a block")
+	txBroadcastCmd.Flags().DurationVar(&waitTimeout, "timeout", 2*time.Minute, "How long to wait for inclusion")
+	txBroadcastCmd.MarkFlagRequired("in")
+
+	txCmd.AddCommand(txBuildCmd)
+	txCmd.AddCommand(txSignCmd)
+	txCmd.AddCommand(txInspectCmd)
+	txCmd.AddCommand(txBroadcastCmd)
+}
+
+func txBuild(cmd *cobra.Command, args []string) {
+	if amount < 0 || fee < 0 {
+		fail("invalid transaction", errors.New("amount and fee cannot be negative"))
+	}
+
+	locked, err := parseTime(lockTime)
+	if err != nil {
+		fail("invalid lock time", err)
+	}
+
+	c := nodeClient()
+	ctx := cmd.Context()
+
+	confirmed, err := c.ConfirmedNonce(ctx, from)
+	if err != nil {
+		fail("failed to get account", err)
+	}
+
+	pending, err := c.PendingNonces(ctx, from)
+	if err != nil {
+		fail("failed to get pending transactions", err)
+	}
+
+	// recorded in the file even when --fee overrides it
+	suggested, err := estimatedFee(ctx, c, feePriority)
+	if err != nil {
+		fail("failed to estimate fee", err)
+	}
+
+	txNonce := nonce
+	if !cmd.Flags().Changed("nonce") {
+		txNonce = wallet.NextNonce(confirmed, pending)
+	}
+
+	txFee := fee
+	if !cmd.Flags().Changed("fee") {
+		txFee = suggested
+	}
+
+	now := time.Now()
+	tx := &transaction.Transaction{
+		From:      from,
+		To:        to,
+		Amount:    amount,
+		Fee:       txFee,
+		Nonce:     txNonce,
+		Data:      data,
+		Timestamp: now.Unix(),
+		Expires:   time.Unix(max(now.Unix(), locked), 0).Add(expiresIn).Unix(),
+		LockTime:  locked,
+	}
+	tx.SetHash()
+
+	f := txfile.New(tx, &txfile.Meta{
+		Node:           nodeURL,
+		BuiltAt:        now.Unix(),
+		ConfirmedNonce: confirmed,
+		PendingTxs:     len(pending),
+		SuggestedFee:   suggested,
+	})
+
+	if outFile == "" {
+		b, err := txfile.Marshal(f)
+		if err != nil {
+			fail("failed to encode transaction", err)
+		}
+		os.Stdout.Write(b)
+		return
+	}
+
+	if err := txfile.Write(outFile, f); err != nil {
+		fail("failed to write transaction", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote %s with nonce %d and fee %d\n", outFile, tx.Nonce, tx.Fee)
+}
+
+// txChainID returns the chain id to verify tx against. Only Ethereum
+// transactions are signed for a chain, the node is asked for its chain id
+// when they come without --chain-id, other transactions stay offline.
+func txChainID(ctx context.Context, tx *transaction.Transaction) (uint64, error) {
+	if chainID != 0 || tx.Raw == "" {
+		return chainID, nil
+	}
+
+	cfg, err := nodeClient().ChainConfig(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	chainID = cfg.ChainID
+	return chainID, nil
+}
+
+func txSign(cmd *cobra.Command, args []string) {
+	f, err := txfile.Read(inFile)
+	if err != nil {
+		fail("failed to read transaction", err)
+	}
+
+	if err := f.Check(); err != nil {
+		fail("refusing to sign", err)
+	}
+
+	tx := &f.Transaction
+	if from == "" {
+		from = tx.From
+	}
+
+	w, err := signingWallet()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if strings.EqualFold(w.Address, tx.From) {
+		if tx.Signature != "" {
+			fail("refusing to sign", errors.New("transaction is already signed"))
+		}
+		err = w.SignTransaction(tx)
+	} else {
+		err = w.CoSign(tx)
+	}
+	if err != nil {
+		fail("failed to sign transaction", err)
+	}
+
+	id, err := txChainID(cmd.Context(), tx)
+	if err != nil {
+		fail("failed to get the chain id, pass --chain-id", err)
+	}
+
+	if err := tx.Verify(id); err != nil {
+		fail("signed transaction does not verify", err)
+	}
+
+	if outFile == "" {
+		outFile = inFile
+	}
+
+	if err := txfile.Write(outFile, f); err != nil {
+		fail("failed to write transaction", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Signed %s by %s\n", tx.Hash, w.Address)
+}
+
+// txInspection is the JSON form of tx inspect.
+type txInspection struct {
+	*txfile.File
+	HashValid bool     `json:"hash_valid"`
+	Expired   bool     `json:"expired"`
+	Signers   []string `json:"signers"`
+	Error     string   `json:"error,omitempty"`
+}
+
+func txInspect(cmd *cobra.Command, args []string) {
+	f, err := txfile.Read(args[0])
+	if err != nil {
+		fail("failed to read transaction", err)
+	}
+
+	tx := &f.Transaction
+	res := txInspection{
+		File:      f,
+		HashValid: f.Check() == nil,
+		Expired:   tx.Expires != 0 && tx.Expires < time.Now().Unix(),
+		Signers:   []string{},
+	}
+
+	if f.Signed() {
+		if id, err := txChainID(cmd.Context(), tx); err != nil {
+			res.Error = fmt.Sprintf("failed to get the chain id, pass --chain-id: %v", err)
+		} else if err := tx.Verify(id); err != nil {
+			res.Error = err.Error()
+		} else if res.Signers, err = tx.Signers(); err != nil {
+			res.Error = err.Error()
+		}
+	}
+
+	printResult(res, func(w io.Writer) {
+		fmt.Fprintf(w, "Version:\t%d\n", f.Version)
+		fmt.Fprintf(w, "Hash:\t%s (%s)\n", tx.Hash, validity(res.HashValid))
+		if tx.Type != transaction.TypeTransfer {
+			fmt.Fprintf(w, "Type:\t%s\n", tx.Type)
+		}
+		fmt.Fprintf(w, "From:\t%s\n", tx.From)
+		fmt.Fprintf(w, "To:\t%s\n", tx.To)
+		fmt.Fprintf(w, "Amount:\t%d\n", tx.Amount)
+		fmt.Fprintf(w, "Fee:\t%d\n", tx.Fee)
+		fmt.Fprintf(w, "Nonce:\t%d\n", tx.Nonce)
+		if tx.Data != "" {
+			fmt.Fprintf(w, "Data:\t%s\n", tx.Data)
+		}
+		fmt.Fprintf(w, "Timestamp:\t%s\n", time.Unix(tx.Timestamp, 0).UTC().Format(time.RFC3339))
+		if tx.LockTime != 0 {
+			fmt.Fprintf(w, "Locked until:\t%s\n", time.Unix(tx.LockTime, 0).UTC().Format(time.RFC3339))
+		}
+		fmt.Fprintf(w, "Expires:\t%s", time.Unix(tx.Expires, 0).UTC().Format(time.RFC3339))
+		if res.Expired {
+			fmt.Fprint(w, " (expired)")
+		}
+		fmt.Fprintln(w)
+
+		switch {
+		case !f.Signed():
+			fmt.Fprintf(w, "Signed:\tno\n")
+		case res.Error != "":
+			fmt.Fprintf(w, "Signed:\tinvalid (%s)\n", res.Error)
+		default:
+			fmt.Fprintf(w, "Signed:\t%s\n", strings.Join(res.Signers, ", "))
+		}
+
+		if m := f.Meta; m != nil {
+			fmt.Fprintf(w, "Built from:\t%s at %s\n", m.Node, time.Unix(m.BuiltAt, 0).UTC().Format(time.RFC3339))
+			fmt.Fprintf(w, "Node nonce:\t%d confirmed, %d pending\n", m.ConfirmedNonce, m.PendingTxs)
+			fmt.Fprintf(w, "Suggested fee:\t%d\n", m.SuggestedFee)
+		}
+	})
+}
+
+func txBroadcast(cmd *cobra.Command, args []string) {
+	f, err := txfile.Read(inFile)
+	if err != nil {
+		fail("failed to read transaction", err)
+	}
+
+	if !f.Signed() {
+		fail("refusing to broadcast", errors.New("transaction is not signed"))
+	}
+
+	c := nodeClient()
+	ctx := cmd.Context()
+
+	hash, err := c.SendTransaction(ctx, &f.Transaction)
+	if err != nil {
+		fail("failed to submit transaction", err)
+	}
+
+	if !wait {
+		printResult(map[string]any{"hash": hash}, func(out io.Writer) {
+			fmt.Fprintf(out, "Hash:\t%s\n", hash)
+		})
+		return
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, waitTimeout)
+	defer cancel()
+
+	res, err := c.WaitForTransaction(waitCtx, hash, time.Second)
+	if err != nil {
+		fail("transaction was not included", err)
+	}
+
+	printTxResult(res)
+}
+
+func validity(ok bool) string {
+	if ok {
+		return "valid"
+	}
+	return "MISMATCH"
+}
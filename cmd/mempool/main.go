@@ -9,8 +9,10 @@ import (
 	"os"
 
 	"com.perkunas/internal/db"
+	"com.perkunas/internal/feed"
 	"com.perkunas/internal/logger"
 	"com.perkunas/internal/models/transaction"
+	"com.perkunas/proto"
 )
 
 var (
@@ -36,6 +38,7 @@ func main() {
 	mempoolSvc := &Mempool{
 		log:     log,
 		txModel: transaction.Model{DB: db},
+		txFeed:  &feed.Feed[*proto.Transaction]{},
 	}
 
 	cleanupJob := mempoolSvc.SpawnCleanupJob(ctx)
@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
 	"time"
 
+	"com.perkunas/internal/feed"
 	"com.perkunas/internal/models/transaction"
 	"com.perkunas/internal/scheduler"
 	"com.perkunas/proto"
@@ -20,6 +23,7 @@ type Mempool struct {
 	proto.UnimplementedMempoolServiceServer
 	log     *slog.Logger
 	txModel transaction.Model
+	txFeed  *feed.Feed[*proto.Transaction]
 	apiPort string
 }
 
@@ -31,11 +35,18 @@ func (mp *Mempool) CreateMempool(ctx context.Context, in *proto.CreateMempoolReq
 	}
 
 	pld := transaction.FromProtoTx(tx)
+	if err := pld.ValidateAddresses(); err != nil {
+		mp.log.Error("rejected transaction with invalid address", "hash", pld.Hash, "err", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	if err := mp.txModel.Save(ctx, pld); err != nil {
 		mp.log.Error("failed saving transaction in mempool", "err", err)
 		return nil, status.Error(codes.Internal, "failed persisting transaction")
 	}
 
+	mp.txFeed.Publish(tx)
+
 	return &proto.CreateMempoolResponse{Hash: pld.Hash}, nil
 }
 
@@ -49,14 +60,68 @@ func (mp *Mempool) DeleteMempoolBatch(ctx context.Context, in *proto.DeleteMempo
 }
 
 func (mp *Mempool) PendingTransactions(ctx context.Context, in *proto.PendingTransactionsRequest) (*proto.PendingTransactionsResponse, error) {
-	txs, err := mp.txModel.Pending(ctx)
+	txs, err := mp.txModel.Pending(ctx, in.GetExecutableAt(), in.GetFrom())
 	if err != nil {
 		mp.log.Error("failed getting pending transactions", "err", err)
 		return nil, status.Error(codes.Internal, "failed getting pending transactions")
 	}
 
+	total, err := mp.txModel.CountPending(ctx, in.GetExecutableAt(), in.GetFrom())
+	if err != nil {
+		mp.log.Error("failed counting pending transactions", "err", err)
+		return nil, status.Error(codes.Internal, "failed counting pending transactions")
+	}
+
 	protoTxs := transaction.ToProtoTxs(txs)
-	return &proto.PendingTransactionsResponse{Transactions: protoTxs}, nil
+	return &proto.PendingTransactionsResponse{Transactions: protoTxs, Total: total}, nil
+}
+
+func (mp *Mempool) GetTransaction(ctx context.Context, in *proto.GetTransactionRequest) (*proto.GetTransactionResponse, error) {
+	tx, err := mp.txModel.GetByHash(ctx, in.GetHash())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "transaction not found")
+	}
+	if err != nil {
+		mp.log.Error("failed getting transaction", "err", err, "hash", in.GetHash())
+		return nil, status.Error(codes.Internal, "failed getting transaction")
+	}
+
+	return &proto.GetTransactionResponse{Transaction: transaction.ToProtoTx(tx)}, nil
+}
+
+// GetDropped reports why a transaction left the mempool without being mined.
+func (mp *Mempool) GetDropped(ctx context.Context, in *proto.GetDroppedRequest) (*proto.DroppedTransaction, error) {
+	dropped, err := mp.txModel.GetDropped(ctx, in.GetHash())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "dropped transaction not found")
+	}
+	if err != nil {
+		mp.log.Error("failed getting dropped transaction", "err", err, "hash", in.GetHash())
+		return nil, status.Error(codes.Internal, "failed getting dropped transaction")
+	}
+
+	return &proto.DroppedTransaction{Hash: dropped.Hash, Reason: dropped.Reason, DroppedAt: dropped.DroppedAt}, nil
+}
+
+// SubscribeTransactions streams transactions as they enter the mempool.
+func (mp *Mempool) SubscribeTransactions(in *proto.SubscribeTransactionsRequest, stream proto.MempoolService_SubscribeTransactionsServer) error {
+	sub := mp.txFeed.Subscribe()
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+
+		case tx, ok := <-sub.C:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "subscriber fell behind")
+			}
+			if err := stream.Send(tx); err != nil {
+				return err
+			}
+		}
+	}
 }
 
 func (mp *Mempool) SpawnCleanupJob(ctx context.Context) *scheduler.Job {
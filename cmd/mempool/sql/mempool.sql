@@ -1,16 +1,32 @@
 CREATE TABLE IF NOT EXISTS mempool(
   id INTEGER PRIMARY KEY AUTOINCREMENT,
   hash TEXT,
+  type TEXT NOT NULL DEFAULT '',
   from_addr TEXT NOT NULL,
   to_addr TEXT NOT NULL,
+  data TEXT NOT NULL DEFAULT '',
   signature TEXT NOT NULL,
+  signatures TEXT NOT NULL DEFAULT '[]',
   fee INTEGER NOT NULL,
   amount INTEGER NOT NULL,
   nonce INTEGER NOT NULL,
   timestamp INTEGER NOT NULL DEFAULT (strftime('%s', 'now')),
-  expires INTEGER NOT NULL DEFAULT (strftime('%s', 'now') + 1500)
+  expires INTEGER NOT NULL DEFAULT (strftime('%s', 'now') + 1500),
+  lock_time INTEGER NOT NULL DEFAULT 0,
+  raw TEXT NOT NULL DEFAULT ''
 );
 
 CREATE INDEX IF NOT EXISTS idx_mempool_hash ON mempool(hash);
 CREATE INDEX IF NOT EXISTS idx_mempool_fee ON mempool(fee DESC);
 CREATE INDEX IF NOT EXISTS idx_mempool_expires ON mempool(expires);
+CREATE INDEX IF NOT EXISTS idx_mempool_from ON mempool(from_addr COLLATE NOCASE, nonce);
+
+-- Transactions that left the mempool without being mined, kept for a day so
+-- clients can learn why
+CREATE TABLE IF NOT EXISTS dropped(
+  hash TEXT PRIMARY KEY,
+  reason TEXT NOT NULL,
+  dropped_at INTEGER NOT NULL DEFAULT (strftime('%s', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_dropped_at ON dropped(dropped_at);
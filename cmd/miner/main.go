@@ -2,12 +2,18 @@ package main
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"flag"
 	"log/slog"
 	"os"
+	"time"
 
+	"com.perkunas/internal/assembler"
+	"com.perkunas/internal/consensus"
 	"com.perkunas/internal/logger"
+	"com.perkunas/internal/models/chainconfig"
 	"com.perkunas/proto"
+	"github.com/ethereum/go-ethereum/crypto"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -16,6 +22,7 @@ func main() {
 	m := &Miner{log: logger.WithJSONFormat().With(slog.String("scope", "miner-svc"))}
 	flag.StringVar(&m.mempoolAPI, "mempoolapi", os.Getenv("MEMPOOL_API"), "mempool api endpoint")
 	flag.StringVar(&m.stateAPI, "stateapi", os.Getenv("STATE_API"), "state api endpoint")
+	flag.StringVar(&m.signerKey, "signerkey", os.Getenv("SIGNER_KEY"), "hex private key used to author blocks")
 
 	// initiate mempool rpc client
 	mempoolConn, mempoolClient, err := mempoolRPCClient(m.mempoolAPI)
@@ -36,12 +43,58 @@ func main() {
 	m.stateRPC = stateClient
 
 	ctx := context.Background()
+
+	// build consensus engine from chain config served by state
+	cfgRes, err := proto.NewConfigServiceClient(stateConn).GetChainConfig(ctx, &proto.GetChainConfigRequest{})
+	if err != nil {
+		m.log.Error("failed getting chain config", "err", err)
+		os.Exit(1)
+	}
+
+	cfg := chainconfig.FromProto(cfgRes.GetConfig())
+	if err := m.setupConsensus(cfg); err != nil {
+		m.log.Error("failed setting up consensus engine", "err", err, "consensus", cfg.Consensus)
+		os.Exit(1)
+	}
+
+	m.assembler = &assembler.Assembler{
+		Log:      m.log,
+		MaxTxs:   cfg.MaxTxPerBlock,
+		MaxBytes: cfg.MaxBlockSize,
+		ChainID:  cfg.ChainID,
+		Accounts: m.getAccount,
+	}
+
 	if err := m.Start(ctx); err != nil {
 		m.log.Error("failed to start the miner", "err", err)
 		os.Exit(1)
 	}
 }
 
+func (m *Miner) setupConsensus(cfg chainconfig.ChainConfig) error {
+	var key *ecdsa.PrivateKey
+	if m.signerKey != "" {
+		k, err := crypto.HexToECDSA(m.signerKey)
+		if err != nil {
+			return err
+		}
+		key = k
+	}
+
+	engine, err := consensus.New(cfg, key)
+	if err != nil {
+		return err
+	}
+
+	m.engine = engine
+	m.sealEmpty = cfg.Consensus == chainconfig.ConsensusPoA
+	if cfg.BlockTime > 0 {
+		m.blockTime = time.Duration(cfg.BlockTime) * time.Second
+	}
+
+	return nil
+}
+
 func mempoolRPCClient(apiUrl string) (*grpc.ClientConn, proto.MempoolServiceClient, error) {
 	conn, err := grpc.NewClient(apiUrl, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"log/slog"
-	"strings"
 	"time"
 
+	"com.perkunas/internal/assembler"
+	"com.perkunas/internal/consensus"
+	"com.perkunas/internal/errmsg"
 	"com.perkunas/internal/models/block"
 	"com.perkunas/internal/models/transaction"
 	"com.perkunas/proto"
@@ -16,40 +18,45 @@ type Miner struct {
 	log        *slog.Logger
 	mempoolAPI string
 	stateAPI   string
+	signerKey  string
+	blockTime  time.Duration
+	engine     consensus.Engine
+	// sealEmpty keeps proof-of-authority blocks on schedule when the mempool
+	// is empty, proof-of-work only mines blocks with transactions
+	sealEmpty  bool
+	assembler  *assembler.Assembler
 	mempoolRPC proto.MempoolServiceClient
 	stateRPC   proto.StateServiceClient
 }
 
 type MiningCandidate struct {
-	PrevBlock  *proto.Block
-	Txs        []*transaction.Transaction
-	Difficulty uint64
-	Nonce      uint64
-	Timestamp  int64
-}
-
-func (m *Miner) getCurrentDifficulty() uint64 {
-	// Implement logic to get current difficulty
-	return 1
+	PrevBlock *proto.Block
+	Txs       []*transaction.Transaction
+	Timestamp int64
 }
 
 func (m *Miner) Start(ctx context.Context) error {
+	if m.blockTime == 0 {
+		m.blockTime = 20 * time.Second
+	}
+
 	for {
-		time.Sleep(20 * time.Second)
+		time.Sleep(m.blockTime)
 		select {
 		case <-ctx.Done():
 			return nil
 
 		default:
 			// 1. get pending transactions from mempool
-			pendTxs, err := m.mempoolRPC.PendingTransactions(ctx, nil)
+			// locked txs stay in the mempool until they are executable
+			pendTxs, err := m.mempoolRPC.PendingTransactions(ctx, &proto.PendingTransactionsRequest{ExecutableAt: time.Now().Unix()})
 			if err != nil {
 				m.log.Error("failed getting pending transactions", "err", err)
 				continue
 			}
 
 			txs := pendTxs.GetTransactions()
-			if len(txs) == 0 {
+			if len(txs) == 0 && !m.sealEmpty {
 				m.log.Info("no transactions in mempool")
 				continue
 			}
@@ -63,14 +70,17 @@ func (m *Miner) Start(ctx context.Context) error {
 
 			// 3. create candidate block
 			candidate := &MiningCandidate{
-				PrevBlock:  prevBlock.GetBlock(),
-				Txs:        transaction.FromProtoTxs(txs),
-				Difficulty: m.getCurrentDifficulty(),
-				Timestamp:  time.Now().Unix(),
+				PrevBlock: prevBlock.GetBlock(),
+				Txs:       transaction.FromProtoTxs(txs),
+				Timestamp: time.Now().Unix(),
 			}
 
-			// 4. mine block (find valid nonce)
+			// 4. mine block (seal with consensus engine)
 			newBlock, err := m.mineBlock(ctx, candidate)
+			if errors.Is(err, errmsg.ErrNotInTurn) {
+				m.log.Info("another signer is in turn", "height", candidate.PrevBlock.GetHeight()+1)
+				continue
+			}
 			if err != nil {
 				m.log.Error("failed to mine block", "err", err)
 				continue
@@ -91,106 +101,66 @@ func (m *Miner) Start(ctx context.Context) error {
 }
 
 func (m *Miner) mineBlock(ctx context.Context, mc *MiningCandidate) (*block.Block, error) {
-	// 1. validate transactions
-	validTxs := m.validateTransactions(ctx, mc.Txs)
-	if len(validTxs) == 0 {
+	// 1. select valid transactions within block limits
+	validTxs := m.assembler.Assemble(ctx, mc.Txs)
+	if len(validTxs) == 0 && !m.sealEmpty {
 		return nil, errors.New("no valid transactions found")
 	}
 
 	// 2. TODO: create block with mining reward
-	block := &block.Block{
-		PrevHash:     mc.PrevBlock.Hash,
-		Height:       mc.PrevBlock.Height + 1,
+	parent := block.FromProtoBlock(mc.PrevBlock)
+	newBlock := &block.Block{
+		PrevHash:     parent.Hash,
+		Height:       parent.Height + 1,
 		Timestamp:    mc.Timestamp,
 		Transactions: validTxs,
-		Difficulty:   mc.Difficulty,
 	}
 
-	// 3. find valid nonce (Proof of Work?)
-	for nonce := uint64(0); ; nonce++ {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
-			block.Nonce = nonce
-			hash, err := block.CalculateHash()
-			if err != nil {
-				return nil, err
-			}
-			block.Hash = hash
-
-			if isHashValid(block.Hash, block.Difficulty) {
-				return block, nil
-			}
-		}
+	// 3. let consensus engine fill in author/difficulty
+	if err := m.engine.Prepare(&parent, newBlock); err != nil {
+		return nil, err
 	}
-}
-
-func (m *Miner) validateTransactions(ctx context.Context, txs []*transaction.Transaction) []*transaction.Transaction {
-	validTxs := make([]*transaction.Transaction, 0)
-
-	// track used nonces to prevent double-spending within same block
-	usedNonces := make(map[string]uint64) // address -> nonce
-
-	for _, tx := range txs {
-		// skip invalid transactions but continue processing others
-		if err := tx.Verify(); err != nil {
-			m.log.Warn("invalid transaction skipped", "hash", tx.Hash, "error", err)
-			continue
-		}
 
-		// check sender balance
-		fromAccountRes, err := m.stateRPC.GetAccountByAddress(ctx, &proto.AccountByAddressReq{Address: tx.From})
-		if err != nil {
-			m.log.Error("failed getting account by address", "address", tx.From, "err", err)
-			continue
-		}
-
-		fromAcc := fromAccountRes.GetAccount()
-		if fromAcc == nil {
-			m.log.Info("account not found by address", "addr", tx.From)
-			continue
-		}
-
-		if fromAcc.GetBalance() < tx.Amount+tx.Fee {
-			m.log.Info("insufficient balance", "addr", tx.From, "balance", fromAcc.GetBalance(), "amount", tx.Amount+tx.Fee)
-			continue
-		}
+	// 4. commit to the state resulting from applying the block
+	stateRoot, err := m.stateRPC.PreviewStateRoot(ctx, &proto.CreateBlockReq{Block: toProtoBlock(newBlock)})
+	if err != nil {
+		return nil, err
+	}
+	newBlock.StateRoot = stateRoot.GetStateRoot()
 
-		// check nonce
-		if tx.Nonce != fromAcc.GetNonce()+1 {
-			m.log.Info("invalid tx nonce", "txNonce", tx.Nonce, "accNonce", fromAcc.GetNonce()+1)
-			continue
-		}
+	// 5. seal the block
+	if err := m.engine.Seal(ctx, newBlock); err != nil {
+		return nil, err
+	}
 
-		if lastNonce, exists := usedNonces[tx.From]; exists && tx.Nonce <= lastNonce {
-			m.log.Info("nonce is smaller or equal to previously used one", "txNonce", tx.Nonce, "lastUsedNonce", lastNonce)
-			continue
-		}
+	return newBlock, nil
+}
 
-		usedNonces[tx.From] = tx.Nonce
-		validTxs = append(validTxs, tx)
+func (m *Miner) getAccount(ctx context.Context, addr string) (*proto.Account, error) {
+	res, err := m.stateRPC.GetAccountByAddress(ctx, &proto.AccountByAddressReq{Address: addr})
+	if err != nil {
+		return nil, err
 	}
 
-	return validTxs
+	return res.GetAccount(), nil
 }
 
 func (m *Miner) persistBlock(ctx context.Context, b *block.Block) error {
-	_, err := m.stateRPC.CreateBlock(ctx, &proto.CreateBlockReq{
-		Block: &proto.Block{
-			Hash:         b.Hash,
-			Height:       b.Height,
-			PrevHash:     b.PrevHash,
-			MerkleRoot:   b.MerkleRoot,
-			Timestamp:    b.Timestamp,
-			Transactions: transaction.ToProtoTxs(b.Transactions),
-		},
-	})
-
+	_, err := m.stateRPC.CreateBlock(ctx, &proto.CreateBlockReq{Block: toProtoBlock(b)})
 	return err
 }
 
+func toProtoBlock(b *block.Block) *proto.Block {
+	pb := block.ToProtoBlock(*b)
+	pb.Transactions = transaction.ToProtoTxs(b.Transactions)
+	return pb
+}
+
 func (m *Miner) deleteTxs(ctx context.Context, b *block.Block) error {
+	if len(b.Transactions) == 0 {
+		return nil
+	}
+
 	var idsToDelete []int64
 	for _, tx := range b.Transactions {
 		idsToDelete = append(idsToDelete, tx.ID)
@@ -199,11 +169,3 @@ func (m *Miner) deleteTxs(ctx context.Context, b *block.Block) error {
 	_, err := m.mempoolRPC.DeleteMempoolBatch(ctx, &proto.DeleteMempoolBatchRequest{Ids: idsToDelete})
 	return err
 }
-
-func isHashValid(hash string, difficulty uint64) bool {
-	// Convert difficulty to required leading zeros
-	prefix := strings.Repeat("0", int(difficulty))
-
-	// Check if hash starts with required number of zeros
-	return strings.HasPrefix(hash, prefix)
-}
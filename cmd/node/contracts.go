@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+
+	"com.perkunas/internal/errmsg"
+	"com.perkunas/internal/httpjsonres"
+	"com.perkunas/internal/models/contract"
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/pkg/address"
+	"com.perkunas/proto"
+	"github.com/ethereum/go-ethereum/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// verifyContract rejects malformed contract transactions and calls of
+// contracts that do not exist before they reach the mempool, the state
+// service rejects a block calling an unknown contract.
+func (n *Node) verifyContract(ctx context.Context, txn *transaction.Transaction) error {
+	if !contract.IsContractTransaction(txn) {
+		return nil
+	}
+
+	if err := contract.Verify(txn); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if txn.Type == transaction.TypeContractDeploy {
+		return nil
+	}
+
+	_, err := n.stateRPC.GetContract(ctx, &proto.ContractReq{Address: txn.To})
+	if status.Code(err) == codes.NotFound {
+		return status.Error(codes.InvalidArgument, errmsg.ErrUnknownContract.Error())
+	}
+
+	return err
+}
+
+func (n *Node) contractByAddress(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	addr, err := address.Parse(r.PathValue("address"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res, err := n.stateRPC.GetContract(r.Context(), &proto.ContractReq{Address: addr})
+	if err != nil {
+		n.log.Error("could not get contract", "err", err)
+		http.Error(w, "could not get contract", rpcErrStatus(err))
+		return
+	}
+
+	if err := httpjsonres.JSON(w, http.StatusOK, res.GetContract()); err != nil {
+		n.log.Error("failed responding to get contract request", "err", err)
+	}
+}
+
+func (n *Node) contractStorage(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	addr, err := address.Parse(r.PathValue("address"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// keys are words, decimal or 0x prefixed hex
+	key, ok := new(big.Int).SetString(r.PathValue("key"), 0)
+	if !ok || key.Sign() < 0 || key.BitLen() > 256 {
+		http.Error(w, "invalid storage key", http.StatusBadRequest)
+		return
+	}
+
+	res, err := n.stateRPC.GetStorage(r.Context(), &proto.StorageReq{Address: addr, Key: common.BigToHash(key).Hex()})
+	if err != nil {
+		n.log.Error("could not get contract storage", "err", err)
+		http.Error(w, "could not get contract storage", rpcErrStatus(err))
+		return
+	}
+
+	if err := httpjsonres.JSON(w, http.StatusOK, res); err != nil {
+		n.log.Error("failed responding to get contract storage request", "err", err)
+	}
+}
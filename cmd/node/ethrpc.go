@@ -0,0 +1,750 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"com.perkunas/internal/bloom"
+	"com.perkunas/internal/models/contract"
+	"com.perkunas/internal/models/eventlog"
+	"com.perkunas/internal/models/receipt"
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/pkg/address"
+	"com.perkunas/pkg/wallet"
+	"com.perkunas/proto"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// JSON-RPC 2.0 error codes.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcServerError    = -32000
+)
+
+// maxRPCBody caps the size of a JSON-RPC request or batch.
+const maxRPCBody = 1 << 20
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+func invalidParams(format string, args ...any) *rpcError {
+	return &rpcError{Code: rpcInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+// ethRPC serves a subset of the Ethereum JSON-RPC API over the state and
+// mempool services, single requests and batches alike. Native transactions
+// are presented with a gas price of 1 and their fee as gas, so costs add
+// up the same for Ethereum tooling.
+func (n *Node) ethRPC(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRPCBody))
+	if err != nil {
+		n.log.Error("could not read request body", "err", err)
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	var res any
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil {
+			res = rpcFailure(nil, &rpcError{Code: rpcParseError, Message: "parse error"})
+		} else if len(batch) == 0 {
+			res = rpcFailure(nil, &rpcError{Code: rpcInvalidRequest, Message: "empty batch"})
+		} else {
+			responses := make([]*rpcResponse, 0, len(batch))
+			for _, msg := range batch {
+				if resp := n.handleRPC(r.Context(), msg); resp != nil {
+					responses = append(responses, resp)
+				}
+			}
+			if len(responses) > 0 {
+				res = responses
+			}
+		}
+	} else if resp := n.handleRPC(r.Context(), body); resp != nil {
+		res = resp
+	}
+
+	// notifications get no response
+	if res == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		n.log.Error("failed responding to json-rpc request", "err", err)
+	}
+}
+
+// handleRPC answers a single request, nil for notifications.
+func (n *Node) handleRPC(ctx context.Context, msg json.RawMessage) *rpcResponse {
+	var req rpcRequest
+	if err := json.Unmarshal(msg, &req); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return rpcFailure(nil, &rpcError{Code: rpcParseError, Message: "parse error"})
+		}
+		return rpcFailure(nil, &rpcError{Code: rpcInvalidRequest, Message: "invalid request"})
+	}
+
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return rpcFailure(req.ID, &rpcError{Code: rpcInvalidRequest, Message: "invalid request"})
+	}
+
+	result, err := n.callRPC(ctx, req.Method, req.Params)
+	if req.ID == nil {
+		return nil
+	}
+
+	if err != nil {
+		var rpcErr *rpcError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &rpcError{Code: rpcServerError, Message: status.Convert(err).Message()}
+		}
+		return rpcFailure(req.ID, rpcErr)
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		n.log.Error("failed encoding json-rpc result", "method", req.Method, "err", err)
+		return rpcFailure(req.ID, &rpcError{Code: rpcServerError, Message: "failed encoding result"})
+	}
+
+	return &rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: b}
+}
+
+func rpcFailure(id json.RawMessage, err *rpcError) *rpcResponse {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+
+	return &rpcResponse{JSONRPC: "2.0", ID: id, Error: err}
+}
+
+func (n *Node) callRPC(ctx context.Context, method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "eth_chainId":
+		return n.ethChainID(ctx)
+	case "eth_blockNumber":
+		lb, err := n.stateRPC.GetLatestBlock(ctx, &proto.LastBlockReq{})
+		if err != nil {
+			return nil, err
+		}
+		return hexutil.Uint64(lb.GetBlock().GetHeight()), nil
+	case "eth_getBalance":
+		return n.ethGetBalance(ctx, params)
+	case "eth_getTransactionCount":
+		return n.ethGetTransactionCount(ctx, params)
+	case "eth_getBlockByNumber":
+		return n.ethGetBlockByNumber(ctx, params)
+	case "eth_getTransactionByHash":
+		return n.ethGetTransactionByHash(ctx, params)
+	case "eth_getTransactionReceipt":
+		return n.ethGetTransactionReceipt(ctx, params)
+	case "eth_sendRawTransaction":
+		return n.ethSendRawTransaction(ctx, params)
+	default:
+		return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("method %s does not exist", method)}
+	}
+}
+
+// parseParams decodes positional params into out, the first required of
+// them must be present.
+func parseParams(raw json.RawMessage, required int, out ...any) error {
+	var params []json.RawMessage
+	if len(raw) > 0 && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return invalidParams("params must be an array")
+		}
+	}
+
+	if len(params) < required || len(params) > len(out) {
+		return invalidParams("expected %d to %d params, got %d", required, len(out), len(params))
+	}
+
+	for i, p := range params {
+		if err := json.Unmarshal(p, out[i]); err != nil {
+			return invalidParams("invalid param %d: %v", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (n *Node) ethChainID(ctx context.Context) (hexutil.Uint64, error) {
+	chainID, err := n.chainID(ctx)
+	return hexutil.Uint64(chainID), err
+}
+
+// blockHeight resolves a block tag or hex number. State is only kept for
+// mined blocks, so pending, safe and finalized all mean the latest block.
+func (n *Node) blockHeight(ctx context.Context, tag string) (uint64, error) {
+	switch tag {
+	case "", "latest", "pending", "safe", "finalized":
+		lb, err := n.stateRPC.GetLatestBlock(ctx, &proto.LastBlockReq{})
+		if err != nil {
+			return 0, err
+		}
+		return lb.GetBlock().GetHeight(), nil
+	case "earliest":
+		return 0, nil
+	}
+
+	height, err := hexutil.DecodeUint64(tag)
+	if err != nil {
+		return 0, invalidParams("invalid block number %q", tag)
+	}
+
+	return height, nil
+}
+
+// accountAt returns the balance and nonce of addr at a block tag, the
+// latest state is read directly, older blocks from the account proofs.
+func (n *Node) accountAt(ctx context.Context, addr, tag string) (int64, uint64, error) {
+	if tag == "" || tag == "latest" || tag == "pending" {
+		res, err := n.stateRPC.GetAccountByAddress(ctx, &proto.AccountByAddressReq{Address: addr})
+		if status.Code(err) == codes.NotFound {
+			return 0, 0, nil
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		return res.GetAccount().GetBalance(), res.GetAccount().GetNonce(), nil
+	}
+
+	height, err := n.blockHeight(ctx, tag)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	res, err := n.stateRPC.GetAccountProof(ctx, &proto.AccountProofReq{Address: addr, Height: height})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return res.GetBalance(), res.GetNonce(), nil
+}
+
+func (n *Node) ethGetBalance(ctx context.Context, params json.RawMessage) (any, error) {
+	var addr, tag string
+	if err := parseParams(params, 1, &addr, &tag); err != nil {
+		return nil, err
+	}
+
+	addr, err := address.Parse(addr)
+	if err != nil {
+		return nil, invalidParams("%v", err)
+	}
+
+	balance, _, err := n.accountAt(ctx, addr, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return (*hexutil.Big)(big.NewInt(balance)), nil
+}
+
+// ethGetTransactionCount is the next Ethereum nonce of an account, which is
+// the nonce of its last transaction since ours start at 1. The pending tag
+// counts the transactions waiting in the mempool.
+func (n *Node) ethGetTransactionCount(ctx context.Context, params json.RawMessage) (any, error) {
+	var addr, tag string
+	if err := parseParams(params, 1, &addr, &tag); err != nil {
+		return nil, err
+	}
+
+	addr, err := address.Parse(addr)
+	if err != nil {
+		return nil, invalidParams("%v", err)
+	}
+
+	_, nonce, err := n.accountAt(ctx, addr, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	if tag == "pending" {
+		pending, err := n.pendingNonces(ctx, addr)
+		if err != nil {
+			return nil, err
+		}
+		nonce = wallet.NextNonce(nonce, pending) - 1
+	}
+
+	return hexutil.Uint64(nonce), nil
+}
+
+type ethBlock struct {
+	Number           hexutil.Uint64   `json:"number"`
+	Hash             string           `json:"hash"`
+	ParentHash       string           `json:"parentHash"`
+	Nonce            types.BlockNonce `json:"nonce"`
+	Sha3Uncles       common.Hash      `json:"sha3Uncles"`
+	LogsBloom        string           `json:"logsBloom"`
+	TransactionsRoot string           `json:"transactionsRoot"`
+	StateRoot        string           `json:"stateRoot"`
+	ReceiptsRoot     common.Hash      `json:"receiptsRoot"`
+	Miner            string           `json:"miner"`
+	Difficulty       hexutil.Uint64   `json:"difficulty"`
+	ExtraData        hexutil.Bytes    `json:"extraData"`
+	Size             hexutil.Uint64   `json:"size"`
+	GasLimit         hexutil.Uint64   `json:"gasLimit"`
+	GasUsed          hexutil.Uint64   `json:"gasUsed"`
+	Timestamp        hexutil.Uint64   `json:"timestamp"`
+	Transactions     []any            `json:"transactions"`
+	Uncles           []string         `json:"uncles"`
+}
+
+// ethGetBlockByNumber returns the block with transaction hashes, or the
+// full transactions when the second param is true.
+func (n *Node) ethGetBlockByNumber(ctx context.Context, params json.RawMessage) (any, error) {
+	var (
+		tag  string
+		full bool
+	)
+	if err := parseParams(params, 1, &tag, &full); err != nil {
+		return nil, err
+	}
+
+	height, err := n.blockHeight(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := n.stateRPC.GetBlockByHeight(ctx, &proto.BlockByHeightReq{Height: height, WithTransactions: true})
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	b := res.GetBlock()
+	logs, err := n.blockLogs(ctx, b.GetHeight())
+	if err != nil {
+		return nil, err
+	}
+	blockBloom := eventlog.NewBlockLogs(b.GetHeight(), b.GetHash(), logs).Bloom
+
+	miner := b.GetMiner()
+	if miner == "" {
+		miner = common.Address{}.Hex()
+	}
+
+	out := &ethBlock{
+		Number:           hexutil.Uint64(b.GetHeight()),
+		Hash:             ethHash(b.GetHash()),
+		ParentHash:       ethHash(b.GetPrevHash()),
+		Nonce:            types.EncodeNonce(b.GetNonce()),
+		Sha3Uncles:       types.EmptyUncleHash,
+		LogsBloom:        blockBloom.Hex(),
+		TransactionsRoot: ethHash(b.GetMerkleRoot()),
+		StateRoot:        ethHash(b.GetStateRoot()),
+		ReceiptsRoot:     types.EmptyReceiptsHash,
+		Miner:            miner,
+		Difficulty:       hexutil.Uint64(b.GetDifficulty()),
+		ExtraData:        hexutil.Bytes{},
+		Timestamp:        hexutil.Uint64(b.GetTimestamp()),
+		Transactions:     make([]any, 0, len(b.GetTransactions())),
+		Uncles:           []string{},
+	}
+
+	for i, tx := range b.GetTransactions() {
+		if !full {
+			out.Transactions = append(out.Transactions, ethHash(tx.GetHash()))
+			continue
+		}
+
+		etx, err := newEthTransaction(transaction.FromProtoTx(tx))
+		if err != nil {
+			return nil, err
+		}
+		etx.setBlock(b, i)
+		out.Transactions = append(out.Transactions, etx)
+	}
+
+	return out, nil
+}
+
+// blockLogs returns the logs of the block at height in order.
+func (n *Node) blockLogs(ctx context.Context, height uint64) ([]eventlog.Log, error) {
+	return n.findLogs(ctx, &proto.LogsReq{FromHeight: height, ToHeight: height, Limit: eventlog.MaxLogs})
+}
+
+// txLogs returns the logs of the transaction hash mined at height in order.
+func (n *Node) txLogs(ctx context.Context, height uint64, hash string) ([]eventlog.Log, error) {
+	return n.findLogs(ctx, &proto.LogsReq{FromHeight: height, ToHeight: height, TxHash: hash, Limit: eventlog.MaxLogs})
+}
+
+func (n *Node) findLogs(ctx context.Context, req *proto.LogsReq) ([]eventlog.Log, error) {
+	// the genesis block has no logs and a to height of 0 means latest
+	if req.GetToHeight() == 0 {
+		return nil, nil
+	}
+
+	res, err := n.stateRPC.GetLogs(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	logs := make([]eventlog.Log, 0, len(res.GetLogs()))
+	for _, l := range res.GetLogs() {
+		logs = append(logs, eventlog.FromProto(l))
+	}
+
+	return logs, nil
+}
+
+type ethTransaction struct {
+	Hash             string          `json:"hash"`
+	Nonce            hexutil.Uint64  `json:"nonce"`
+	BlockHash        *string         `json:"blockHash"`
+	BlockNumber      *hexutil.Uint64 `json:"blockNumber"`
+	TransactionIndex *hexutil.Uint64 `json:"transactionIndex"`
+	From             string          `json:"from"`
+	To               *string         `json:"to"`
+	Value            *hexutil.Big    `json:"value"`
+	Gas              hexutil.Uint64  `json:"gas"`
+	GasPrice         *hexutil.Big    `json:"gasPrice"`
+	Input            hexutil.Bytes   `json:"input"`
+	Type             hexutil.Uint64  `json:"type"`
+	ChainID          *hexutil.Big    `json:"chainId,omitempty"`
+	V                *hexutil.Big    `json:"v"`
+	R                *hexutil.Big    `json:"r"`
+	S                *hexutil.Big    `json:"s"`
+}
+
+// newEthTransaction presents tx as an Ethereum transaction. Transactions
+// sent with eth_sendRawTransaction keep their original gas, type and
+// signature, native ones get the fee as gas at a gas price of 1.
+func newEthTransaction(tx transaction.Transaction) (*ethTransaction, error) {
+	out := &ethTransaction{
+		Hash:     ethHash(tx.Hash),
+		Nonce:    hexutil.Uint64(tx.Nonce - 1),
+		From:     tx.From,
+		Value:    (*hexutil.Big)(big.NewInt(tx.Amount)),
+		Gas:      hexutil.Uint64(tx.Fee),
+		GasPrice: (*hexutil.Big)(big.NewInt(1)),
+		Input:    ethInput(tx.Data),
+		V:        new(hexutil.Big),
+		R:        new(hexutil.Big),
+		S:        new(hexutil.Big),
+	}
+
+	if tx.Type != transaction.TypeContractDeploy {
+		to := tx.To
+		out.To = &to
+	}
+
+	if tx.Raw != "" {
+		etx, err := tx.EthereumTx()
+		if err != nil {
+			return nil, err
+		}
+
+		v, r, s := etx.RawSignatureValues()
+		out.Gas = hexutil.Uint64(etx.Gas())
+		out.GasPrice = (*hexutil.Big)(etx.GasFeeCap())
+		out.Input = etx.Data()
+		out.Type = hexutil.Uint64(etx.Type())
+		out.V, out.R, out.S = (*hexutil.Big)(v), (*hexutil.Big)(r), (*hexutil.Big)(s)
+		if etx.Protected() {
+			out.ChainID = (*hexutil.Big)(etx.ChainId())
+		}
+		return out, nil
+	}
+
+	// signatures are r, s and a recovery id of 0 or 1
+	if sig, err := hex.DecodeString(tx.Signature); err == nil && len(sig) == 65 {
+		out.R = (*hexutil.Big)(new(big.Int).SetBytes(sig[:32]))
+		out.S = (*hexutil.Big)(new(big.Int).SetBytes(sig[32:64]))
+		out.V = (*hexutil.Big)(big.NewInt(int64(sig[64]) + 27))
+	}
+
+	return out, nil
+}
+
+func (t *ethTransaction) setBlock(b *proto.Block, index int) {
+	hash := ethHash(b.GetHash())
+	height := hexutil.Uint64(b.GetHeight())
+	i := hexutil.Uint64(index)
+	t.BlockHash, t.BlockNumber, t.TransactionIndex = &hash, &height, &i
+}
+
+// ethInput decodes hex data, other data such as token or multisig
+// definitions is returned as its bytes.
+func ethInput(data string) hexutil.Bytes {
+	if b, err := hexutil.Decode(data); err == nil {
+		return b
+	}
+
+	return []byte(data)
+}
+
+func ethHash(h string) string {
+	if h == "" {
+		return common.Hash{}.Hex()
+	}
+
+	return "0x" + strings.TrimPrefix(h, "0x")
+}
+
+// minedTransaction looks up a mined transaction and its block, with the
+// receipts of the block when withReceipts is set, nil when it is not mined.
+func (n *Node) minedTransaction(ctx context.Context, hash string, withReceipts bool) (*proto.TransactionRes, *proto.BlockByHeightRes, int, error) {
+	res, err := n.stateRPC.GetTransaction(ctx, &proto.TransactionReq{Hash: hash})
+	if status.Code(err) == codes.NotFound {
+		return nil, nil, 0, nil
+	}
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	b, err := n.stateRPC.GetBlockByHeight(ctx, &proto.BlockByHeightReq{Height: res.GetHeight(), WithTransactions: true, WithReceipts: withReceipts})
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	for i, tx := range b.GetBlock().GetTransactions() {
+		if tx.GetHash() == hash {
+			return res, b, i, nil
+		}
+	}
+
+	return nil, nil, 0, status.Error(codes.Internal, "transaction missing from block")
+}
+
+func txHashParam(params json.RawMessage) (string, error) {
+	var hash string
+	if err := parseParams(params, 1, &hash); err != nil {
+		return "", err
+	}
+
+	hash = strings.ToLower(strings.TrimPrefix(hash, "0x"))
+	if _, err := hex.DecodeString(hash); err != nil || len(hash) != 64 {
+		return "", invalidParams("invalid transaction hash")
+	}
+
+	return hash, nil
+}
+
+// ethGetTransactionByHash finds mined transactions first, then the ones
+// waiting in the mempool, which have no block.
+func (n *Node) ethGetTransactionByHash(ctx context.Context, params json.RawMessage) (any, error) {
+	hash, err := txHashParam(params)
+	if err != nil {
+		return nil, err
+	}
+
+	res, b, index, err := n.minedTransaction(ctx, hash, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if res != nil {
+		etx, err := newEthTransaction(transaction.FromProtoTx(res.GetTransaction()))
+		if err != nil {
+			return nil, err
+		}
+		etx.setBlock(b.GetBlock(), index)
+		return etx, nil
+	}
+
+	pending, err := n.mempoolRPC.GetTransaction(ctx, &proto.GetTransactionRequest{Hash: hash})
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newEthTransaction(transaction.FromProtoTx(pending.GetTransaction()))
+}
+
+type ethLog struct {
+	Address          string         `json:"address"`
+	Topics           []string       `json:"topics"`
+	Data             string         `json:"data"`
+	BlockNumber      hexutil.Uint64 `json:"blockNumber"`
+	BlockHash        string         `json:"blockHash"`
+	TransactionHash  string         `json:"transactionHash"`
+	TransactionIndex hexutil.Uint64 `json:"transactionIndex"`
+	LogIndex         hexutil.Uint64 `json:"logIndex"`
+	Removed          bool           `json:"removed"`
+}
+
+type ethReceipt struct {
+	TransactionHash   string         `json:"transactionHash"`
+	TransactionIndex  hexutil.Uint64 `json:"transactionIndex"`
+	BlockHash         string         `json:"blockHash"`
+	BlockNumber       hexutil.Uint64 `json:"blockNumber"`
+	From              string         `json:"from"`
+	To                *string        `json:"to"`
+	ContractAddress   *string        `json:"contractAddress"`
+	CumulativeGasUsed hexutil.Uint64 `json:"cumulativeGasUsed"`
+	GasUsed           hexutil.Uint64 `json:"gasUsed"`
+	EffectiveGasPrice *hexutil.Big   `json:"effectiveGasPrice"`
+	Logs              []ethLog       `json:"logs"`
+	LogsBloom         string         `json:"logsBloom"`
+	Status            hexutil.Uint64 `json:"status"`
+	Type              hexutil.Uint64 `json:"type"`
+}
+
+// ethGetTransactionReceipt returns the receipt of a mined transaction. Only
+// accepted transactions have status 1. Gas is counted in units charged, at
+// an effective gas price of 1.
+func (n *Node) ethGetTransactionReceipt(ctx context.Context, params json.RawMessage) (any, error) {
+	hash, err := txHashParam(params)
+	if err != nil {
+		return nil, err
+	}
+
+	res, br, index, err := n.minedTransaction(ctx, hash, true)
+	if err != nil || res == nil {
+		return nil, err
+	}
+	b := br.GetBlock()
+
+	tx := transaction.FromProtoTx(res.GetTransaction())
+	etx, err := newEthTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ethReceipt{
+		TransactionHash:   etx.Hash,
+		TransactionIndex:  hexutil.Uint64(index),
+		BlockHash:         ethHash(b.GetHash()),
+		BlockNumber:       hexutil.Uint64(b.GetHeight()),
+		From:              tx.From,
+		To:                etx.To,
+		EffectiveGasPrice: (*hexutil.Big)(big.NewInt(1)),
+		Logs:              []ethLog{},
+		Type:              etx.Type,
+	}
+
+	if res.GetStatus() == receipt.StatusAccepted {
+		out.Status = 1
+	}
+
+	out.GasUsed = ethGasUsed(&tx, res.GetStatus(), res.GetGasUsed())
+
+	receipts := br.GetReceipts()
+	if len(receipts) != len(b.GetTransactions()) {
+		return nil, status.Error(codes.Internal, "block receipts do not match its transactions")
+	}
+	for i, ptx := range b.GetTransactions()[:index+1] {
+		btx := transaction.FromProtoTx(ptx)
+		out.CumulativeGasUsed += ethGasUsed(&btx, receipts[i].GetStatus(), receipts[i].GetGasUsed())
+	}
+
+	if tx.Type == transaction.TypeContractDeploy && out.Status == 1 {
+		addr := tx.To
+		out.ContractAddress = &addr
+	}
+
+	logs, err := n.txLogs(ctx, b.GetHeight(), tx.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	var txBloom bloom.Bloom
+	for _, l := range logs {
+		out.Logs = append(out.Logs, ethLog{
+			Address:          l.Address,
+			Topics:           l.Topics,
+			Data:             l.Data,
+			BlockNumber:      out.BlockNumber,
+			BlockHash:        out.BlockHash,
+			TransactionHash:  out.TransactionHash,
+			TransactionIndex: out.TransactionIndex,
+			LogIndex:         hexutil.Uint64(l.Index),
+		})
+
+		txBloom.Add(common.HexToAddress(l.Address).Bytes())
+		for _, t := range l.Topics {
+			txBloom.Add(common.HexToHash(t).Bytes())
+		}
+	}
+	out.LogsBloom = txBloom.Hex()
+
+	return out, nil
+}
+
+// ethGasUsed is the gas a transaction used. Contracts report what they ran,
+// other transactions pay their fee as gas at a price of 1 and ones with a bad
+// nonce pay nothing.
+func ethGasUsed(tx *transaction.Transaction, receiptStatus string, gasUsed int64) hexutil.Uint64 {
+	switch {
+	case receiptStatus == receipt.StatusBadNonce:
+		return 0
+	case contract.IsContractTransaction(tx):
+		return hexutil.Uint64(gasUsed)
+	default:
+		return hexutil.Uint64(tx.Fee)
+	}
+}
+
+// ethSendRawTransaction maps a signed Ethereum transaction onto ours and
+// submits it like POST /transactions does. The transaction keeps its
+// Ethereum hash.
+func (n *Node) ethSendRawTransaction(ctx context.Context, params json.RawMessage) (any, error) {
+	var raw string
+	if err := parseParams(params, 1, &raw); err != nil {
+		return nil, err
+	}
+
+	txn, err := transaction.FromEthereum(raw)
+	if err != nil {
+		return nil, invalidParams("%v", err)
+	}
+
+	if txn.Type == transaction.TypeContractDeploy {
+		txn.To = contract.DeriveAddress(txn.From, txn.Nonce)
+	}
+
+	if _, err := n.submitTransaction(ctx, txn); err != nil {
+		return nil, err
+	}
+
+	return ethHash(txn.Hash), nil
+}
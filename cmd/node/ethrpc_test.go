@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"com.perkunas/internal/models/receipt"
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/proto"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// receiptChain serves one block of txs with their receipts and logs.
+type receiptChain struct {
+	proto.StateServiceClient
+	txs      []*proto.Transaction
+	receipts []*proto.TxReceipt
+	logs     []*proto.IndexedLog
+}
+
+func (c *receiptChain) GetTransaction(ctx context.Context, in *proto.TransactionReq, opts ...grpc.CallOption) (*proto.TransactionRes, error) {
+	for i, tx := range c.txs {
+		if tx.GetHash() == in.GetHash() {
+			return &proto.TransactionRes{Transaction: tx, Height: 7, BlockHash: "block", Status: c.receipts[i].GetStatus(), GasUsed: c.receipts[i].GetGasUsed()}, nil
+		}
+	}
+
+	return nil, status.Error(codes.NotFound, "transaction not found")
+}
+
+func (c *receiptChain) GetBlockByHeight(ctx context.Context, in *proto.BlockByHeightReq, opts ...grpc.CallOption) (*proto.BlockByHeightRes, error) {
+	res := &proto.BlockByHeightRes{Block: &proto.Block{Height: 7, Hash: "block", Transactions: c.txs}}
+	if in.GetWithReceipts() {
+		res.Receipts = c.receipts
+	}
+
+	return res, nil
+}
+
+func (c *receiptChain) GetLogs(ctx context.Context, in *proto.LogsReq, opts ...grpc.CallOption) (*proto.LogsRes, error) {
+	res := &proto.LogsRes{}
+	for _, l := range c.logs {
+		if in.GetTxHash() == "" || l.GetTxHash() == in.GetTxHash() {
+			res.Logs = append(res.Logs, l)
+		}
+	}
+
+	return res, nil
+}
+
+func TestEthGetTransactionReceipt_Cumulative(t *testing.T) {
+	hash := func(c string) string { return strings.Repeat(c, 64) }
+	chain := &receiptChain{
+		txs: []*proto.Transaction{
+			{Hash: hash("a"), Fee: 5, Nonce: 1},
+			{Hash: hash("b"), Fee: 9, Nonce: 9},
+			{Hash: hash("c"), Type: transaction.TypeContractCall, Fee: 100, Nonce: 2},
+			{Hash: hash("d"), Fee: 3, Nonce: 3},
+		},
+		receipts: []*proto.TxReceipt{
+			{TxHash: hash("a"), Status: receipt.StatusAccepted},
+			{TxHash: hash("b"), Status: receipt.StatusBadNonce},
+			{TxHash: hash("c"), Status: receipt.StatusAccepted, GasUsed: 40},
+			{TxHash: hash("d"), Status: receipt.StatusAccepted},
+		},
+		logs: []*proto.IndexedLog{
+			{TxHash: hash("c"), BlockHeight: 7, Index: 0, Log: &proto.Log{Address: "0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19b2"}},
+		},
+	}
+	n := &Node{stateRPC: chain}
+
+	tests := []struct {
+		hash       string
+		gas        uint64
+		cumulative uint64
+		logs       int
+	}{
+		{hash: hash("a"), gas: 5, cumulative: 5},
+		{hash: hash("b"), gas: 0, cumulative: 5},
+		{hash: hash("c"), gas: 40, cumulative: 45, logs: 1},
+		{hash: hash("d"), gas: 3, cumulative: 48},
+	}
+
+	for _, tt := range tests {
+		params, _ := json.Marshal([]string{"0x" + tt.hash})
+		res, err := n.ethGetTransactionReceipt(context.Background(), params)
+		assert.NoError(t, err)
+
+		r := res.(*ethReceipt)
+		assert.Equal(t, hexutil.Uint64(tt.gas), r.GasUsed, tt.hash)
+		assert.Equal(t, hexutil.Uint64(tt.cumulative), r.CumulativeGasUsed, tt.hash)
+		assert.Len(t, r.Logs, tt.logs, tt.hash)
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed explorer
+var explorerFiles embed.FS
+
+// explorerUI serves the read-only block explorer under /explorer/. The pages
+// are static, they query the node's own /graphql endpoint from the browser.
+func explorerUI() http.Handler {
+	files, err := fs.Sub(explorerFiles, "explorer")
+	if err != nil {
+		panic(err)
+	}
+
+	return http.StripPrefix("/explorer/", http.FileServerFS(files))
+}
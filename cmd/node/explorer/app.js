@@ -0,0 +1,289 @@
+// Read-only explorer over the node's GraphQL endpoint. Pages are routed by
+// the location hash: #/, #/block/<height>, #/tx/<hash>, #/address/<address>
+// and #/mempool, list pages take an ?after=<cursor> for the next page.
+"use strict";
+
+const page = document.getElementById("page");
+
+async function query(q, variables) {
+  const res = await fetch("../graphql", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ query: q, variables }),
+  });
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  const body = await res.json();
+  if (body.errors && body.errors.length) {
+    throw new Error(body.errors.map((e) => e.message).join(", "));
+  }
+  return body.data;
+}
+
+function esc(v) {
+  return String(v ?? "").replace(/[&<>"']/g, (c) => `&#${c.charCodeAt(0)};`);
+}
+
+function short(hash) {
+  return hash.length > 20 ? `${hash.slice(0, 10)}…${hash.slice(-8)}` : hash;
+}
+
+function time(ts) {
+  return ts ? new Date(ts * 1000).toLocaleString() : "";
+}
+
+const link = {
+  block: (h) => `<a href="#/block/${esc(h)}">${esc(h)}</a>`,
+  tx: (h) => `<a class="mono" href="#/tx/${esc(h)}">${esc(short(h))}</a>`,
+  address: (a) => (a ? `<a class="mono" href="#/address/${esc(a)}">${esc(short(a))}</a>` : `<span class="muted">none</span>`),
+};
+
+function status(s) {
+  return `<span class="status ${esc(s)}">${esc(s)}</span>`;
+}
+
+function details(rows) {
+  return `<dl>${rows.map(([k, v]) => `<dt>${esc(k)}</dt><dd>${v}</dd>`).join("")}</dl>`;
+}
+
+function pager(route, pageInfo) {
+  if (!pageInfo.hasNextPage) {
+    return "";
+  }
+  return `<div class="pager"><a href="#${route}?after=${esc(pageInfo.endCursor)}">Next page</a></div>`;
+}
+
+function txTable(txs, withBlock) {
+  if (!txs.length) {
+    return `<p class="muted">No transactions.</p>`;
+  }
+
+  const rows = txs.map((t) => `
+    <tr>
+      <td>${link.tx(t.hash)}</td>
+      <td>${esc(t.type || "transfer")}</td>
+      <td>${link.address(t.from.address)}</td>
+      <td>${link.address(t.to.address)}</td>
+      <td>${esc(t.amount)}</td>
+      <td>${esc(t.fee)}</td>
+      ${withBlock ? `<td>${t.block ? link.block(t.block.height) : ""}</td>` : ""}
+      <td>${status(t.status)}</td>
+    </tr>`);
+
+  return `<table>
+    <tr><th>Hash</th><th>Type</th><th>From</th><th>To</th><th>Amount</th><th>Fee</th>${withBlock ? "<th>Block</th>" : ""}<th>Status</th></tr>
+    ${rows.join("")}
+  </table>`;
+}
+
+const txFields = "hash type amount fee status from { address } to { address }";
+
+async function blocksPage(params) {
+  const data = await query(`query($after: String) {
+    pendingTransactions(first: 1) { totalCount }
+    blocks(first: 20, after: $after) {
+      nodes { height hash timestamp miner transactionCount }
+      pageInfo { hasNextPage endCursor }
+    }
+  }`, { after: params.get("after") });
+
+  const rows = data.blocks.nodes.map((b) => `
+    <tr>
+      <td>${link.block(b.height)}</td>
+      <td class="mono">${esc(short(b.hash))}</td>
+      <td>${esc(time(b.timestamp))}</td>
+      <td>${link.address(b.miner)}</td>
+      <td>${esc(b.transactionCount)}</td>
+    </tr>`);
+
+  return `<h1>Latest blocks</h1>
+    <p><a href="#/mempool">${esc(data.pendingTransactions.totalCount)} pending transactions</a></p>
+    <table>
+      <tr><th>Height</th><th>Hash</th><th>Time</th><th>Miner</th><th>Transactions</th></tr>
+      ${rows.join("")}
+    </table>
+    ${pager("/", data.blocks.pageInfo)}`;
+}
+
+async function blockPage(height, params) {
+  const data = await query(`query($height: Long, $after: String) {
+    block(height: $height) {
+      height hash prevHash merkleRoot stateRoot timestamp nonce difficulty miner transactionCount
+      transactions(first: 50, after: $after) {
+        nodes { ${txFields} }
+        pageInfo { hasNextPage endCursor }
+      }
+    }
+  }`, { height, after: params.get("after") });
+
+  const b = data.block;
+  if (!b) {
+    return `<p class="error">Block ${esc(height)} not found.</p>`;
+  }
+
+  return `<h1>Block ${esc(b.height)}</h1>
+    ${details([
+      ["Hash", `<span class="mono">${esc(b.hash)}</span>`],
+      ["Parent", b.height > 0 ? `<a class="mono" href="#/block/${esc(b.height - 1)}">${esc(b.prevHash)}</a>` : ""],
+      ["Time", esc(time(b.timestamp))],
+      ["Miner", link.address(b.miner)],
+      ["Merkle root", `<span class="mono">${esc(b.merkleRoot)}</span>`],
+      ["State root", `<span class="mono">${esc(b.stateRoot)}</span>`],
+      ["Difficulty", esc(b.difficulty)],
+      ["Nonce", esc(b.nonce)],
+      ["Transactions", esc(b.transactionCount)],
+    ])}
+    <h2>Transactions</h2>
+    ${txTable(b.transactions.nodes, false)}
+    ${pager(`/block/${b.height}`, b.transactions.pageInfo)}`;
+}
+
+async function txPage(hash) {
+  const data = await query(`query($hash: String!) {
+    transaction(hash: $hash) {
+      hash type amount fee nonce data timestamp lockTime status
+      from { address } to { address }
+      block { height hash }
+      receipt { status gasUsed error logs { address topics data } }
+    }
+  }`, { hash });
+
+  const t = data.transaction;
+  if (!t) {
+    return `<p class="error">Transaction ${esc(hash)} not found.</p>`;
+  }
+
+  const rows = [
+    ["Hash", `<span class="mono">${esc(t.hash)}</span>`],
+    ["Status", status(t.status)],
+    ["Block", t.block ? link.block(t.block.height) : `<span class="muted">pending</span>`],
+    ["Type", esc(t.type || "transfer")],
+    ["From", link.address(t.from.address)],
+    ["To", link.address(t.to.address)],
+    ["Amount", esc(t.amount)],
+    ["Fee", esc(t.fee)],
+    ["Nonce", esc(t.nonce)],
+    ["Time", esc(time(t.timestamp))],
+  ];
+  if (t.lockTime) {
+    rows.push(["Lock time", esc(time(t.lockTime))]);
+  }
+  if (t.data) {
+    rows.push(["Data", `<span class="mono">${esc(t.data)}</span>`]);
+  }
+
+  let receipt = "";
+  if (t.receipt) {
+    const r = t.receipt;
+    const logs = r.logs.map((l, i) => `
+      <tr>
+        <td>${i}</td>
+        <td>${link.address(l.address)}</td>
+        <td class="mono">${l.topics.map(esc).join("<br>")}</td>
+        <td class="mono">${esc(l.data)}</td>
+      </tr>`);
+
+    receipt = `<h2>Receipt</h2>
+      ${details([
+        ["Status", status(r.status)],
+        ["Gas used", esc(r.gasUsed)],
+        ...(r.error ? [["Error", esc(r.error)]] : []),
+      ])}
+      ${logs.length ? `<h2>Logs</h2><table><tr><th>#</th><th>Address</th><th>Topics</th><th>Data</th></tr>${logs.join("")}</table>` : ""}`;
+  }
+
+  return `<h1>Transaction</h1>${details(rows)}${receipt}`;
+}
+
+async function addressPage(address, params) {
+  const data = await query(`query($address: String!, $after: String) {
+    account(address: $address) {
+      address balance nonce
+      pendingTransactions { ${txFields} }
+      transactions(first: 25, after: $after) {
+        totalCount
+        nodes { ${txFields} block { height } }
+        pageInfo { hasNextPage endCursor }
+      }
+    }
+  }`, { address, after: params.get("after") });
+
+  const a = data.account;
+  return `<h1>Address</h1>
+    ${details([
+      ["Address", `<span class="mono">${esc(a.address)}</span>`],
+      ["Balance", esc(a.balance)],
+      ["Nonce", esc(a.nonce)],
+      ["Transactions", esc(a.transactions.totalCount)],
+    ])}
+    ${a.pendingTransactions.length ? `<h2>Pending</h2>${txTable(a.pendingTransactions, false)}` : ""}
+    <h2>History</h2>
+    ${txTable(a.transactions.nodes, true)}
+    ${pager(`/address/${a.address}`, a.transactions.pageInfo)}`;
+}
+
+async function mempoolPage(params) {
+  const data = await query(`query($after: String) {
+    pendingTransactions(first: 50, after: $after) {
+      totalCount
+      nodes { ${txFields} }
+      pageInfo { hasNextPage endCursor }
+    }
+  }`, { after: params.get("after") });
+
+  const p = data.pendingTransactions;
+  return `<h1>Mempool</h1>
+    <p class="muted">${esc(p.totalCount)} pending transactions, highest fee first.</p>
+    ${txTable(p.nodes, false)}
+    ${pager("/mempool", p.pageInfo)}`;
+}
+
+async function render() {
+  const [path, search] = location.hash.slice(1).split("?");
+  const params = new URLSearchParams(search);
+  const [, name, arg] = (path || "/").split("/").map(decodeURIComponent);
+
+  page.innerHTML = `<p class="muted">Loading…</p>`;
+  try {
+    switch (name) {
+      case "block":
+        page.innerHTML = await blockPage(arg, params);
+        break;
+      case "tx":
+        page.innerHTML = await txPage(arg);
+        break;
+      case "address":
+        page.innerHTML = await addressPage(arg, params);
+        break;
+      case "mempool":
+        page.innerHTML = await mempoolPage(params);
+        break;
+      default:
+        page.innerHTML = await blocksPage(params);
+    }
+  } catch (err) {
+    page.innerHTML = `<p class="error">${esc(err.message)}</p>`;
+  }
+}
+
+document.getElementById("search").addEventListener("submit", (e) => {
+  e.preventDefault();
+  const q = e.target.q.value.trim();
+  if (!q) {
+    return;
+  }
+
+  if (/^\d+$/.test(q)) {
+    location.hash = `#/block/${q}`;
+  } else if (/^0x[0-9a-fA-F]{40}$/.test(q)) {
+    location.hash = `#/address/${q}`;
+  } else {
+    location.hash = `#/tx/${q}`;
+  }
+  e.target.reset();
+});
+
+window.addEventListener("hashchange", render);
+render();
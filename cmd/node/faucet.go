@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"com.perkunas/internal/httpjsonres"
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/pkg/address"
+	"com.perkunas/pkg/wallet"
+	"com.perkunas/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultFaucetAmount   = 1000
+	defaultFaucetFee      = 1
+	defaultFaucetInterval = 24 * time.Hour
+	// maxFaucetBody caps the size of a faucet request, it holds one address.
+	maxFaucetBody = 1 << 10
+	// minFaucetSweep is how many reservations are kept before expired ones
+	// are swept.
+	minFaucetSweep = 1024
+)
+
+// faucet sends a fixed amount from a keystore account to whoever asks, at
+// most once per interval for every address and every client IP. The client
+// IP is the peer address, or behind a reverse proxy the last address in the
+// ipHeader it sets.
+type faucet struct {
+	n        *Node
+	wallet   *wallet.Wallet
+	amount   int64
+	fee      int64
+	interval time.Duration
+	ipHeader string
+	nonces   *wallet.NonceManager
+
+	mu     sync.Mutex
+	byAddr map[string]time.Time
+	byIP   map[string]time.Time
+	// sweepAt is the number of reservations that triggers the next sweep
+	sweepAt int
+}
+
+type faucetReq struct {
+	Address string `json:"address"`
+}
+
+type faucetRes struct {
+	Hash   string `json:"hash"`
+	Amount int64  `json:"amount"`
+	Nonce  uint64 `json:"nonce"`
+}
+
+// newFaucet unlocks the faucet account configured by the FAUCET_ env vars,
+// nil when FAUCET_KEYSTORE is unset and the faucet is off.
+func newFaucet(n *Node) (*faucet, error) {
+	keystore := os.Getenv("FAUCET_KEYSTORE")
+	if keystore == "" {
+		return nil, nil
+	}
+
+	f := &faucet{
+		n:        n,
+		amount:   defaultFaucetAmount,
+		fee:      defaultFaucetFee,
+		interval: defaultFaucetInterval,
+		ipHeader: http.CanonicalHeaderKey(os.Getenv("FAUCET_CLIENT_IP_HEADER")),
+		nonces:   wallet.NewNonceManager(nodeNonces{n}),
+		byAddr:   make(map[string]time.Time),
+		byIP:     make(map[string]time.Time),
+		sweepAt:  minFaucetSweep,
+	}
+
+	for name, dst := range map[string]*int64{"FAUCET_AMOUNT": &f.amount, "FAUCET_FEE": &f.fee} {
+		if v := os.Getenv(name); v != "" {
+			i, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || i < 0 {
+				return nil, fmt.Errorf("invalid %s %q", name, v)
+			}
+			*dst = i
+		}
+	}
+	if f.amount == 0 {
+		return nil, errors.New("FAUCET_AMOUNT must be positive")
+	}
+
+	if v := os.Getenv("FAUCET_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid FAUCET_INTERVAL %q", v)
+		}
+		f.interval = d
+	}
+
+	path := keystore
+	if info, err := os.Stat(keystore); err != nil {
+		return nil, err
+	} else if info.IsDir() {
+		addr, err := address.Parse(os.Getenv("FAUCET_ADDRESS"))
+		if err != nil {
+			return nil, fmt.Errorf("FAUCET_ADDRESS picks the key from a keystore directory: %w", err)
+		}
+		if path, err = wallet.Find(keystore, addr); err != nil {
+			return nil, err
+		}
+	}
+
+	password, err := os.ReadFile(os.Getenv("FAUCET_PASSWORD_FILE"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read FAUCET_PASSWORD_FILE: %w", err)
+	}
+
+	if f.wallet, err = wallet.Load(path, strings.TrimRight(string(password), "\r\n")); err != nil {
+		return nil, fmt.Errorf("failed to unlock faucet key: %w", err)
+	}
+
+	return f, nil
+}
+
+// ServeHTTP sends the faucet amount to the requested address.
+func (f *faucet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req faucetReq
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxFaucetBody)).Decode(&req); err != nil {
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	to, err := address.Parse(req.Address)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if to == f.wallet.Address {
+		http.Error(w, "cannot fund the faucet itself", http.StatusBadRequest)
+		return
+	}
+
+	ip := f.clientIP(r)
+	if wait := f.reserve(to, ip); wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		http.Error(w, fmt.Sprintf("faucet limit reached, try again in %s", wait.Round(time.Second)), http.StatusTooManyRequests)
+		return
+	}
+
+	res, err := f.send(r.Context(), to)
+	if err != nil {
+		f.release(to, ip)
+		f.n.log.Error("faucet transaction failed", "to", to, "err", err)
+		http.Error(w, "faucet transaction failed: "+status.Convert(err).Message(), rpcErrStatus(err))
+		return
+	}
+
+	f.n.log.Info("faucet funded address", "to", to, "ip", ip, "hash", res.Hash)
+	if err := httpjsonres.JSON(w, http.StatusOK, res); err != nil {
+		f.n.log.Error("failed responding to faucet request", "err", err)
+	}
+}
+
+// clientIP returns the address the per IP limit applies to. Only the proxy's
+// own entry of ipHeader is trusted, clients can send earlier ones.
+func (f *faucet) clientIP(r *http.Request) string {
+	if f.ipHeader != "" {
+		values := r.Header.Values(f.ipHeader)
+		if len(values) > 0 {
+			entries := strings.Split(values[len(values)-1], ",")
+			if ip := strings.TrimSpace(entries[len(entries)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return ip
+}
+
+// reserve records a payout to addr requested from ip, or returns how long
+// until one of them may ask again. Expired reservations are swept once the
+// maps doubled since the last sweep, until then they count as free.
+func (f *faucet) reserve(addr, ip string) time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	if len(f.byAddr)+len(f.byIP) >= f.sweepAt {
+		f.sweep(now)
+	}
+
+	var wait time.Duration
+	if at, ok := f.byAddr[addr]; ok {
+		wait = f.interval - now.Sub(at)
+	}
+	if at, ok := f.byIP[ip]; ok {
+		wait = max(wait, f.interval-now.Sub(at))
+	}
+	if wait > 0 {
+		return wait
+	}
+
+	f.byAddr[addr] = now
+	f.byIP[ip] = now
+	return 0
+}
+
+// sweep drops the reservations older than the interval.
+func (f *faucet) sweep(now time.Time) {
+	for _, seen := range []map[string]time.Time{f.byAddr, f.byIP} {
+		for k, at := range seen {
+			if now.Sub(at) >= f.interval {
+				delete(seen, k)
+			}
+		}
+	}
+
+	f.sweepAt = max(2*(len(f.byAddr)+len(f.byIP)), minFaucetSweep)
+}
+
+// release forgets a reservation whose payout failed.
+func (f *faucet) release(addr, ip string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.byAddr, addr)
+	delete(f.byIP, ip)
+}
+
+func (f *faucet) send(ctx context.Context, to string) (*faucetRes, error) {
+	from := f.wallet.Address
+
+	nonce, err := f.nonces.Next(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &transaction.Transaction{
+		From:      from,
+		To:        to,
+		Amount:    f.amount,
+		Fee:       f.fee,
+		Nonce:     nonce,
+		Timestamp: time.Now().Unix(),
+	}
+	tx.Expires = time.Unix(tx.Timestamp, 0).Add(15 * time.Minute).Unix()
+	tx.SetHash()
+
+	if err := f.wallet.SignTransaction(tx); err != nil {
+		f.nonces.Release(from, nonce)
+		return nil, err
+	}
+
+	if _, err := f.n.submitTransaction(ctx, tx); err != nil {
+		// resync when the node's view of the nonces moved on
+		if status.Code(err) == codes.FailedPrecondition {
+			f.nonces.Reset(from)
+		} else {
+			f.nonces.Release(from, nonce)
+		}
+		return nil, err
+	}
+
+	return &faucetRes{Hash: tx.Hash, Amount: tx.Amount, Nonce: tx.Nonce}, nil
+}
+
+// nodeNonces is the wallet.NonceSource of accounts as this node sees them.
+type nodeNonces struct {
+	n *Node
+}
+
+func (s nodeNonces) ConfirmedNonce(ctx context.Context, addr string) (uint64, error) {
+	res, err := s.n.stateRPC.GetAccountByAddress(ctx, &proto.AccountByAddressReq{Address: addr})
+	if status.Code(err) == codes.NotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return res.GetAccount().GetNonce(), nil
+}
+
+func (s nodeNonces) PendingNonces(ctx context.Context, addr string) ([]uint64, error) {
+	return s.n.pendingNonces(ctx, addr)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testFaucet(interval time.Duration) *faucet {
+	return &faucet{
+		interval: interval,
+		byAddr:   make(map[string]time.Time),
+		byIP:     make(map[string]time.Time),
+		sweepAt:  minFaucetSweep,
+	}
+}
+
+func TestFaucet_Reserve(t *testing.T) {
+	f := testFaucet(time.Hour)
+
+	assert.Zero(t, f.reserve("alice", "1.1.1.1"))
+
+	// the address and the IP are limited on their own
+	wait := f.reserve("alice", "2.2.2.2")
+	assert.Greater(t, wait, 59*time.Minute)
+	assert.LessOrEqual(t, wait, time.Hour)
+	assert.Greater(t, f.reserve("bob", "1.1.1.1"), time.Duration(0))
+	_, ok := f.byIP["2.2.2.2"]
+	assert.False(t, ok)
+
+	// the longest of both waits is reported
+	f.byAddr["alice"] = time.Now().Add(-50 * time.Minute)
+	assert.Zero(t, f.reserve("carol", "3.3.3.3"))
+	f.byIP["3.3.3.3"] = time.Now().Add(-30 * time.Minute)
+	wait = f.reserve("alice", "3.3.3.3")
+	assert.Greater(t, wait, 29*time.Minute)
+	assert.LessOrEqual(t, wait, 30*time.Minute)
+
+	// expired reservations count as free
+	f.byAddr["alice"] = time.Now().Add(-time.Hour)
+	f.byIP["1.1.1.1"] = time.Now().Add(-time.Hour)
+	assert.Zero(t, f.reserve("alice", "1.1.1.1"))
+}
+
+func TestFaucet_Sweep(t *testing.T) {
+	f := testFaucet(time.Hour)
+	f.sweepAt = 4
+
+	f.byAddr["alice"] = time.Now().Add(-time.Hour)
+	f.byIP["1.1.1.1"] = time.Now().Add(-time.Hour)
+	assert.Zero(t, f.reserve("bob", "2.2.2.2"))
+
+	// below sweepAt expired reservations are kept
+	assert.Len(t, f.byAddr, 2)
+
+	// reaching it sweeps them
+	assert.Zero(t, f.reserve("carol", "3.3.3.3"))
+	assert.Equal(t, []string{"bob", "carol"}, slices.Sorted(maps.Keys(f.byAddr)))
+	assert.Equal(t, minFaucetSweep, f.sweepAt)
+}
+
+func TestFaucet_ServeHTTP_BodyLimit(t *testing.T) {
+	f := testFaucet(time.Hour)
+
+	body := `{"address":"` + strings.Repeat("0", maxFaucetBody) + `"}`
+	w := httptest.NewRecorder()
+	f.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/faucet", strings.NewReader(body)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid request payload")
+}
+
+func TestFaucet_Release(t *testing.T) {
+	f := testFaucet(time.Hour)
+
+	assert.Zero(t, f.reserve("alice", "1.1.1.1"))
+	f.release("alice", "1.1.1.1")
+
+	assert.Zero(t, f.reserve("alice", "2.2.2.2"))
+	f.release("alice", "2.2.2.2")
+	assert.Zero(t, f.reserve("bob", "2.2.2.2"))
+}
+
+func TestFaucet_ClientIP(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/faucet", nil)
+	r.RemoteAddr = "10.0.0.1:5000"
+	r.Header.Add("X-Forwarded-For", "6.6.6.6, 1.1.1.1")
+
+	f := testFaucet(time.Hour)
+	assert.Equal(t, "10.0.0.1", f.clientIP(r))
+
+	f.ipHeader = http.CanonicalHeaderKey("x-forwarded-for")
+	assert.Equal(t, "1.1.1.1", f.clientIP(r))
+
+	r.Header.Add("X-Forwarded-For", "2.2.2.2")
+	assert.Equal(t, "2.2.2.2", f.clientIP(r))
+
+	r.Header.Del("X-Forwarded-For")
+	assert.Equal(t, "10.0.0.1", f.clientIP(r))
+}
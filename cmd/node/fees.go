@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"com.perkunas/internal/fees"
+	"com.perkunas/internal/httpjsonres"
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultFeeTargetBlocks = 3
+	maxFeeTargetBlocks     = 100
+	// feeSampleBlocks is how many of the latest blocks the estimate looks at.
+	feeSampleBlocks = 20
+)
+
+// feeEstimate serves EstimateFee, the blocks query param is the target.
+func (n *Node) feeEstimate(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	req := &proto.EstimateFeeRequest{}
+	if v := r.URL.Query().Get("blocks"); v != "" {
+		target, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid blocks", http.StatusBadRequest)
+			return
+		}
+		req.TargetBlocks = target
+	}
+
+	res, err := n.EstimateFee(r.Context(), req)
+	if err != nil {
+		http.Error(w, status.Convert(err).Message(), rpcErrStatus(err))
+		return
+	}
+
+	if err := httpjsonres.JSON(w, http.StatusOK, res); err != nil {
+		n.log.Error("failed responding to fee estimate request", "err", err)
+	}
+}
+
+// EstimateFee suggests low, medium and high fees for a transaction to be
+// mined within the target blocks, from the fees of the latest blocks and the
+// transactions waiting ahead in the mempool.
+func (n *Node) EstimateFee(ctx context.Context, req *proto.EstimateFeeRequest) (*proto.EstimateFeeResponse, error) {
+	target := req.GetTargetBlocks()
+	if target == 0 {
+		target = defaultFeeTargetBlocks
+	}
+	if target > maxFeeTargetBlocks {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("target blocks must be at most %d", maxFeeTargetBlocks))
+	}
+
+	cfg, err := n.configRPC.GetChainConfig(ctx, &proto.GetChainConfigRequest{})
+	if err != nil {
+		n.log.Error("could not get chain config", "err", err)
+		return nil, status.Error(codes.Internal, "could not get chain config")
+	}
+
+	// locked transactions can not compete for the next blocks
+	pendingRes, err := n.mempoolRPC.PendingTransactions(ctx, &proto.PendingTransactionsRequest{ExecutableAt: time.Now().Unix()})
+	if err != nil {
+		n.log.Error("could not get pending transactions", "err", err)
+		return nil, status.Error(codes.Internal, "could not get pending transactions")
+	}
+
+	// every executable transaction competes for block space by its fee, the
+	// mempool sends the highest fees and counts the rest
+	pending := make([]int64, 0, len(pendingRes.GetTransactions()))
+	for _, tx := range pendingRes.GetTransactions() {
+		pending = append(pending, tx.GetFee())
+	}
+
+	recent, sampled, err := n.recentFees(ctx)
+	if err != nil {
+		n.log.Error("could not get recent blocks", "err", err)
+		return nil, status.Error(codes.Internal, "could not get recent blocks")
+	}
+
+	capacity := cfg.GetConfig().GetMaxTxPerBlock()
+	e := fees.Suggest(recent, pending, pendingRes.GetTotal(), capacity, target)
+
+	return &proto.EstimateFeeResponse{
+		TargetBlocks:        target,
+		Low:                 e.Low,
+		Medium:              e.Medium,
+		High:                e.High,
+		PendingTransactions: pendingRes.GetTotal(),
+		SampledBlocks:       sampled,
+		BlockCapacity:       capacity,
+	}, nil
+}
+
+// recentFees returns the fees included in the latest blocks and how many
+// blocks were looked at. Contract fees are gas budgets, not bids, and are
+// left out.
+func (n *Node) recentFees(ctx context.Context) ([]int64, uint64, error) {
+	lb, err := n.stateRPC.GetLatestBlock(ctx, &proto.LastBlockReq{})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var recent []int64
+	var sampled uint64
+	// the genesis block has no transactions
+	for h := lb.GetBlock().GetHeight(); h > 0 && sampled < feeSampleBlocks; h-- {
+		res, err := n.stateRPC.GetBlockByHeight(ctx, &proto.BlockByHeightReq{Height: h, WithTransactions: true})
+		if err != nil {
+			return nil, 0, err
+		}
+		sampled++
+
+		for _, tx := range res.GetBlock().GetTransactions() {
+			if tx.GetType() == transaction.TypeContractDeploy || tx.GetType() == transaction.TypeContractCall {
+				continue
+			}
+			recent = append(recent, tx.GetFee())
+		}
+	}
+
+	return recent, sampled, nil
+}
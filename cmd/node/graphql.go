@@ -0,0 +1,594 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"com.perkunas/internal/httpjsonres"
+	"com.perkunas/pkg/address"
+	"com.perkunas/proto"
+	"github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//go:embed schema.graphql
+var graphQLSchema string
+
+const (
+	// gqlMaxDepth caps how deep selections nest.
+	gqlMaxDepth = 10
+	// gqlMaxCost is how many state and mempool calls one query may make.
+	gqlMaxCost = 200
+	// gqlMaxPage caps the first argument of paginated fields.
+	gqlMaxPage = 50
+)
+
+var errQueryTooComplex = fmt.Errorf("query exceeds the complexity limit of %d calls", gqlMaxCost)
+
+type graphQLReq struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// gqlBudget counts the calls a query makes to the state and mempool
+// services. The query is cancelled once it runs out.
+type gqlBudget struct {
+	left   atomic.Int64
+	cancel context.CancelFunc
+}
+
+type gqlBudgetKey struct{}
+
+// charge spends one call of the query budget.
+func charge(ctx context.Context) error {
+	b := ctx.Value(gqlBudgetKey{}).(*gqlBudget)
+	if b.left.Add(-1) < 0 {
+		b.cancel()
+		return errQueryTooComplex
+	}
+
+	return nil
+}
+
+// graphQL serves queries over the state and mempool services. Besides the
+// depth limit every query has a budget of upstream calls, a query that
+// would exceed it is rejected without data.
+func (n *Node) graphQL() http.Handler {
+	schema := graphql.MustParseSchema(graphQLSchema, &gqlQuery{n: n},
+		graphql.UseStringDescriptions(),
+		graphql.MaxDepth(gqlMaxDepth),
+	)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		var req graphQLReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Query == "" {
+			n.log.Error("could not read request body", "err", err)
+			http.Error(w, "invalid request payload", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		budget := &gqlBudget{cancel: cancel}
+		budget.left.Store(gqlMaxCost)
+		ctx = context.WithValue(ctx, gqlBudgetKey{}, budget)
+
+		res := schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+		if budget.left.Load() < 0 {
+			res = &graphql.Response{Errors: []*gqlerrors.QueryError{{Message: errQueryTooComplex.Error()}}}
+		}
+
+		if err := httpjsonres.JSON(w, http.StatusOK, res); err != nil {
+			n.log.Error("failed responding to graphql request", "err", err)
+		}
+	})
+}
+
+// gqlLong is the Long scalar.
+type gqlLong int64
+
+func (gqlLong) ImplementsGraphQLType(name string) bool {
+	return name == "Long"
+}
+
+func (l *gqlLong) UnmarshalGraphQL(input any) error {
+	switch v := input.(type) {
+	case int32:
+		*l = gqlLong(v)
+	case int64:
+		*l = gqlLong(v)
+	case float64:
+		if v != float64(int64(v)) {
+			return fmt.Errorf("%v is not an integer", v)
+		}
+		*l = gqlLong(v)
+	case string:
+		i, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", v)
+		}
+		*l = gqlLong(i)
+	default:
+		return fmt.Errorf("wrong type for Long: %T", input)
+	}
+
+	return nil
+}
+
+func (l gqlLong) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, int64(l), 10), nil
+}
+
+// gqlError hides the grpc details of upstream errors.
+func gqlError(err error) error {
+	if err == nil || errors.Is(err, errQueryTooComplex) {
+		return err
+	}
+
+	return errors.New(status.Convert(err).Message())
+}
+
+// gqlPage returns the bounds of the page of first items after the offset
+// cursor.
+func gqlPage(total int, first int32, after *string) (int, int, error) {
+	if first < 1 || first > gqlMaxPage {
+		return 0, 0, fmt.Errorf("first must be between 1 and %d", gqlMaxPage)
+	}
+
+	start := 0
+	if after != nil {
+		i, err := strconv.Atoi(*after)
+		if err != nil || i < 0 {
+			return 0, 0, errors.New("invalid cursor")
+		}
+		start = min(i, total)
+	}
+
+	return start, min(start+int(first), total), nil
+}
+
+type gqlPageInfo struct {
+	hasNext   bool
+	endCursor *string
+}
+
+func (p gqlPageInfo) HasNextPage() bool  { return p.hasNext }
+func (p gqlPageInfo) EndCursor() *string { return p.endCursor }
+
+type gqlQuery struct {
+	n *Node
+}
+
+type pageArgs struct {
+	First int32
+	After *string
+}
+
+func (q *gqlQuery) Block(ctx context.Context, args struct{ Height *gqlLong }) (*gqlBlock, error) {
+	var height uint64
+	if args.Height != nil {
+		if *args.Height < 0 {
+			return nil, errors.New("height cannot be negative")
+		}
+		height = uint64(*args.Height)
+	} else {
+		if err := charge(ctx); err != nil {
+			return nil, err
+		}
+		lb, err := q.n.stateRPC.GetLatestBlock(ctx, &proto.LastBlockReq{})
+		if err != nil {
+			return nil, gqlError(err)
+		}
+		height = lb.GetBlock().GetHeight()
+	}
+
+	return q.n.gqlBlockAt(ctx, height)
+}
+
+func (q *gqlQuery) Blocks(ctx context.Context, args pageArgs) (*gqlBlockConnection, error) {
+	_, size, err := gqlPage(gqlMaxPage, args.First, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// the cursor is the height of the last block on the previous page
+	var next uint64
+	if args.After != nil {
+		h, err := strconv.ParseUint(*args.After, 10, 64)
+		if err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+		if h == 0 {
+			return &gqlBlockConnection{}, nil
+		}
+		next = h - 1
+	} else {
+		if err := charge(ctx); err != nil {
+			return nil, err
+		}
+		lb, err := q.n.stateRPC.GetLatestBlock(ctx, &proto.LastBlockReq{})
+		if err != nil {
+			return nil, gqlError(err)
+		}
+		next = lb.GetBlock().GetHeight()
+	}
+
+	conn := &gqlBlockConnection{}
+	for range size {
+		b, err := q.n.gqlBlockAt(ctx, next)
+		if err != nil {
+			return nil, err
+		}
+		if b != nil {
+			conn.nodes = append(conn.nodes, b)
+		}
+
+		cursor := strconv.FormatUint(next, 10)
+		conn.pageInfo = gqlPageInfo{hasNext: next > 0, endCursor: &cursor}
+		if next == 0 {
+			break
+		}
+		next--
+	}
+
+	return conn, nil
+}
+
+func (q *gqlQuery) Transaction(ctx context.Context, args struct{ Hash string }) (*gqlTransaction, error) {
+	hash := strings.TrimPrefix(args.Hash, "0x")
+	if err := charge(ctx); err != nil {
+		return nil, err
+	}
+
+	res, err := q.n.stateRPC.GetTransaction(ctx, &proto.TransactionReq{Hash: hash})
+	if err == nil {
+		t := &gqlTransaction{n: q.n, tx: res.GetTransaction()}
+		t.once.Do(func() { t.mined = res })
+		return t, nil
+	}
+	if status.Code(err) != codes.NotFound {
+		return nil, gqlError(err)
+	}
+
+	if err := charge(ctx); err != nil {
+		return nil, err
+	}
+
+	pending, err := q.n.mempoolRPC.GetTransaction(ctx, &proto.GetTransactionRequest{Hash: hash})
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, gqlError(err)
+	}
+
+	t := &gqlTransaction{n: q.n, tx: pending.GetTransaction()}
+	t.once.Do(func() {})
+	return t, nil
+}
+
+func (q *gqlQuery) Account(args struct{ Address string }) (*gqlAccount, error) {
+	addr, err := address.Parse(args.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gqlAccount{n: q.n, address: addr}, nil
+}
+
+func (q *gqlQuery) PendingTransactions(ctx context.Context, args pageArgs) (*gqlTransactionConnection, error) {
+	if err := charge(ctx); err != nil {
+		return nil, err
+	}
+
+	res, err := q.n.mempoolRPC.PendingTransactions(ctx, &proto.PendingTransactionsRequest{})
+	if err != nil {
+		return nil, gqlError(err)
+	}
+
+	return newTransactionConnection(q.n, res.GetTransactions(), args, true)
+}
+
+// gqlBlockAt returns the block at height with its transactions, nil when
+// there is none.
+func (n *Node) gqlBlockAt(ctx context.Context, height uint64) (*gqlBlock, error) {
+	if err := charge(ctx); err != nil {
+		return nil, err
+	}
+
+	res, err := n.stateRPC.GetBlockByHeight(ctx, &proto.BlockByHeightReq{Height: height, WithTransactions: true})
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, gqlError(err)
+	}
+
+	return &gqlBlock{n: n, b: res.GetBlock()}, nil
+}
+
+type gqlBlock struct {
+	n *Node
+	b *proto.Block
+}
+
+func (b *gqlBlock) Height() gqlLong     { return gqlLong(b.b.GetHeight()) }
+func (b *gqlBlock) Hash() string        { return b.b.GetHash() }
+func (b *gqlBlock) PrevHash() string    { return b.b.GetPrevHash() }
+func (b *gqlBlock) MerkleRoot() string  { return b.b.GetMerkleRoot() }
+func (b *gqlBlock) StateRoot() string   { return b.b.GetStateRoot() }
+func (b *gqlBlock) Timestamp() gqlLong  { return gqlLong(b.b.GetTimestamp()) }
+func (b *gqlBlock) Nonce() gqlLong      { return gqlLong(b.b.GetNonce()) }
+func (b *gqlBlock) Difficulty() gqlLong { return gqlLong(b.b.GetDifficulty()) }
+func (b *gqlBlock) Miner() string       { return b.b.GetMiner() }
+
+func (b *gqlBlock) TransactionCount() int32 {
+	return int32(len(b.b.GetTransactions()))
+}
+
+func (b *gqlBlock) Parent(ctx context.Context) (*gqlBlock, error) {
+	if b.b.GetHeight() == 0 {
+		return nil, nil
+	}
+
+	return b.n.gqlBlockAt(ctx, b.b.GetHeight()-1)
+}
+
+func (b *gqlBlock) Transactions(args pageArgs) (*gqlTransactionConnection, error) {
+	return newTransactionConnection(b.n, b.b.GetTransactions(), args, false)
+}
+
+type gqlBlockConnection struct {
+	nodes    []*gqlBlock
+	pageInfo gqlPageInfo
+}
+
+func (c *gqlBlockConnection) Nodes() []*gqlBlock    { return c.nodes }
+func (c *gqlBlockConnection) PageInfo() gqlPageInfo { return c.pageInfo }
+
+type gqlTransactionConnection struct {
+	nodes    []*gqlTransaction
+	total    int
+	pageInfo gqlPageInfo
+}
+
+func newTransactionConnection(n *Node, txs []*proto.Transaction, args pageArgs, pending bool) (*gqlTransactionConnection, error) {
+	start, end, err := gqlPage(len(txs), args.First, args.After)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := strconv.Itoa(end)
+	conn := &gqlTransactionConnection{
+		total:    len(txs),
+		pageInfo: gqlPageInfo{hasNext: end < len(txs), endCursor: &cursor},
+	}
+
+	for _, tx := range txs[start:end] {
+		t := &gqlTransaction{n: n, tx: tx}
+		if pending {
+			t.once.Do(func() {})
+		}
+		conn.nodes = append(conn.nodes, t)
+	}
+
+	return conn, nil
+}
+
+func (c *gqlTransactionConnection) Nodes() []*gqlTransaction { return c.nodes }
+func (c *gqlTransactionConnection) TotalCount() int32        { return int32(c.total) }
+func (c *gqlTransactionConnection) PageInfo() gqlPageInfo    { return c.pageInfo }
+
+// gqlTransaction looks its receipt up on first use, transactions known to
+// be pending or already looked up have the lookup done.
+type gqlTransaction struct {
+	n  *Node
+	tx *proto.Transaction
+
+	once  sync.Once
+	mined *proto.TransactionRes
+	err   error
+}
+
+func (t *gqlTransaction) Hash() string       { return t.tx.GetHash() }
+func (t *gqlTransaction) Type() string       { return t.tx.GetType() }
+func (t *gqlTransaction) Amount() gqlLong    { return gqlLong(t.tx.GetAmount()) }
+func (t *gqlTransaction) Fee() gqlLong       { return gqlLong(t.tx.GetFee()) }
+func (t *gqlTransaction) Nonce() gqlLong     { return gqlLong(t.tx.GetNonce()) }
+func (t *gqlTransaction) Data() string       { return t.tx.GetData() }
+func (t *gqlTransaction) Timestamp() gqlLong { return gqlLong(t.tx.GetTimestamp()) }
+func (t *gqlTransaction) LockTime() gqlLong  { return gqlLong(t.tx.GetLockTime()) }
+func (t *gqlTransaction) From() *gqlAccount  { return &gqlAccount{n: t.n, address: t.tx.GetFromAddr()} }
+func (t *gqlTransaction) To() *gqlAccount    { return &gqlAccount{n: t.n, address: t.tx.GetToAddr()} }
+
+// receipt returns the mined transaction, nil while it is pending.
+func (t *gqlTransaction) receipt(ctx context.Context) (*proto.TransactionRes, error) {
+	t.once.Do(func() {
+		if t.err = charge(ctx); t.err != nil {
+			return
+		}
+
+		res, err := t.n.stateRPC.GetTransaction(ctx, &proto.TransactionReq{Hash: t.tx.GetHash()})
+		if status.Code(err) == codes.NotFound {
+			return
+		}
+		t.mined, t.err = res, gqlError(err)
+	})
+
+	return t.mined, t.err
+}
+
+func (t *gqlTransaction) Status(ctx context.Context) (string, error) {
+	res, err := t.receipt(ctx)
+	if err != nil {
+		return "", err
+	}
+	if res == nil {
+		return txStatusPending, nil
+	}
+
+	return res.GetStatus(), nil
+}
+
+func (t *gqlTransaction) Block(ctx context.Context) (*gqlBlock, error) {
+	res, err := t.receipt(ctx)
+	if err != nil || res == nil {
+		return nil, err
+	}
+
+	return t.n.gqlBlockAt(ctx, res.GetHeight())
+}
+
+func (t *gqlTransaction) Receipt(ctx context.Context) (*gqlReceipt, error) {
+	res, err := t.receipt(ctx)
+	if err != nil || res == nil {
+		return nil, err
+	}
+
+	return &gqlReceipt{res: res}, nil
+}
+
+type gqlReceipt struct {
+	res *proto.TransactionRes
+}
+
+func (r *gqlReceipt) Status() string   { return r.res.GetStatus() }
+func (r *gqlReceipt) GasUsed() gqlLong { return gqlLong(r.res.GetGasUsed()) }
+func (r *gqlReceipt) Error() string    { return r.res.GetError() }
+
+func (r *gqlReceipt) Logs() []*gqlLog {
+	logs := make([]*gqlLog, 0, len(r.res.GetLogs()))
+	for _, l := range r.res.GetLogs() {
+		logs = append(logs, &gqlLog{l: l})
+	}
+
+	return logs
+}
+
+type gqlLog struct {
+	l *proto.Log
+}
+
+func (l *gqlLog) Address() string  { return l.l.GetAddress() }
+func (l *gqlLog) Topics() []string { return l.l.GetTopics() }
+func (l *gqlLog) Data() string     { return l.l.GetData() }
+
+// gqlAccount looks the account up on first use, an address that never
+// received anything has a zero balance.
+type gqlAccount struct {
+	n       *Node
+	address string
+
+	once sync.Once
+	acc  *proto.Account
+	err  error
+}
+
+func (a *gqlAccount) Address() string { return a.address }
+
+func (a *gqlAccount) account(ctx context.Context) (*proto.Account, error) {
+	a.once.Do(func() {
+		if a.err = charge(ctx); a.err != nil {
+			return
+		}
+
+		res, err := a.n.stateRPC.GetAccountByAddress(ctx, &proto.AccountByAddressReq{Address: a.address})
+		if status.Code(err) == codes.NotFound {
+			a.acc = &proto.Account{Address: a.address}
+			return
+		}
+		a.acc, a.err = res.GetAccount(), gqlError(err)
+	})
+
+	return a.acc, a.err
+}
+
+func (a *gqlAccount) Balance(ctx context.Context) (gqlLong, error) {
+	acc, err := a.account(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return gqlLong(acc.GetBalance()), nil
+}
+
+func (a *gqlAccount) Nonce(ctx context.Context) (gqlLong, error) {
+	acc, err := a.account(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return gqlLong(acc.GetNonce()), nil
+}
+
+func (a *gqlAccount) Transactions(ctx context.Context, args pageArgs) (*gqlTransactionConnection, error) {
+	// the page is cut by the state service, check the args before calling it
+	if _, _, err := gqlPage(0, args.First, args.After); err != nil {
+		return nil, err
+	}
+	offset := 0
+	if args.After != nil {
+		offset, _ = strconv.Atoi(*args.After)
+	}
+
+	if err := charge(ctx); err != nil {
+		return nil, err
+	}
+
+	res, err := a.n.stateRPC.GetAccountTransactions(ctx, &proto.AccountTransactionsReq{
+		Address: a.address,
+		Limit:   uint32(args.First),
+		Offset:  uint32(offset),
+	})
+	if err != nil {
+		return nil, gqlError(err)
+	}
+
+	end := offset + len(res.GetTransactions())
+	cursor := strconv.Itoa(end)
+	conn := &gqlTransactionConnection{
+		total:    int(res.GetTotal()),
+		pageInfo: gqlPageInfo{hasNext: uint64(end) < res.GetTotal(), endCursor: &cursor},
+	}
+	for _, mined := range res.GetTransactions() {
+		t := &gqlTransaction{n: a.n, tx: mined.GetTransaction()}
+		t.once.Do(func() { t.mined = mined })
+		conn.nodes = append(conn.nodes, t)
+	}
+
+	return conn, nil
+}
+
+func (a *gqlAccount) PendingTransactions(ctx context.Context) ([]*gqlTransaction, error) {
+	if err := charge(ctx); err != nil {
+		return nil, err
+	}
+
+	res, err := a.n.mempoolRPC.PendingTransactions(ctx, &proto.PendingTransactionsRequest{From: a.address})
+	if err != nil {
+		return nil, gqlError(err)
+	}
+
+	txs := make([]*gqlTransaction, 0, len(res.GetTransactions()))
+	for _, tx := range res.GetTransactions() {
+		t := &gqlTransaction{n: a.n, tx: tx}
+		t.once.Do(func() {})
+		txs = append(txs, t)
+	}
+
+	return txs, nil
+}
@@ -1,15 +1,28 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
+	"com.perkunas/internal/errmsg"
 	"com.perkunas/internal/httpjsonres"
+	"com.perkunas/internal/models/multisig"
 	"com.perkunas/internal/models/transaction"
+	"com.perkunas/pkg/address"
+	"com.perkunas/pkg/wallet"
 	"com.perkunas/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// txStatusPending is reported for transactions still waiting in the mempool.
+const txStatusPending = "PENDING"
+
 func (n *Node) createTransaction(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
@@ -20,45 +33,218 @@ func (n *Node) createTransaction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	createResp, err := n.submitTransaction(r.Context(), &txn)
+	if err != nil {
+		http.Error(w, status.Convert(err).Message(), rpcErrStatus(err))
+		return
+	}
+
+	if err := httpjsonres.JSON(w, http.StatusOK, createResp); err != nil {
+		n.log.Error("failed responding to create transaction request", "err", err)
+	}
+}
+
+// submitTransaction checks txn against the chain and pushes it to the
+// mempool. Rejections are status errors, InvalidArgument when the
+// transaction itself is at fault.
+func (n *Node) submitTransaction(ctx context.Context, txn *transaction.Transaction) (*proto.CreateMempoolResponse, error) {
 	if txn.Timestamp == 0 {
 		txn.Timestamp = time.Now().Unix()
 	}
 
+	if txn.LockTime < 0 {
+		return nil, status.Error(codes.InvalidArgument, "lock time cannot be negative")
+	}
+
+	// locked transactions wait in the mempool, they expire after the lock
 	if txn.Expires == 0 {
-		txn.Expires = time.Now().Add(15 * time.Minute).Unix()
+		txn.Expires = time.Unix(max(time.Now().Unix(), txn.LockTime), 0).Add(15 * time.Minute).Unix()
 	}
 
-	if err := txn.Verify(); err != nil {
+	if txn.LockTime != 0 && txn.Expires <= txn.LockTime {
+		return nil, status.Error(codes.InvalidArgument, "transaction expires before its lock time")
+	}
+
+	if err := txn.ValidateAddresses(); err != nil {
+		n.log.Error("invalid transaction address", "tx", txn.Hash, "err", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	chainID, err := n.chainID(ctx)
+	if err != nil {
+		n.log.Error("could not get chain config", "err", err)
+		return nil, status.Error(codes.Internal, "could not get chain config")
+	}
+
+	if err := txn.Verify(chainID); err != nil {
 		n.log.Error("invalid or tampered transaction", "tx", txn, "err", err)
-		http.Error(w, "invalid or tampered transaction data", http.StatusBadRequest)
-		return
+		if errors.Is(err, errmsg.ErrInvalidChainID) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.InvalidArgument, "invalid or tampered transaction data")
+	}
+
+	if err := n.verifyMultisig(ctx, txn, chainID); err != nil {
+		n.log.Error("invalid multisig transaction", "tx", txn.Hash, "err", err)
+		return nil, err
+	}
+
+	if err := n.verifyToken(ctx, txn); err != nil {
+		n.log.Error("invalid token transaction", "tx", txn.Hash, "err", err)
+		return nil, err
+	}
+
+	if err := n.verifyHTLC(ctx, txn); err != nil {
+		n.log.Error("invalid htlc transaction", "tx", txn.Hash, "err", err)
+		return nil, err
+	}
+
+	if err := n.verifyContract(ctx, txn); err != nil {
+		n.log.Error("invalid contract transaction", "tx", txn.Hash, "err", err)
+		return nil, err
 	}
 
-	fromAcc, err := n.stateRPC.GetAccountByAddress(r.Context(), &proto.AccountByAddressReq{Address: txn.From})
+	fromAcc, err := n.stateRPC.GetAccountByAddress(ctx, &proto.AccountByAddressReq{Address: txn.From})
 	if err != nil {
 		n.log.Error("could not get account by address", "err", err)
-		http.Error(w, "could not get account by address", http.StatusBadRequest)
-		return
+		return nil, status.Error(codes.InvalidArgument, "could not get account by address")
+	}
+
+	// the sender may queue transactions, the next nonce follows its pending ones
+	pending, err := n.pendingNonces(ctx, txn.From)
+	if err != nil {
+		n.log.Error("could not get pending transactions", "err", err)
+		return nil, status.Error(codes.Internal, "could not get pending transactions")
 	}
 
 	accNonce := fromAcc.GetAccount().GetNonce()
-	if txn.Nonce != accNonce+1 {
-		n.log.Warn("invalid nonce", "acc nonce", accNonce, "tx nonce", txn.Nonce)
-		http.Error(w, "invalid nonce", http.StatusBadRequest)
-		return
+	if expected := wallet.NextNonce(accNonce, pending); txn.Nonce != expected {
+		n.log.Warn("invalid nonce", "acc nonce", accNonce, "tx nonce", txn.Nonce, "expected nonce", expected)
+		// a distinct code, so callers managing nonces can tell it apart
+		return nil, status.Error(codes.FailedPrecondition, fmt.Sprintf("%v, expected %d", errmsg.ErrInvalidNonce, expected))
 	}
 
-	protoTxn := transaction.ToProtoTx(txn)
-	pld := &proto.CreateMempoolRequest{Transaction: protoTxn}
-	createResp, err := n.mempoolRPC.CreateMempool(r.Context(), pld)
+	pld := &proto.CreateMempoolRequest{Transaction: transaction.ToProtoTx(*txn)}
+	createResp, err := n.mempoolRPC.CreateMempool(ctx, pld)
 	if err != nil {
 		n.log.Error("could not push transaction to mempool", "txHash", txn.Hash, "err", err)
-		http.Error(w, "could not create transaction", http.StatusBadRequest)
+		return nil, status.Error(codes.InvalidArgument, "could not create transaction")
+	}
+
+	return createResp, nil
+}
+
+// verifyMultisig rejects malformed multisig creations and spends that lack
+// owner signatures before they reach the mempool. The state service checks
+// them again when the block is applied.
+func (n *Node) verifyMultisig(ctx context.Context, txn *transaction.Transaction, chainID uint64) error {
+	if txn.Type == transaction.TypeMultisigCreate {
+		if _, err := multisig.FromCreateTransaction(txn); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil
+	}
+
+	if !txn.IsMultisig() {
+		return nil
+	}
+
+	res, err := n.stateRPC.GetMultisig(ctx, &proto.MultisigReq{Address: txn.From})
+	if status.Code(err) == codes.NotFound {
+		return status.Error(codes.InvalidArgument, errmsg.ErrUnknownMultisig.Error())
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := multisig.FromProto(res.GetMultisig()).Verify(txn, chainID); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return nil
+}
+
+func (n *Node) multisigByAddress(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	addr, err := address.Parse(r.PathValue("address"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	if err := httpjsonres.JSON(w, http.StatusOK, createResp); err != nil {
-		n.log.Error("failed responding to create transaction request", "err", err)
+	res, err := n.stateRPC.GetMultisig(r.Context(), &proto.MultisigReq{Address: addr})
+	if err != nil {
+		n.log.Error("could not get multisig account", "err", err)
+		http.Error(w, "could not get multisig account", rpcErrStatus(err))
+		return
+	}
+
+	if err := httpjsonres.JSON(w, http.StatusOK, res.GetMultisig()); err != nil {
+		n.log.Error("failed responding to get multisig request", "err", err)
+	}
+}
+
+// chainID is the id Ethereum transactions must be signed for.
+func (n *Node) chainID(ctx context.Context) (uint64, error) {
+	res, err := n.configRPC.GetChainConfig(ctx, &proto.GetChainConfigRequest{})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.GetConfig().GetChainId(), nil
+}
+
+func (n *Node) pendingNonces(ctx context.Context, from string) ([]uint64, error) {
+	res, err := n.mempoolRPC.PendingTransactions(ctx, &proto.PendingTransactionsRequest{From: from})
+	if err != nil {
+		return nil, err
+	}
+
+	nonces := make([]uint64, 0, len(res.GetTransactions()))
+	for _, tx := range res.GetTransactions() {
+		nonces = append(nonces, tx.GetNonce())
+	}
+
+	return nonces, nil
+}
+
+type verifyMessageReq struct {
+	Address   string `json:"address"`
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+}
+
+type verifyMessageRes struct {
+	Valid  bool   `json:"valid"`
+	Signer string `json:"signer,omitempty"`
+}
+
+// verifyMessage checks a message signed with wallet.SignMessage, so services
+// can authenticate users by address ownership.
+func (n *Node) verifyMessage(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req verifyMessageReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Address == "" || req.Signature == "" {
+		n.log.Error("could not read request body", "err", err)
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	signer, err := wallet.RecoverMessageSigner([]byte(req.Message), req.Signature)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res := verifyMessageRes{
+		Valid:  wallet.VerifyMessage(req.Address, []byte(req.Message), req.Signature) == nil,
+		Signer: signer,
+	}
+
+	if err := httpjsonres.JSON(w, http.StatusOK, res); err != nil {
+		n.log.Error("failed responding to verify request", "err", err)
 	}
 }
 
@@ -76,3 +262,216 @@ func (n *Node) nodeStatus(w http.ResponseWriter, r *http.Request) {
 		n.log.Error("failed responding to get latest block from stet service", "err", err)
 	}
 }
+
+func (n *Node) accountByAddress(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	addr, err := address.Parse(r.PathValue("address"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res, err := n.stateRPC.GetAccountByAddress(r.Context(), &proto.AccountByAddressReq{Address: addr})
+	if err != nil {
+		n.log.Error("could not get account by address", "err", err)
+		http.Error(w, "could not get account", rpcErrStatus(err))
+		return
+	}
+
+	if err := httpjsonres.JSON(w, http.StatusOK, res.GetAccount()); err != nil {
+		n.log.Error("failed responding to get account request", "err", err)
+	}
+}
+
+// accountTransactions lists the mined transactions sent from or to the
+// address, newest first, limit and offset are optional.
+func (n *Node) accountTransactions(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	addr, err := address.Parse(r.PathValue("address"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req := &proto.AccountTransactionsReq{Address: addr}
+	for name, dst := range map[string]*uint32{"limit": &req.Limit, "offset": &req.Offset} {
+		if v := r.URL.Query().Get(name); v != "" {
+			i, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid %s", name), http.StatusBadRequest)
+				return
+			}
+			*dst = uint32(i)
+		}
+	}
+
+	res, err := n.stateRPC.GetAccountTransactions(r.Context(), req)
+	if err != nil {
+		n.log.Error("could not get account transactions", "err", err)
+		http.Error(w, "could not get account transactions", rpcErrStatus(err))
+		return
+	}
+
+	if err := httpjsonres.JSON(w, http.StatusOK, res); err != nil {
+		n.log.Error("failed responding to get account transactions request", "err", err)
+	}
+}
+
+// transactionByHash looks the transaction up in the chain first and falls
+// back to the mempool for transactions that are not mined yet.
+func (n *Node) transactionByHash(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	hash := r.PathValue("hash")
+	res, err := n.stateRPC.GetTransaction(r.Context(), &proto.TransactionReq{Hash: hash})
+	if status.Code(err) == codes.NotFound {
+		var pending *proto.GetTransactionResponse
+		pending, err = n.mempoolRPC.GetTransaction(r.Context(), &proto.GetTransactionRequest{Hash: hash})
+		if err == nil {
+			res = &proto.TransactionRes{Transaction: pending.GetTransaction(), Status: txStatusPending}
+		}
+	}
+	if err != nil {
+		n.log.Error("could not get transaction", "err", err, "hash", hash)
+		http.Error(w, "could not get transaction", rpcErrStatus(err))
+		return
+	}
+
+	if err := httpjsonres.JSON(w, http.StatusOK, res); err != nil {
+		n.log.Error("failed responding to get transaction request", "err", err)
+	}
+}
+
+// pendingTransactions lists the mempool, optionally only the transactions
+// sent by the from query param.
+func (n *Node) pendingTransactions(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	res, err := n.mempoolRPC.PendingTransactions(r.Context(), &proto.PendingTransactionsRequest{
+		From: r.URL.Query().Get("from"),
+	})
+	if err != nil {
+		n.log.Error("could not get pending transactions", "err", err)
+		http.Error(w, "could not get pending transactions", rpcErrStatus(err))
+		return
+	}
+
+	if err := httpjsonres.JSON(w, http.StatusOK, res); err != nil {
+		n.log.Error("failed responding to pending transactions request", "err", err)
+	}
+}
+
+func (n *Node) chainConfig(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	res, err := n.configRPC.GetChainConfig(r.Context(), &proto.GetChainConfigRequest{})
+	if err != nil {
+		n.log.Error("could not get chain config", "err", err)
+		http.Error(w, "could not get chain config", rpcErrStatus(err))
+		return
+	}
+
+	if err := httpjsonres.JSON(w, http.StatusOK, res.GetConfig()); err != nil {
+		n.log.Error("failed responding to chain config request", "err", err)
+	}
+}
+
+func (n *Node) blockByHeight(w http.ResponseWriter, r *http.Request) {
+	n.getBlock(w, r, true)
+}
+
+func (n *Node) headerByHeight(w http.ResponseWriter, r *http.Request) {
+	n.getBlock(w, r, false)
+}
+
+func (n *Node) getBlock(w http.ResponseWriter, r *http.Request, withTxs bool) {
+	defer r.Body.Close()
+
+	height, err := strconv.ParseUint(r.PathValue("height"), 10, 64)
+	if err != nil {
+		n.log.Error("invalid height", "err", err)
+		http.Error(w, "invalid height", http.StatusBadRequest)
+		return
+	}
+
+	res, err := n.stateRPC.GetBlockByHeight(r.Context(), &proto.BlockByHeightReq{Height: height, WithTransactions: withTxs})
+	if err != nil {
+		n.log.Error("could not get block", "err", err, "height", height)
+		http.Error(w, "could not get block", rpcErrStatus(err))
+		return
+	}
+
+	if err := httpjsonres.JSON(w, http.StatusOK, res.GetBlock()); err != nil {
+		n.log.Error("failed responding to get block request", "err", err)
+	}
+}
+
+func (n *Node) transactionProof(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	res, err := n.stateRPC.GetTransactionProof(r.Context(), &proto.TxProofReq{TxHash: r.PathValue("hash")})
+	if err != nil {
+		n.log.Error("could not get transaction proof", "err", err)
+		http.Error(w, "could not get transaction proof", rpcErrStatus(err))
+		return
+	}
+
+	if err := httpjsonres.JSON(w, http.StatusOK, res); err != nil {
+		n.log.Error("failed responding to transaction proof request", "err", err)
+	}
+}
+
+func (n *Node) accountProof(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	height, err := n.heightParam(r)
+	if err != nil {
+		n.log.Error("invalid height", "err", err)
+		http.Error(w, "invalid height", http.StatusBadRequest)
+		return
+	}
+
+	res, err := n.stateRPC.GetAccountProof(r.Context(), &proto.AccountProofReq{
+		Address:    r.PathValue("address"),
+		Height:     height,
+		StorageKey: r.URL.Query().Get("storage"),
+	})
+	if err != nil {
+		n.log.Error("could not get account proof", "err", err)
+		http.Error(w, "could not get account proof", rpcErrStatus(err))
+		return
+	}
+
+	if err := httpjsonres.JSON(w, http.StatusOK, res); err != nil {
+		n.log.Error("failed responding to account proof request", "err", err)
+	}
+}
+
+// heightParam reads the optional height query param, defaulting to the latest block.
+func (n *Node) heightParam(r *http.Request) (uint64, error) {
+	if h := r.URL.Query().Get("height"); h != "" {
+		return strconv.ParseUint(h, 10, 64)
+	}
+
+	lb, err := n.stateRPC.GetLatestBlock(r.Context(), &proto.LastBlockReq{})
+	if err != nil {
+		return 0, err
+	}
+
+	return lb.GetBlock().GetHeight(), nil
+}
+
+func rpcErrStatus(err error) int {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.FailedPrecondition:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
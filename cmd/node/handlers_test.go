@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"math/big"
+	"testing"
+
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/proto"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type stubConfig struct {
+	proto.ConfigServiceClient
+	chainID uint64
+}
+
+func (c stubConfig) GetChainConfig(ctx context.Context, in *proto.GetChainConfigRequest, opts ...grpc.CallOption) (*proto.GetChainConfigResponse, error) {
+	return &proto.GetChainConfigResponse{Config: &proto.ChainConfig{ChainId: c.chainID}}, nil
+}
+
+func TestSubmitTransaction_ForeignChainRaw(t *testing.T) {
+	n := &Node{
+		log:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		configRPC: stubConfig{chainID: 4242},
+	}
+
+	key, err := crypto.HexToECDSA("a6f7fa0885f49b8327376bdcc1da167750ec8004b1331705358c7fb697a74fbb")
+	assert.NoError(t, err)
+
+	to := common.HexToAddress("0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19b2")
+	for name, signer := range map[string]types.Signer{
+		"unprotected": types.HomesteadSigner{},
+		"other chain": types.LatestSignerForChainID(big.NewInt(1)),
+	} {
+		etx, err := types.SignNewTx(key, signer, &types.LegacyTx{To: &to, Value: big.NewInt(10), Gas: 21000, GasPrice: big.NewInt(1)})
+		assert.NoError(t, err)
+		b, err := etx.MarshalBinary()
+		assert.NoError(t, err)
+
+		txn, err := transaction.FromEthereum(hexutil.Encode(b))
+		assert.NoError(t, err)
+
+		// the state and mempool clients are nil, the transaction must be
+		// rejected before they are asked
+		_, err = n.submitTransaction(context.Background(), txn)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), name)
+		assert.Contains(t, status.Convert(err).Message(), "expected chain id 4242", name)
+	}
+}
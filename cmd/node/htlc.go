@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"com.perkunas/internal/errmsg"
+	"com.perkunas/internal/httpjsonres"
+	"com.perkunas/internal/models/htlc"
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/pkg/address"
+	"com.perkunas/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// verifyHTLC rejects malformed locks and settlements the state service would
+// reject before they reach the mempool. Claims are only accepted before the
+// timeout and expire with it, refunds only after it, so a pending claim and
+// refund of the same contract can not both become executable.
+func (n *Node) verifyHTLC(ctx context.Context, txn *transaction.Transaction) error {
+	now := time.Now().Unix()
+
+	switch txn.Type {
+	case transaction.TypeHTLCLock:
+		h, err := htlc.FromLockTransaction(txn)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		if h.Timeout <= max(now, txn.LockTime) {
+			return status.Errorf(codes.InvalidArgument, "%v: timeout is in the past", errmsg.ErrInvalidHTLC)
+		}
+		return nil
+
+	case transaction.TypeHTLCClaim, transaction.TypeHTLCRefund:
+	default:
+		return nil
+	}
+
+	res, err := n.stateRPC.GetHTLC(ctx, &proto.HTLCReq{Address: txn.To})
+	if status.Code(err) == codes.NotFound {
+		return status.Error(codes.InvalidArgument, errmsg.ErrUnknownHTLC.Error())
+	}
+	if err != nil {
+		return err
+	}
+	h := htlc.FromProto(res.GetHtlc())
+
+	if txn.Type == transaction.TypeHTLCClaim {
+		if _, err := h.VerifyClaim(txn, now); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		// a claim left in the mempool after the timeout would be rejected
+		txn.Expires = min(txn.Expires, h.Timeout)
+	} else {
+		if err := h.VerifyRefund(txn, now); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	pending, err := n.mempoolRPC.PendingTransactions(ctx, &proto.PendingTransactionsRequest{})
+	if err != nil {
+		return err
+	}
+
+	for _, ptx := range pending.GetTransactions() {
+		if ptx.GetToAddr() != h.Address || ptx.GetHash() == txn.Hash {
+			continue
+		}
+		if t := ptx.GetType(); t == transaction.TypeHTLCClaim || t == transaction.TypeHTLCRefund {
+			return status.Errorf(codes.InvalidArgument, "%v: settlement %s is pending", errmsg.ErrHTLCClosed, ptx.GetHash())
+		}
+	}
+
+	return nil
+}
+
+func (n *Node) htlcByAddress(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	addr, err := address.Parse(r.PathValue("address"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res, err := n.stateRPC.GetHTLC(r.Context(), &proto.HTLCReq{Address: addr})
+	if err != nil {
+		n.log.Error("could not get htlc", "err", err)
+		http.Error(w, "could not get htlc", rpcErrStatus(err))
+		return
+	}
+
+	if err := httpjsonres.JSON(w, http.StatusOK, res.GetHtlc()); err != nil {
+		n.log.Error("failed responding to get htlc request", "err", err)
+	}
+}
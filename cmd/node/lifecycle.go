@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"com.perkunas/internal/httpjsonres"
+	"com.perkunas/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Lifecycle stages of a submitted transaction.
+const (
+	lifecyclePending = "pending"
+	lifecycleMined   = "mined"
+	lifecycleDropped = "dropped"
+)
+
+// txLifecycle is where a transaction is after it was submitted. Mined
+// transactions carry their receipt status and confirmations, dropped ones
+// the reason they left the mempool.
+type txLifecycle struct {
+	Hash          string `json:"hash"`
+	Status        string `json:"status"`
+	ReceiptStatus string `json:"receipt_status,omitempty"`
+	BlockHeight   uint64 `json:"block_height,omitempty"`
+	BlockHash     string `json:"block_hash,omitempty"`
+	Confirmations uint64 `json:"confirmations,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+	DroppedAt     int64  `json:"dropped_at,omitempty"`
+}
+
+func (n *Node) transactionStatus(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	hash := r.PathValue("hash")
+	res, err := n.lifecycle(r.Context(), hash)
+	if err != nil {
+		if status.Code(err) != codes.NotFound {
+			n.log.Error("could not get transaction status", "err", err, "hash", hash)
+		}
+		http.Error(w, "could not get transaction status", rpcErrStatus(err))
+		return
+	}
+
+	if err := httpjsonres.JSON(w, http.StatusOK, res); err != nil {
+		n.log.Error("failed responding to transaction status request", "err", err)
+	}
+}
+
+// lifecycle looks the transaction up in the chain, the mempool and the
+// mempool's dropped transactions. The chain is asked again last because the
+// miner deletes mined transactions from the mempool after the block lands.
+func (n *Node) lifecycle(ctx context.Context, hash string) (*txLifecycle, error) {
+	if res, err := n.minedLifecycle(ctx, hash); status.Code(err) != codes.NotFound {
+		return res, err
+	}
+
+	_, err := n.mempoolRPC.GetTransaction(ctx, &proto.GetTransactionRequest{Hash: hash})
+	if err == nil {
+		return &txLifecycle{Hash: hash, Status: lifecyclePending}, nil
+	}
+	if status.Code(err) != codes.NotFound {
+		return nil, err
+	}
+
+	dropped, err := n.mempoolRPC.GetDropped(ctx, &proto.GetDroppedRequest{Hash: hash})
+	if err == nil {
+		return &txLifecycle{
+			Hash:      hash,
+			Status:    lifecycleDropped,
+			Reason:    dropped.GetReason(),
+			DroppedAt: dropped.GetDroppedAt(),
+		}, nil
+	}
+	if status.Code(err) != codes.NotFound {
+		return nil, err
+	}
+
+	return n.minedLifecycle(ctx, hash)
+}
+
+func (n *Node) minedLifecycle(ctx context.Context, hash string) (*txLifecycle, error) {
+	tx, err := n.stateRPC.GetTransaction(ctx, &proto.TransactionReq{Hash: hash})
+	if err != nil {
+		return nil, err
+	}
+
+	lb, err := n.stateRPC.GetLatestBlock(ctx, &proto.LastBlockReq{})
+	if err != nil {
+		return nil, err
+	}
+
+	res := &txLifecycle{
+		Hash:          hash,
+		Status:        lifecycleMined,
+		ReceiptStatus: tx.GetStatus(),
+		BlockHeight:   tx.GetHeight(),
+		BlockHash:     tx.GetBlockHash(),
+	}
+	if latest := lb.GetBlock().GetHeight(); latest >= tx.GetHeight() {
+		res.Confirmations = latest - tx.GetHeight() + 1
+	}
+
+	return res, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// stubChain serves mined transactions, hiding them from the first hidden
+// lookups as if their block landed in between.
+type stubChain struct {
+	proto.StateServiceClient
+	mined  map[string]*proto.TransactionRes
+	hidden int
+	calls  int
+}
+
+func (s *stubChain) GetTransaction(ctx context.Context, in *proto.TransactionReq, opts ...grpc.CallOption) (*proto.TransactionRes, error) {
+	s.calls++
+	if tx, ok := s.mined[in.GetHash()]; ok && s.calls > s.hidden {
+		return tx, nil
+	}
+
+	return nil, status.Error(codes.NotFound, "transaction not found")
+}
+
+func (s *stubChain) GetLatestBlock(ctx context.Context, in *proto.LastBlockReq, opts ...grpc.CallOption) (*proto.LastBlockRes, error) {
+	return &proto.LastBlockRes{Block: &proto.Block{Height: 5}}, nil
+}
+
+type stubMempool struct {
+	proto.MempoolServiceClient
+	pending map[string]bool
+	dropped map[string]string
+}
+
+func (m *stubMempool) GetTransaction(ctx context.Context, in *proto.GetTransactionRequest, opts ...grpc.CallOption) (*proto.GetTransactionResponse, error) {
+	if !m.pending[in.GetHash()] {
+		return nil, status.Error(codes.NotFound, "transaction not found")
+	}
+
+	return &proto.GetTransactionResponse{}, nil
+}
+
+func (m *stubMempool) GetDropped(ctx context.Context, in *proto.GetDroppedRequest, opts ...grpc.CallOption) (*proto.DroppedTransaction, error) {
+	reason, ok := m.dropped[in.GetHash()]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "dropped transaction not found")
+	}
+
+	return &proto.DroppedTransaction{Hash: in.GetHash(), Reason: reason, DroppedAt: 1}, nil
+}
+
+func TestLifecycle_LookupOrder(t *testing.T) {
+	ctx := context.Background()
+	chain := &stubChain{mined: map[string]*proto.TransactionRes{
+		"mined":  {Status: "ACCEPTED", Height: 3, BlockHash: "block"},
+		"landed": {Status: "ACCEPTED", Height: 5, BlockHash: "block"},
+	}}
+	mempool := &stubMempool{
+		pending: map[string]bool{"pending": true, "mined": true},
+		dropped: map[string]string{"dropped": transaction.DropReasonSuperseded, "mined": transaction.DropReasonSuperseded},
+	}
+	n := &Node{stateRPC: chain, mempoolRPC: mempool}
+
+	// the chain wins over what the mempool still knows
+	res, err := n.lifecycle(ctx, "mined")
+	assert.NoError(t, err)
+	assert.Equal(t, lifecycleMined, res.Status)
+	assert.Equal(t, uint64(3), res.Confirmations)
+
+	res, err = n.lifecycle(ctx, "pending")
+	assert.NoError(t, err)
+	assert.Equal(t, lifecyclePending, res.Status)
+
+	res, err = n.lifecycle(ctx, "dropped")
+	assert.NoError(t, err)
+	assert.Equal(t, lifecycleDropped, res.Status)
+	assert.Equal(t, transaction.DropReasonSuperseded, res.Reason)
+
+	// mined while the mempool was asked, after the miner deleted it
+	chain.calls, chain.hidden = 0, 1
+	res, err = n.lifecycle(ctx, "landed")
+	assert.NoError(t, err)
+	assert.Equal(t, lifecycleMined, res.Status)
+	assert.Equal(t, uint64(1), res.Confirmations)
+	assert.Equal(t, 2, chain.calls)
+
+	_, err = n.lifecycle(ctx, "unknown")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
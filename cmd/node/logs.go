@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"com.perkunas/internal/httpjsonres"
+	"com.perkunas/internal/models/eventlog"
+)
+
+// logs lists contract logs. The address param may repeat, topic0 to topic3
+// take comma separated alternatives, from, to and limit are optional.
+func (n *Node) logs(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	f, err := parseLogFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res, err := n.stateRPC.GetLogs(r.Context(), eventlog.FilterToProto(f))
+	if err != nil {
+		n.log.Error("could not get logs", "err", err)
+		http.Error(w, "could not get logs", rpcErrStatus(err))
+		return
+	}
+
+	logs := make([]eventlog.Log, 0, len(res.GetLogs()))
+	for _, l := range res.GetLogs() {
+		logs = append(logs, eventlog.FromProto(l))
+	}
+
+	if err := httpjsonres.JSON(w, http.StatusOK, map[string]any{"logs": logs}); err != nil {
+		n.log.Error("failed responding to get logs request", "err", err)
+	}
+}
+
+func parseLogFilter(q url.Values) (*eventlog.Filter, error) {
+	f := &eventlog.Filter{Addresses: q["address"]}
+
+	for i := range eventlog.MaxTopics {
+		v := q.Get(fmt.Sprintf("topic%d", i))
+		if v == "" {
+			f.Topics = append(f.Topics, nil)
+			continue
+		}
+		f.Topics = append(f.Topics, strings.Split(v, ","))
+	}
+
+	for name, dst := range map[string]*uint64{"from": &f.FromHeight, "to": &f.ToHeight} {
+		if v := q.Get(name); v != "" {
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s height", name)
+			}
+			*dst = n
+		}
+	}
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid limit")
+		}
+		f.Limit = n
+	}
+
+	if err := f.Normalize(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
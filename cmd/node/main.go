@@ -21,11 +21,15 @@ type Node struct {
 	proto.UnimplementedNodeServiceServer
 	log        *slog.Logger
 	apiPort    string
+	grpcPort   string
 	mempoolAPI string
 	stateAPI   string
 	peerNodes  []peernode.Node
 	mempoolRPC proto.MempoolServiceClient
 	stateRPC   proto.StateServiceClient
+	configRPC  proto.ConfigServiceClient
+	explorer   bool
+	faucet     *faucet
 }
 
 func main() {
@@ -33,6 +37,8 @@ func main() {
 	flag.StringVar(&n.mempoolAPI, "mempoolapi", os.Getenv("MEMPOOL_API"), "mempool api endpoint")
 	flag.StringVar(&n.stateAPI, "stateapi", os.Getenv("STATE_API"), "state api endpoint")
 	flag.StringVar(&n.apiPort, "apiport", os.Getenv("API_PORT"), "node api port")
+	flag.StringVar(&n.grpcPort, "grpcport", os.Getenv("GRPC_PORT"), "node grpc port, grpc is off when empty")
+	flag.BoolVar(&n.explorer, "explorer", os.Getenv("EXPLORER") == "true", "serve the block explorer under /explorer/")
 
 	// initiate mempool rpc client
 	memPoolConn, client, err := mempoolRpcClient(n.mempoolAPI)
@@ -51,6 +57,25 @@ func main() {
 	}
 	defer stateConn.Close()
 	n.stateRPC = stateClient
+	n.configRPC = proto.NewConfigServiceClient(stateConn)
+
+	// unlock the faucet account when one is configured
+	if n.faucet, err = newFaucet(n); err != nil {
+		n.log.Error("failed setting up faucet", "err", err)
+		os.Exit(1)
+	}
+	if n.faucet != nil {
+		n.log.Info("faucet enabled", "address", n.faucet.wallet.Address, "amount", n.faucet.amount, "interval", n.faucet.interval.String())
+	}
+
+	if n.grpcPort != "" {
+		go func() {
+			if err := n.startRPC(); err != nil {
+				n.log.Error("failed starting grpc server", "err", err)
+				os.Exit(1)
+			}
+		}()
+	}
 
 	// start http server
 	srv := httpServer(n.getRouter(), n.apiPort)
@@ -8,7 +8,39 @@ func (n *Node) getRouter() *http.ServeMux {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("POST /transactions", n.createTransaction)
+	mux.HandleFunc("GET /transactions/{hash}", n.transactionByHash)
+	mux.HandleFunc("GET /transactions/{hash}/status", n.transactionStatus)
+	mux.HandleFunc("GET /accounts/{address}", n.accountByAddress)
+	mux.HandleFunc("GET /accounts/{address}/transactions", n.accountTransactions)
+	mux.HandleFunc("GET /accounts/{address}/tokens", n.accountTokens)
+	mux.HandleFunc("GET /multisig/{address}", n.multisigByAddress)
+	mux.HandleFunc("GET /tokens/{address}", n.tokenByAddress)
+	mux.HandleFunc("GET /tokens/{address}/balances/{owner}", n.tokenBalance)
+	mux.HandleFunc("GET /tokens/{address}/allowances/{owner}/{spender}", n.tokenAllowance)
+	mux.HandleFunc("GET /htlc/{address}", n.htlcByAddress)
+	mux.HandleFunc("GET /contracts/{address}", n.contractByAddress)
+	mux.HandleFunc("GET /contracts/{address}/storage/{key}", n.contractStorage)
+	mux.HandleFunc("GET /logs", n.logs)
+	mux.HandleFunc("GET /mempool", n.pendingTransactions)
+	mux.HandleFunc("POST /verify", n.verifyMessage)
 	mux.HandleFunc("GET /status", n.nodeStatus)
+	mux.HandleFunc("GET /config", n.chainConfig)
+	mux.HandleFunc("GET /fees/estimate", n.feeEstimate)
+	mux.HandleFunc("GET /blocks/{height}", n.blockByHeight)
+	mux.HandleFunc("GET /headers/{height}", n.headerByHeight)
+	mux.HandleFunc("GET /proofs/transactions/{hash}", n.transactionProof)
+	mux.HandleFunc("GET /proofs/accounts/{address}", n.accountProof)
+	mux.HandleFunc("POST /rpc", n.ethRPC)
+	mux.Handle("GET /ws", n.webSocket())
+	mux.Handle("POST /graphql", n.graphQL())
+
+	if n.faucet != nil {
+		mux.Handle("POST /faucet", n.faucet)
+	}
+
+	if n.explorer {
+		mux.Handle("GET /explorer/", explorerUI())
+	}
 
 	return mux
 }
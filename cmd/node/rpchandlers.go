@@ -2,12 +2,31 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"net"
 
 	"com.perkunas/proto"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
 )
 
+// startRPC serves the NodeService on the grpc port.
+func (n *Node) startRPC() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%s", n.grpcPort))
+	if err != nil {
+		return fmt.Errorf("failed starting net listener %w", err)
+	}
+
+	server := grpc.NewServer()
+	reflection.Register(server)
+	proto.RegisterNodeServiceServer(server, n)
+
+	n.log.Info("rpc server started", "port exposed", n.grpcPort)
+	return server.Serve(listener)
+}
+
 func (n *Node) GetNodeStatus(ctx context.Context, req *proto.GetNodeStatusRequest) (*proto.NodeStatusResponse, error) {
 	latestBlock, err := n.stateRPC.GetLatestBlock(ctx, nil)
 	if err != nil {
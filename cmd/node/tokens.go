@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"com.perkunas/internal/errmsg"
+	"com.perkunas/internal/httpjsonres"
+	"com.perkunas/internal/models/token"
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/pkg/address"
+	"com.perkunas/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// verifyToken rejects malformed token transactions and transfers the sender
+// can not cover before they reach the mempool. Pending transfers are counted,
+// a block with a transfer the state service rejects would never be mined.
+func (n *Node) verifyToken(ctx context.Context, txn *transaction.Transaction) error {
+	switch txn.Type {
+	case transaction.TypeTokenCreate:
+		if _, err := token.FromCreateTransaction(txn); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil
+
+	case transaction.TypeTokenTransfer, transaction.TypeTokenApprove:
+	default:
+		return nil
+	}
+
+	tr, err := token.ParseTransfer(txn)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	_, err = n.stateRPC.GetToken(ctx, &proto.TokenReq{Address: tr.Token})
+	if status.Code(err) == codes.NotFound {
+		return status.Error(codes.InvalidArgument, errmsg.ErrUnknownToken.Error())
+	}
+	if err != nil || txn.Type == transaction.TypeTokenApprove {
+		return err
+	}
+
+	holder := tr.Holder(txn)
+	spentBalance, spentAllowance, err := n.pendingTokenSpends(ctx, tr.Token, holder, txn.From)
+	if err != nil {
+		return err
+	}
+
+	bal, err := n.stateRPC.GetTokenBalance(ctx, &proto.TokenBalanceReq{Token: tr.Token, Address: holder})
+	if err != nil {
+		return err
+	}
+	if bal.GetBalance().GetBalance()-spentBalance < tr.Amount {
+		return status.Error(codes.InvalidArgument, errmsg.ErrInsufficientTokens.Error())
+	}
+
+	if holder == txn.From {
+		return nil
+	}
+
+	allowance, err := n.stateRPC.GetTokenAllowance(ctx, &proto.TokenAllowanceReq{Token: tr.Token, Owner: holder, Spender: txn.From})
+	if err != nil {
+		return err
+	}
+	if allowance.GetAmount()-spentAllowance < tr.Amount {
+		return status.Error(codes.InvalidArgument, errmsg.ErrInsufficientAllowance.Error())
+	}
+
+	return nil
+}
+
+// pendingTokenSpends sums the pending transfers of holder's tokens, and the
+// part of them spender makes through an allowance.
+func (n *Node) pendingTokenSpends(ctx context.Context, tokenAddr, holder, spender string) (balance, allowance int64, err error) {
+	res, err := n.mempoolRPC.PendingTransactions(ctx, &proto.PendingTransactionsRequest{})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, ptx := range res.GetTransactions() {
+		tx := transaction.FromProtoTx(ptx)
+		if tx.Type != transaction.TypeTokenTransfer {
+			continue
+		}
+
+		tr, err := token.ParseTransfer(&tx)
+		if err != nil || tr.Token != tokenAddr || tr.Holder(&tx) != holder {
+			continue
+		}
+
+		balance += tr.Amount
+		if tx.From == spender && tr.Owner != "" {
+			allowance += tr.Amount
+		}
+	}
+
+	return balance, allowance, nil
+}
+
+func (n *Node) tokenByAddress(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	addr, err := address.Parse(r.PathValue("address"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res, err := n.stateRPC.GetToken(r.Context(), &proto.TokenReq{Address: addr})
+	if err != nil {
+		n.log.Error("could not get token", "err", err)
+		http.Error(w, "could not get token", rpcErrStatus(err))
+		return
+	}
+
+	if err := httpjsonres.JSON(w, http.StatusOK, res.GetToken()); err != nil {
+		n.log.Error("failed responding to get token request", "err", err)
+	}
+}
+
+func (n *Node) tokenBalance(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	addrs, err := parseAddresses(r.PathValue("address"), r.PathValue("owner"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res, err := n.stateRPC.GetTokenBalance(r.Context(), &proto.TokenBalanceReq{Token: addrs[0], Address: addrs[1]})
+	if err != nil {
+		n.log.Error("could not get token balance", "err", err)
+		http.Error(w, "could not get token balance", rpcErrStatus(err))
+		return
+	}
+
+	if err := httpjsonres.JSON(w, http.StatusOK, res.GetBalance()); err != nil {
+		n.log.Error("failed responding to get token balance request", "err", err)
+	}
+}
+
+func (n *Node) tokenAllowance(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	addrs, err := parseAddresses(r.PathValue("address"), r.PathValue("owner"), r.PathValue("spender"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res, err := n.stateRPC.GetTokenAllowance(r.Context(), &proto.TokenAllowanceReq{Token: addrs[0], Owner: addrs[1], Spender: addrs[2]})
+	if err != nil {
+		n.log.Error("could not get token allowance", "err", err)
+		http.Error(w, "could not get token allowance", rpcErrStatus(err))
+		return
+	}
+
+	if err := httpjsonres.JSON(w, http.StatusOK, res); err != nil {
+		n.log.Error("failed responding to get token allowance request", "err", err)
+	}
+}
+
+func (n *Node) accountTokens(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	addr, err := address.Parse(r.PathValue("address"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res, err := n.stateRPC.GetTokenBalances(r.Context(), &proto.TokenBalancesReq{Address: addr})
+	if err != nil {
+		n.log.Error("could not get token balances", "err", err)
+		http.Error(w, "could not get token balances", rpcErrStatus(err))
+		return
+	}
+
+	if err := httpjsonres.JSON(w, http.StatusOK, res); err != nil {
+		n.log.Error("failed responding to get token balances request", "err", err)
+	}
+}
+
+// parseAddresses parses address path values into their checksummed form.
+func parseAddresses(in ...string) ([]string, error) {
+	res := make([]string, len(in))
+	for i, s := range in {
+		addr, err := address.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = addr
+	}
+
+	return res, nil
+}
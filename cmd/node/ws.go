@@ -0,0 +1,397 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"com.perkunas/pkg/address"
+	"com.perkunas/proto"
+	"golang.org/x/net/websocket"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Subscription topics of the /ws endpoint.
+const (
+	topicBlocks       = "blocks"
+	topicPendingTxs   = "pending_transactions"
+	topicAddress      = "address"
+	topicConfirmation = "confirmation"
+)
+
+const (
+	// wsMaxSubscriptions caps the subscriptions of one connection.
+	wsMaxSubscriptions = 32
+	// wsMaxConfirmations caps how many blocks a confirmation is followed.
+	wsMaxConfirmations = 100
+	wsWriteTimeout     = 10 * time.Second
+)
+
+type wsRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params wsParams        `json:"params"`
+}
+
+type wsParams struct {
+	Topic         string `json:"topic"`
+	Address       string `json:"address"`
+	Hash          string `json:"hash"`
+	Confirmations uint64 `json:"confirmations"`
+	Subscription  string `json:"subscription"`
+}
+
+type wsResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result any             `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// wsNotification carries the data of a subscription. Done is set on the
+// last one, when the subscription completed or failed with Error.
+type wsNotification struct {
+	Subscription string `json:"subscription"`
+	Topic        string `json:"topic"`
+	Data         any    `json:"data,omitempty"`
+	Done         bool   `json:"done,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// wsActivity is a transaction sent from or to a watched address, PENDING
+// when it enters the mempool and with its receipt status once mined.
+type wsActivity struct {
+	Transaction *proto.Transaction `json:"transaction"`
+	Status      string             `json:"status"`
+	BlockHeight uint64             `json:"block_height,omitempty"`
+	BlockHash   string             `json:"block_hash,omitempty"`
+}
+
+type wsConfirmation struct {
+	Hash          string `json:"hash"`
+	Status        string `json:"status"`
+	BlockHeight   uint64 `json:"block_height"`
+	BlockHash     string `json:"block_hash"`
+	Confirmations uint64 `json:"confirmations"`
+}
+
+// wsSession is one websocket connection and its subscriptions.
+type wsSession struct {
+	n      *Node
+	ws     *websocket.Conn
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	writeMu sync.Mutex
+
+	mu     sync.Mutex
+	subs   map[string]context.CancelFunc
+	nextID uint64
+}
+
+// webSocket serves subscriptions to new blocks, pending transactions, the
+// activity of an address and the confirmation of a transaction. Requests are
+// {"id": 1, "method": "subscribe", "params": {"topic": "blocks"}} and
+// {"id": 2, "method": "unsubscribe", "params": {"subscription": "1"}}, the
+// data arrives as notifications naming the subscription.
+func (n *Node) webSocket() http.Handler {
+	// no origin check, everything served is public chain data
+	return websocket.Server{Handler: n.serveWS}
+}
+
+func (n *Node) serveWS(ws *websocket.Conn) {
+	defer ws.Close()
+
+	// the http server deadlines would cut the connection
+	if err := ws.SetDeadline(time.Time{}); err != nil {
+		n.log.Error("could not clear websocket deadline", "err", err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ws.Request().Context())
+	defer cancel()
+
+	s := &wsSession{n: n, ws: ws, ctx: ctx, cancel: cancel, subs: make(map[string]context.CancelFunc)}
+	for {
+		var req wsRequest
+		err := websocket.JSON.Receive(ws, &req)
+
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr) || errors.As(err, &typeErr):
+			s.send(wsResponse{Error: "invalid request"})
+			continue
+		case errors.Is(err, io.EOF) || ctx.Err() != nil:
+			return
+		case err != nil:
+			n.log.Warn("websocket read failed", "err", err)
+			return
+		}
+
+		switch req.Method {
+		case "subscribe":
+			id, err := s.subscribe(req.Params)
+			if err != nil {
+				s.send(wsResponse{ID: req.ID, Error: err.Error()})
+				continue
+			}
+			s.send(wsResponse{ID: req.ID, Result: id})
+		case "unsubscribe":
+			s.send(wsResponse{ID: req.ID, Result: s.unsubscribe(req.Params.Subscription)})
+		default:
+			s.send(wsResponse{ID: req.ID, Error: fmt.Sprintf("unknown method %q", req.Method)})
+		}
+	}
+}
+
+// send writes v, a failed write closes the connection.
+func (s *wsSession) send(v any) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if s.ctx.Err() != nil {
+		return
+	}
+
+	s.ws.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	if err := websocket.JSON.Send(s.ws, v); err != nil {
+		s.n.log.Warn("websocket write failed", "err", err)
+		s.cancel()
+		s.ws.Close()
+	}
+}
+
+func (s *wsSession) subscribe(p wsParams) (string, error) {
+	run, err := s.n.wsTopic(p)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.subs) >= wsMaxSubscriptions {
+		return "", fmt.Errorf("at most %d subscriptions per connection", wsMaxSubscriptions)
+	}
+
+	s.nextID++
+	id := strconv.FormatUint(s.nextID, 10)
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.subs[id] = cancel
+
+	go func() {
+		defer s.unsubscribe(id)
+
+		err := run(ctx, func(data any) {
+			s.send(wsNotification{Subscription: id, Topic: p.Topic, Data: data})
+		})
+
+		// unsubscribed or disconnected, nobody to tell
+		if ctx.Err() != nil {
+			return
+		}
+
+		done := wsNotification{Subscription: id, Topic: p.Topic, Done: true}
+		if err != nil {
+			s.n.log.Warn("websocket subscription failed", "topic", p.Topic, "err", err)
+			done.Error = status.Convert(err).Message()
+		}
+		s.send(done)
+	}()
+
+	return id, nil
+}
+
+func (s *wsSession) unsubscribe(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cancel, ok := s.subs[id]
+	if ok {
+		cancel()
+		delete(s.subs, id)
+	}
+
+	return ok
+}
+
+// wsRunner streams the data of a subscription until ctx is done, a nil
+// error means the subscription completed.
+type wsRunner func(ctx context.Context, notify func(any)) error
+
+func (n *Node) wsTopic(p wsParams) (wsRunner, error) {
+	switch p.Topic {
+	case topicBlocks:
+		return n.wsBlocks, nil
+
+	case topicPendingTxs:
+		return n.wsPendingTransactions, nil
+
+	case topicAddress:
+		addr, err := address.Parse(p.Address)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, notify func(any)) error {
+			return n.wsAddress(ctx, addr, notify)
+		}, nil
+
+	case topicConfirmation:
+		hash := strings.TrimPrefix(p.Hash, "0x")
+		if hash == "" {
+			return nil, errors.New("hash is required")
+		}
+		confirmations := max(p.Confirmations, 1)
+		if confirmations > wsMaxConfirmations {
+			return nil, fmt.Errorf("at most %d confirmations can be followed", wsMaxConfirmations)
+		}
+		return func(ctx context.Context, notify func(any)) error {
+			return n.wsConfirmation(ctx, hash, confirmations, notify)
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown topic %q", p.Topic)
+	}
+}
+
+func (n *Node) wsBlocks(ctx context.Context, notify func(any)) error {
+	stream, err := n.stateRPC.SubscribeBlocks(ctx, &proto.SubscribeBlocksReq{})
+	if err != nil {
+		return err
+	}
+
+	for {
+		ev, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		notify(ev.GetBlock())
+	}
+}
+
+func (n *Node) wsPendingTransactions(ctx context.Context, notify func(any)) error {
+	stream, err := n.mempoolRPC.SubscribeTransactions(ctx, &proto.SubscribeTransactionsRequest{})
+	if err != nil {
+		return err
+	}
+
+	for {
+		tx, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		notify(tx)
+	}
+}
+
+// wsAddress follows the mempool and the chain for transactions from or to addr.
+func (n *Node) wsAddress(ctx context.Context, addr string, notify func(any)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	involves := func(tx *proto.Transaction) bool {
+		return tx.GetFromAddr() == addr || tx.GetToAddr() == addr
+	}
+
+	pending := make(chan error, 1)
+	go func() {
+		pending <- n.wsPendingTransactions(ctx, func(data any) {
+			if tx := data.(*proto.Transaction); involves(tx) {
+				notify(wsActivity{Transaction: tx, Status: txStatusPending})
+			}
+		})
+		cancel()
+	}()
+
+	stream, err := n.stateRPC.SubscribeBlocks(ctx, &proto.SubscribeBlocksReq{})
+	if err != nil {
+		return err
+	}
+
+	for {
+		ev, err := stream.Recv()
+		if err != nil {
+			// the mempool stream failing cancels this one, report its error
+			if ctx.Err() != nil {
+				select {
+				case err = <-pending:
+				default:
+				}
+			}
+			return err
+		}
+
+		b := ev.GetBlock()
+		for _, tx := range b.GetTransactions() {
+			if involves(tx) {
+				notify(wsActivity{
+					Transaction: tx,
+					Status:      ev.GetStatuses()[tx.GetHash()],
+					BlockHeight: b.GetHeight(),
+					BlockHash:   b.GetHash(),
+				})
+			}
+		}
+	}
+}
+
+// wsConfirmation notifies when the transaction is mined and on every block
+// after, until it has the wanted confirmations. A transaction mined before
+// the subscription is picked up from the chain.
+func (n *Node) wsConfirmation(ctx context.Context, hash string, want uint64, notify func(any)) error {
+	// subscribe before the lookup so no block falls in between
+	stream, err := n.stateRPC.SubscribeBlocks(ctx, &proto.SubscribeBlocksReq{})
+	if err != nil {
+		return err
+	}
+	if _, err := stream.Header(); err != nil {
+		return err
+	}
+
+	var mined *wsConfirmation
+	res, err := n.stateRPC.GetTransaction(ctx, &proto.TransactionReq{Hash: hash})
+	switch {
+	case err == nil:
+		mined = &wsConfirmation{Hash: hash, Status: res.GetStatus(), BlockHeight: res.GetHeight(), BlockHash: res.GetBlockHash()}
+		lb, err := n.stateRPC.GetLatestBlock(ctx, &proto.LastBlockReq{})
+		if err != nil {
+			return err
+		}
+		mined.Confirmations = lb.GetBlock().GetHeight() - mined.BlockHeight + 1
+		notify(*mined)
+	case status.Code(err) != codes.NotFound:
+		return err
+	}
+
+	for mined == nil || mined.Confirmations < want {
+		ev, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		b := ev.GetBlock()
+		if mined == nil {
+			st, ok := ev.GetStatuses()[hash]
+			if !ok {
+				continue
+			}
+			mined = &wsConfirmation{Hash: hash, Status: st, BlockHeight: b.GetHeight(), BlockHash: b.GetHash()}
+		} else if b.GetHeight() < mined.BlockHeight+mined.Confirmations {
+			// already counted by the lookup
+			continue
+		}
+
+		mined.Confirmations = b.GetHeight() - mined.BlockHeight + 1
+		notify(*mined)
+	}
+
+	return nil
+}
@@ -3,18 +3,27 @@ package main
 import (
 	"context"
 	_ "embed"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log/slog"
 	"os"
 
+	"com.perkunas/internal/consensus"
 	"com.perkunas/internal/db"
+	"com.perkunas/internal/feed"
 	"com.perkunas/internal/logger"
 	"com.perkunas/internal/models/account"
 	"com.perkunas/internal/models/balancechange"
 	"com.perkunas/internal/models/block"
+	"com.perkunas/internal/models/contract"
+	"com.perkunas/internal/models/eventlog"
 	"com.perkunas/internal/models/genesisblock"
+	"com.perkunas/internal/models/htlc"
+	"com.perkunas/internal/models/multisig"
 	"com.perkunas/internal/models/receipt"
+	"com.perkunas/internal/models/token"
+	"com.perkunas/proto"
 )
 
 //go:embed sql/state.sql
@@ -39,6 +48,19 @@ func main() {
 	}
 	defer db.Close()
 
+	var genesis genesisblock.GenesisBlock
+	if err := json.Unmarshal([]byte(genesisJson), &genesis); err != nil {
+		log.Error("unable to unmarshal genesis block json", "err", err)
+		os.Exit(1)
+	}
+
+	// state only verifies blocks so engine does not need a signer key
+	engine, err := consensus.New(genesis.Config, nil)
+	if err != nil {
+		log.Error("failed setting up consensus engine", "err", err, "consensus", genesis.Config.Consensus)
+		os.Exit(1)
+	}
+
 	s := &State{
 		db:                 db,
 		log:                log,
@@ -46,14 +68,28 @@ func main() {
 		balanceChangeModel: &balancechange.Model{DB: db},
 		blockModel:         &block.Model{DB: db},
 		genesisBlockModel:  &genesisblock.Model{DB: db},
+		multisigModel:      &multisig.Model{DB: db},
+		tokenModel:         &token.Model{DB: db},
+		htlcModel:          &htlc.Model{DB: db},
+		contractModel:      &contract.Model{DB: db},
+		logModel:           &eventlog.Model{DB: db},
+		logFeed:            &feed.Feed[eventlog.BlockLogs]{},
+		blockFeed:          &feed.Feed[*proto.BlockEvent]{},
 		receiptModel:       &receipt.Model{DB: db},
+		chainConfig:        genesis.Config,
+		engine:             engine,
 	}
 
-	if err := s.ensureGenesisBlock(ctx); err != nil {
+	if err := s.ensureGenesisBlock(ctx, genesis); err != nil {
 		s.log.Error("failed to create genesis block", "err", err)
 		os.Exit(1)
 	}
 
+	if err := s.loadStateTree(ctx); err != nil {
+		s.log.Error("failed to load state tree", "err", err)
+		os.Exit(1)
+	}
+
 	flag.StringVar(&s.apiPort, "apiport", os.Getenv("API_PORT"), "api port")
 	if err := s.Start(); err != nil {
 		log.Error("failed to start grpc server", "err", err)
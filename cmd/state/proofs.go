@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"com.perkunas/internal/smt"
+	"com.perkunas/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *State) GetTransactionProof(ctx context.Context, in *proto.TxProofReq) (*proto.TxProofRes, error) {
+	rcpt, err := s.receiptModel.GetByTxHash(ctx, in.GetTxHash())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "transaction not found")
+	}
+	if err != nil {
+		s.log.Error("failed getting receipt", "err", err, "txHash", in.GetTxHash())
+		return nil, status.Error(codes.Internal, "failed getting receipt")
+	}
+
+	blockDB, err := s.blockModel.GetByHash(ctx, rcpt.BlockHash)
+	if err != nil {
+		s.log.Error("failed getting block", "err", err, "blockHash", rcpt.BlockHash)
+		return nil, status.Error(codes.Internal, "failed getting block")
+	}
+
+	b, err := blockDB.WithTransactions()
+	if err != nil {
+		s.log.Error("failed decoding block transactions", "err", err, "blockHash", rcpt.BlockHash)
+		return nil, status.Error(codes.Internal, "failed decoding block transactions")
+	}
+
+	p, err := b.MerkleProof(in.GetTxHash())
+	if err != nil {
+		s.log.Error("failed building transaction proof", "err", err, "txHash", in.GetTxHash())
+		return nil, status.Error(codes.Internal, "failed building transaction proof")
+	}
+
+	return &proto.TxProofRes{
+		TxHash:     p.TxHash,
+		BlockHash:  p.BlockHash,
+		Height:     p.Height,
+		MerkleRoot: p.MerkleRoot,
+		Index:      p.Index,
+		Siblings:   p.Siblings,
+	}, nil
+}
+
+func (s *State) GetAccountProof(ctx context.Context, in *proto.AccountProofReq) (*proto.AccountProofRes, error) {
+	blockDB, err := s.blockModel.GetByHeight(ctx, in.GetHeight())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "block not found")
+	}
+	if err != nil {
+		s.log.Error("failed getting block", "err", err, "height", in.GetHeight())
+		return nil, status.Error(codes.Internal, "failed getting block")
+	}
+
+	accounts, err := s.accModel.AllAtHeight(ctx, in.GetHeight())
+	if err != nil {
+		s.log.Error("failed getting accounts at height", "err", err, "height", in.GetHeight())
+		return nil, status.Error(codes.Internal, "failed getting accounts at height")
+	}
+
+	tree := smt.New(toLeaves(accounts))
+	if tree.Root() != blockDB.StateRoot {
+		s.log.Error("account history does not match block state root", "height", in.GetHeight(), "stateRoot", blockDB.StateRoot)
+		return nil, status.Error(codes.FailedPrecondition, "state for height is not available")
+	}
+
+	p := tree.Prove(in.GetAddress())
+	res := &proto.AccountProofRes{
+		Address:     p.Address,
+		Balance:     p.Balance,
+		Nonce:       p.Nonce,
+		Exists:      p.Exists,
+		Height:      blockDB.Height,
+		StateRoot:   p.StateRoot,
+		Bitmap:      p.Bitmap,
+		Siblings:    p.Siblings,
+		StorageRoot: p.StorageRoot,
+	}
+
+	if key := in.GetStorageKey(); key != "" {
+		storage, err := s.storageProof(ctx, p.Address, p.StorageRoot, key)
+		if err != nil {
+			return nil, err
+		}
+		res.Storage = storage
+	}
+
+	return res, nil
+}
+
+// storageProof proves key against the storage root an account had at some
+// height. Only the current storage is kept, so the root has to be the
+// account's current one.
+func (s *State) storageProof(ctx context.Context, address, storageRoot, key string) (*proto.StorageProof, error) {
+	storage, err := s.accModel.Storage(ctx, address)
+	if err != nil {
+		s.log.Error("failed getting account storage", "err", err, "address", address)
+		return nil, status.Error(codes.Internal, "failed getting account storage")
+	}
+
+	items := toItems(storage)
+	if smt.StorageRoot(items) != storageRoot {
+		return nil, status.Error(codes.FailedPrecondition, "storage for height is not available")
+	}
+
+	p := smt.NewStorage(items).ProveStorage(key)
+	return &proto.StorageProof{
+		Key:      p.Key,
+		Value:    p.Value,
+		Exists:   p.Exists,
+		Bitmap:   p.Bitmap,
+		Siblings: p.Siblings,
+	}, nil
+}
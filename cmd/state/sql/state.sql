@@ -3,6 +3,7 @@ CREATE TABLE IF NOT EXISTS accounts (
   address TEXT UNIQUE NOT NULL,
   balance INTEGER NOT NULL DEFAULT 0 CHECK (balance >= 0),
   nonce INTEGER NOT NULL DEFAULT 0 CHECK (nonce >= 0),
+  storage_root TEXT NOT NULL DEFAULT '',
   timestamp INTEGER NOT NULL DEFAULT (strftime('%s', 'now'))
 ) STRICT;
 
@@ -20,6 +21,25 @@ WHERE
 
 END;
 
+-- Account state per block height, used to prove state of past blocks
+CREATE TABLE IF NOT EXISTS account_history (
+  address TEXT NOT NULL,
+  balance INTEGER NOT NULL,
+  nonce INTEGER NOT NULL,
+  storage_root TEXT NOT NULL DEFAULT '',
+  block_height INTEGER NOT NULL,
+  PRIMARY KEY (address, block_height)
+) STRICT;
+
+-- M-of-N accounts, owners is a JSON array of addresses
+CREATE TABLE IF NOT EXISTS multisig_accounts (
+  address TEXT PRIMARY KEY,
+  threshold INTEGER NOT NULL CHECK (threshold > 0),
+  owners TEXT NOT NULL CHECK (json_valid(owners)),
+  tx_hash TEXT NOT NULL,
+  block_height INTEGER NOT NULL
+) STRICT;
+
 -- For transaction history/audit
 CREATE TABLE IF NOT EXISTS balance_changes (
   id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -42,9 +62,12 @@ CREATE TABLE IF NOT EXISTS blocks (
   hash TEXT PRIMARY KEY,
   prev_hash TEXT NOT NULL,
   merkle_root TEXT NOT NULL,
+  state_root TEXT NOT NULL DEFAULT '',
   height INTEGER NOT NULL UNIQUE DEFAULT 0 CHECK (height >= 0),
   difficulty INTEGER NOT NULL DEFAULT 0 CHECK (nonce >= 0),
   nonce INTEGER NOT NULL DEFAULT 0 CHECK (nonce >= 0),
+  miner TEXT NOT NULL DEFAULT '',
+  signature TEXT NOT NULL DEFAULT '',
   timestamp INTEGER NOT NULL DEFAULT (strftime('%s', 'now')),
   transactions TEXT DEFAULT '[]' CHECK (json_valid(transactions))
 ) STRICT;
@@ -57,6 +80,7 @@ CREATE TABLE IF NOT EXISTS receipts (
   status TEXT NOT NULL,
   gas_used INTEGER NOT NULL,
   logs TEXT DEFAULT '[]' CHECK (json_valid(logs)),
+  error TEXT NOT NULL DEFAULT '',
   timestamp INTEGER NOT NULL DEFAULT (strftime('%s', 'now')),
   FOREIGN KEY (block_hash) REFERENCES blocks(hash) ON DELETE CASCADE
 ) STRICT;
@@ -64,3 +88,260 @@ CREATE TABLE IF NOT EXISTS receipts (
 CREATE INDEX IF NOT EXISTS idx_receipts_block_hash ON receipts(block_hash);
 
 CREATE INDEX IF NOT EXISTS idx_receipts_tx_hash ON receipts(tx_hash);
+
+CREATE TABLE IF NOT EXISTS tokens (
+  address TEXT PRIMARY KEY,
+  symbol TEXT NOT NULL,
+  name TEXT NOT NULL DEFAULT '',
+  decimals INTEGER NOT NULL CHECK (decimals >= 0 AND decimals <= 18),
+  supply INTEGER NOT NULL CHECK (supply > 0),
+  creator TEXT NOT NULL,
+  tx_hash TEXT NOT NULL,
+  block_height INTEGER NOT NULL
+) STRICT;
+
+CREATE TABLE IF NOT EXISTS token_balances (
+  token TEXT NOT NULL,
+  address TEXT NOT NULL,
+  balance INTEGER NOT NULL DEFAULT 0 CHECK (balance >= 0),
+  PRIMARY KEY (token, address),
+  FOREIGN KEY (token) REFERENCES tokens(address)
+) STRICT;
+
+CREATE INDEX IF NOT EXISTS idx_token_balances_address ON token_balances(address);
+
+CREATE TABLE IF NOT EXISTS token_allowances (
+  token TEXT NOT NULL,
+  owner TEXT NOT NULL,
+  spender TEXT NOT NULL,
+  amount INTEGER NOT NULL CHECK (amount >= 0),
+  PRIMARY KEY (token, owner, spender),
+  FOREIGN KEY (token) REFERENCES tokens(address)
+) STRICT;
+
+CREATE INDEX IF NOT EXISTS idx_token_allowances_owner ON token_allowances(owner);
+
+CREATE TABLE IF NOT EXISTS htlcs (
+  address TEXT PRIMARY KEY,
+  sender TEXT NOT NULL,
+  recipient TEXT NOT NULL,
+  amount INTEGER NOT NULL CHECK (amount > 0),
+  hash_lock TEXT NOT NULL,
+  timeout INTEGER NOT NULL,
+  status TEXT NOT NULL CHECK (status IN ('OPEN', 'CLAIMED', 'REFUNDED')),
+  preimage TEXT NOT NULL DEFAULT '',
+  tx_hash TEXT NOT NULL,
+  block_height INTEGER NOT NULL
+) STRICT;
+
+CREATE INDEX IF NOT EXISTS idx_htlcs_recipient ON htlcs(recipient);
+
+CREATE TABLE IF NOT EXISTS contracts (
+  address TEXT PRIMARY KEY,
+  creator TEXT NOT NULL,
+  code TEXT NOT NULL,
+  tx_hash TEXT NOT NULL,
+  block_height INTEGER NOT NULL
+) STRICT;
+
+CREATE TABLE IF NOT EXISTS contract_storage (
+  contract TEXT NOT NULL,
+  key TEXT NOT NULL,
+  value TEXT NOT NULL,
+  PRIMARY KEY (contract, key),
+  FOREIGN KEY (contract) REFERENCES contracts(address)
+) STRICT;
+
+-- Addresses whose leaf in the state tree changed since the last state root.
+-- The triggers skip known addresses rather than INSERT OR IGNORE, which an
+-- outer upsert would override.
+CREATE TABLE IF NOT EXISTS state_changes (address TEXT PRIMARY KEY) STRICT;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_accounts_insert
+AFTER INSERT ON accounts BEGIN
+INSERT INTO state_changes (address)
+SELECT NEW.address
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = NEW.address);
+END;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_accounts_update
+AFTER UPDATE OF balance, nonce ON accounts BEGIN
+INSERT INTO state_changes (address)
+SELECT NEW.address
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = NEW.address);
+END;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_multisig_accounts_insert
+AFTER INSERT ON multisig_accounts BEGIN
+INSERT INTO state_changes (address)
+SELECT NEW.address
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = NEW.address);
+END;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_multisig_accounts_update
+AFTER UPDATE ON multisig_accounts BEGIN
+INSERT INTO state_changes (address)
+SELECT NEW.address
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = NEW.address);
+END;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_multisig_accounts_delete
+AFTER DELETE ON multisig_accounts BEGIN
+INSERT INTO state_changes (address)
+SELECT OLD.address
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = OLD.address);
+END;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_tokens_insert
+AFTER INSERT ON tokens BEGIN
+INSERT INTO state_changes (address)
+SELECT NEW.address
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = NEW.address);
+END;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_tokens_update
+AFTER UPDATE ON tokens BEGIN
+INSERT INTO state_changes (address)
+SELECT NEW.address
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = NEW.address);
+END;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_tokens_delete
+AFTER DELETE ON tokens BEGIN
+INSERT INTO state_changes (address)
+SELECT OLD.address
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = OLD.address);
+END;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_token_balances_insert
+AFTER INSERT ON token_balances BEGIN
+INSERT INTO state_changes (address)
+SELECT NEW.address
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = NEW.address);
+END;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_token_balances_update
+AFTER UPDATE ON token_balances BEGIN
+INSERT INTO state_changes (address)
+SELECT NEW.address
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = NEW.address);
+END;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_token_balances_delete
+AFTER DELETE ON token_balances BEGIN
+INSERT INTO state_changes (address)
+SELECT OLD.address
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = OLD.address);
+END;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_token_allowances_insert
+AFTER INSERT ON token_allowances BEGIN
+INSERT INTO state_changes (address)
+SELECT NEW.owner
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = NEW.owner);
+END;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_token_allowances_update
+AFTER UPDATE ON token_allowances BEGIN
+INSERT INTO state_changes (address)
+SELECT NEW.owner
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = NEW.owner);
+END;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_token_allowances_delete
+AFTER DELETE ON token_allowances BEGIN
+INSERT INTO state_changes (address)
+SELECT OLD.owner
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = OLD.owner);
+END;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_htlcs_insert
+AFTER INSERT ON htlcs BEGIN
+INSERT INTO state_changes (address)
+SELECT NEW.address
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = NEW.address);
+END;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_htlcs_update
+AFTER UPDATE ON htlcs BEGIN
+INSERT INTO state_changes (address)
+SELECT NEW.address
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = NEW.address);
+END;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_htlcs_delete
+AFTER DELETE ON htlcs BEGIN
+INSERT INTO state_changes (address)
+SELECT OLD.address
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = OLD.address);
+END;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_contracts_insert
+AFTER INSERT ON contracts BEGIN
+INSERT INTO state_changes (address)
+SELECT NEW.address
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = NEW.address);
+END;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_contracts_update
+AFTER UPDATE ON contracts BEGIN
+INSERT INTO state_changes (address)
+SELECT NEW.address
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = NEW.address);
+END;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_contracts_delete
+AFTER DELETE ON contracts BEGIN
+INSERT INTO state_changes (address)
+SELECT OLD.address
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = OLD.address);
+END;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_contract_storage_insert
+AFTER INSERT ON contract_storage BEGIN
+INSERT INTO state_changes (address)
+SELECT NEW.contract
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = NEW.contract);
+END;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_contract_storage_update
+AFTER UPDATE ON contract_storage BEGIN
+INSERT INTO state_changes (address)
+SELECT NEW.contract
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = NEW.contract);
+END;
+
+CREATE TRIGGER IF NOT EXISTS state_changes_contract_storage_delete
+AFTER DELETE ON contract_storage BEGIN
+INSERT INTO state_changes (address)
+SELECT OLD.contract
+WHERE NOT EXISTS (SELECT 1 FROM state_changes WHERE address = OLD.contract);
+END;
+
+CREATE TABLE IF NOT EXISTS logs (
+  block_height INTEGER NOT NULL,
+  block_hash TEXT NOT NULL,
+  log_index INTEGER NOT NULL,
+  tx_hash TEXT NOT NULL,
+  address TEXT NOT NULL,
+  topic0 TEXT NOT NULL DEFAULT '',
+  topic1 TEXT NOT NULL DEFAULT '',
+  topic2 TEXT NOT NULL DEFAULT '',
+  topic3 TEXT NOT NULL DEFAULT '',
+  data TEXT NOT NULL,
+  PRIMARY KEY (block_height, log_index),
+  FOREIGN KEY (block_hash) REFERENCES blocks(hash) ON DELETE CASCADE
+) STRICT;
+
+CREATE INDEX IF NOT EXISTS idx_logs_address ON logs(address, block_height);
+
+CREATE INDEX IF NOT EXISTS idx_logs_tx_hash ON logs(tx_hash);
+
+CREATE INDEX IF NOT EXISTS idx_logs_topic0 ON logs(topic0, block_height);
+
+CREATE TABLE IF NOT EXISTS log_blooms (
+  block_height INTEGER PRIMARY KEY,
+  block_hash TEXT NOT NULL,
+  bloom TEXT NOT NULL,
+  FOREIGN KEY (block_hash) REFERENCES blocks(hash) ON DELETE CASCADE
+) STRICT;
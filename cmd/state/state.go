@@ -2,28 +2,45 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
-	"sort"
+	"sync"
 
+	"com.perkunas/internal/consensus"
 	"com.perkunas/internal/db"
+	"com.perkunas/internal/errmsg"
+	"com.perkunas/internal/feed"
 	"com.perkunas/internal/models/account"
 	"com.perkunas/internal/models/balancechange"
 	"com.perkunas/internal/models/block"
+	"com.perkunas/internal/models/chainconfig"
+	"com.perkunas/internal/models/contract"
+	"com.perkunas/internal/models/eventlog"
 	"com.perkunas/internal/models/genesisblock"
+	"com.perkunas/internal/models/htlc"
+	"com.perkunas/internal/models/multisig"
 	"com.perkunas/internal/models/receipt"
+	"com.perkunas/internal/models/token"
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/internal/smt"
+	"com.perkunas/internal/vm"
 	"com.perkunas/proto"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/jmoiron/sqlx"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
 )
 
 type State struct {
 	proto.UnimplementedStateServiceServer
+	proto.UnimplementedConfigServiceServer
 	log                *slog.Logger
 	apiPort            string
 	db                 *db.DB
@@ -32,9 +49,23 @@ type State struct {
 	genesisBlockModel  *genesisblock.Model
 	receiptModel       *receipt.Model
 	balanceChangeModel *balancechange.Model
+	multisigModel      *multisig.Model
+	tokenModel         *token.Model
+	htlcModel          *htlc.Model
+	contractModel      *contract.Model
+	logModel           *eventlog.Model
+	logFeed            *feed.Feed[eventlog.BlockLogs]
+	blockFeed          *feed.Feed[*proto.BlockEvent]
+	chainConfig        chainconfig.ChainConfig
+	engine             consensus.Engine
+
+	// tree is the state of the latest block, stateMu serializes the blocks
+	// and previews that build on it
+	stateMu sync.Mutex
+	tree    *smt.Tree
 }
 
-func (s *State) ensureGenesisBlock(ctx context.Context) error {
+func (s *State) ensureGenesisBlock(ctx context.Context, gBlock genesisblock.GenesisBlock) error {
 	hasGenesis, err := s.genesisBlockModel.HasGenesisBlock(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to check for genesis block presence %w", err)
@@ -42,11 +73,7 @@ func (s *State) ensureGenesisBlock(ctx context.Context) error {
 
 	if !hasGenesis {
 		// create genesis block
-		var gBlock genesisblock.GenesisBlock
-		if err := json.Unmarshal([]byte(genesisJson), &gBlock); err != nil {
-			return fmt.Errorf("unable to unmarshal genesis block json %w", err)
-		}
-
+		gBlock.StateRoot = genesisStateRoot(gBlock.Accounts)
 		blockHash, err := gBlock.CalculateHash()
 		if err != nil {
 			return fmt.Errorf("unable to calculate genesis block hash %w", err)
@@ -70,6 +97,16 @@ func (s *State) ensureGenesisBlock(ctx context.Context) error {
 			return fmt.Errorf("unable to persist genesis accounts %w", err)
 		}
 
+		if err := s.accModel.SnapshotWithTX(ctx, dbTx, 0); err != nil {
+			dbTx.Rollback()
+			return fmt.Errorf("unable to snapshot genesis accounts %w", err)
+		}
+
+		if err := s.accModel.ClearChangesWithTX(ctx, dbTx); err != nil {
+			dbTx.Rollback()
+			return fmt.Errorf("unable to clear genesis state changes %w", err)
+		}
+
 		if err := dbTx.Commit(); err != nil {
 			return fmt.Errorf("failed creating genesis block %w", err)
 		}
@@ -78,8 +115,33 @@ func (s *State) ensureGenesisBlock(ctx context.Context) error {
 	return nil
 }
 
+// loadStateTree builds the state tree of the latest block, later blocks only
+// update the leaves they touch.
+func (s *State) loadStateTree(ctx context.Context) error {
+	accounts, err := s.accModel.All(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to load accounts %w", err)
+	}
+
+	latest, err := s.blockModel.GetLatest(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get latest block %w", err)
+	}
+
+	tree := smt.New(toLeaves(accounts))
+	if tree.Root() != latest.StateRoot {
+		return fmt.Errorf("accounts do not match state root %s of block %d", latest.StateRoot, latest.Height)
+	}
+
+	s.tree = tree
+	return nil
+}
+
 func (s *State) GetAccountByAddress(ctx context.Context, in *proto.AccountByAddressReq) (*proto.AccountByAddressRes, error) {
 	acc, err := s.accModel.Get(ctx, in.GetAddress())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "account not found")
+	}
 	if err != nil {
 		s.log.Error("failed to get account", "err", err, "addr", in.GetAddress())
 		return nil, status.Error(codes.Internal, "failed to get account")
@@ -97,16 +159,28 @@ func (s *State) CreateBlock(ctx context.Context, in *proto.CreateBlockReq) (*pro
 		return &proto.CreateBlockRes{Message: "NO_STATE_DATA"}, nil
 	}
 
+	// proof-of-authority signers seal empty blocks to keep to the schedule
 	txs := block.GetTransactions()
-	if len(txs) == 0 {
+	if len(txs) == 0 && s.chainConfig.Consensus != chainconfig.ConsensusPoA {
 		s.log.Info("missing transactions to update balances")
 		return &proto.CreateBlockRes{Message: "MISSING_STATE_TXS"}, nil
 	}
 
-	// order by timestamp so we process older txs first
-	sort.Slice(txs, func(i, j int) bool {
-		return txs[i].GetTimestamp() < txs[j].GetTimestamp()
-	})
+	if err := validateTransactions(block, s.chainConfig); err != nil {
+		s.log.Error("invalid block transactions", "err", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.verifyHeader(ctx, block); err != nil {
+		s.log.Error("block failed consensus verification", "err", err, "hash", block.GetHash(), "height", block.GetHeight())
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// txs are applied in block order, the assembler already keeps every
+	// sender's txs in nonce order
+
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
 
 	dbTx, err := s.db.WriteDB.BeginTxx(ctx, nil)
 	if err != nil {
@@ -114,89 +188,816 @@ func (s *State) CreateBlock(ctx context.Context, in *proto.CreateBlockReq) (*pro
 		return nil, status.Error(codes.Internal, "failed to begin DB transaction")
 	}
 
-	if err := s.updateBalances(ctx, dbTx, txs, block); err != nil {
-		s.log.Error("failed updating balances", "err", err)
+	results, err := s.applyTransactions(ctx, dbTx, txs, block)
+	if err != nil {
+		s.log.Error("invalid block transaction", "err", err)
+		dbTx.Rollback()
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	tree, err := s.stateTree(ctx, dbTx)
+	if err != nil {
+		s.log.Error("failed calculating state root", "err", err)
 		dbTx.Rollback()
-		return nil, status.Error(codes.Internal, "failed updating balances")
+		return nil, status.Error(codes.Internal, "failed calculating state root")
 	}
 
-	if err := s.createBlock(ctx, dbTx, txs, block); err != nil {
+	if stateRoot := tree.Root(); stateRoot != block.GetStateRoot() {
+		s.log.Error("state root mismatch", "expected", stateRoot, "got", block.GetStateRoot(), "hash", block.GetHash())
+		dbTx.Rollback()
+		return nil, status.Error(codes.InvalidArgument, "state root mismatch")
+	}
+
+	if err := s.accModel.SnapshotWithTX(ctx, dbTx, block.GetHeight()); err != nil {
+		s.log.Error("failed snapshotting account state", "err", err)
+		dbTx.Rollback()
+		return nil, status.Error(codes.Internal, "failed snapshotting account state")
+	}
+
+	if err := s.createBlock(ctx, dbTx, txs, block, results); err != nil {
 		s.log.Error("failed creating block", "err", err)
 		dbTx.Rollback()
 		return nil, status.Error(codes.Internal, "failed creating block")
 	}
 
+	blockLogs := eventlog.NewBlockLogs(block.GetHeight(), block.GetHash(), collectLogs(txs, results))
+	if err := s.logModel.InsertWithTX(ctx, dbTx, blockLogs, block.GetHash()); err != nil {
+		s.log.Error("failed indexing logs", "err", err)
+		dbTx.Rollback()
+		return nil, status.Error(codes.Internal, "failed indexing logs")
+	}
+
 	if err := dbTx.Commit(); err != nil {
 		s.log.Error("failed creating block", "err", err)
 		return nil, status.Error(codes.Internal, "failed creating block")
 	}
+	s.tree = tree
+
+	if len(blockLogs.Logs) > 0 {
+		s.logFeed.Publish(blockLogs)
+	}
+
+	statuses := make(map[string]string, len(results))
+	for hash, res := range results {
+		statuses[hash] = res.Status
+	}
+	s.blockFeed.Publish(&proto.BlockEvent{Block: block, Statuses: statuses})
 
 	return &proto.CreateBlockRes{Message: "STATE_UPDATED"}, nil
 }
 
-func (s *State) updateBalances(ctx context.Context, dbTx *sqlx.Tx, txs []*proto.Transaction, pb *proto.Block) error {
-	for _, tx := range txs {
-		fromAcc, err := s.accModel.UpsertNoUpdate(ctx, dbTx, tx.GetFromAddr())
+// PreviewStateRoot applies the block's transactions without committing them
+// and returns the resulting state root, so the miner can seal it into the header.
+func (s *State) PreviewStateRoot(ctx context.Context, in *proto.CreateBlockReq) (*proto.StateRootRes, error) {
+	block := in.GetBlock()
+	if block == nil {
+		return nil, status.Error(codes.InvalidArgument, "request payload missing block")
+	}
+
+	if err := validateTransactions(block, s.chainConfig); err != nil {
+		s.log.Error("invalid block transactions", "err", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	dbTx, err := s.db.WriteDB.BeginTxx(ctx, nil)
+	if err != nil {
+		s.log.Error("failed to begin DB transaction", "err", err)
+		return nil, status.Error(codes.Internal, "failed to begin DB transaction")
+	}
+	defer dbTx.Rollback()
+
+	if _, err := s.applyTransactions(ctx, dbTx, block.GetTransactions(), block); err != nil {
+		s.log.Error("invalid block transaction", "err", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	tree, err := s.stateTree(ctx, dbTx)
+	if err != nil {
+		s.log.Error("failed calculating state root", "err", err)
+		return nil, status.Error(codes.Internal, "failed calculating state root")
+	}
+
+	return &proto.StateRootRes{StateRoot: tree.Root()}, nil
+}
+
+// stateTree returns the state tree after the changes made in dbTx. Only the
+// addresses the triggers in state.sql recorded are rehashed, s.tree is left
+// as it is until the block commits.
+func (s *State) stateTree(ctx context.Context, dbTx *sqlx.Tx) (*smt.Tree, error) {
+	addrs, err := s.accModel.ChangedAddressesWithTX(ctx, dbTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed loading changed addresses %w", err)
+	}
+
+	for _, addr := range addrs {
+		items, err := s.accModel.StorageWithTX(ctx, dbTx, addr)
 		if err != nil {
-			return fmt.Errorf("failed to upsert src account %w", err)
+			return nil, fmt.Errorf("failed loading storage of %s %w", addr, err)
+		}
+
+		if err := s.accModel.SetStorageRootWithTX(ctx, dbTx, addr, smt.StorageRoot(toItems(items))); err != nil {
+			return nil, fmt.Errorf("failed setting storage root of %s %w", addr, err)
+		}
+	}
+
+	accounts, err := s.accModel.ChangedWithTX(ctx, dbTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed loading changed accounts %w", err)
+	}
+
+	if err := s.accModel.ClearChangesWithTX(ctx, dbTx); err != nil {
+		return nil, fmt.Errorf("failed clearing state changes %w", err)
+	}
+
+	return s.tree.Update(toLeaves(accounts)...), nil
+}
+
+func genesisStateRoot(accounts []account.Account) string {
+	return smt.New(toLeaves(accounts)).Root()
+}
+
+func toLeaves(accounts []account.Account) []smt.Leaf {
+	leaves := make([]smt.Leaf, 0, len(accounts))
+	for _, acc := range accounts {
+		leaves = append(leaves, smt.Leaf{Address: acc.Address, Balance: acc.Balance, Nonce: acc.Nonce, StorageRoot: acc.StorageRoot})
+	}
+
+	return leaves
+}
+
+func toItems(storage []account.StorageItem) []smt.Item {
+	items := make([]smt.Item, 0, len(storage))
+	for _, it := range storage {
+		items = append(items, smt.Item{Key: it.Key, Value: it.Value})
+	}
+
+	return items
+}
+
+// blockSize measures pb the way the assembler fills blocks, as the
+// serialized block.
+func blockSize(pb *proto.Block) uint64 {
+	b := block.FromProtoBlock(pb)
+	b.Transactions = transaction.FromProtoTxs(pb.GetTransactions())
+	return b.Size()
+}
+
+func (s *State) verifyHeader(ctx context.Context, pb *proto.Block) error {
+	parent, err := s.blockModel.GetLatest(ctx)
+	if err != nil {
+		return fmt.Errorf("failed getting parent block %w", err)
+	}
+
+	b := block.FromProtoBlock(pb)
+	b.Transactions = transaction.FromProtoTxs(pb.GetTransactions())
+	return s.engine.VerifyHeader(&parent, &b)
+}
+
+// validateTransactions checks the transactions of a block before any is
+// applied: their count and the block size are within the chain limits, their
+// addresses are valid, their signatures verify for the chain id and none is
+// locked past the block timestamp.
+func validateTransactions(pb *proto.Block, cfg chainconfig.ChainConfig) error {
+	txs := pb.GetTransactions()
+	if cfg.MaxTxPerBlock > 0 && uint64(len(txs)) > cfg.MaxTxPerBlock {
+		return fmt.Errorf("block exceeds max transactions per block, %d > %d", len(txs), cfg.MaxTxPerBlock)
+	}
+
+	if cfg.MaxBlockSize > 0 {
+		if size := blockSize(pb); size > cfg.MaxBlockSize {
+			return fmt.Errorf("block exceeds max block size, %d > %d", size, cfg.MaxBlockSize)
+		}
+	}
+
+	for _, ptx := range txs {
+		tx := transaction.FromProtoTx(ptx)
+		if err := tx.ValidateAddresses(); err != nil {
+			return fmt.Errorf("tx %s: %w", tx.Hash, err)
+		}
+
+		if err := tx.Verify(cfg.ChainID); err != nil {
+			return fmt.Errorf("tx %s: %w", tx.Hash, err)
+		}
+
+		if !tx.Executable(pb.GetTimestamp()) {
+			return fmt.Errorf("tx %s: %w", tx.Hash, errmsg.ErrTxLocked)
+		}
+	}
+
+	return nil
+}
+
+// applyTransactions applies txs in block order, so a sender's transactions
+// and the accounts, tokens and contracts they create build on each other. A
+// transaction that can not be executed is still included with a receipt
+// saying why, see applyTransaction. An error means the block itself is
+// invalid or the state could not be written.
+func (s *State) applyTransactions(ctx context.Context, dbTx *sqlx.Tx, txs []*proto.Transaction, pb *proto.Block) (map[string]receipt.Result, error) {
+	results := make(map[string]receipt.Result, len(txs))
+
+	for _, ptx := range txs {
+		tx := transaction.FromProtoTx(ptx)
+
+		res, err := s.applyTransaction(ctx, dbTx, &tx, pb)
+		if err != nil {
+			return nil, fmt.Errorf("tx %s: %w", tx.Hash, err)
+		}
+		if res.Error != "" {
+			s.log.Info("transaction failed", "tx", tx.Hash, "status", res.Status, "err", res.Error)
+		}
+
+		results[tx.Hash] = res
+	}
+
+	return results, nil
+}
+
+// applyTransaction checks the nonce and balance of the sender, moves the
+// amount and fee and then runs what the transaction type does on top. When
+// that fails every change of the transaction is rolled back to a savepoint
+// and the transaction fails with its fee charged.
+func (s *State) applyTransaction(ctx context.Context, dbTx *sqlx.Tx, tx *transaction.Transaction, pb *proto.Block) (receipt.Result, error) {
+	// a spend without enough owner signatures is not the multisig account's
+	// transaction, it must not even pay a fee
+	if err := s.authorizeMultisig(ctx, dbTx, tx); err != nil {
+		return receipt.Result{}, err
+	}
+
+	sender, err := s.accModel.UpsertNoUpdate(ctx, dbTx, tx.From)
+	if err != nil {
+		return receipt.Result{}, fmt.Errorf("failed to upsert src account %w", err)
+	}
+
+	next := sender.Nonce + 1
+	if tx.Nonce < next {
+		return receipt.Result{}, fmt.Errorf("nonce %d was already used", tx.Nonce)
+	}
+	if tx.Nonce > next {
+		return s.failTransaction(ctx, dbTx, tx, pb, receipt.StatusBadNonce, fmt.Errorf("nonce %d, expected %d", tx.Nonce, next))
+	}
+	if sender.Balance < tx.Amount+tx.Fee {
+		return s.failTransaction(ctx, dbTx, tx, pb, receipt.StatusInsufficientFunds,
+			fmt.Errorf("balance %d does not cover amount and fee %d", sender.Balance, tx.Amount+tx.Fee))
+	}
+
+	if _, err := dbTx.ExecContext(ctx, "SAVEPOINT tx"); err != nil {
+		return receipt.Result{}, fmt.Errorf("failed to create savepoint %w", err)
+	}
+
+	res, execErr := s.executeTransaction(ctx, dbTx, tx, pb)
+	if execErr != nil && !isExecutionError(execErr) {
+		return receipt.Result{}, execErr
+	}
+
+	if execErr != nil {
+		if _, err := dbTx.ExecContext(ctx, "ROLLBACK TO tx"); err != nil {
+			return receipt.Result{}, fmt.Errorf("failed to roll back to savepoint %w", err)
+		}
+	}
+
+	if _, err := dbTx.ExecContext(ctx, "RELEASE tx"); err != nil {
+		return receipt.Result{}, fmt.Errorf("failed to release savepoint %w", err)
+	}
+
+	if execErr != nil {
+		return s.failTransaction(ctx, dbTx, tx, pb, receipt.StatusReverted, execErr)
+	}
+
+	return res, nil
+}
+
+// executeTransaction applies a transaction the sender can pay for.
+func (s *State) executeTransaction(ctx context.Context, dbTx *sqlx.Tx, tx *transaction.Transaction, pb *proto.Block) (receipt.Result, error) {
+	if err := s.transfer(ctx, dbTx, tx, pb); err != nil {
+		return receipt.Result{}, err
+	}
+
+	var err error
+	switch tx.Type {
+	case transaction.TypeMultisigCreate:
+		err = s.createMultisig(ctx, dbTx, tx, pb)
+	case transaction.TypeTokenCreate:
+		err = s.createToken(ctx, dbTx, tx, pb)
+	case transaction.TypeTokenTransfer:
+		err = s.transferTokens(ctx, dbTx, tx)
+	case transaction.TypeTokenApprove:
+		err = s.approveTokens(ctx, dbTx, tx)
+	case transaction.TypeHTLCLock:
+		err = s.lockHTLC(ctx, dbTx, tx, pb)
+	case transaction.TypeHTLCClaim, transaction.TypeHTLCRefund:
+		err = s.settleHTLC(ctx, dbTx, tx, pb)
+	case transaction.TypeContractDeploy, transaction.TypeContractCall:
+		return s.applyContract(ctx, dbTx, tx, pb)
+	}
+	if err != nil {
+		return receipt.Result{}, err
+	}
+
+	return receipt.Result{Status: receipt.StatusAccepted}, nil
+}
+
+// executionErrors fail the transaction that caused them, the block including
+// it stays valid.
+var executionErrors = []error{
+	errmsg.ErrInvalidMultisig,
+	errmsg.ErrMultisigExists,
+	errmsg.ErrInvalidToken,
+	errmsg.ErrTokenExists,
+	errmsg.ErrUnknownToken,
+	errmsg.ErrInsufficientTokens,
+	errmsg.ErrInsufficientAllowance,
+	errmsg.ErrInvalidHTLC,
+	errmsg.ErrUnknownHTLC,
+	errmsg.ErrHTLCClosed,
+	errmsg.ErrHTLCExpired,
+	errmsg.ErrHTLCNotExpired,
+	errmsg.ErrInvalidPreimage,
+	errmsg.ErrNotHTLCParty,
+	errmsg.ErrInvalidContract,
+	errmsg.ErrUnknownContract,
+	errmsg.ErrIntrinsicGas,
+}
+
+func isExecutionError(err error) bool {
+	for _, target := range executionErrors {
+		if errors.Is(err, target) {
+			return true
 		}
+	}
+
+	return false
+}
+
+// failTransaction includes a transaction that could not be executed. It
+// charges as much of the fee as the sender holds and uses up the nonce,
+// unless the nonce was the problem: a transaction sent out of order is
+// dropped without a charge.
+func (s *State) failTransaction(ctx context.Context, dbTx *sqlx.Tx, tx *transaction.Transaction, pb *proto.Block, status string, cause error) (receipt.Result, error) {
+	res := receipt.Result{Status: status, Error: cause.Error()}
+	if status == receipt.StatusBadNonce {
+		return res, nil
+	}
+
+	sender, err := s.accModel.UpsertNoUpdate(ctx, dbTx, tx.From)
+	if err != nil {
+		return receipt.Result{}, fmt.Errorf("failed to upsert src account %w", err)
+	}
+
+	charge := min(tx.Fee, max(sender.Balance, 0))
+	bc := balancechange.BalanceChange{
+		PreviousBalance: sender.Balance,
+		NewBalance:      sender.Balance - charge,
+		ChangeAmount:    -charge,
+		AccountID:       sender.ID,
+		BlockHeight:     pb.GetHeight(),
+		BlockHash:       pb.GetHash(),
+		TxHash:          tx.Hash,
+		Timestamp:       tx.Timestamp,
+	}
+
+	if err := s.balanceChangeModel.Crete(ctx, dbTx, bc); err != nil {
+		return receipt.Result{}, fmt.Errorf("failed to create source acc balance change record %w", err)
+	}
+
+	if _, err := s.accModel.Upsert(ctx, dbTx, account.Account{
+		Address: sender.Address,
+		Balance: bc.NewBalance,
+		Nonce:   tx.Nonce,
+	}); err != nil {
+		return receipt.Result{}, fmt.Errorf("failed to update source account balance %w", err)
+	}
+
+	return res, nil
+}
+
+// transfer charges the amount and fee to the sender, which burns the fee,
+// and credits the amount to the recipient. Only the sender's nonce moves.
+func (s *State) transfer(ctx context.Context, dbTx *sqlx.Tx, tx *transaction.Transaction, pb *proto.Block) error {
+	fromAcc, err := s.accModel.UpsertNoUpdate(ctx, dbTx, tx.From)
+	if err != nil {
+		return fmt.Errorf("failed to upsert src account %w", err)
+	}
+
+	fromAccBc := balancechange.BalanceChange{
+		PreviousBalance: fromAcc.Balance,
+		NewBalance:      fromAcc.Balance - tx.Amount - tx.Fee,
+		ChangeAmount:    -(tx.Amount + tx.Fee),
+		AccountID:       fromAcc.ID,
+		BlockHeight:     pb.GetHeight(),
+		BlockHash:       pb.GetHash(),
+		TxHash:          tx.Hash,
+		Timestamp:       tx.Timestamp,
+	}
+
+	if err := s.balanceChangeModel.Crete(ctx, dbTx, fromAccBc); err != nil {
+		return fmt.Errorf("failed to create source acc balance change record %w", err)
+	}
+
+	if _, err := s.accModel.Upsert(ctx, dbTx, account.Account{
+		Address: fromAcc.Address,
+		Balance: fromAccBc.NewBalance,
+		Nonce:   tx.Nonce,
+	}); err != nil {
+		return fmt.Errorf("failed to update source account balance %w", err)
+	}
+
+	// read after the debit, a transfer to self must not mint coins
+	toAcc, err := s.accModel.UpsertNoUpdate(ctx, dbTx, tx.To)
+	if err != nil {
+		return fmt.Errorf("failed to upsert dest account %w", err)
+	}
+
+	toAccBc := balancechange.BalanceChange{
+		PreviousBalance: toAcc.Balance,
+		NewBalance:      toAcc.Balance + tx.Amount,
+		ChangeAmount:    tx.Amount,
+		AccountID:       toAcc.ID,
+		BlockHeight:     pb.GetHeight(),
+		BlockHash:       pb.GetHash(),
+		TxHash:          tx.Hash,
+		Timestamp:       tx.Timestamp,
+	}
+
+	if err := s.balanceChangeModel.Crete(ctx, dbTx, toAccBc); err != nil {
+		return fmt.Errorf("failed to create destination acc balance change record %w", err)
+	}
+
+	if _, err := s.accModel.Upsert(ctx, dbTx, account.Account{
+		Address: toAcc.Address,
+		Balance: toAccBc.NewBalance,
+		Nonce:   toAcc.Nonce,
+	}); err != nil {
+		return fmt.Errorf("failed to update destination account balance %w", err)
+	}
+
+	return nil
+}
+
+// authorizeMultisig checks the owner signatures of spends from multisig
+// accounts, the account may be created earlier in the same block.
+func (s *State) authorizeMultisig(ctx context.Context, dbTx *sqlx.Tx, tx *transaction.Transaction) error {
+	if tx.Type == transaction.TypeMultisigCreate || !tx.IsMultisig() {
+		return nil
+	}
+
+	m, err := s.multisigModel.GetWithTX(ctx, dbTx, tx.From)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errmsg.ErrUnknownMultisig
+	}
+	if err != nil {
+		return err
+	}
+
+	return m.Verify(tx, s.chainConfig.ChainID)
+}
+
+func (s *State) createMultisig(ctx context.Context, dbTx *sqlx.Tx, tx *transaction.Transaction, pb *proto.Block) error {
+	m, err := multisig.FromCreateTransaction(tx)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.multisigModel.GetWithTX(ctx, dbTx, m.Address)
+	if err == nil {
+		return errmsg.ErrMultisigExists
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
 
-		toAcc, err := s.accModel.UpsertNoUpdate(ctx, dbTx, tx.GetToAddr())
+	if err := s.multisigModel.InsertWithTX(ctx, dbTx, m, tx.Hash, pb.GetHeight()); err != nil {
+		return fmt.Errorf("failed to record multisig account %w", err)
+	}
+
+	return nil
+}
+
+func (s *State) createToken(ctx context.Context, dbTx *sqlx.Tx, tx *transaction.Transaction, pb *proto.Block) error {
+	t, err := token.FromCreateTransaction(tx)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.tokenModel.GetWithTX(ctx, dbTx, t.Address)
+	if err == nil {
+		return errmsg.ErrTokenExists
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if err := s.tokenModel.InsertWithTX(ctx, dbTx, t, tx.Hash, pb.GetHeight()); err != nil {
+		return fmt.Errorf("failed to record token %w", err)
+	}
+
+	return s.tokenModel.SetBalanceWithTX(ctx, dbTx, t.Address, t.Creator, t.Supply)
+}
+
+func (s *State) transferTokens(ctx context.Context, dbTx *sqlx.Tx, tx *transaction.Transaction) error {
+	tr, err := s.tokenTransfer(ctx, dbTx, tx)
+	if err != nil {
+		return err
+	}
+
+	holder := tr.Holder(tx)
+	if holder != tx.From {
+		allowance, err := s.tokenModel.AllowanceWithTX(ctx, dbTx, tr.Token, holder, tx.From)
 		if err != nil {
-			return fmt.Errorf("failed to upsert dest account %w", err)
+			return err
+		}
+		if allowance < tr.Amount {
+			return errmsg.ErrInsufficientAllowance
 		}
 
-		fromAccBc := balancechange.BalanceChange{
-			PreviousBalance: fromAcc.Balance,
-			NewBalance:      fromAcc.Balance - tx.GetAmount() - tx.GetFee(),
-			ChangeAmount:    -(tx.GetAmount() + tx.GetFee()),
-			AccountID:       fromAcc.ID,
-			BlockHeight:     pb.GetHeight(),
-			BlockHash:       pb.GetHash(),
-			TxHash:          tx.GetHash(),
-			Timestamp:       tx.GetTimestamp(),
+		if err := s.tokenModel.SetAllowanceWithTX(ctx, dbTx, tr.Token, holder, tx.From, allowance-tr.Amount); err != nil {
+			return fmt.Errorf("failed to update token allowance %w", err)
 		}
+	}
+
+	fromBalance, err := s.tokenModel.BalanceWithTX(ctx, dbTx, tr.Token, holder)
+	if err != nil {
+		return err
+	}
+	if fromBalance < tr.Amount {
+		return errmsg.ErrInsufficientTokens
+	}
+
+	if err := s.tokenModel.SetBalanceWithTX(ctx, dbTx, tr.Token, holder, fromBalance-tr.Amount); err != nil {
+		return fmt.Errorf("failed to update source token balance %w", err)
+	}
+
+	// read after the debit, a transfer to self must not mint tokens
+	toBalance, err := s.tokenModel.BalanceWithTX(ctx, dbTx, tr.Token, tx.To)
+	if err != nil {
+		return err
+	}
+
+	if err := s.tokenModel.SetBalanceWithTX(ctx, dbTx, tr.Token, tx.To, toBalance+tr.Amount); err != nil {
+		return fmt.Errorf("failed to update destination token balance %w", err)
+	}
+
+	return nil
+}
+
+func (s *State) approveTokens(ctx context.Context, dbTx *sqlx.Tx, tx *transaction.Transaction) error {
+	tr, err := s.tokenTransfer(ctx, dbTx, tx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.tokenModel.SetAllowanceWithTX(ctx, dbTx, tr.Token, tx.From, tx.To, tr.Amount); err != nil {
+		return fmt.Errorf("failed to update token allowance %w", err)
+	}
+
+	return nil
+}
+
+// tokenTransfer parses the data of a transfer or approve and checks that the
+// token exists.
+func (s *State) tokenTransfer(ctx context.Context, dbTx *sqlx.Tx, tx *transaction.Transaction) (*token.Transfer, error) {
+	tr, err := token.ParseTransfer(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.tokenModel.GetWithTX(ctx, dbTx, tr.Token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errmsg.ErrUnknownToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return tr, nil
+}
+
+func (s *State) lockHTLC(ctx context.Context, dbTx *sqlx.Tx, tx *transaction.Transaction, pb *proto.Block) error {
+	h, err := htlc.FromLockTransaction(tx)
+	if err != nil {
+		return err
+	}
+
+	if h.Timeout <= pb.GetTimestamp() {
+		return fmt.Errorf("%w: timeout is in the past", errmsg.ErrInvalidHTLC)
+	}
+
+	_, err = s.htlcModel.GetWithTX(ctx, dbTx, h.Address)
+	if err == nil {
+		return fmt.Errorf("%w: %s already exists", errmsg.ErrInvalidHTLC, h.Address)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if err := s.htlcModel.InsertWithTX(ctx, dbTx, h, tx.Hash, pb.GetHeight()); err != nil {
+		return fmt.Errorf("failed to record htlc %w", err)
+	}
+
+	return nil
+}
+
+func (s *State) settleHTLC(ctx context.Context, dbTx *sqlx.Tx, tx *transaction.Transaction, pb *proto.Block) error {
+	h, err := s.htlcModel.GetWithTX(ctx, dbTx, tx.To)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errmsg.ErrUnknownHTLC
+	}
+	if err != nil {
+		return err
+	}
 
-		if err := s.balanceChangeModel.Crete(ctx, dbTx, fromAccBc); err != nil {
-			return fmt.Errorf("failed to create source acc balance change record %w", err)
+	payee, newStatus, preimage := h.Recipient, htlc.StatusClaimed, ""
+	if tx.Type == transaction.TypeHTLCClaim {
+		if preimage, err = h.VerifyClaim(tx, pb.GetTimestamp()); err != nil {
+			return err
+		}
+	} else {
+		if err := h.VerifyRefund(tx, pb.GetTimestamp()); err != nil {
+			return err
 		}
+		payee, newStatus = h.Sender, htlc.StatusRefunded
+	}
+
+	if err := s.adjustBalance(ctx, dbTx, h.Address, -h.Amount, tx, pb); err != nil {
+		return err
+	}
+
+	if err := s.adjustBalance(ctx, dbTx, payee, h.Amount, tx, pb); err != nil {
+		return err
+	}
+
+	if err := s.htlcModel.SettleWithTX(ctx, dbTx, h.Address, newStatus, preimage); err != nil {
+		return fmt.Errorf("failed to settle htlc %w", err)
+	}
+
+	return nil
+}
 
-		if _, err := s.accModel.Upsert(ctx, dbTx, account.Account{
-			Address: fromAcc.Address,
-			Balance: fromAccBc.NewBalance,
-			Nonce:   fromAcc.Nonce + 1,
-		}); err != nil {
-			return fmt.Errorf("failed to update source account balance %w", err)
+// applyContract deploys a contract or runs the code of a called one. The fee
+// is the gas limit and was charged with the value, the unused gas is
+// refunded. A failed execution leaves the contract storage alone and returns
+// the value.
+func (s *State) applyContract(ctx context.Context, dbTx *sqlx.Tx, tx *transaction.Transaction, pb *proto.Block) (receipt.Result, error) {
+	if err := contract.Verify(tx); err != nil {
+		return receipt.Result{}, err
+	}
+
+	var (
+		res receipt.Result
+		err error
+	)
+	if tx.Type == transaction.TypeContractDeploy {
+		res, err = s.deployContract(ctx, dbTx, tx, pb)
+	} else {
+		res, err = s.callContract(ctx, dbTx, tx, pb)
+	}
+	if err != nil {
+		return receipt.Result{}, err
+	}
+
+	if refund := tx.Fee - res.GasUsed; refund > 0 {
+		if err := s.adjustBalance(ctx, dbTx, tx.From, refund, tx, pb); err != nil {
+			return receipt.Result{}, err
 		}
+	}
+
+	return res, nil
+}
+
+func (s *State) deployContract(ctx context.Context, dbTx *sqlx.Tx, tx *transaction.Transaction, pb *proto.Block) (receipt.Result, error) {
+	c, err := contract.FromDeployTransaction(tx)
+	if err != nil {
+		return receipt.Result{}, err
+	}
+
+	_, err = s.contractModel.GetWithTX(ctx, dbTx, c.Address)
+	if err == nil {
+		return receipt.Result{}, fmt.Errorf("%w: %s already exists", errmsg.ErrInvalidContract, c.Address)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return receipt.Result{}, err
+	}
+
+	if err := s.contractModel.InsertWithTX(ctx, dbTx, c, tx.Hash, pb.GetHeight()); err != nil {
+		return receipt.Result{}, fmt.Errorf("failed to record contract %w", err)
+	}
+
+	return receipt.Result{Status: receipt.StatusAccepted, GasUsed: contract.IntrinsicGas(tx)}, nil
+}
+
+func (s *State) callContract(ctx context.Context, dbTx *sqlx.Tx, tx *transaction.Transaction, pb *proto.Block) (receipt.Result, error) {
+	c, err := s.contractModel.GetWithTX(ctx, dbTx, tx.To)
+	if errors.Is(err, sql.ErrNoRows) {
+		return receipt.Result{}, errmsg.ErrUnknownContract
+	}
+	if err != nil {
+		return receipt.Result{}, err
+	}
+
+	input, err := contract.ParseInput(tx)
+	if err != nil {
+		return receipt.Result{}, err
+	}
+
+	intrinsic := contract.IntrinsicGas(tx)
+	vmCtx := vm.Context{
+		Address:   common.HexToAddress(c.Address),
+		Caller:    common.HexToAddress(tx.From),
+		Value:     tx.Amount,
+		Input:     input,
+		Timestamp: pb.GetTimestamp(),
+		Height:    pb.GetHeight(),
+	}
+
+	out, err := vm.Run(c.CodeBytes(), vmCtx, &contractStorage{ctx: ctx, dbTx: dbTx, model: s.contractModel, address: c.Address}, tx.Fee-intrinsic)
+	if err != nil {
+		return receipt.Result{}, err
+	}
 
-		toAccBc := balancechange.BalanceChange{
-			PreviousBalance: toAcc.Balance,
-			NewBalance:      toAcc.Balance + tx.GetAmount(),
-			ChangeAmount:    tx.GetAmount(),
-			AccountID:       toAcc.ID,
-			BlockHeight:     pb.GetHeight(),
-			BlockHash:       pb.GetHash(),
-			TxHash:          tx.GetHash(),
-			Timestamp:       tx.GetTimestamp(),
+	res := receipt.Result{Status: receipt.StatusAccepted, GasUsed: intrinsic + out.GasUsed, Logs: out.Logs}
+	if out.Err != nil {
+		s.log.Info("contract execution failed", "tx", tx.Hash, "contract", c.Address, "err", out.Err)
+
+		res.Status, res.Error = receipt.StatusReverted, out.Err.Error()
+		if errors.Is(out.Err, vm.ErrOutOfGas) {
+			res.Status = receipt.StatusOutOfGas
 		}
 
-		if err := s.balanceChangeModel.Crete(ctx, dbTx, toAccBc); err != nil {
-			return fmt.Errorf("failed to create destination acc balance change record %w", err)
+		if tx.Amount > 0 {
+			if err := s.adjustBalance(ctx, dbTx, c.Address, -tx.Amount, tx, pb); err != nil {
+				return receipt.Result{}, err
+			}
+			if err := s.adjustBalance(ctx, dbTx, tx.From, tx.Amount, tx, pb); err != nil {
+				return receipt.Result{}, err
+			}
 		}
 
-		if _, err := s.accModel.Upsert(ctx, dbTx, account.Account{
-			Address: toAcc.Address,
-			Balance: toAccBc.NewBalance,
-			Nonce:   toAcc.Nonce + 1,
-		}); err != nil {
-			return fmt.Errorf("failed to update destination account balance %w", err)
+		return res, nil
+	}
+
+	for key, value := range out.Storage {
+		if err := s.contractModel.SetStorageWithTX(ctx, dbTx, c.Address, key, value); err != nil {
+			return receipt.Result{}, fmt.Errorf("failed to write contract storage %w", err)
 		}
 	}
 
+	return res, nil
+}
+
+// contractStorage reads contract storage inside the block's DB transaction.
+type contractStorage struct {
+	ctx     context.Context
+	dbTx    *sqlx.Tx
+	model   *contract.Model
+	address string
+}
+
+func (cs *contractStorage) Load(key common.Hash) (common.Hash, error) {
+	return cs.model.StorageWithTX(cs.ctx, cs.dbTx, cs.address, key)
+}
+
+// adjustBalance changes the balance of address by change on behalf of tx,
+// leaving its nonce alone.
+func (s *State) adjustBalance(ctx context.Context, dbTx *sqlx.Tx, address string, change int64, tx *transaction.Transaction, pb *proto.Block) error {
+	acc, err := s.accModel.UpsertNoUpdate(ctx, dbTx, address)
+	if err != nil {
+		return fmt.Errorf("failed to upsert account %w", err)
+	}
+
+	bc := balancechange.BalanceChange{
+		PreviousBalance: acc.Balance,
+		NewBalance:      acc.Balance + change,
+		ChangeAmount:    change,
+		AccountID:       acc.ID,
+		BlockHeight:     pb.GetHeight(),
+		BlockHash:       pb.GetHash(),
+		TxHash:          tx.Hash,
+		Timestamp:       tx.Timestamp,
+	}
+
+	if err := s.balanceChangeModel.Crete(ctx, dbTx, bc); err != nil {
+		return fmt.Errorf("failed to create balance change record %w", err)
+	}
+
+	if _, err := s.accModel.Upsert(ctx, dbTx, account.Account{
+		Address: acc.Address,
+		Balance: bc.NewBalance,
+		Nonce:   acc.Nonce,
+	}); err != nil {
+		return fmt.Errorf("failed to update account balance %w", err)
+	}
+
 	return nil
 }
 
-func (s *State) createBlock(ctx context.Context, dbTx *sqlx.Tx, txs []*proto.Transaction, pb *proto.Block) error {
+func (s *State) createBlock(ctx context.Context, dbTx *sqlx.Tx, txs []*proto.Transaction, pb *proto.Block, results map[string]receipt.Result) error {
+	if txs == nil {
+		// an empty block still stores an array, json_each reads null as a value
+		txs = []*proto.Transaction{}
+	}
+
 	txsJson, err := json.Marshal(txs)
 	if err != nil {
 		return fmt.Errorf("createBlock failed to Marshal txs %w", err)
@@ -207,10 +1008,13 @@ func (s *State) createBlock(ctx context.Context, dbTx *sqlx.Tx, txs []*proto.Tra
 			Hash:       pb.GetHash(),
 			PrevHash:   pb.GetPrevHash(),
 			MerkleRoot: pb.GetMerkleRoot(),
+			StateRoot:  pb.GetStateRoot(),
 			Timestamp:  pb.GetTimestamp(),
 			Height:     pb.GetHeight(),
 			Nonce:      pb.GetNonce(),
-			// Difficulty: pb.GetDifficulty(),
+			Difficulty: pb.GetDifficulty(),
+			Miner:      pb.GetMiner(),
+			Signature:  pb.GetSignature(),
 		},
 		TransactionsDB: string(txsJson),
 	}
@@ -219,7 +1023,7 @@ func (s *State) createBlock(ctx context.Context, dbTx *sqlx.Tx, txs []*proto.Tra
 		return fmt.Errorf("failed persisting block data %w, height: %v, block_hash: %v", err, blockPld.Height, blockPld.Hash)
 	}
 
-	receiptsPld := receipt.ProtoToReceipts(txs, pb.GetHash())
+	receiptsPld := receipt.ProtoToReceipts(txs, pb.GetHash(), results)
 	if err := s.receiptModel.InsertBatch(ctx, dbTx, receiptsPld); err != nil {
 		return fmt.Errorf("failed persisting receipts %w", err)
 	}
@@ -227,6 +1031,16 @@ func (s *State) createBlock(ctx context.Context, dbTx *sqlx.Tx, txs []*proto.Tra
 	return nil
 }
 
+// collectLogs returns the logs of a block's transactions in block order.
+func collectLogs(txs []*proto.Transaction, results map[string]receipt.Result) []eventlog.Log {
+	logs := make([]eventlog.Log, 0)
+	for _, tx := range txs {
+		logs = append(logs, eventlog.FromVM(tx.GetHash(), results[tx.GetHash()].Logs)...)
+	}
+
+	return logs
+}
+
 func (s *State) GetLatestBlock(ctx context.Context, in *proto.LastBlockReq) (*proto.LastBlockRes, error) {
 	latestBlock, err := s.blockModel.GetLatest(ctx)
 	if err != nil {
@@ -239,6 +1053,408 @@ func (s *State) GetLatestBlock(ctx context.Context, in *proto.LastBlockReq) (*pr
 	}, nil
 }
 
+func (s *State) GetBlockByHeight(ctx context.Context, in *proto.BlockByHeightReq) (*proto.BlockByHeightRes, error) {
+	blockDB, err := s.blockModel.GetByHeight(ctx, in.GetHeight())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "block not found")
+	}
+	if err != nil {
+		s.log.Error("failed getting block", "err", err, "height", in.GetHeight())
+		return nil, status.Error(codes.Internal, "failed getting block")
+	}
+
+	if !in.GetWithTransactions() && !in.GetWithReceipts() {
+		return &proto.BlockByHeightRes{Block: block.ToProtoBlock(blockDB.Block)}, nil
+	}
+
+	b, err := blockDB.WithTransactions()
+	if err != nil {
+		s.log.Error("failed decoding block transactions", "err", err, "height", in.GetHeight())
+		return nil, status.Error(codes.Internal, "failed decoding block transactions")
+	}
+
+	res := &proto.BlockByHeightRes{Block: block.ToProtoBlock(b)}
+	if in.GetWithTransactions() {
+		res.Block.Transactions = transaction.ToProtoTxs(b.Transactions)
+	}
+
+	if in.GetWithReceipts() {
+		receipts, err := s.receiptModel.GetByBlockHash(ctx, b.Hash)
+		if err != nil {
+			s.log.Error("failed getting block receipts", "err", err, "height", in.GetHeight())
+			return nil, status.Error(codes.Internal, "failed getting block receipts")
+		}
+
+		byHash := make(map[string]receipt.Receipt, len(receipts))
+		for _, r := range receipts {
+			byHash[r.TxHash] = r
+		}
+
+		// receipts are stored unordered, the block has the order
+		for _, tx := range b.Transactions {
+			r := byHash[tx.Hash]
+			res.Receipts = append(res.Receipts, &proto.TxReceipt{TxHash: tx.Hash, Status: r.Status, GasUsed: r.GasUsed})
+		}
+	}
+
+	return res, nil
+}
+
+func (s *State) GetTransaction(ctx context.Context, in *proto.TransactionReq) (*proto.TransactionRes, error) {
+	rcpt, err := s.receiptModel.GetByTxHash(ctx, in.GetHash())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "transaction not found")
+	}
+	if err != nil {
+		s.log.Error("failed getting receipt", "err", err, "txHash", in.GetHash())
+		return nil, status.Error(codes.Internal, "failed getting receipt")
+	}
+
+	blockDB, err := s.blockModel.GetByHash(ctx, rcpt.BlockHash)
+	if err != nil {
+		s.log.Error("failed getting block", "err", err, "blockHash", rcpt.BlockHash)
+		return nil, status.Error(codes.Internal, "failed getting block")
+	}
+
+	b, err := blockDB.WithTransactions()
+	if err != nil {
+		s.log.Error("failed decoding block transactions", "err", err, "blockHash", rcpt.BlockHash)
+		return nil, status.Error(codes.Internal, "failed decoding block transactions")
+	}
+
+	logs, err := rcpt.DecodeLogs()
+	if err != nil {
+		s.log.Error("failed decoding receipt logs", "err", err, "txHash", in.GetHash())
+		return nil, status.Error(codes.Internal, "failed decoding receipt logs")
+	}
+
+	for _, tx := range b.Transactions {
+		if tx.Hash == in.GetHash() {
+			return &proto.TransactionRes{
+				Transaction: transaction.ToProtoTx(*tx),
+				BlockHash:   b.Hash,
+				Height:      b.Height,
+				Status:      rcpt.Status,
+				GasUsed:     rcpt.GasUsed,
+				Logs:        receipt.LogsToProto(logs),
+				Error:       rcpt.Error,
+			}, nil
+		}
+	}
+
+	s.log.Error("receipt points to block without the transaction", "txHash", in.GetHash(), "blockHash", rcpt.BlockHash)
+	return nil, status.Error(codes.Internal, "transaction missing from block")
+}
+
+// maxAccountTransactions caps the page of GetAccountTransactions.
+const maxAccountTransactions = 100
+
+// GetAccountTransactions returns the mined transactions sent from or to an
+// address, newest first.
+func (s *State) GetAccountTransactions(ctx context.Context, in *proto.AccountTransactionsReq) (*proto.AccountTransactionsRes, error) {
+	limit := uint32(maxAccountTransactions)
+	if in.GetLimit() > 0 {
+		limit = min(in.GetLimit(), limit)
+	}
+
+	rows, total, err := s.blockModel.TransactionsOf(ctx, in.GetAddress(), limit, in.GetOffset())
+	if err != nil {
+		s.log.Error("failed getting account transactions", "err", err, "addr", in.GetAddress())
+		return nil, status.Error(codes.Internal, "failed getting account transactions")
+	}
+
+	res := &proto.AccountTransactionsRes{Total: total}
+	for _, row := range rows {
+		var tx transaction.Transaction
+		if err := json.Unmarshal([]byte(row.Transaction), &tx); err != nil {
+			s.log.Error("failed decoding block transaction", "err", err, "blockHash", row.BlockHash)
+			return nil, status.Error(codes.Internal, "failed decoding block transactions")
+		}
+
+		rcpt := receipt.Receipt{Logs: json.RawMessage(row.Logs)}
+		logs, err := rcpt.DecodeLogs()
+		if err != nil {
+			s.log.Error("failed decoding receipt logs", "err", err, "txHash", tx.Hash)
+			return nil, status.Error(codes.Internal, "failed decoding receipt logs")
+		}
+
+		res.Transactions = append(res.Transactions, &proto.TransactionRes{
+			Transaction: transaction.ToProtoTx(tx),
+			BlockHash:   row.BlockHash,
+			Height:      row.Height,
+			Status:      row.Status,
+			GasUsed:     row.GasUsed,
+			Logs:        receipt.LogsToProto(logs),
+			Error:       row.Error,
+		})
+	}
+
+	return res, nil
+}
+
+func (s *State) GetMultisig(ctx context.Context, in *proto.MultisigReq) (*proto.MultisigRes, error) {
+	m, err := s.multisigModel.Get(ctx, in.GetAddress())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "multisig account not found")
+	}
+	if err != nil {
+		s.log.Error("failed getting multisig account", "err", err, "address", in.GetAddress())
+		return nil, status.Error(codes.Internal, "failed getting multisig account")
+	}
+
+	return &proto.MultisigRes{Multisig: m.ToProto()}, nil
+}
+
+func (s *State) GetToken(ctx context.Context, in *proto.TokenReq) (*proto.TokenRes, error) {
+	t, err := s.tokenModel.Get(ctx, in.GetAddress())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "token not found")
+	}
+	if err != nil {
+		s.log.Error("failed getting token", "err", err, "address", in.GetAddress())
+		return nil, status.Error(codes.Internal, "failed getting token")
+	}
+
+	return &proto.TokenRes{Token: t.ToProto()}, nil
+}
+
+func (s *State) GetTokenBalance(ctx context.Context, in *proto.TokenBalanceReq) (*proto.TokenBalanceRes, error) {
+	b, err := s.tokenModel.Balance(ctx, in.GetToken(), in.GetAddress())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "token not found")
+	}
+	if err != nil {
+		s.log.Error("failed getting token balance", "err", err, "token", in.GetToken(), "address", in.GetAddress())
+		return nil, status.Error(codes.Internal, "failed getting token balance")
+	}
+
+	return &proto.TokenBalanceRes{Balance: b.ToProto()}, nil
+}
+
+func (s *State) GetTokenBalances(ctx context.Context, in *proto.TokenBalancesReq) (*proto.TokenBalancesRes, error) {
+	balances, err := s.tokenModel.Balances(ctx, in.GetAddress())
+	if err != nil {
+		s.log.Error("failed getting token balances", "err", err, "address", in.GetAddress())
+		return nil, status.Error(codes.Internal, "failed getting token balances")
+	}
+
+	return &proto.TokenBalancesRes{Balances: token.BalancesToProto(balances)}, nil
+}
+
+func (s *State) GetTokenAllowance(ctx context.Context, in *proto.TokenAllowanceReq) (*proto.TokenAllowanceRes, error) {
+	if _, err := s.tokenModel.Get(ctx, in.GetToken()); errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "token not found")
+	}
+
+	amount, err := s.tokenModel.Allowance(ctx, in.GetToken(), in.GetOwner(), in.GetSpender())
+	if err != nil {
+		s.log.Error("failed getting token allowance", "err", err, "token", in.GetToken())
+		return nil, status.Error(codes.Internal, "failed getting token allowance")
+	}
+
+	return &proto.TokenAllowanceRes{
+		Token:   in.GetToken(),
+		Owner:   in.GetOwner(),
+		Spender: in.GetSpender(),
+		Amount:  amount,
+	}, nil
+}
+
+func (s *State) GetHTLC(ctx context.Context, in *proto.HTLCReq) (*proto.HTLCRes, error) {
+	h, err := s.htlcModel.Get(ctx, in.GetAddress())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "htlc not found")
+	}
+	if err != nil {
+		s.log.Error("failed getting htlc", "err", err, "address", in.GetAddress())
+		return nil, status.Error(codes.Internal, "failed getting htlc")
+	}
+
+	return &proto.HTLCRes{Htlc: h.ToProto()}, nil
+}
+
+func (s *State) GetContract(ctx context.Context, in *proto.ContractReq) (*proto.ContractRes, error) {
+	c, err := s.contractModel.Get(ctx, in.GetAddress())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "contract not found")
+	}
+	if err != nil {
+		s.log.Error("failed getting contract", "err", err, "address", in.GetAddress())
+		return nil, status.Error(codes.Internal, "failed getting contract")
+	}
+
+	return &proto.ContractRes{Contract: c.ToProto()}, nil
+}
+
+func (s *State) GetStorage(ctx context.Context, in *proto.StorageReq) (*proto.StorageRes, error) {
+	if _, err := s.contractModel.Get(ctx, in.GetAddress()); errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "contract not found")
+	}
+
+	value, err := s.contractModel.Storage(ctx, in.GetAddress(), common.HexToHash(in.GetKey()))
+	if err != nil {
+		s.log.Error("failed getting contract storage", "err", err, "address", in.GetAddress())
+		return nil, status.Error(codes.Internal, "failed getting contract storage")
+	}
+
+	return &proto.StorageRes{Value: value.Hex()}, nil
+}
+
+func (s *State) GetLogs(ctx context.Context, in *proto.LogsReq) (*proto.LogsRes, error) {
+	f := eventlog.FilterFromProto(in)
+	if err := f.Normalize(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	limit := eventlog.MaxLogs
+	if f.Limit > 0 {
+		limit = min(f.Limit, limit)
+	}
+
+	logs, _, err := s.findLogs(ctx, f, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.LogsRes{Logs: eventlog.ToProto(logs)}, nil
+}
+
+// SubscribeLogs streams the logs matching the filter as blocks are added.
+// With a from height the matching logs since are sent first, to height and
+// limit do not apply.
+func (s *State) SubscribeLogs(in *proto.LogsReq, stream proto.StateService_SubscribeLogsServer) error {
+	ctx := stream.Context()
+
+	f := eventlog.FilterFromProto(in)
+	if err := f.Normalize(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// subscribe before reading the history so no block falls in between
+	sub := s.logFeed.Subscribe()
+	defer sub.Unsubscribe()
+
+	var sent uint64
+	if f.FromHeight > 0 {
+		f.ToHeight = 0
+		logs, to, err := s.findLogs(ctx, f, 0)
+		if err != nil {
+			return err
+		}
+
+		for i := range logs {
+			if err := stream.Send(logs[i].ToProto()); err != nil {
+				return err
+			}
+		}
+		sent = to
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case b, ok := <-sub.C:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "subscriber fell behind")
+			}
+			if b.Height <= sent || !f.MatchesBloom(&b.Bloom) {
+				continue
+			}
+
+			for i := range b.Logs {
+				if !f.Matches(&b.Logs[i]) {
+					continue
+				}
+				if err := stream.Send(b.Logs[i].ToProto()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// SubscribeBlocks streams blocks with their transactions and receipt
+// statuses as they are added.
+func (s *State) SubscribeBlocks(in *proto.SubscribeBlocksReq, stream proto.StateService_SubscribeBlocksServer) error {
+	sub := s.blockFeed.Subscribe()
+	defer sub.Unsubscribe()
+
+	// the headers tell the client it is subscribed
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+
+		case ev, ok := <-sub.C:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "subscriber fell behind")
+			}
+			if err := stream.Send(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// findLogs returns up to limit logs matching a normalized filter, all of
+// them for limit 0, and the last height searched. Only blocks whose bloom
+// matches are read.
+func (s *State) findLogs(ctx context.Context, f *eventlog.Filter, limit int) ([]eventlog.Log, uint64, error) {
+	latest, err := s.blockModel.GetLatest(ctx)
+	if err != nil {
+		s.log.Error("failed getting latest block", "err", err)
+		return nil, 0, status.Error(codes.Internal, "failed getting latest block")
+	}
+
+	to := latest.Height
+	if f.ToHeight != 0 {
+		to = min(f.ToHeight, to)
+	}
+
+	if to >= f.FromHeight && to-f.FromHeight >= eventlog.MaxBlockRange {
+		return nil, 0, status.Errorf(codes.InvalidArgument, "%v: at most %d blocks can be searched at once", errmsg.ErrInvalidLogFilter, eventlog.MaxBlockRange)
+	}
+
+	heights, err := s.logModel.Candidates(ctx, f, f.FromHeight, to)
+	if err != nil {
+		s.log.Error("failed reading log blooms", "err", err)
+		return nil, 0, status.Error(codes.Internal, "failed reading log blooms")
+	}
+
+	logs, err := s.logModel.Find(ctx, f, heights, limit)
+	if err != nil {
+		s.log.Error("failed getting logs", "err", err)
+		return nil, 0, status.Error(codes.Internal, "failed getting logs")
+	}
+
+	return logs, to, nil
+}
+
+func (s *State) GetChainConfig(ctx context.Context, in *proto.GetChainConfigRequest) (*proto.GetChainConfigResponse, error) {
+	return &proto.GetChainConfigResponse{Config: s.chainConfig.ToProto()}, nil
+}
+
+func (s *State) GetCurrentDifficulty(ctx context.Context, in *proto.GetCurrentDifficultyRequest) (*proto.GetCurrentDifficultyResponse, error) {
+	latestBlock, err := s.blockModel.GetLatest(ctx)
+	if err != nil {
+		s.log.Info("failed getting latest block data", "err", err)
+		return nil, status.Error(codes.Internal, "failed getting latest block data")
+	}
+
+	difficulty := latestBlock.Difficulty
+	if latestBlock.Height == 0 {
+		difficulty = s.chainConfig.InitialDifficulty
+	}
+
+	return &proto.GetCurrentDifficultyResponse{Difficulty: difficulty}, nil
+}
+
 func (s *State) Start() error {
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%s", s.apiPort))
 	if err != nil {
@@ -248,6 +1464,7 @@ func (s *State) Start() error {
 	server := grpc.NewServer()
 	reflection.Register(server)
 	proto.RegisterStateServiceServer(server, s)
+	proto.RegisterConfigServiceServer(server, s)
 
 	s.log.Info("rpc server started", "port exposed", s.apiPort)
 	return server.Serve(listener)
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"com.perkunas/internal/models/account"
+	"com.perkunas/internal/models/balancechange"
+	"com.perkunas/internal/models/chainconfig"
+	"com.perkunas/internal/models/receipt"
+	"com.perkunas/internal/models/token"
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/proto"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	alice = "0xE07cD67682C4b43bEF6b399bb7C180D975571aaa"
+	bob   = "0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19b2"
+	carol = "0x3dA6a9A1E0c50bEd5C4A7cA1c2a0fE9DDc3B3B1e"
+)
+
+// testState returns a state service on an in-memory database and an open
+// block transaction holding accounts.
+func testState(t *testing.T, accounts ...account.Account) (*State, *sqlx.Tx) {
+	t.Helper()
+	ctx := context.Background()
+
+	d, err := dbConnect(ctx, ":memory:", stateSql)
+	assert.NoError(t, err)
+	t.Cleanup(func() { d.Close() })
+
+	s := &State{
+		db:                 d,
+		log:                slog.New(slog.NewTextHandler(io.Discard, nil)),
+		accModel:           &account.Model{DB: d},
+		balanceChangeModel: &balancechange.Model{DB: d},
+		tokenModel:         &token.Model{DB: d},
+	}
+
+	dbTx, err := d.WriteDB.BeginTxx(ctx, nil)
+	assert.NoError(t, err)
+	t.Cleanup(func() { dbTx.Rollback() })
+
+	assert.NoError(t, s.accModel.BatchInsert(ctx, dbTx, accounts))
+	return s, dbTx
+}
+
+func getAccount(t *testing.T, s *State, dbTx *sqlx.Tx, addr string) account.Account {
+	t.Helper()
+
+	acc, err := s.accModel.UpsertNoUpdate(context.Background(), dbTx, addr)
+	assert.NoError(t, err)
+	return acc
+}
+
+func TestApplyTransaction_Accepted(t *testing.T) {
+	ctx := context.Background()
+	s, dbTx := testState(t, account.Account{Address: alice, Balance: 100, Nonce: 2})
+
+	tx := &transaction.Transaction{Hash: "tx", From: alice, To: bob, Amount: 30, Fee: 5, Nonce: 3}
+	res, err := s.applyTransaction(ctx, dbTx, tx, &proto.Block{Height: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, receipt.StatusAccepted, res.Status)
+
+	sender := getAccount(t, s, dbTx, alice)
+	assert.Equal(t, int64(65), sender.Balance)
+	assert.Equal(t, uint64(3), sender.Nonce)
+	assert.Equal(t, int64(30), getAccount(t, s, dbTx, bob).Balance)
+}
+
+func TestApplyTransaction_BadNonce(t *testing.T) {
+	ctx := context.Background()
+	s, dbTx := testState(t, account.Account{Address: alice, Balance: 100, Nonce: 2})
+
+	tx := &transaction.Transaction{Hash: "tx", From: alice, To: bob, Amount: 30, Fee: 5, Nonce: 5}
+	res, err := s.applyTransaction(ctx, dbTx, tx, &proto.Block{Height: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, receipt.StatusBadNonce, res.Status)
+	assert.NotEmpty(t, res.Error)
+
+	// included without a charge and without using up a nonce
+	sender := getAccount(t, s, dbTx, alice)
+	assert.Equal(t, int64(100), sender.Balance)
+	assert.Equal(t, uint64(2), sender.Nonce)
+	assert.Zero(t, getAccount(t, s, dbTx, bob).Balance)
+}
+
+func TestApplyTransaction_InsufficientFunds(t *testing.T) {
+	tests := []struct {
+		name    string
+		balance int64
+		want    int64
+	}{
+		{name: "covers fee", balance: 50, want: 40},
+		{name: "below fee", balance: 4, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s, dbTx := testState(t, account.Account{Address: alice, Balance: tt.balance})
+
+			tx := &transaction.Transaction{Hash: "tx", From: alice, To: bob, Amount: 100, Fee: 10, Nonce: 1}
+			res, err := s.applyTransaction(ctx, dbTx, tx, &proto.Block{Height: 1})
+			assert.NoError(t, err)
+			assert.Equal(t, receipt.StatusInsufficientFunds, res.Status)
+
+			sender := getAccount(t, s, dbTx, alice)
+			assert.Equal(t, tt.want, sender.Balance)
+			assert.Equal(t, uint64(1), sender.Nonce)
+			assert.Zero(t, getAccount(t, s, dbTx, bob).Balance)
+		})
+	}
+}
+
+func TestApplyTransaction_RevertedRollsBack(t *testing.T) {
+	ctx := context.Background()
+	s, dbTx := testState(t,
+		account.Account{Address: alice, Balance: 100},
+		account.Account{Address: bob, Balance: 100},
+	)
+	pb := &proto.Block{Height: 1}
+
+	create, err := token.CreateTransaction(alice, token.Definition{Symbol: "TKN", Supply: 100}, 1, 1)
+	assert.NoError(t, err)
+	res, err := s.applyTransaction(ctx, dbTx, create, pb)
+	assert.NoError(t, err)
+	assert.Equal(t, receipt.StatusAccepted, res.Status)
+	tkn := create.To
+
+	// bob may spend more than alice holds
+	approve, err := token.ApproveTransaction(alice, bob, token.Transfer{Token: tkn, Amount: 500}, 1, 2)
+	assert.NoError(t, err)
+	res, err = s.applyTransaction(ctx, dbTx, approve, pb)
+	assert.NoError(t, err)
+	assert.Equal(t, receipt.StatusAccepted, res.Status)
+
+	// the allowance is used up before the balance check fails
+	spend, err := token.TransferTransaction(bob, carol, token.Transfer{Token: tkn, Amount: 200, Owner: alice}, 5, 1)
+	assert.NoError(t, err)
+	res, err = s.applyTransaction(ctx, dbTx, spend, pb)
+	assert.NoError(t, err)
+	assert.Equal(t, receipt.StatusReverted, res.Status)
+	assert.NotEmpty(t, res.Error)
+
+	allowance, err := s.tokenModel.AllowanceWithTX(ctx, dbTx, tkn, alice, bob)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(500), allowance)
+
+	for addr, want := range map[string]int64{alice: 100, carol: 0} {
+		balance, err := s.tokenModel.BalanceWithTX(ctx, dbTx, tkn, addr)
+		assert.NoError(t, err)
+		assert.Equal(t, want, balance, addr)
+	}
+
+	// the fee is still charged and the nonce used up
+	spender := getAccount(t, s, dbTx, bob)
+	assert.Equal(t, int64(95), spender.Balance)
+	assert.Equal(t, uint64(1), spender.Nonce)
+}
+
+func TestApplyTransactions_UsedNonceRejectsBlock(t *testing.T) {
+	ctx := context.Background()
+	s, dbTx := testState(t, account.Account{Address: alice, Balance: 100, Nonce: 2})
+	pb := &proto.Block{Height: 1}
+
+	used := &transaction.Transaction{Hash: "used", From: alice, To: bob, Amount: 1, Fee: 1, Nonce: 2}
+	_, err := s.applyTransaction(ctx, dbTx, used, pb)
+	assert.Error(t, err)
+
+	// a replay later in the same block
+	txs := []*proto.Transaction{
+		{Hash: "first", FromAddr: alice, ToAddr: bob, Amount: 1, Fee: 1, Nonce: 3},
+		{Hash: "replay", FromAddr: alice, ToAddr: bob, Amount: 1, Fee: 1, Nonce: 3},
+	}
+	_, err = s.applyTransactions(ctx, dbTx, txs, pb)
+	assert.ErrorContains(t, err, "replay")
+}
+
+func TestValidateTransactions_Limits(t *testing.T) {
+	pb := &proto.Block{Transactions: []*proto.Transaction{{Hash: "a"}, {Hash: "b"}}}
+
+	err := validateTransactions(pb, chainconfig.ChainConfig{MaxTxPerBlock: 1})
+	assert.ErrorContains(t, err, "max transactions")
+
+	err = validateTransactions(pb, chainconfig.ChainConfig{MaxBlockSize: blockSize(pb) - 1})
+	assert.ErrorContains(t, err, "max block size")
+}
@@ -0,0 +1,213 @@
+package assembler
+
+import (
+	"container/heap"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sort"
+
+	"com.perkunas/internal/models/block"
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/proto"
+)
+
+// AccountFunc returns the confirmed state of an account.
+type AccountFunc func(ctx context.Context, addr string) (*proto.Account, error)
+
+// Assembler picks the transactions that go into the next block. It fills the
+// block highest fee first while keeping every sender's transactions in nonce
+// order, and tracks each sender's balance as their transactions are included
+// so nobody can overspend across several transactions in one block.
+type Assembler struct {
+	Log      *slog.Logger
+	MaxTxs   uint64 // 0 means unlimited
+	MaxBytes uint64 // 0 means unlimited
+	ChainID  uint64 // chain Ethereum transactions must be signed for
+	Accounts AccountFunc
+}
+
+type sender struct {
+	addr      string
+	balance   int64
+	nextNonce uint64
+	txs       []*transaction.Transaction
+}
+
+func (s *sender) head() *transaction.Transaction {
+	return s.txs[0]
+}
+
+func (a *Assembler) Assemble(ctx context.Context, txs []*transaction.Transaction) []*transaction.Transaction {
+	senders := a.groupBySender(ctx, txs)
+
+	queue := make(senderQueue, 0, len(senders))
+	for _, s := range senders {
+		queue = append(queue, s)
+	}
+	heap.Init(&queue)
+
+	selected := make([]*transaction.Transaction, 0)
+	size := headerSize()
+
+	for queue.Len() > 0 {
+		if a.MaxTxs > 0 && uint64(len(selected)) >= a.MaxTxs {
+			break
+		}
+
+		s := queue[0]
+		tx := s.head()
+
+		// an already mined nonce left behind in the mempool is skipped
+		if tx.Nonce < s.nextNonce {
+			a.Log.Info("stale tx nonce", "hash", tx.Hash, "txNonce", tx.Nonce, "expectedNonce", s.nextNonce)
+			s.txs = s.txs[1:]
+			if len(s.txs) == 0 {
+				heap.Pop(&queue)
+			} else {
+				heap.Fix(&queue, 0)
+			}
+			continue
+		}
+
+		// a gap or an unaffordable tx makes all later txs of the sender unusable
+		if tx.Nonce != s.nextNonce {
+			a.Log.Info("invalid tx nonce", "hash", tx.Hash, "txNonce", tx.Nonce, "expectedNonce", s.nextNonce)
+			heap.Pop(&queue)
+			continue
+		}
+
+		if s.balance < tx.Amount+tx.Fee {
+			a.Log.Info("insufficient balance", "addr", s.addr, "balance", s.balance, "amount", tx.Amount+tx.Fee)
+			heap.Pop(&queue)
+			continue
+		}
+
+		txSize := TxSize(tx)
+		if a.MaxBytes > 0 && size+txSize > a.MaxBytes {
+			a.Log.Info("tx does not fit into block", "hash", tx.Hash, "size", txSize, "blockSize", size)
+			heap.Pop(&queue)
+			continue
+		}
+
+		selected = append(selected, tx)
+		size += txSize
+		s.balance -= tx.Amount + tx.Fee
+		s.nextNonce++
+		s.txs = s.txs[1:]
+
+		if len(s.txs) == 0 {
+			heap.Pop(&queue)
+		} else {
+			heap.Fix(&queue, 0)
+		}
+	}
+
+	return selected
+}
+
+// groupBySender drops txs with bad signatures, loads each sender's account and
+// orders its txs by nonce, keeping the highest fee tx when a nonce repeats.
+func (a *Assembler) groupBySender(ctx context.Context, txs []*transaction.Transaction) []*sender {
+	byAddr := make(map[string]*sender)
+	order := make([]string, 0)
+
+	for _, tx := range txs {
+		// skip invalid transactions but continue processing others
+		if err := tx.Verify(a.ChainID); err != nil {
+			a.Log.Warn("invalid transaction skipped", "hash", tx.Hash, "error", err)
+			continue
+		}
+
+		s, ok := byAddr[tx.From]
+		if !ok {
+			acc, err := a.Accounts(ctx, tx.From)
+			if err != nil {
+				a.Log.Error("failed getting account by address", "address", tx.From, "err", err)
+				continue
+			}
+
+			if acc == nil {
+				a.Log.Info("account not found by address", "addr", tx.From)
+				continue
+			}
+
+			s = &sender{addr: tx.From, balance: acc.GetBalance(), nextNonce: acc.GetNonce() + 1}
+			byAddr[tx.From] = s
+			order = append(order, tx.From)
+		}
+
+		s.txs = append(s.txs, tx)
+	}
+
+	res := make([]*sender, 0, len(order))
+	for _, addr := range order {
+		s := byAddr[addr]
+		sort.SliceStable(s.txs, func(i, j int) bool {
+			if s.txs[i].Nonce != s.txs[j].Nonce {
+				return s.txs[i].Nonce < s.txs[j].Nonce
+			}
+			return s.txs[i].Fee > s.txs[j].Fee
+		})
+
+		deduped := s.txs[:0]
+		for i, tx := range s.txs {
+			if i > 0 && tx.Nonce == s.txs[i-1].Nonce {
+				continue
+			}
+			deduped = append(deduped, tx)
+		}
+		s.txs = deduped
+
+		res = append(res, s)
+	}
+
+	return res
+}
+
+// TxSize is the number of bytes tx takes in the serialized block.
+func TxSize(tx *transaction.Transaction) uint64 {
+	b, err := json.Marshal(tx)
+	if err != nil {
+		return 0
+	}
+
+	// +1 for the separator in the transactions array
+	return uint64(len(b)) + 1
+}
+
+// headerSize leaves room for the header the miner seals after assembly, the
+// state service rejects blocks over MaxBytes.
+func headerSize() uint64 {
+	return block.MaxHeaderSize()
+}
+
+// senderQueue is a max-heap of senders keyed by the fee of their next tx.
+type senderQueue []*sender
+
+func (q senderQueue) Len() int { return len(q) }
+
+func (q senderQueue) Less(i, j int) bool {
+	a, b := q[i].head(), q[j].head()
+	if a.Fee != b.Fee {
+		return a.Fee > b.Fee
+	}
+
+	if a.Timestamp != b.Timestamp {
+		return a.Timestamp < b.Timestamp
+	}
+
+	return a.Hash < b.Hash
+}
+
+func (q senderQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *senderQueue) Push(x any) { *q = append(*q, x.(*sender)) }
+
+func (q *senderQueue) Pop() any {
+	old := *q
+	n := len(old)
+	s := old[n-1]
+	*q = old[:n-1]
+	return s
+}
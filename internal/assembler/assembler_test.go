@@ -0,0 +1,149 @@
+package assembler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"com.perkunas/internal/models/block"
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/pkg/wallet"
+	"com.perkunas/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func signedTx(t *testing.T, w *wallet.Wallet, nonce uint64, amount, fee int64) *transaction.Transaction {
+	t.Helper()
+
+	tx := &transaction.Transaction{
+		From:      w.Address,
+		To:        "0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19b2",
+		Amount:    amount,
+		Fee:       fee,
+		Nonce:     nonce,
+		Timestamp: time.Now().Unix(),
+	}
+	tx.SetHash()
+	assert.NoError(t, w.SignTransaction(tx))
+	return tx
+}
+
+func testAssembler(accounts map[string]*proto.Account) *Assembler {
+	return &Assembler{
+		Log: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Accounts: func(ctx context.Context, addr string) (*proto.Account, error) {
+			return accounts[addr], nil
+		},
+	}
+}
+
+func TestAssemble_OrdersByFeeRespectingNonce(t *testing.T) {
+	alice, _ := wallet.New()
+	bob, _ := wallet.New()
+
+	a := testAssembler(map[string]*proto.Account{
+		alice.Address: {Address: alice.Address, Balance: 1000},
+		bob.Address:   {Address: bob.Address, Balance: 1000},
+	})
+
+	a1 := signedTx(t, alice, 1, 10, 1)
+	a2 := signedTx(t, alice, 2, 10, 50)
+	b1 := signedTx(t, bob, 1, 10, 20)
+
+	res := a.Assemble(context.Background(), []*transaction.Transaction{a2, b1, a1})
+	assert.Equal(t, []*transaction.Transaction{b1, a1, a2}, res)
+}
+
+func TestAssemble_TracksBalanceWithinBlock(t *testing.T) {
+	alice, _ := wallet.New()
+
+	a := testAssembler(map[string]*proto.Account{
+		alice.Address: {Address: alice.Address, Balance: 100, Nonce: 4},
+	})
+
+	tx1 := signedTx(t, alice, 5, 60, 5)
+	tx2 := signedTx(t, alice, 6, 60, 5)
+
+	res := a.Assemble(context.Background(), []*transaction.Transaction{tx1, tx2})
+	assert.Equal(t, []*transaction.Transaction{tx1}, res)
+}
+
+func TestAssemble_SkipsNonceGapsAndDuplicates(t *testing.T) {
+	alice, _ := wallet.New()
+
+	a := testAssembler(map[string]*proto.Account{
+		alice.Address: {Address: alice.Address, Balance: 1000},
+	})
+
+	low := signedTx(t, alice, 1, 10, 1)
+	high := signedTx(t, alice, 1, 20, 9)
+	gap := signedTx(t, alice, 3, 10, 1)
+
+	res := a.Assemble(context.Background(), []*transaction.Transaction{low, high, gap})
+	assert.Equal(t, []*transaction.Transaction{high}, res)
+}
+
+func TestAssemble_SkipsStaleNonces(t *testing.T) {
+	alice, _ := wallet.New()
+
+	a := testAssembler(map[string]*proto.Account{
+		alice.Address: {Address: alice.Address, Balance: 1000, Nonce: 3},
+	})
+
+	// nonces 2 and 3 were mined but are still in the mempool
+	stale2 := signedTx(t, alice, 2, 10, 50)
+	stale3 := signedTx(t, alice, 3, 10, 50)
+	tx4 := signedTx(t, alice, 4, 10, 1)
+	tx5 := signedTx(t, alice, 5, 10, 1)
+
+	res := a.Assemble(context.Background(), []*transaction.Transaction{tx5, stale3, tx4, stale2})
+	assert.Equal(t, []*transaction.Transaction{tx4, tx5}, res)
+}
+
+func TestAssemble_EnforcesLimits(t *testing.T) {
+	alice, _ := wallet.New()
+	bob, _ := wallet.New()
+
+	accounts := map[string]*proto.Account{
+		alice.Address: {Address: alice.Address, Balance: 1000},
+		bob.Address:   {Address: bob.Address, Balance: 1000},
+	}
+
+	a1 := signedTx(t, alice, 1, 10, 5)
+	a2 := signedTx(t, alice, 2, 10, 5)
+	b1 := signedTx(t, bob, 1, 10, 1)
+
+	a := testAssembler(accounts)
+	a.MaxTxs = 2
+	res := a.Assemble(context.Background(), []*transaction.Transaction{a1, a2, b1})
+	assert.Equal(t, []*transaction.Transaction{a1, a2}, res)
+
+	a = testAssembler(accounts)
+	a.MaxBytes = headerSize() + TxSize(a1)
+	res = a.Assemble(context.Background(), []*transaction.Transaction{a1, a2, b1})
+	assert.Equal(t, []*transaction.Transaction{a1}, res)
+
+	// the sealed block still fits
+	b := block.NewBlock()
+	b.PrevHash, b.StateRoot, b.Miner = strings.Repeat("0", 64), strings.Repeat("0", 64), alice.Address
+	b.Transactions = res
+	b.Hash, _ = b.CalculateHash()
+	assert.LessOrEqual(t, b.Size(), a.MaxBytes)
+}
+
+func TestAssemble_DropsInvalidSignatures(t *testing.T) {
+	alice, _ := wallet.New()
+
+	a := testAssembler(map[string]*proto.Account{
+		alice.Address: {Address: alice.Address, Balance: 1000},
+	})
+
+	tx := signedTx(t, alice, 1, 10, 1)
+	tx.Amount = 999
+
+	res := a.Assemble(context.Background(), []*transaction.Transaction{tx})
+	assert.Empty(t, res)
+}
@@ -0,0 +1,62 @@
+// Package bloom is the 2048 bit filter kept per block over the addresses and
+// topics of its logs. A miss means the block has no log with the value, a hit
+// may be a false positive. The construction is Ethereum's logsBloom: three
+// bits per value taken from its keccak hash.
+package bloom
+
+import (
+	"encoding/hex"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Size is the size of a bloom in bytes.
+const Size = 256
+
+type Bloom [Size]byte
+
+// Add sets the bits of data.
+func (b *Bloom) Add(data []byte) {
+	for _, bit := range bits(data) {
+		b[Size-1-bit/8] |= 1 << (bit % 8)
+	}
+}
+
+// Test reports whether data may have been added.
+func (b *Bloom) Test(data []byte) bool {
+	for _, bit := range bits(data) {
+		if b[Size-1-bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (b *Bloom) Hex() string {
+	return "0x" + hex.EncodeToString(b[:])
+}
+
+// FromHex parses a bloom encoded by Hex.
+func FromHex(s string) (Bloom, error) {
+	var b Bloom
+
+	if len(s) != 2+2*Size || s[:2] != "0x" {
+		return b, errors.New("bloom must be 256 bytes of 0x prefixed hex")
+	}
+
+	_, err := hex.Decode(b[:], []byte(s[2:]))
+	return b, err
+}
+
+func bits(data []byte) [3]uint {
+	h := crypto.Keccak256(data)
+
+	var res [3]uint
+	for i := range res {
+		res[i] = (uint(h[2*i])<<8 | uint(h[2*i+1])) & (Size*8 - 1)
+	}
+
+	return res
+}
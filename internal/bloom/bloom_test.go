@@ -0,0 +1,27 @@
+package bloom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBloom(t *testing.T) {
+	var b Bloom
+	assert.False(t, b.Test([]byte("transfer")))
+
+	b.Add([]byte("transfer"))
+	b.Add([]byte("approve"))
+	assert.True(t, b.Test([]byte("transfer")))
+	assert.True(t, b.Test([]byte("approve")))
+	assert.False(t, b.Test([]byte("mint")))
+
+	decoded, err := FromHex(b.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, b, decoded)
+
+	for _, s := range []string{"", "0x00", b.Hex()[2:] + "00", "0x" + string(make([]byte, 2*Size))} {
+		_, err := FromHex(s)
+		assert.Error(t, err, s)
+	}
+}
@@ -0,0 +1,62 @@
+package consensus
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"time"
+
+	"com.perkunas/internal/errmsg"
+	"com.perkunas/internal/models/block"
+	"com.perkunas/internal/models/chainconfig"
+)
+
+// Engine decides who may author a block and what makes a sealed block valid.
+type Engine interface {
+	// Prepare fills in the consensus fields of b so it can be built on top of parent.
+	Prepare(parent *block.Block, b *block.Block) error
+	// Seal finalises b (hash, nonce, signature), blocking until done or ctx is cancelled.
+	Seal(ctx context.Context, b *block.Block) error
+	// VerifyHeader checks that b is a correctly sealed successor of parent.
+	VerifyHeader(parent *block.Block, b *block.Block) error
+	// Author returns the address of the account that sealed b.
+	Author(b *block.Block) (string, error)
+}
+
+// New builds the engine selected by the chain config. The key is used as the
+// block author; it is mandatory for proof-of-authority sealing and optional otherwise.
+func New(cfg chainconfig.ChainConfig, key *ecdsa.PrivateKey) (Engine, error) {
+	switch cfg.Consensus {
+	case "", chainconfig.ConsensusPoW:
+		return NewPoW(cfg.InitialDifficulty, key), nil
+	case chainconfig.ConsensusPoA:
+		return NewPoA(cfg.Signers, time.Duration(cfg.BlockTime)*time.Second, key)
+	default:
+		return nil, fmt.Errorf("%w: %s", errmsg.ErrUnknownConsensus, cfg.Consensus)
+	}
+}
+
+// verifyLinkage checks the parts of a header every engine agrees on: it
+// extends parent and its hash and merkle root match its contents.
+func verifyLinkage(parent *block.Block, b *block.Block) error {
+	if b.PrevHash != parent.Hash || b.Height != parent.Height+1 {
+		return errmsg.ErrInvalidParent
+	}
+
+	if b.Timestamp < parent.Timestamp {
+		return errmsg.ErrInvalidParent
+	}
+
+	// hash a copy so the caller's merkle root is left as received
+	cp := *b
+	hash, err := cp.CalculateHash()
+	if err != nil {
+		return err
+	}
+
+	if hash != b.Hash || cp.MerkleRoot != b.MerkleRoot {
+		return errmsg.ErrInvalidBlockHash
+	}
+
+	return nil
+}
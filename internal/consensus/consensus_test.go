@@ -0,0 +1,130 @@
+package consensus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"com.perkunas/internal/errmsg"
+	"com.perkunas/internal/models/block"
+	"com.perkunas/internal/models/chainconfig"
+	"com.perkunas/internal/models/transaction"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func testParent() *block.Block {
+	parent := &block.Block{Height: 4, Timestamp: time.Now().Add(-time.Minute).Unix()}
+	parent.Hash, _ = parent.CalculateHash()
+	return parent
+}
+
+func testChild(parent *block.Block) *block.Block {
+	return &block.Block{
+		PrevHash:  parent.Hash,
+		Height:    parent.Height + 1,
+		Timestamp: time.Now().Unix(),
+		Transactions: []*transaction.Transaction{
+			{From: "sender", To: "recipient", Amount: 10, Fee: 1, Nonce: 1},
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	e, err := New(chainconfig.ChainConfig{InitialDifficulty: 1}, nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &PoW{}, e)
+
+	_, err = New(chainconfig.ChainConfig{Consensus: "pos"}, nil)
+	assert.ErrorIs(t, err, errmsg.ErrUnknownConsensus)
+
+	_, err = New(chainconfig.ChainConfig{Consensus: chainconfig.ConsensusPoA}, nil)
+	assert.Error(t, err)
+}
+
+func TestPoW_SealAndVerify(t *testing.T) {
+	e := NewPoW(2, nil)
+	parent := testParent()
+	b := testChild(parent)
+
+	assert.NoError(t, e.Prepare(parent, b))
+	assert.Equal(t, uint64(2), b.Difficulty)
+	assert.NoError(t, e.Seal(context.Background(), b))
+	assert.True(t, isHashValid(b.Hash, 2))
+	assert.NoError(t, e.VerifyHeader(parent, b))
+
+	// tampering with contents invalidates the hash
+	b.Transactions[0].Amount = 1000
+	assert.ErrorIs(t, e.VerifyHeader(parent, b), errmsg.ErrInvalidBlockHash)
+}
+
+func TestPoW_VerifyRejectsWrongParent(t *testing.T) {
+	e := NewPoW(1, nil)
+	parent := testParent()
+	b := testChild(parent)
+	b.PrevHash = "unknown"
+
+	assert.NoError(t, e.Prepare(parent, b))
+	assert.NoError(t, e.Seal(context.Background(), b))
+	assert.ErrorIs(t, e.VerifyHeader(parent, b), errmsg.ErrInvalidParent)
+}
+
+func TestPoA_SealAndVerify(t *testing.T) {
+	key1, _ := crypto.GenerateKey()
+	key2, _ := crypto.GenerateKey()
+	signers := []string{
+		crypto.PubkeyToAddress(key1.PublicKey).Hex(),
+		crypto.PubkeyToAddress(key2.PublicKey).Hex(),
+	}
+
+	parent := testParent()
+
+	// height 5 belongs to signers[1]
+	outOfTurn, err := NewPoA(signers, 0, key1)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, outOfTurn.Prepare(parent, testChild(parent)), errmsg.ErrNotInTurn)
+
+	e, err := NewPoA(signers, time.Second, key2)
+	assert.NoError(t, err)
+
+	b := testChild(parent)
+	assert.NoError(t, e.Prepare(parent, b))
+	assert.Equal(t, signers[1], b.Miner)
+	assert.NoError(t, e.Seal(context.Background(), b))
+	assert.NotEmpty(t, b.Signature)
+
+	verifier, err := NewPoA(signers, time.Second, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, verifier.VerifyHeader(parent, b))
+
+	author, err := verifier.Author(b)
+	assert.NoError(t, err)
+	assert.Equal(t, signers[1], author)
+
+	// a block signed by the wrong authority is rejected
+	forged := *b
+	outOfTurn.key = key1
+	assert.NoError(t, outOfTurn.Seal(context.Background(), &forged))
+	assert.ErrorIs(t, verifier.VerifyHeader(parent, &forged), errmsg.ErrUnauthorizedSigner)
+}
+
+func TestPoA_RespectsPeriod(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signers := []string{crypto.PubkeyToAddress(key.PublicKey).Hex()}
+	e, err := NewPoA(signers, time.Hour, key)
+	assert.NoError(t, err)
+
+	parent := testParent()
+	b := testChild(parent)
+	assert.NoError(t, e.Prepare(parent, b))
+	assert.Equal(t, parent.Timestamp+3600, b.Timestamp)
+
+	// sealing waits for the slot, so a cancelled context aborts it
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, e.Seal(ctx, b), context.DeadlineExceeded)
+
+	b.Timestamp = parent.Timestamp + 1
+	b.Hash, _ = b.CalculateHash()
+	assert.ErrorIs(t, e.VerifyHeader(parent, b), errmsg.ErrBlockTooEarly)
+}
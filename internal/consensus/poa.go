@@ -0,0 +1,141 @@
+package consensus
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"time"
+
+	"com.perkunas/internal/errmsg"
+	"com.perkunas/internal/models/block"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// PoA is a round-robin proof-of-authority engine: the block at height h must be
+// signed by Signers[h % len(Signers)] no sooner than Period after its parent.
+type PoA struct {
+	Signers []string
+	Period  time.Duration
+	key     *ecdsa.PrivateKey
+	address string
+}
+
+func NewPoA(signers []string, period time.Duration, key *ecdsa.PrivateKey) (*PoA, error) {
+	if len(signers) == 0 {
+		return nil, errmsg.ErrUnauthorizedSigner
+	}
+
+	e := &PoA{Period: period, key: key}
+	for _, s := range signers {
+		e.Signers = append(e.Signers, common.HexToAddress(s).Hex())
+	}
+
+	if key != nil {
+		e.address = crypto.PubkeyToAddress(key.PublicKey).Hex()
+	}
+
+	return e, nil
+}
+
+// InTurn returns the signer scheduled to author the block at height.
+func (e *PoA) InTurn(height uint64) string {
+	return e.Signers[height%uint64(len(e.Signers))]
+}
+
+func (e *PoA) Prepare(parent *block.Block, b *block.Block) error {
+	if e.key == nil {
+		return errmsg.ErrMissingSignerKey
+	}
+
+	if e.InTurn(b.Height) != e.address {
+		return errmsg.ErrNotInTurn
+	}
+
+	b.Miner = e.address
+	b.Difficulty = 0
+	b.Nonce = 0
+
+	if slot := parent.Timestamp + int64(e.Period.Seconds()); b.Timestamp < slot {
+		b.Timestamp = slot
+	}
+
+	return nil
+}
+
+func (e *PoA) Seal(ctx context.Context, b *block.Block) error {
+	if e.key == nil {
+		return errmsg.ErrMissingSignerKey
+	}
+
+	// wait for the block's slot instead of burning cpu
+	if wait := time.Until(time.Unix(b.Timestamp, 0)); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	hash, err := b.CalculateHash()
+	if err != nil {
+		return err
+	}
+	b.Hash = hash
+
+	digest, err := hex.DecodeString(hash)
+	if err != nil {
+		return errmsg.ErrInvalidBlockHash
+	}
+
+	sig, err := crypto.Sign(digest, e.key)
+	if err != nil {
+		return errmsg.ErrSigningError
+	}
+
+	b.Signature = hex.EncodeToString(sig)
+	return nil
+}
+
+func (e *PoA) VerifyHeader(parent *block.Block, b *block.Block) error {
+	if err := verifyLinkage(parent, b); err != nil {
+		return err
+	}
+
+	if b.Timestamp < parent.Timestamp+int64(e.Period.Seconds()) {
+		return errmsg.ErrBlockTooEarly
+	}
+
+	signer, err := e.Author(b)
+	if err != nil {
+		return err
+	}
+
+	if signer != b.Miner || signer != e.InTurn(b.Height) {
+		return errmsg.ErrUnauthorizedSigner
+	}
+
+	return nil
+}
+
+func (e *PoA) Author(b *block.Block) (string, error) {
+	digest, err := hex.DecodeString(b.Hash)
+	if err != nil {
+		return "", errmsg.ErrInvalidBlockHash
+	}
+
+	sig, err := hex.DecodeString(b.Signature)
+	if err != nil {
+		return "", errmsg.ErrInvalidSignatureFormat
+	}
+
+	pubKey, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		return "", errmsg.ErrSignatureRecoveryFailed
+	}
+
+	return crypto.PubkeyToAddress(*pubKey).Hex(), nil
+}
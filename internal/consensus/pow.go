@@ -0,0 +1,80 @@
+package consensus
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"strings"
+
+	"com.perkunas/internal/errmsg"
+	"com.perkunas/internal/models/block"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// PoW seals blocks by searching for a nonce whose hash has Difficulty leading zeros.
+type PoW struct {
+	Difficulty uint64
+	coinbase   string
+}
+
+func NewPoW(difficulty uint64, key *ecdsa.PrivateKey) *PoW {
+	e := &PoW{Difficulty: difficulty}
+	if key != nil {
+		e.coinbase = crypto.PubkeyToAddress(key.PublicKey).Hex()
+	}
+
+	return e
+}
+
+func (e *PoW) Prepare(parent *block.Block, b *block.Block) error {
+	b.Difficulty = e.Difficulty
+	b.Miner = e.coinbase
+	return nil
+}
+
+func (e *PoW) Seal(ctx context.Context, b *block.Block) error {
+	for nonce := uint64(0); ; nonce++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			b.Nonce = nonce
+			hash, err := b.CalculateHash()
+			if err != nil {
+				return err
+			}
+			b.Hash = hash
+
+			if isHashValid(b.Hash, b.Difficulty) {
+				return nil
+			}
+		}
+	}
+}
+
+func (e *PoW) VerifyHeader(parent *block.Block, b *block.Block) error {
+	if err := verifyLinkage(parent, b); err != nil {
+		return err
+	}
+
+	if b.Difficulty != e.Difficulty {
+		return errmsg.ErrInvalidDifficulty
+	}
+
+	if !isHashValid(b.Hash, b.Difficulty) {
+		return errmsg.ErrInsufficientWork
+	}
+
+	return nil
+}
+
+func (e *PoW) Author(b *block.Block) (string, error) {
+	return b.Miner, nil
+}
+
+func isHashValid(hash string, difficulty uint64) bool {
+	// Convert difficulty to required leading zeros
+	prefix := strings.Repeat("0", int(difficulty))
+
+	// Check if hash starts with required number of zeros
+	return strings.HasPrefix(hash, prefix)
+}
@@ -12,4 +12,39 @@ var (
 	ErrSignatureRecoveryFailed = errors.New("failed to recover public key from signature")
 	ErrInvalidPublicKeyFormat  = errors.New("invalid public key format")
 	ErrSignatureSenderMismatch = errors.New("signature does not match sender address")
+	ErrUnknownConsensus        = errors.New("unknown consensus engine")
+	ErrMissingSignerKey        = errors.New("consensus engine requires a signer key")
+	ErrInvalidParent           = errors.New("block does not extend parent")
+	ErrInvalidBlockHash        = errors.New("invalid block hash")
+	ErrInvalidDifficulty       = errors.New("invalid block difficulty")
+	ErrInsufficientWork        = errors.New("block hash does not satisfy difficulty")
+	ErrBlockTooEarly           = errors.New("block timestamp is before scheduled slot")
+	ErrNotInTurn               = errors.New("signer is not in turn for block height")
+	ErrUnauthorizedSigner      = errors.New("block signed by unauthorized signer")
+	ErrTxNotInBlock            = errors.New("transaction not found in block")
+	ErrInvalidMultisig         = errors.New("invalid multisig account definition")
+	ErrMultisigExists          = errors.New("multisig account already exists")
+	ErrUnknownMultisig         = errors.New("sender is not a multisig account")
+	ErrMultisigThreshold       = errors.New("not enough owner signatures")
+	ErrNotMultisigOwner        = errors.New("signer is not an owner of the multisig account")
+	ErrInvalidToken            = errors.New("invalid token transaction")
+	ErrTokenExists             = errors.New("token already exists")
+	ErrUnknownToken            = errors.New("unknown token")
+	ErrInsufficientTokens      = errors.New("insufficient token balance")
+	ErrInsufficientAllowance   = errors.New("insufficient token allowance")
+	ErrTxLocked                = errors.New("transaction is not executable before its lock time")
+	ErrInvalidHTLC             = errors.New("invalid hash time-locked contract")
+	ErrUnknownHTLC             = errors.New("unknown hash time-locked contract")
+	ErrHTLCClosed              = errors.New("hash time-locked contract was already claimed or refunded")
+	ErrHTLCExpired             = errors.New("hash time-locked contract timed out")
+	ErrHTLCNotExpired          = errors.New("hash time-locked contract has not timed out yet")
+	ErrInvalidPreimage         = errors.New("preimage does not match the hash lock")
+	ErrNotHTLCParty            = errors.New("sender is not allowed to settle the hash time-locked contract")
+	ErrInvalidContract         = errors.New("invalid contract transaction")
+	ErrUnknownContract         = errors.New("unknown contract")
+	ErrIntrinsicGas            = errors.New("fee does not cover the intrinsic gas")
+	ErrInvalidLogFilter        = errors.New("invalid log filter")
+	ErrInvalidRawTx            = errors.New("invalid raw ethereum transaction")
+	ErrInvalidChainID          = errors.New("ethereum transaction is not signed for this chain")
+	ErrInvalidNonce            = errors.New("invalid nonce")
 )
@@ -0,0 +1,61 @@
+// Package feed fans values out to subscribers, such as the blocks the state
+// service adds or the transactions the mempool accepts.
+package feed
+
+import "sync"
+
+// Buffer is how many values a subscriber may fall behind.
+const Buffer = 64
+
+// Feed fans values out to subscribers. Publishing never blocks, a
+// subscriber that falls behind is dropped by closing its channel.
+type Feed[T any] struct {
+	mu   sync.Mutex
+	subs map[*Subscription[T]]struct{}
+}
+
+type Subscription[T any] struct {
+	C    <-chan T
+	c    chan T
+	feed *Feed[T]
+}
+
+func (f *Feed[T]) Subscribe() *Subscription[T] {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.subs == nil {
+		f.subs = make(map[*Subscription[T]]struct{})
+	}
+
+	c := make(chan T, Buffer)
+	sub := &Subscription[T]{C: c, c: c, feed: f}
+	f.subs[sub] = struct{}{}
+
+	return sub
+}
+
+func (f *Feed[T]) Publish(v T) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for sub := range f.subs {
+		select {
+		case sub.c <- v:
+		default:
+			delete(f.subs, sub)
+			close(sub.c)
+		}
+	}
+}
+
+// Unsubscribe stops delivery, it is safe to call more than once.
+func (s *Subscription[T]) Unsubscribe() {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+
+	if _, ok := s.feed.subs[s]; ok {
+		delete(s.feed.subs, s)
+		close(s.c)
+	}
+}
@@ -0,0 +1,30 @@
+package feed
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeed(t *testing.T) {
+	var feed Feed[int]
+
+	fast, slow := feed.Subscribe(), feed.Subscribe()
+	defer fast.Unsubscribe()
+
+	for i := range Buffer + 1 {
+		feed.Publish(i)
+		assert.Equal(t, i, <-fast.C)
+	}
+
+	// the slow subscriber got the buffered values and was then dropped
+	for i := range Buffer {
+		v, ok := <-slow.C
+		assert.True(t, ok)
+		assert.Equal(t, i, v)
+	}
+	_, ok := <-slow.C
+	assert.False(t, ok)
+
+	slow.Unsubscribe()
+}
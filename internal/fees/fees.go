@@ -0,0 +1,83 @@
+// Package fees suggests transaction fees from what recent blocks included
+// and how deep the mempool is.
+package fees
+
+import (
+	"slices"
+)
+
+// MinFee is suggested when nothing competes for block space.
+const MinFee int64 = 1
+
+// Percentiles of the recently included fees each level starts from.
+const (
+	lowPercentile    = 25
+	mediumPercentile = 50
+	highPercentile   = 90
+)
+
+// Estimate is a fee per level, each at least the one below.
+type Estimate struct {
+	Low    int64
+	Medium int64
+	High   int64
+}
+
+// Suggest returns fees for inclusion within target blocks. recent are the
+// fees included in the latest blocks, pending the highest fees waiting in the
+// mempool and depth how many transactions wait in total, which may be more
+// than len(pending). Blocks take the highest fees first and at most capacity
+// transactions, 0 means no limit.
+//
+// Low and medium pay at least what it takes to get ahead of the pending
+// transactions that do not fit in target blocks, high what it takes to make
+// the next block.
+func Suggest(recent, pending []int64, depth, capacity, target uint64) Estimate {
+	target = max(target, 1)
+
+	sorted := slices.Clone(recent)
+	slices.Sort(sorted)
+
+	e := Estimate{
+		Low:    max(percentile(sorted, lowPercentile), outbid(pending, depth, capacity, target)),
+		Medium: max(percentile(sorted, mediumPercentile), outbid(pending, depth, capacity, target)),
+		High:   max(percentile(sorted, highPercentile), outbid(pending, depth, capacity, 1)),
+	}
+	e.Medium = max(e.Medium, e.Low)
+	e.High = max(e.High, e.Medium)
+
+	return e
+}
+
+// percentile returns the p-th percentile of the sorted fees, MinFee when
+// there are none.
+func percentile(sorted []int64, p int) int64 {
+	if len(sorted) == 0 {
+		return MinFee
+	}
+
+	i := (len(sorted) - 1) * p / 100
+	return max(sorted[i], MinFee)
+}
+
+// outbid returns the lowest fee that still makes one of the next blocks
+// transactions, given the pending fees ahead of it.
+func outbid(pending []int64, depth, capacity, blocks uint64) int64 {
+	slots := capacity * blocks
+	if capacity == 0 || depth < slots || len(pending) == 0 {
+		return MinFee
+	}
+
+	sorted := slices.Clone(pending)
+	slices.Sort(sorted)
+	slices.Reverse(sorted)
+
+	// the fee of the last one in is not known, getting ahead of the lowest
+	// known fee is enough as fewer than slots transactions pay more
+	if uint64(len(sorted)) < slots {
+		return sorted[len(sorted)-1] + 1
+	}
+
+	// ties keep their mempool order, so pay one more than the last one in
+	return sorted[slots-1] + 1
+}
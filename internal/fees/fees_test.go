@@ -0,0 +1,63 @@
+package fees
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestEmptyChain(t *testing.T) {
+	e := Suggest(nil, nil, 0, 500, 3)
+	assert.Equal(t, Estimate{Low: MinFee, Medium: MinFee, High: MinFee}, e)
+}
+
+func TestSuggestFromRecentBlocks(t *testing.T) {
+	recent := []int64{10, 1, 4, 2, 8, 3, 6, 5, 9, 7}
+
+	e := Suggest(recent, nil, 0, 500, 3)
+	assert.Equal(t, int64(3), e.Low)
+	assert.Equal(t, int64(5), e.Medium)
+	assert.Equal(t, int64(9), e.High)
+}
+
+func TestSuggestFullMempool(t *testing.T) {
+	// two blocks of two transactions are already waiting
+	pending := []int64{20, 5, 30, 10, 2}
+
+	e := Suggest(nil, pending, uint64(len(pending)), 2, 2)
+	assert.Equal(t, int64(6), e.Low)
+	assert.Equal(t, int64(6), e.Medium)
+	assert.Equal(t, int64(21), e.High)
+
+	// more blocks to wait fit everything
+	e = Suggest(nil, pending, uint64(len(pending)), 2, 3)
+	assert.Equal(t, MinFee, e.Low)
+	assert.Equal(t, int64(21), e.High)
+}
+
+func TestSuggestTruncatedMempool(t *testing.T) {
+	// only the three highest of ten pending fees are known
+	pending := []int64{30, 20, 10}
+	e := Suggest(nil, pending, 10, 2, 2)
+	assert.Equal(t, int64(11), e.Low)
+	assert.Equal(t, int64(21), e.High)
+
+	// the unknown ones fit into the target blocks
+	e = Suggest(nil, pending, 10, 2, 6)
+	assert.Equal(t, MinFee, e.Low)
+}
+
+func TestSuggestUnlimitedBlocks(t *testing.T) {
+	e := Suggest([]int64{4}, []int64{100, 200}, 2, 0, 1)
+	assert.Equal(t, Estimate{Low: 4, Medium: 4, High: 4}, e)
+}
+
+func TestSuggestLevelsOrdered(t *testing.T) {
+	// a busy mempool can push the floor above the recent high
+	e := Suggest([]int64{1, 2, 3}, []int64{50, 40}, 2, 1, 2)
+	assert.Equal(t, int64(41), e.Low)
+	assert.Equal(t, int64(41), e.Medium)
+	assert.Equal(t, int64(51), e.High)
+	assert.LessOrEqual(t, e.Low, e.Medium)
+	assert.LessOrEqual(t, e.Medium, e.High)
+}
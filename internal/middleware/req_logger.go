@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"bufio"
+	"net"
 	"net/http"
 	"time"
 
@@ -17,6 +19,16 @@ func (w *wrappedResponseWriter) WriteHeader(statusCode int) {
 	w.statusCode = statusCode
 }
 
+// Hijack hands the connection to websocket handlers.
+func (w *wrappedResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.statusCode = http.StatusSwitchingProtocols
+	}
+
+	return conn, rw, err
+}
+
 func LogReq(next http.Handler) http.Handler {
 	log := logger.WithJSONFormat()
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
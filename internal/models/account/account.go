@@ -3,11 +3,20 @@ package account
 import "com.perkunas/proto"
 
 type Account struct {
-	ID        int64  `json:"id" db:"id"`
-	Address   string `json:"address" db:"address"`
-	Balance   int64  `json:"balance" db:"balance"`
-	Nonce     uint64 `json:"nonce" db:"nonce"`
-	Timestamp int64  `json:"timestamp" db:"timestamp"`
+	ID      int64  `json:"id" db:"id"`
+	Address string `json:"address" db:"address"`
+	Balance int64  `json:"balance" db:"balance"`
+	Nonce   uint64 `json:"nonce" db:"nonce"`
+	// StorageRoot commits to the items kept at the address, see StorageWithTX
+	StorageRoot string `json:"storage_root" db:"storage_root"`
+	Timestamp   int64  `json:"timestamp" db:"timestamp"`
+}
+
+// StorageItem is one entry of the state kept at an address besides its
+// balance and nonce.
+type StorageItem struct {
+	Key   string `db:"key"`
+	Value string `db:"value"`
 }
 
 func (acc Account) ToProto() *proto.Account {
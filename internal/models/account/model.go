@@ -71,6 +71,170 @@ func (am *Model) BatchInsert(ctx context.Context, db *sqlx.Tx, accounts []Accoun
 	return nil
 }
 
+// All returns every account with its storage root.
+func (am *Model) All(ctx context.Context) ([]Account, error) {
+	query := `
+		SELECT id, address, balance, nonce, storage_root, timestamp
+		FROM accounts
+	`
+
+	var res []Account
+	if err := am.DB.ReadDB.SelectContext(ctx, &res, query); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ChangedAddressesWithTX returns the addresses whose account or storage
+// changed since ClearChangesWithTX.
+func (am *Model) ChangedAddressesWithTX(ctx context.Context, db *sqlx.Tx) ([]string, error) {
+	var res []string
+	if err := db.SelectContext(ctx, &res, `SELECT address FROM state_changes ORDER BY address`); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ChangedWithTX returns the accounts that changed since ClearChangesWithTX.
+func (am *Model) ChangedWithTX(ctx context.Context, db *sqlx.Tx) ([]Account, error) {
+	query := `
+		SELECT a.id, a.address, a.balance, a.nonce, a.storage_root, a.timestamp
+		FROM accounts a
+		JOIN state_changes c ON c.address = a.address
+	`
+
+	var res []Account
+	if err := db.SelectContext(ctx, &res, query); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (am *Model) ClearChangesWithTX(ctx context.Context, db *sqlx.Tx) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM state_changes`)
+	return err
+}
+
+// StorageWithTX returns the items kept at addr besides its balance and nonce:
+//
+//	multisig                           [threshold, owners] of a multisig account
+//	token                              [symbol, name, decimals, supply, creator] of a token
+//	token_balance/<token>              balance of token held by addr
+//	token_allowance/<token>/<spender>  amount spender may move of addr's token
+//	htlc                               [sender, recipient, amount, hash_lock, timeout, status, preimage]
+//	code                               code of a contract
+//	storage/<slot>                     value of a contract's storage slot
+func (am *Model) StorageWithTX(ctx context.Context, db *sqlx.Tx, addr string) ([]StorageItem, error) {
+	return storage(ctx, db, addr)
+}
+
+// Storage returns the items kept at addr, see StorageWithTX.
+func (am *Model) Storage(ctx context.Context, addr string) ([]StorageItem, error) {
+	return storage(ctx, am.DB.ReadDB, addr)
+}
+
+func storage(ctx context.Context, db sqlx.QueryerContext, addr string) ([]StorageItem, error) {
+	query := `
+		SELECT 'multisig' AS key, json_array(threshold, json(owners)) AS value
+		FROM multisig_accounts WHERE address = :addr
+		UNION ALL
+		SELECT 'token', json_array(symbol, name, decimals, supply, creator)
+		FROM tokens WHERE address = :addr
+		UNION ALL
+		SELECT 'token_balance/' || token, CAST(balance AS TEXT)
+		FROM token_balances WHERE address = :addr
+		UNION ALL
+		SELECT 'token_allowance/' || token || '/' || spender, CAST(amount AS TEXT)
+		FROM token_allowances WHERE owner = :addr
+		UNION ALL
+		SELECT 'htlc', json_array(sender, recipient, amount, hash_lock, timeout, status, preimage)
+		FROM htlcs WHERE address = :addr
+		UNION ALL
+		SELECT 'code', code
+		FROM contracts WHERE address = :addr
+		UNION ALL
+		SELECT 'storage/' || key, value
+		FROM contract_storage WHERE contract = :addr
+	`
+
+	query, args, err := sqlx.Named(query, map[string]any{"addr": addr})
+	if err != nil {
+		return nil, err
+	}
+
+	var res []StorageItem
+	if err := sqlx.SelectContext(ctx, db, &res, query, args...); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// SetStorageRootWithTX records the storage root of addr, creating the account
+// when it only keeps storage.
+func (am *Model) SetStorageRootWithTX(ctx context.Context, db *sqlx.Tx, addr, root string) error {
+	query := `
+		INSERT INTO accounts (address, storage_root)
+		VALUES (?, ?)
+		ON CONFLICT (address) DO UPDATE SET storage_root = excluded.storage_root
+	`
+	if root == "" {
+		query = `UPDATE accounts SET storage_root = ? WHERE address = ?`
+		_, err := db.ExecContext(ctx, query, root, addr)
+		return err
+	}
+
+	_, err := db.ExecContext(ctx, query, addr, root)
+	return err
+}
+
+// SnapshotWithTX records the state of every account that changed since its
+// last snapshot as of block height, so state can be rebuilt for past blocks.
+func (am *Model) SnapshotWithTX(ctx context.Context, db *sqlx.Tx, height uint64) error {
+	query := `
+		INSERT INTO account_history (address, balance, nonce, storage_root, block_height)
+		SELECT a.address, a.balance, a.nonce, a.storage_root, ?
+		FROM accounts a
+		WHERE NOT EXISTS (
+			SELECT 1 FROM account_history h
+			WHERE h.address = a.address
+				AND h.balance = a.balance
+				AND h.nonce = a.nonce
+				AND h.storage_root = a.storage_root
+				AND h.block_height = (
+					SELECT MAX(block_height) FROM account_history WHERE address = a.address
+				)
+		)
+		ON CONFLICT (address, block_height) DO UPDATE
+		SET balance = excluded.balance, nonce = excluded.nonce, storage_root = excluded.storage_root
+	`
+
+	_, err := db.ExecContext(ctx, query, height)
+	return err
+}
+
+// AllAtHeight returns the state of every account as of block height.
+func (am *Model) AllAtHeight(ctx context.Context, height uint64) ([]Account, error) {
+	query := `
+		SELECT h.address, h.balance, h.nonce, h.storage_root
+		FROM account_history h
+		WHERE h.block_height = (
+			SELECT MAX(block_height) FROM account_history
+			WHERE address = h.address AND block_height <= ?
+		)
+	`
+
+	var res []Account
+	if err := am.DB.ReadDB.SelectContext(ctx, &res, query, height); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
 func (am *Model) Get(ctx context.Context, addr string) (Account, error) {
 	query := `
 		SELECT id, address, balance, nonce, timestamp
@@ -4,9 +4,14 @@ import (
 	"crypto/sha256"
 	"encoding/binary"
 	"encoding/hex"
+	"encoding/json"
+	"math"
+	"strings"
 	"time"
 
+	"com.perkunas/internal/errmsg"
 	"com.perkunas/internal/models/transaction"
+	"com.perkunas/pkg/proof"
 	"com.perkunas/proto"
 )
 
@@ -14,10 +19,13 @@ type Block struct {
 	Hash         string                     `json:"hash" db:"hash"`
 	PrevHash     string                     `json:"prev_hash" db:"prev_hash"`
 	MerkleRoot   string                     `json:"merkle_root" db:"merkle_root"`
+	StateRoot    string                     `json:"state_root" db:"state_root"`
 	Timestamp    int64                      `json:"timestamp" db:"timestamp"`
 	Height       uint64                     `json:"height" db:"height"`
 	Nonce        uint64                     `json:"nonce" db:"nonce"`
 	Difficulty   uint64                     `json:"difficulty" db:"difficulty"`
+	Miner        string                     `json:"miner" db:"miner"`
+	Signature    string                     `json:"signature" db:"signature"`
 	Transactions []*transaction.Transaction `json:"transactions" db:"-"`
 }
 
@@ -42,16 +50,45 @@ func (b *Block) CalculateHash() (string, error) {
 	binary.Write(hasher, binary.LittleEndian, b.Height)
 	binary.Write(hasher, binary.LittleEndian, b.Nonce)
 	hasher.Write([]byte(b.MerkleRoot))
+	hasher.Write([]byte(b.Miner))
+	hasher.Write([]byte(b.StateRoot))
 
 	return hex.EncodeToString(hasher.Sum(nil)), nil
 }
 
+// Size is the number of bytes b takes serialized, which the chain's
+// max_block_size limits.
+func (b *Block) Size() uint64 {
+	bs, err := json.Marshal(b)
+	if err != nil {
+		return 0
+	}
+
+	return uint64(len(bs))
+}
+
+// MaxHeaderSize is the most bytes a block without transactions takes
+// serialized, so transactions can be picked before the header is sealed.
+func MaxHeaderSize() uint64 {
+	hash := strings.Repeat("f", 64)
+	b := Block{
+		Hash:       hash,
+		PrevHash:   hash,
+		MerkleRoot: hash,
+		StateRoot:  hash,
+		Timestamp:  math.MinInt64,
+		Height:     math.MaxUint64,
+		Nonce:      math.MaxUint64,
+		Difficulty: math.MaxUint64,
+		Miner:      "0x" + strings.Repeat("f", 40),
+		Signature:  strings.Repeat("f", 130),
+	}
+
+	return b.Size()
+}
+
 func hashPair(left, right string) string {
-	hasher := sha256.New()
-	hasher.Write([]byte(left))
-	hasher.Write([]byte(right))
-	hash := hasher.Sum(nil)
-	return hex.EncodeToString(hash)
+	return proof.HashPair(left, right)
 }
 
 func (b *Block) AddTransaction(transaction *transaction.Transaction) error {
@@ -61,10 +98,56 @@ func (b *Block) AddTransaction(transaction *transaction.Transaction) error {
 }
 
 func (b *Block) CalculateMerkleRoot() string {
-	if len(b.Transactions) == 0 {
+	levels := b.merkleLevels()
+	if len(levels) == 0 {
 		return ""
 	}
 
+	return levels[len(levels)-1][0]
+}
+
+// MerkleProof returns the sibling path from the transaction with txHash up to
+// the block's merkle root, verifiable with proof.VerifyTransaction.
+func (b *Block) MerkleProof(txHash string) (*proof.TxProof, error) {
+	levels := b.merkleLevels()
+
+	index := -1
+	for i, tx := range b.Transactions {
+		if tx.Hash == txHash {
+			index = i
+			break
+		}
+	}
+
+	if index < 0 {
+		return nil, errmsg.ErrTxNotInBlock
+	}
+
+	p := &proof.TxProof{
+		TxHash:     levels[0][index],
+		BlockHash:  b.Hash,
+		Height:     b.Height,
+		MerkleRoot: levels[len(levels)-1][0],
+		Index:      uint64(index),
+		Siblings:   make([]string, 0, len(levels)-1),
+	}
+
+	// every level but the root is padded to even length
+	for _, level := range levels[:len(levels)-1] {
+		p.Siblings = append(p.Siblings, level[index^1])
+		index /= 2
+	}
+
+	return p, nil
+}
+
+// merkleLevels returns every level of the transaction tree, leaves first and
+// the root last. Levels with an odd number of hashes get the last one duplicated.
+func (b *Block) merkleLevels() [][]string {
+	if len(b.Transactions) == 0 {
+		return nil
+	}
+
 	currentLevel := make([]string, 0)
 
 	// Convert transaction hashes to hex strings
@@ -78,6 +161,8 @@ func (b *Block) CalculateMerkleRoot() string {
 		currentLevel = append(currentLevel, currentLevel[len(currentLevel)-1])
 	}
 
+	levels := [][]string{currentLevel}
+
 	// Keep hashing pairs until we get to the root
 	for len(currentLevel) > 1 {
 		nextLevel := make([]string, 0)
@@ -93,9 +178,25 @@ func (b *Block) CalculateMerkleRoot() string {
 		if len(currentLevel)%2 == 1 && len(currentLevel) > 1 {
 			currentLevel = append(currentLevel, currentLevel[len(currentLevel)-1])
 		}
+
+		levels = append(levels, currentLevel)
+	}
+
+	return levels
+}
+
+// WithTransactions decodes the transactions stored alongside the block.
+func (b BlockDB) WithTransactions() (Block, error) {
+	res := b.Block
+	if b.TransactionsDB == "" {
+		return res, nil
+	}
+
+	if err := json.Unmarshal([]byte(b.TransactionsDB), &res.Transactions); err != nil {
+		return res, err
 	}
 
-	return currentLevel[0]
+	return res, nil
 }
 
 func FromProtoBlock(in *proto.Block) Block {
@@ -103,10 +204,13 @@ func FromProtoBlock(in *proto.Block) Block {
 		Hash:       in.GetHash(),
 		PrevHash:   in.GetPrevHash(),
 		MerkleRoot: in.GetMerkleRoot(),
+		StateRoot:  in.GetStateRoot(),
 		Timestamp:  in.GetTimestamp(),
 		Height:     in.GetHeight(),
 		Nonce:      in.GetNonce(),
-		// Difficulty: in.Difficulty(), ?
+		Difficulty: in.GetDifficulty(),
+		Miner:      in.GetMiner(),
+		Signature:  in.GetSignature(),
 	}
 }
 
@@ -115,8 +219,12 @@ func ToProtoBlock(in Block) *proto.Block {
 		Hash:       in.Hash,
 		PrevHash:   in.PrevHash,
 		MerkleRoot: in.MerkleRoot,
+		StateRoot:  in.StateRoot,
 		Height:     in.Height,
 		Nonce:      in.Nonce,
 		Timestamp:  in.Timestamp,
+		Difficulty: in.Difficulty,
+		Miner:      in.Miner,
+		Signature:  in.Signature,
 	}
 }
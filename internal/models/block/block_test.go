@@ -4,7 +4,9 @@ import (
 	"testing"
 	"time"
 
+	"com.perkunas/internal/errmsg"
 	"com.perkunas/internal/models/transaction"
+	"com.perkunas/pkg/proof"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -115,3 +117,41 @@ func TestCalculateMerkleRoot(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotEqual(t, root2, root3)
 }
+
+func TestMerkleProof(t *testing.T) {
+	for _, count := range []int{1, 2, 3, 5, 8} {
+		block := NewBlock()
+		for i := 0; i < count; i++ {
+			tx := &transaction.Transaction{
+				From:   "sender",
+				To:     "recipient",
+				Amount: int64(100 + i),
+				Nonce:  uint64(i),
+			}
+			tx.SetHash()
+			assert.NoError(t, block.AddTransaction(tx))
+		}
+
+		for _, tx := range block.Transactions {
+			p, err := block.MerkleProof(tx.Hash)
+			assert.NoError(t, err)
+			assert.Equal(t, block.MerkleRoot, p.MerkleRoot)
+			assert.NoError(t, proof.VerifyTransaction(block.MerkleRoot, p))
+		}
+	}
+
+	block := NewBlock()
+	_, err := block.MerkleProof("missing")
+	assert.ErrorIs(t, err, errmsg.ErrTxNotInBlock)
+}
+
+func TestSize(t *testing.T) {
+	block := NewBlock()
+	block.PrevHash = "previous_hash"
+	empty := block.Size()
+	assert.NotZero(t, empty)
+	assert.LessOrEqual(t, empty, MaxHeaderSize())
+
+	block.AddTransaction(&transaction.Transaction{From: "sender", To: "receiver", Amount: 100, Nonce: 1})
+	assert.Greater(t, block.Size(), empty)
+}
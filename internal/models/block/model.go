@@ -13,8 +13,8 @@ type Model struct {
 
 func (bm *Model) Save(ctx context.Context, b BlockDB) error {
 	query := `
-		INSERT INTO blocks (hash, prev_hash, merkle_root, timestamp, height, nonce, transactions)
-		VALUES (:hash, :prev_hash, :merkle_root, :timestamp, :height, :nonce, :transactions)
+		INSERT INTO blocks (hash, prev_hash, merkle_root, state_root, timestamp, height, nonce, difficulty, miner, signature, transactions)
+		VALUES (:hash, :prev_hash, :merkle_root, :state_root, :timestamp, :height, :nonce, :difficulty, :miner, :signature, :transactions)
 	`
 	_, err := bm.DB.WriteDB.NamedExecContext(ctx, query, b)
 	return err
@@ -22,22 +22,69 @@ func (bm *Model) Save(ctx context.Context, b BlockDB) error {
 
 func (bm *Model) SaveWithTX(ctx context.Context, db *sqlx.Tx, b BlockDB) error {
 	query := `
-		INSERT INTO blocks (hash, prev_hash, merkle_root, timestamp, height, nonce, transactions)
-		VALUES (:hash, :prev_hash, :merkle_root, :timestamp, :height, :nonce, :transactions)
+		INSERT INTO blocks (hash, prev_hash, merkle_root, state_root, timestamp, height, nonce, difficulty, miner, signature, transactions)
+		VALUES (:hash, :prev_hash, :merkle_root, :state_root, :timestamp, :height, :nonce, :difficulty, :miner, :signature, :transactions)
 	`
 	_, err := db.NamedExecContext(ctx, query, b)
 	return err
 }
 
+func (bm *Model) GetByHash(ctx context.Context, hash string) (BlockDB, error) {
+	query := `
+		SELECT
+			hash,
+			prev_hash,
+			merkle_root,
+			state_root,
+			height,
+			nonce,
+			difficulty,
+			miner,
+			signature,
+			timestamp,
+			transactions
+		FROM blocks
+		WHERE hash = ?
+	`
+
+	var res BlockDB
+	return res, bm.DB.ReadDB.GetContext(ctx, &res, query, hash)
+}
+
+func (bm *Model) GetByHeight(ctx context.Context, height uint64) (BlockDB, error) {
+	query := `
+		SELECT
+			hash,
+			prev_hash,
+			merkle_root,
+			state_root,
+			height,
+			nonce,
+			difficulty,
+			miner,
+			signature,
+			timestamp,
+			transactions
+		FROM blocks
+		WHERE height = ?
+	`
+
+	var res BlockDB
+	return res, bm.DB.ReadDB.GetContext(ctx, &res, query, height)
+}
+
 func (bm *Model) GetLatest(ctx context.Context) (Block, error) {
 	query := `
 		SELECT
 			hash,
 			prev_hash,
 			merkle_root,
+			state_root,
 			height,
 			nonce,
 			difficulty,
+			miner,
+			signature,
 			timestamp
 		FROM blocks
 		ORDER BY height DESC LIMIT 1
@@ -46,3 +93,49 @@ func (bm *Model) GetLatest(ctx context.Context) (Block, error) {
 	var res Block
 	return res, bm.DB.ReadDB.Get(&res, query)
 }
+
+// AddressTx is a mined transaction sent from or to an address with its
+// receipt, Transaction holds the transaction JSON.
+type AddressTx struct {
+	Height      uint64 `db:"height"`
+	BlockHash   string `db:"block_hash"`
+	Transaction string `db:"tx"`
+	Status      string `db:"status"`
+	GasUsed     int64  `db:"gas_used"`
+	Logs        string `db:"logs"`
+	Error       string `db:"error"`
+}
+
+// TransactionsOf returns the transactions sent from or to address, newest
+// first, and how many there are in total.
+func (bm *Model) TransactionsOf(ctx context.Context, address string, limit, offset uint32) ([]AddressTx, uint64, error) {
+	query := `
+		SELECT
+			b.height,
+			b.hash AS block_hash,
+			t.value AS tx,
+			COALESCE(r.status, '') AS status,
+			COALESCE(r.gas_used, 0) AS gas_used,
+			COALESCE(r.logs, '[]') AS logs,
+			COALESCE(r.error, '') AS error
+		FROM blocks b, json_each(b.transactions) t
+		LEFT JOIN receipts r ON r.tx_hash = json_extract(t.value, '$.hash')
+		WHERE json_extract(t.value, '$.from_addr') = ? OR json_extract(t.value, '$.to_addr') = ?
+		ORDER BY b.height DESC, t.key ASC
+		LIMIT ? OFFSET ?
+	`
+
+	res := make([]AddressTx, 0)
+	if err := bm.DB.ReadDB.SelectContext(ctx, &res, query, address, address, limit, offset); err != nil {
+		return nil, 0, err
+	}
+
+	countQuery := `
+		SELECT COUNT(*)
+		FROM blocks b, json_each(b.transactions) t
+		WHERE json_extract(t.value, '$.from_addr') = ? OR json_extract(t.value, '$.to_addr') = ?
+	`
+
+	var total uint64
+	return res, total, bm.DB.ReadDB.GetContext(ctx, &total, countQuery, address, address)
+}
@@ -1,9 +1,49 @@
 package chainconfig
 
+import "com.perkunas/proto"
+
+const (
+	ConsensusPoW = "pow"
+	ConsensusPoA = "poa"
+)
+
 type ChainConfig struct {
-	InitialDifficulty uint64
-	BlockTime         uint64
-	DifficultyAdjust  uint64
-	MaxTxPerBlock     uint64
-	BlockReward       uint64
+	// ChainID identifies the chain in signed Ethereum transactions
+	ChainID           uint64   `json:"chain_id"`
+	InitialDifficulty uint64   `json:"initial_difficulty"`
+	BlockTime         uint64   `json:"block_time"`
+	DifficultyAdjust  uint64   `json:"difficulty_adjust"`
+	MaxTxPerBlock     uint64   `json:"max_tx_per_block"`
+	MaxBlockSize      uint64   `json:"max_block_size"`
+	BlockReward       uint64   `json:"block_reward"`
+	Consensus         string   `json:"consensus"`
+	Signers           []string `json:"signers"`
+}
+
+func (cc ChainConfig) ToProto() *proto.ChainConfig {
+	return &proto.ChainConfig{
+		ChainId:           cc.ChainID,
+		InitialDifficulty: cc.InitialDifficulty,
+		BlockTime:         cc.BlockTime,
+		DifficultyAdjust:  cc.DifficultyAdjust,
+		MaxTxPerBlock:     cc.MaxTxPerBlock,
+		MaxBlockSize:      cc.MaxBlockSize,
+		BlockReward:       cc.BlockReward,
+		Consensus:         cc.Consensus,
+		Signers:           cc.Signers,
+	}
+}
+
+func FromProto(in *proto.ChainConfig) ChainConfig {
+	return ChainConfig{
+		ChainID:           in.GetChainId(),
+		InitialDifficulty: in.GetInitialDifficulty(),
+		BlockTime:         in.GetBlockTime(),
+		DifficultyAdjust:  in.GetDifficultyAdjust(),
+		MaxTxPerBlock:     in.GetMaxTxPerBlock(),
+		MaxBlockSize:      in.GetMaxBlockSize(),
+		BlockReward:       in.GetBlockReward(),
+		Consensus:         in.GetConsensus(),
+		Signers:           in.GetSigners(),
+	}
 }
@@ -0,0 +1,180 @@
+package contract
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"com.perkunas/internal/errmsg"
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/internal/vm"
+	"com.perkunas/proto"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Contract is code deployed on-chain. Its address is derived from the
+// creator and the nonce of the deploy transaction. The code runs on every
+// call, with the calldata and value of the call transaction.
+type Contract struct {
+	Address string `json:"address" db:"address"`
+	Creator string `json:"creator" db:"creator"`
+	// Code is hex encoded with a 0x prefix
+	Code string `json:"code" db:"code"`
+}
+
+type ContractDB struct {
+	Contract
+	TxHash      string `db:"tx_hash"`
+	BlockHeight uint64 `db:"block_height"`
+}
+
+// IsContractTransaction reports whether tx deploys or calls a contract.
+func IsContractTransaction(tx *transaction.Transaction) bool {
+	return tx.Type == transaction.TypeContractDeploy || tx.Type == transaction.TypeContractCall
+}
+
+// DeriveAddress returns the address of the contract deployed by creator with
+// the transaction of the given nonce.
+func DeriveAddress(creator string, nonce uint64) string {
+	buf := []byte("contract")
+	buf = append(buf, common.HexToAddress(creator).Bytes()...)
+	buf = binary.BigEndian.AppendUint64(buf, nonce)
+
+	return common.BytesToAddress(crypto.Keccak256(buf)[12:]).Hex()
+}
+
+// DeployTransaction returns an unsigned transaction deploying code. The
+// value is credited to the contract, the fee is the gas limit.
+func DeployTransaction(creator string, code []byte, value, fee int64, nonce uint64) (*transaction.Transaction, error) {
+	tx := &transaction.Transaction{
+		Type:   transaction.TypeContractDeploy,
+		From:   creator,
+		To:     DeriveAddress(creator, nonce),
+		Data:   hexutil.Encode(code),
+		Amount: value,
+		Fee:    fee,
+		Nonce:  nonce,
+	}
+
+	if _, err := FromDeployTransaction(tx); err != nil {
+		return nil, err
+	}
+	tx.SetHash()
+
+	return tx, nil
+}
+
+// CallTransaction returns an unsigned transaction calling a contract with
+// input as calldata. The value is credited to the contract, the fee is the
+// gas limit.
+func CallTransaction(from, contract string, input []byte, value, fee int64, nonce uint64) *transaction.Transaction {
+	tx := &transaction.Transaction{
+		Type:   transaction.TypeContractCall,
+		From:   from,
+		To:     contract,
+		Amount: value,
+		Fee:    fee,
+		Nonce:  nonce,
+	}
+	if len(input) > 0 {
+		tx.Data = hexutil.Encode(input)
+	}
+	tx.SetHash()
+
+	return tx
+}
+
+// FromDeployTransaction reads the contract a deploy transaction creates.
+func FromDeployTransaction(tx *transaction.Transaction) (*Contract, error) {
+	if tx.Type != transaction.TypeContractDeploy {
+		return nil, fmt.Errorf("%w: not a deploy transaction", errmsg.ErrInvalidContract)
+	}
+
+	code, err := hexutil.Decode(tx.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: code must be 0x prefixed hex: %v", errmsg.ErrInvalidContract, err)
+	}
+
+	if len(code) > vm.MaxCodeSize {
+		return nil, fmt.Errorf("%w: code is %d bytes, at most %d are allowed", errmsg.ErrInvalidContract, len(code), vm.MaxCodeSize)
+	}
+
+	addr := DeriveAddress(tx.From, tx.Nonce)
+	if tx.To != addr {
+		return nil, fmt.Errorf("%w: recipient %s is not the derived address %s", errmsg.ErrInvalidContract, tx.To, addr)
+	}
+
+	return &Contract{Address: addr, Creator: tx.From, Code: tx.Data}, nil
+}
+
+// ParseInput reads the calldata of a call transaction.
+func ParseInput(tx *transaction.Transaction) ([]byte, error) {
+	if tx.Type != transaction.TypeContractCall {
+		return nil, fmt.Errorf("%w: not a call transaction", errmsg.ErrInvalidContract)
+	}
+
+	if tx.Data == "" {
+		return nil, nil
+	}
+
+	input, err := hexutil.Decode(tx.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: calldata must be 0x prefixed hex: %v", errmsg.ErrInvalidContract, err)
+	}
+
+	return input, nil
+}
+
+// IntrinsicGas is what a contract transaction costs before any code runs,
+// it grows with the size of the code or calldata.
+func IntrinsicGas(tx *transaction.Transaction) int64 {
+	size := int64(max(len(tx.Data)-2, 0) / 2)
+	if tx.Type == transaction.TypeContractDeploy {
+		return vm.GasDeploy + vm.GasCodeByte*size
+	}
+
+	return vm.GasCall + vm.GasInputByte*size
+}
+
+// Verify checks a contract transaction without looking at the chain: the
+// data decodes and the fee covers the intrinsic gas.
+func Verify(tx *transaction.Transaction) error {
+	var err error
+	if tx.Type == transaction.TypeContractDeploy {
+		_, err = FromDeployTransaction(tx)
+	} else {
+		_, err = ParseInput(tx)
+	}
+	if err != nil {
+		return err
+	}
+
+	if gas := IntrinsicGas(tx); tx.Fee < gas {
+		return fmt.Errorf("%w: needs %d, fee is %d", errmsg.ErrIntrinsicGas, gas, tx.Fee)
+	}
+
+	return nil
+}
+
+// CodeBytes returns the decoded code.
+func (c *Contract) CodeBytes() []byte {
+	code, _ := hexutil.Decode(c.Code)
+	return code
+}
+
+func (c *Contract) ToProto() *proto.Contract {
+	return &proto.Contract{
+		Address: c.Address,
+		Creator: c.Creator,
+		Code:    c.Code,
+	}
+}
+
+func FromProto(in *proto.Contract) *Contract {
+	return &Contract{
+		Address: in.GetAddress(),
+		Creator: in.GetCreator(),
+		Code:    in.GetCode(),
+	}
+}
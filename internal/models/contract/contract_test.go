@@ -0,0 +1,56 @@
+package contract
+
+import (
+	"testing"
+
+	"com.perkunas/internal/errmsg"
+	"com.perkunas/internal/vm"
+	"github.com/stretchr/testify/assert"
+)
+
+const creator = "0xE07cD67682C4b43bEF6b399bb7C180D975571aaa"
+
+func TestDeployTransaction(t *testing.T) {
+	code := []byte{byte(vm.PUSH1), 1, byte(vm.RETURN)}
+
+	tx, err := DeployTransaction(creator, code, 0, 100, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, DeriveAddress(creator, 3), tx.To)
+	assert.Equal(t, vm.GasDeploy+3*vm.GasCodeByte, IntrinsicGas(tx))
+	assert.NoError(t, Verify(tx))
+
+	c, err := FromDeployTransaction(tx)
+	assert.NoError(t, err)
+	assert.Equal(t, code, c.CodeBytes())
+
+	// the address is bound to the deploy nonce
+	tx.Nonce = 4
+	_, err = FromDeployTransaction(tx)
+	assert.ErrorIs(t, err, errmsg.ErrInvalidContract)
+
+	_, err = DeployTransaction(creator, make([]byte, vm.MaxCodeSize+1), 0, 1e6, 3)
+	assert.ErrorIs(t, err, errmsg.ErrInvalidContract)
+
+	tx, err = DeployTransaction(creator, code, 0, 10, 3)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, Verify(tx), errmsg.ErrIntrinsicGas)
+}
+
+func TestCallTransaction(t *testing.T) {
+	addr := DeriveAddress(creator, 0)
+
+	tx := CallTransaction(creator, addr, []byte{1, 2}, 5, 30, 1)
+	input, err := ParseInput(tx)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1, 2}, input)
+	assert.Equal(t, vm.GasCall+2*vm.GasInputByte, IntrinsicGas(tx))
+	assert.NoError(t, Verify(tx))
+
+	tx = CallTransaction(creator, addr, nil, 0, 30, 1)
+	input, err = ParseInput(tx)
+	assert.NoError(t, err)
+	assert.Empty(t, input)
+
+	tx.Data = "zz"
+	assert.ErrorIs(t, Verify(tx), errmsg.ErrInvalidContract)
+}
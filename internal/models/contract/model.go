@@ -0,0 +1,87 @@
+package contract
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"com.perkunas/internal/db"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jmoiron/sqlx"
+)
+
+type Model struct {
+	DB *db.DB
+}
+
+func (cm *Model) Get(ctx context.Context, address string) (*Contract, error) {
+	return get(ctx, cm.DB.ReadDB, address)
+}
+
+func (cm *Model) GetWithTX(ctx context.Context, db *sqlx.Tx, address string) (*Contract, error) {
+	return get(ctx, db, address)
+}
+
+func (cm *Model) InsertWithTX(ctx context.Context, db *sqlx.Tx, c *Contract, txHash string, height uint64) error {
+	query := `
+		INSERT INTO contracts (address, creator, code, tx_hash, block_height)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	_, err := db.ExecContext(ctx, query, c.Address, c.Creator, c.Code, txHash, height)
+	return err
+}
+
+// Storage returns a storage slot of a contract, zero when it was never set.
+func (cm *Model) Storage(ctx context.Context, contract string, key common.Hash) (common.Hash, error) {
+	return storage(ctx, cm.DB.ReadDB, contract, key)
+}
+
+func (cm *Model) StorageWithTX(ctx context.Context, db *sqlx.Tx, contract string, key common.Hash) (common.Hash, error) {
+	return storage(ctx, db, contract, key)
+}
+
+// SetStorageWithTX writes a storage slot, zero values are not stored.
+func (cm *Model) SetStorageWithTX(ctx context.Context, db *sqlx.Tx, contract string, key, value common.Hash) error {
+	if value == (common.Hash{}) {
+		_, err := db.ExecContext(ctx, `DELETE FROM contract_storage WHERE contract = ? AND key = ?`, contract, key.Hex())
+		return err
+	}
+
+	query := `
+		INSERT INTO contract_storage (contract, key, value)
+		VALUES (?, ?, ?)
+		ON CONFLICT (contract, key) DO UPDATE SET value = excluded.value
+	`
+
+	_, err := db.ExecContext(ctx, query, contract, key.Hex(), value.Hex())
+	return err
+}
+
+func get(ctx context.Context, q sqlx.QueryerContext, address string) (*Contract, error) {
+	query := `
+		SELECT address, creator, code, tx_hash, block_height
+		FROM contracts
+		WHERE address = ?
+	`
+
+	var res ContractDB
+	if err := sqlx.GetContext(ctx, q, &res, query, address); err != nil {
+		return nil, err
+	}
+
+	return &res.Contract, nil
+}
+
+func storage(ctx context.Context, q sqlx.QueryerContext, contract string, key common.Hash) (common.Hash, error) {
+	var value string
+	err := sqlx.GetContext(ctx, q, &value, `SELECT value FROM contract_storage WHERE contract = ? AND key = ?`, contract, key.Hex())
+	if errors.Is(err, sql.ErrNoRows) {
+		return common.Hash{}, nil
+	}
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return common.HexToHash(value), nil
+}
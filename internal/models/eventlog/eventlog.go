@@ -0,0 +1,213 @@
+// Package eventlog indexes the logs contracts emit, by block, address and
+// topics.
+package eventlog
+
+import (
+	"fmt"
+	"slices"
+
+	"com.perkunas/internal/bloom"
+	"com.perkunas/internal/errmsg"
+	"com.perkunas/internal/vm"
+	"com.perkunas/pkg/address"
+	"com.perkunas/proto"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	// MaxTopics is the most topics a log has, LOG4 emits four.
+	MaxTopics = 4
+	// MaxBlockRange is the most blocks a query may span.
+	MaxBlockRange = 10000
+	// MaxLogs is the most logs a query returns.
+	MaxLogs = 1000
+)
+
+// Log is a contract log and where it was emitted. Index is the position of
+// the log in its block.
+type Log struct {
+	Address     string   `json:"address"`
+	Topics      []string `json:"topics"`
+	Data        string   `json:"data"`
+	BlockHeight uint64   `json:"block_height"`
+	BlockHash   string   `json:"block_hash"`
+	TxHash      string   `json:"tx_hash"`
+	Index       uint32   `json:"index"`
+}
+
+// BlockLogs are the logs of one block and their bloom.
+type BlockLogs struct {
+	Height uint64
+	Bloom  bloom.Bloom
+	Logs   []Log
+}
+
+// NewBlockLogs numbers the logs of a block in order and computes the bloom.
+func NewBlockLogs(height uint64, hash string, logs []Log) BlockLogs {
+	b := BlockLogs{Height: height, Logs: logs}
+	for i := range b.Logs {
+		l := &b.Logs[i]
+		l.BlockHeight, l.BlockHash, l.Index = height, hash, uint32(i)
+
+		b.Bloom.Add(common.HexToAddress(l.Address).Bytes())
+		for _, t := range l.Topics {
+			b.Bloom.Add(common.HexToHash(t).Bytes())
+		}
+	}
+
+	return b
+}
+
+// FromVM returns the logs a transaction emitted, not yet numbered.
+func FromVM(txHash string, in []vm.Log) []Log {
+	res := make([]Log, 0, len(in))
+	for _, l := range in {
+		res = append(res, Log{Address: l.Address, Topics: l.Topics, Data: l.Data, TxHash: txHash})
+	}
+
+	return res
+}
+
+// Filter selects logs. Topics[i] lists the accepted values of the i-th
+// topic, an empty list accepts any; a log with fewer topics than the filter
+// constrains does not match. ToHeight 0 is the latest block.
+type Filter struct {
+	FromHeight uint64
+	ToHeight   uint64
+	Addresses  []string
+	Topics     [][]string
+	Limit      int
+	// TxHash keeps only the logs of one transaction when set
+	TxHash string
+}
+
+// Normalize validates the filter and puts addresses and topics into the
+// form logs are stored in.
+func (f *Filter) Normalize() error {
+	if f.ToHeight != 0 && f.ToHeight < f.FromHeight {
+		return fmt.Errorf("%w: to height is below from height", errmsg.ErrInvalidLogFilter)
+	}
+
+	if len(f.Topics) > MaxTopics {
+		return fmt.Errorf("%w: logs have at most %d topics", errmsg.ErrInvalidLogFilter, MaxTopics)
+	}
+
+	for i, a := range f.Addresses {
+		addr, err := address.Parse(a)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errmsg.ErrInvalidLogFilter, err)
+		}
+		f.Addresses[i] = addr
+	}
+
+	for _, values := range f.Topics {
+		for i, v := range values {
+			b, err := hexutil.Decode(v)
+			if err != nil || len(b) > common.HashLength {
+				return fmt.Errorf("%w: topic %q is not a hex word", errmsg.ErrInvalidLogFilter, v)
+			}
+			values[i] = common.BytesToHash(b).Hex()
+		}
+	}
+
+	return nil
+}
+
+// Matches reports whether l passes the filter, heights aside.
+func (f *Filter) Matches(l *Log) bool {
+	if len(f.Addresses) > 0 && !slices.Contains(f.Addresses, l.Address) {
+		return false
+	}
+
+	for i, values := range f.Topics {
+		if len(values) == 0 {
+			continue
+		}
+		if i >= len(l.Topics) || !slices.Contains(values, l.Topics[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MatchesBloom reports whether a block with bloom b may have a matching log.
+func (f *Filter) MatchesBloom(b *bloom.Bloom) bool {
+	if len(f.Addresses) > 0 && !slices.ContainsFunc(f.Addresses, func(a string) bool {
+		return b.Test(common.HexToAddress(a).Bytes())
+	}) {
+		return false
+	}
+
+	for _, values := range f.Topics {
+		if len(values) > 0 && !slices.ContainsFunc(values, func(t string) bool {
+			return b.Test(common.HexToHash(t).Bytes())
+		}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (l *Log) ToProto() *proto.IndexedLog {
+	return &proto.IndexedLog{
+		Log:         &proto.Log{Address: l.Address, Topics: l.Topics, Data: l.Data},
+		BlockHeight: l.BlockHeight,
+		BlockHash:   l.BlockHash,
+		TxHash:      l.TxHash,
+		Index:       l.Index,
+	}
+}
+
+func ToProto(in []Log) []*proto.IndexedLog {
+	res := make([]*proto.IndexedLog, 0, len(in))
+	for i := range in {
+		res = append(res, in[i].ToProto())
+	}
+
+	return res
+}
+
+func FromProto(in *proto.IndexedLog) Log {
+	return Log{
+		Address:     in.GetLog().GetAddress(),
+		Topics:      in.GetLog().GetTopics(),
+		Data:        in.GetLog().GetData(),
+		BlockHeight: in.GetBlockHeight(),
+		BlockHash:   in.GetBlockHash(),
+		TxHash:      in.GetTxHash(),
+		Index:       in.GetIndex(),
+	}
+}
+
+func FilterToProto(f *Filter) *proto.LogsReq {
+	req := &proto.LogsReq{
+		FromHeight: f.FromHeight,
+		ToHeight:   f.ToHeight,
+		Addresses:  f.Addresses,
+		Limit:      uint32(f.Limit),
+		TxHash:     f.TxHash,
+	}
+	for _, values := range f.Topics {
+		req.Topics = append(req.Topics, &proto.TopicSet{Values: values})
+	}
+
+	return req
+}
+
+func FilterFromProto(in *proto.LogsReq) *Filter {
+	f := &Filter{
+		FromHeight: in.GetFromHeight(),
+		ToHeight:   in.GetToHeight(),
+		Addresses:  in.GetAddresses(),
+		Limit:      int(in.GetLimit()),
+		TxHash:     in.GetTxHash(),
+	}
+	for _, t := range in.GetTopics() {
+		f.Topics = append(f.Topics, t.GetValues())
+	}
+
+	return f
+}
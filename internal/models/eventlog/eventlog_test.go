@@ -0,0 +1,86 @@
+package eventlog
+
+import (
+	"testing"
+
+	"com.perkunas/internal/errmsg"
+	"com.perkunas/internal/vm"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	counter = "0xE07cD67682C4b43bEF6b399bb7C180D975571aaa"
+	token   = "0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19b2"
+)
+
+var (
+	incremented = common.HexToHash("0xc0ffee").Hex()
+	transfer    = common.HexToHash("0x01").Hex()
+	alice       = common.HexToHash("0xa11ce").Hex()
+)
+
+func testBlock() BlockLogs {
+	logs := FromVM("tx1", []vm.Log{{Address: counter, Topics: []string{incremented}, Data: "0x01"}})
+	logs = append(logs, FromVM("tx2", []vm.Log{{Address: token, Topics: []string{transfer, alice}, Data: "0x05"}})...)
+
+	return NewBlockLogs(7, "hash", logs)
+}
+
+func TestNewBlockLogs(t *testing.T) {
+	b := testBlock()
+	assert.Equal(t, uint32(1), b.Logs[1].Index)
+	assert.Equal(t, uint64(7), b.Logs[1].BlockHeight)
+	assert.Equal(t, "tx2", b.Logs[1].TxHash)
+
+	assert.True(t, b.Bloom.Test(common.HexToAddress(counter).Bytes()))
+	assert.True(t, b.Bloom.Test(common.HexToHash(alice).Bytes()))
+}
+
+func TestFilter(t *testing.T) {
+	b := testBlock()
+
+	for name, tc := range map[string]struct {
+		filter Filter
+		want   []int
+	}{
+		"everything":    {Filter{}, []int{0, 1}},
+		"address":       {Filter{Addresses: []string{token}}, []int{1}},
+		"lower case":    {Filter{Addresses: []string{"0x76f86614a08683bdfd4a44df1ee24e94bf5c19b2"}}, []int{1}},
+		"first topic":   {Filter{Topics: [][]string{{"0xc0ffee", "0x02"}}}, []int{0}},
+		"any then one":  {Filter{Topics: [][]string{{}, {alice}}}, []int{1}},
+		"missing topic": {Filter{Topics: [][]string{{incremented}, {alice}}}, nil},
+		"both":          {Filter{Addresses: []string{counter}, Topics: [][]string{{transfer}}}, nil},
+	} {
+		f := tc.filter
+		assert.NoError(t, f.Normalize(), name)
+
+		var got []int
+		for i := range b.Logs {
+			if f.Matches(&b.Logs[i]) {
+				got = append(got, i)
+			}
+		}
+		assert.Equal(t, tc.want, got, name)
+
+		// the bloom never rules out a block with a match
+		if len(got) > 0 {
+			assert.True(t, f.MatchesBloom(&b.Bloom), name)
+		}
+	}
+
+	f := Filter{Topics: [][]string{{common.HexToHash("0xdead").Hex()}}}
+	assert.False(t, f.MatchesBloom(&b.Bloom))
+}
+
+func TestFilter_Normalize(t *testing.T) {
+	for _, f := range []Filter{
+		{FromHeight: 5, ToHeight: 4},
+		{Addresses: []string{"bob"}},
+		{Topics: [][]string{{"c0ffee"}}},
+		{Topics: [][]string{{"0x" + common.Bytes2Hex(make([]byte, 33))}}},
+		{Topics: make([][]string, MaxTopics+1)},
+	} {
+		assert.ErrorIs(t, f.Normalize(), errmsg.ErrInvalidLogFilter, f)
+	}
+}
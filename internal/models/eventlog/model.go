@@ -0,0 +1,175 @@
+package eventlog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"com.perkunas/internal/bloom"
+	"com.perkunas/internal/db"
+	"github.com/jmoiron/sqlx"
+)
+
+type Model struct {
+	DB *db.DB
+}
+
+// logDB is a log row, topics are stored one per column so they can be
+// indexed.
+type logDB struct {
+	BlockHeight uint64 `db:"block_height"`
+	BlockHash   string `db:"block_hash"`
+	Index       uint32 `db:"log_index"`
+	TxHash      string `db:"tx_hash"`
+	Address     string `db:"address"`
+	Topic0      string `db:"topic0"`
+	Topic1      string `db:"topic1"`
+	Topic2      string `db:"topic2"`
+	Topic3      string `db:"topic3"`
+	Data        string `db:"data"`
+}
+
+func toDB(l *Log) logDB {
+	var topics [MaxTopics]string
+	copy(topics[:], l.Topics)
+
+	return logDB{
+		BlockHeight: l.BlockHeight,
+		BlockHash:   l.BlockHash,
+		Index:       l.Index,
+		TxHash:      l.TxHash,
+		Address:     l.Address,
+		Topic0:      topics[0],
+		Topic1:      topics[1],
+		Topic2:      topics[2],
+		Topic3:      topics[3],
+		Data:        l.Data,
+	}
+}
+
+func (l *logDB) toLog() Log {
+	topics := make([]string, 0, MaxTopics)
+	for _, t := range []string{l.Topic0, l.Topic1, l.Topic2, l.Topic3} {
+		if t == "" {
+			break
+		}
+		topics = append(topics, t)
+	}
+
+	return Log{
+		Address:     l.Address,
+		Topics:      topics,
+		Data:        l.Data,
+		BlockHeight: l.BlockHeight,
+		BlockHash:   l.BlockHash,
+		TxHash:      l.TxHash,
+		Index:       l.Index,
+	}
+}
+
+// InsertWithTX indexes the logs of a block and records its bloom. Blocks
+// without logs have no bloom row.
+func (lm *Model) InsertWithTX(ctx context.Context, db *sqlx.Tx, b BlockLogs, hash string) error {
+	if len(b.Logs) == 0 {
+		return nil
+	}
+
+	rows := make([]logDB, 0, len(b.Logs))
+	for i := range b.Logs {
+		rows = append(rows, toDB(&b.Logs[i]))
+	}
+
+	query := `
+		INSERT INTO logs (block_height, block_hash, log_index, tx_hash, address, topic0, topic1, topic2, topic3, data)
+		VALUES (:block_height, :block_hash, :log_index, :tx_hash, :address, :topic0, :topic1, :topic2, :topic3, :data)
+	`
+	if _, err := db.NamedExecContext(ctx, query, rows); err != nil {
+		return err
+	}
+
+	_, err := db.ExecContext(ctx, `INSERT INTO log_blooms (block_height, block_hash, bloom) VALUES (?, ?, ?)`, b.Height, hash, b.Bloom.Hex())
+	return err
+}
+
+// Candidates returns the heights in [from, to] whose bloom matches f, the
+// only blocks that can hold a matching log.
+func (lm *Model) Candidates(ctx context.Context, f *Filter, from, to uint64) ([]uint64, error) {
+	var rows []struct {
+		Height uint64 `db:"block_height"`
+		Bloom  string `db:"bloom"`
+	}
+
+	query := `SELECT block_height, bloom FROM log_blooms WHERE block_height BETWEEN ? AND ? ORDER BY block_height`
+	if err := lm.DB.ReadDB.SelectContext(ctx, &rows, query, from, to); err != nil {
+		return nil, err
+	}
+
+	heights := make([]uint64, 0)
+	for _, r := range rows {
+		b, err := bloom.FromHex(r.Bloom)
+		if err != nil {
+			return nil, err
+		}
+		if f.MatchesBloom(&b) {
+			heights = append(heights, r.Height)
+		}
+	}
+
+	return heights, nil
+}
+
+// Find returns the logs of the given blocks that match f, in chain order.
+// A limit of 0 returns all of them.
+func (lm *Model) Find(ctx context.Context, f *Filter, heights []uint64, limit int) ([]Log, error) {
+	res := make([]Log, 0)
+	if len(heights) == 0 {
+		return res, nil
+	}
+
+	where := []string{"block_height IN (?)"}
+	args := []any{heights}
+
+	if len(f.Addresses) > 0 {
+		where = append(where, "address IN (?)")
+		args = append(args, f.Addresses)
+	}
+
+	for i, values := range f.Topics {
+		if len(values) > 0 {
+			where = append(where, fmt.Sprintf("topic%d IN (?)", i))
+			args = append(args, values)
+		}
+	}
+
+	if f.TxHash != "" {
+		where = append(where, "tx_hash = ?")
+		args = append(args, f.TxHash)
+	}
+
+	query := `
+		SELECT block_height, block_hash, log_index, tx_hash, address, topic0, topic1, topic2, topic3, data
+		FROM logs
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY block_height, log_index
+	`
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	query, args, err := sqlx.In(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []logDB
+	if err := lm.DB.ReadDB.SelectContext(ctx, &rows, lm.DB.ReadDB.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		res = append(res, rows[i].toLog())
+	}
+
+	return res, nil
+}
@@ -3,9 +3,11 @@ package genesisblock
 import (
 	"com.perkunas/internal/models/account"
 	"com.perkunas/internal/models/block"
+	"com.perkunas/internal/models/chainconfig"
 )
 
 type GenesisBlock struct {
 	block.BlockDB
-	Accounts []account.Account `json:"accounts"`
+	Accounts []account.Account       `json:"accounts"`
+	Config   chainconfig.ChainConfig `json:"config"`
 }
@@ -13,8 +13,8 @@ type Model struct {
 
 func (bm *Model) SaveWithTX(ctx context.Context, db *sqlx.Tx, b GenesisBlock) error {
 	query := `
-		INSERT INTO blocks (hash, prev_hash, merkle_root, timestamp, height, nonce, transactions)
-		VALUES (:hash, :prev_hash, :merkle_root, :timestamp, :height, :nonce, :transactions)
+		INSERT INTO blocks (hash, prev_hash, merkle_root, state_root, timestamp, height, nonce, difficulty, miner, signature, transactions)
+		VALUES (:hash, :prev_hash, :merkle_root, :state_root, :timestamp, :height, :nonce, :difficulty, :miner, :signature, :transactions)
 	`
 	_, err := db.NamedExecContext(ctx, query, b.BlockDB)
 	return err
@@ -0,0 +1,282 @@
+package htlc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"com.perkunas/internal/errmsg"
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/pkg/address"
+	"com.perkunas/proto"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// HTLC statuses
+const (
+	StatusOpen     = "OPEN"
+	StatusClaimed  = "CLAIMED"
+	StatusRefunded = "REFUNDED"
+)
+
+// PreimageSize is the size of secrets generated by NewSecret.
+const PreimageSize = 32
+
+// HTLC is a hash time-locked contract. The locked amount sits in an account
+// derived from the lock transaction until the recipient claims it with the
+// preimage of HashLock before Timeout, or the sender takes it back after.
+// The hash lock is SHA-256, so a swap can be paired with an HTLC on a chain
+// that uses the same construction.
+type HTLC struct {
+	Address   string `json:"address" db:"address"`
+	Sender    string `json:"sender" db:"sender"`
+	Recipient string `json:"recipient" db:"recipient"`
+	Amount    int64  `json:"amount" db:"amount"`
+	HashLock  string `json:"hash_lock" db:"hash_lock"`
+	Timeout   int64  `json:"timeout" db:"timeout"`
+	Status    string `json:"status" db:"status"`
+	// Preimage is revealed by the claim, the counterparty of a swap reads it
+	// here to claim on the other chain
+	Preimage string `json:"preimage,omitempty" db:"preimage"`
+}
+
+type HTLCDB struct {
+	HTLC
+	TxHash      string `db:"tx_hash"`
+	BlockHeight uint64 `db:"block_height"`
+}
+
+// Lock is the data of a lock transaction.
+type Lock struct {
+	Recipient string `json:"recipient"`
+	HashLock  string `json:"hash_lock"`
+	Timeout   int64  `json:"timeout"`
+}
+
+// Claim is the data of a claim transaction.
+type Claim struct {
+	Preimage string `json:"preimage"`
+}
+
+// NewSecret returns a random preimage and its hash lock, both hex encoded.
+func NewSecret() (preimage, hashLock string, err error) {
+	secret := make([]byte, PreimageSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	preimage = hex.EncodeToString(secret)
+	return preimage, HashPreimage(secret), nil
+}
+
+// HashPreimage returns the hex encoded hash lock of a preimage.
+func HashPreimage(preimage []byte) string {
+	sum := sha256.Sum256(preimage)
+	return hex.EncodeToString(sum[:])
+}
+
+// DeriveAddress returns the address holding the funds of the HTLC created by
+// sender with the transaction of the given nonce.
+func DeriveAddress(sender string, nonce uint64) string {
+	buf := []byte("htlc")
+	buf = append(buf, common.HexToAddress(sender).Bytes()...)
+	buf = binary.BigEndian.AppendUint64(buf, nonce)
+
+	return common.BytesToAddress(crypto.Keccak256(buf)[12:]).Hex()
+}
+
+// LockTransaction returns an unsigned transaction locking amount for the
+// recipient of lock.
+func LockTransaction(sender string, lock Lock, amount, fee int64, nonce uint64) (*transaction.Transaction, error) {
+	data, err := json.Marshal(lock)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &transaction.Transaction{
+		Type:   transaction.TypeHTLCLock,
+		From:   sender,
+		To:     DeriveAddress(sender, nonce),
+		Data:   string(data),
+		Amount: amount,
+		Fee:    fee,
+		Nonce:  nonce,
+	}
+
+	if _, err := FromLockTransaction(tx); err != nil {
+		return nil, err
+	}
+	tx.SetHash()
+
+	return tx, nil
+}
+
+// ClaimTransaction returns an unsigned transaction paying h out to its
+// recipient. It expires at the timeout, after which the claim is invalid.
+func (h *HTLC) ClaimTransaction(preimage string, fee int64, nonce uint64) (*transaction.Transaction, error) {
+	data, err := json.Marshal(Claim{Preimage: preimage})
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &transaction.Transaction{
+		Type:    transaction.TypeHTLCClaim,
+		From:    h.Recipient,
+		To:      h.Address,
+		Data:    string(data),
+		Fee:     fee,
+		Nonce:   nonce,
+		Expires: h.Timeout,
+	}
+	tx.SetHash()
+
+	return tx, nil
+}
+
+// RefundTransaction returns an unsigned transaction paying h back to its
+// sender. Its lock time is past the timeout, no block before it can include
+// the refund.
+func (h *HTLC) RefundTransaction(fee int64, nonce uint64) *transaction.Transaction {
+	tx := &transaction.Transaction{
+		Type:     transaction.TypeHTLCRefund,
+		From:     h.Sender,
+		To:       h.Address,
+		Fee:      fee,
+		Nonce:    nonce,
+		LockTime: h.Timeout + 1,
+	}
+	tx.SetHash()
+
+	return tx
+}
+
+// FromLockTransaction reads the HTLC a lock transaction creates.
+func FromLockTransaction(tx *transaction.Transaction) (*HTLC, error) {
+	if tx.Type != transaction.TypeHTLCLock {
+		return nil, fmt.Errorf("%w: not a lock transaction", errmsg.ErrInvalidHTLC)
+	}
+
+	var lock Lock
+	if err := json.Unmarshal([]byte(tx.Data), &lock); err != nil {
+		return nil, fmt.Errorf("%w: %v", errmsg.ErrInvalidHTLC, err)
+	}
+
+	if err := address.Validate(lock.Recipient); err != nil {
+		return nil, fmt.Errorf("%w: recipient: %w", errmsg.ErrInvalidHTLC, err)
+	}
+
+	if h, err := hex.DecodeString(lock.HashLock); err != nil || len(h) != sha256.Size || lock.HashLock != strings.ToLower(lock.HashLock) {
+		return nil, fmt.Errorf("%w: hash lock must be a lower case hex SHA-256 hash", errmsg.ErrInvalidHTLC)
+	}
+
+	if lock.Timeout <= 0 {
+		return nil, fmt.Errorf("%w: timeout must be a unix time", errmsg.ErrInvalidHTLC)
+	}
+
+	if tx.Amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", errmsg.ErrInvalidHTLC)
+	}
+
+	addr := DeriveAddress(tx.From, tx.Nonce)
+	if tx.To != addr {
+		return nil, fmt.Errorf("%w: recipient %s is not the derived address %s", errmsg.ErrInvalidHTLC, tx.To, addr)
+	}
+
+	return &HTLC{
+		Address:   addr,
+		Sender:    tx.From,
+		Recipient: lock.Recipient,
+		Amount:    tx.Amount,
+		HashLock:  lock.HashLock,
+		Timeout:   lock.Timeout,
+		Status:    StatusOpen,
+	}, nil
+}
+
+// VerifyClaim checks that tx claims h with the right preimage in a block
+// with timestamp at, and returns the preimage.
+func (h *HTLC) VerifyClaim(tx *transaction.Transaction, at int64) (string, error) {
+	if err := h.verifySettlement(tx, transaction.TypeHTLCClaim, h.Recipient); err != nil {
+		return "", err
+	}
+
+	if at > h.Timeout {
+		return "", errmsg.ErrHTLCExpired
+	}
+
+	var claim Claim
+	if err := json.Unmarshal([]byte(tx.Data), &claim); err != nil {
+		return "", fmt.Errorf("%w: %v", errmsg.ErrInvalidHTLC, err)
+	}
+
+	preimage, err := hex.DecodeString(claim.Preimage)
+	if err != nil || HashPreimage(preimage) != h.HashLock {
+		return "", errmsg.ErrInvalidPreimage
+	}
+
+	return claim.Preimage, nil
+}
+
+// VerifyRefund checks that tx refunds h in a block with timestamp at.
+func (h *HTLC) VerifyRefund(tx *transaction.Transaction, at int64) error {
+	if err := h.verifySettlement(tx, transaction.TypeHTLCRefund, h.Sender); err != nil {
+		return err
+	}
+
+	if at <= h.Timeout {
+		return errmsg.ErrHTLCNotExpired
+	}
+
+	return nil
+}
+
+func (h *HTLC) verifySettlement(tx *transaction.Transaction, typ, party string) error {
+	if tx.Type != typ || tx.To != h.Address {
+		return fmt.Errorf("%w: not a %s of %s", errmsg.ErrInvalidHTLC, typ, h.Address)
+	}
+
+	if tx.Amount != 0 {
+		return fmt.Errorf("%w: settlements can not move funds", errmsg.ErrInvalidHTLC)
+	}
+
+	if h.Status != StatusOpen {
+		return errmsg.ErrHTLCClosed
+	}
+
+	if tx.From != party {
+		return errmsg.ErrNotHTLCParty
+	}
+
+	return nil
+}
+
+func (h *HTLC) ToProto() *proto.HTLC {
+	return &proto.HTLC{
+		Address:   h.Address,
+		Sender:    h.Sender,
+		Recipient: h.Recipient,
+		Amount:    h.Amount,
+		HashLock:  h.HashLock,
+		Timeout:   h.Timeout,
+		Status:    h.Status,
+		Preimage:  h.Preimage,
+	}
+}
+
+func FromProto(in *proto.HTLC) *HTLC {
+	return &HTLC{
+		Address:   in.GetAddress(),
+		Sender:    in.GetSender(),
+		Recipient: in.GetRecipient(),
+		Amount:    in.GetAmount(),
+		HashLock:  in.GetHashLock(),
+		Timeout:   in.GetTimeout(),
+		Status:    in.GetStatus(),
+		Preimage:  in.GetPreimage(),
+	}
+}
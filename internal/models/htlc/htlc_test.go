@@ -0,0 +1,118 @@
+package htlc
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"com.perkunas/internal/errmsg"
+	"com.perkunas/internal/models/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	sender    = "0xE07cD67682C4b43bEF6b399bb7C180D975571aaa"
+	recipient = "0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19b2"
+)
+
+func testHTLC(t *testing.T) (*HTLC, string) {
+	secret, lock, err := NewSecret()
+	assert.NoError(t, err)
+
+	tx, err := LockTransaction(sender, Lock{Recipient: recipient, HashLock: lock, Timeout: 1000}, 50, 1, 3)
+	assert.NoError(t, err)
+
+	h, err := FromLockTransaction(tx)
+	assert.NoError(t, err)
+
+	return h, secret
+}
+
+func TestNewSecret(t *testing.T) {
+	secret, lock, err := NewSecret()
+	assert.NoError(t, err)
+
+	b, err := hex.DecodeString(secret)
+	assert.NoError(t, err)
+	assert.Len(t, b, PreimageSize)
+	assert.Equal(t, HashPreimage(b), lock)
+}
+
+func TestFromLockTransaction(t *testing.T) {
+	h, _ := testHTLC(t)
+	assert.Equal(t, DeriveAddress(sender, 3), h.Address)
+	assert.Equal(t, sender, h.Sender)
+	assert.Equal(t, recipient, h.Recipient)
+	assert.Equal(t, int64(50), h.Amount)
+	assert.Equal(t, StatusOpen, h.Status)
+
+	_, lock, _ := NewSecret()
+	for _, l := range []Lock{
+		{Recipient: "bob", HashLock: lock, Timeout: 1000},
+		{Recipient: recipient, HashLock: "abcd", Timeout: 1000},
+		{Recipient: recipient, HashLock: lock, Timeout: 0},
+	} {
+		_, err := LockTransaction(sender, l, 50, 1, 3)
+		assert.ErrorIs(t, err, errmsg.ErrInvalidHTLC, l)
+	}
+
+	_, err := LockTransaction(sender, Lock{Recipient: recipient, HashLock: lock, Timeout: 1000}, 0, 1, 3)
+	assert.ErrorIs(t, err, errmsg.ErrInvalidHTLC)
+
+	// the address is bound to the lock nonce
+	tx, err := LockTransaction(sender, Lock{Recipient: recipient, HashLock: lock, Timeout: 1000}, 50, 1, 3)
+	assert.NoError(t, err)
+	tx.Nonce = 4
+	_, err = FromLockTransaction(tx)
+	assert.ErrorIs(t, err, errmsg.ErrInvalidHTLC)
+}
+
+func TestVerifyClaim(t *testing.T) {
+	h, secret := testHTLC(t)
+
+	tx, err := h.ClaimTransaction(secret, 1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, h.Timeout, tx.Expires)
+
+	got, err := h.VerifyClaim(tx, 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, secret, got)
+
+	_, err = h.VerifyClaim(tx, 1001)
+	assert.ErrorIs(t, err, errmsg.ErrHTLCExpired)
+
+	other, _, _ := NewSecret()
+	tx, err = h.ClaimTransaction(other, 1, 0)
+	assert.NoError(t, err)
+	_, err = h.VerifyClaim(tx, 900)
+	assert.ErrorIs(t, err, errmsg.ErrInvalidPreimage)
+
+	tx, err = h.ClaimTransaction(secret, 1, 0)
+	assert.NoError(t, err)
+	tx.From = sender
+	_, err = h.VerifyClaim(tx, 900)
+	assert.ErrorIs(t, err, errmsg.ErrNotHTLCParty)
+
+	h.Status = StatusRefunded
+	tx.From = recipient
+	_, err = h.VerifyClaim(tx, 900)
+	assert.ErrorIs(t, err, errmsg.ErrHTLCClosed)
+}
+
+func TestVerifyRefund(t *testing.T) {
+	h, _ := testHTLC(t)
+
+	tx := h.RefundTransaction(1, 4)
+	assert.Equal(t, transaction.TypeHTLCRefund, tx.Type)
+	assert.False(t, tx.Executable(h.Timeout))
+	assert.True(t, tx.Executable(h.Timeout+1))
+
+	assert.ErrorIs(t, h.VerifyRefund(tx, 1000), errmsg.ErrHTLCNotExpired)
+	assert.NoError(t, h.VerifyRefund(tx, 1001))
+
+	tx.Amount = 5
+	assert.ErrorIs(t, h.VerifyRefund(tx, 1001), errmsg.ErrInvalidHTLC)
+
+	tx.Amount = 0
+	tx.From = recipient
+	assert.ErrorIs(t, h.VerifyRefund(tx, 1001), errmsg.ErrNotHTLCParty)
+}
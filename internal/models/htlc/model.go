@@ -0,0 +1,53 @@
+package htlc
+
+import (
+	"context"
+
+	"com.perkunas/internal/db"
+	"github.com/jmoiron/sqlx"
+)
+
+type Model struct {
+	DB *db.DB
+}
+
+func (hm *Model) Get(ctx context.Context, address string) (*HTLC, error) {
+	return get(ctx, hm.DB.ReadDB, address)
+}
+
+func (hm *Model) GetWithTX(ctx context.Context, db *sqlx.Tx, address string) (*HTLC, error) {
+	return get(ctx, db, address)
+}
+
+func (hm *Model) InsertWithTX(ctx context.Context, db *sqlx.Tx, h *HTLC, txHash string, height uint64) error {
+	query := `
+		INSERT INTO htlcs (address, sender, recipient, amount, hash_lock, timeout, status, preimage, tx_hash, block_height)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := db.ExecContext(ctx, query, h.Address, h.Sender, h.Recipient, h.Amount, h.HashLock, h.Timeout, h.Status, h.Preimage, txHash, height)
+	return err
+}
+
+// SettleWithTX closes an open HTLC, recording the preimage of a claim.
+func (hm *Model) SettleWithTX(ctx context.Context, db *sqlx.Tx, address, status, preimage string) error {
+	query := `UPDATE htlcs SET status = ?, preimage = ? WHERE address = ? AND status = ?`
+
+	_, err := db.ExecContext(ctx, query, status, preimage, address, StatusOpen)
+	return err
+}
+
+func get(ctx context.Context, q sqlx.QueryerContext, address string) (*HTLC, error) {
+	query := `
+		SELECT address, sender, recipient, amount, hash_lock, timeout, status, preimage, tx_hash, block_height
+		FROM htlcs
+		WHERE address = ?
+	`
+
+	var res HTLCDB
+	if err := sqlx.GetContext(ctx, q, &res, query, address); err != nil {
+		return nil, err
+	}
+
+	return &res.HTLC, nil
+}
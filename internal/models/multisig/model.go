@@ -0,0 +1,55 @@
+package multisig
+
+import (
+	"context"
+	"encoding/json"
+
+	"com.perkunas/internal/db"
+	"github.com/jmoiron/sqlx"
+)
+
+type Model struct {
+	DB *db.DB
+}
+
+func (mm *Model) Get(ctx context.Context, address string) (*Multisig, error) {
+	return get(ctx, mm.DB.ReadDB, address)
+}
+
+func (mm *Model) GetWithTX(ctx context.Context, db *sqlx.Tx, address string) (*Multisig, error) {
+	return get(ctx, db, address)
+}
+
+func (mm *Model) InsertWithTX(ctx context.Context, db *sqlx.Tx, m *Multisig, txHash string, height uint64) error {
+	owners, err := json.Marshal(m.Owners)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO multisig_accounts (address, threshold, owners, tx_hash, block_height)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	_, err = db.ExecContext(ctx, query, m.Address, m.Threshold, string(owners), txHash, height)
+	return err
+}
+
+func get(ctx context.Context, q sqlx.QueryerContext, address string) (*Multisig, error) {
+	query := `
+		SELECT address, threshold, owners, tx_hash, block_height
+		FROM multisig_accounts
+		WHERE address = ?
+	`
+
+	var res MultisigDB
+	if err := sqlx.GetContext(ctx, q, &res, query, address); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(res.OwnersDB), &res.Owners); err != nil {
+		return nil, err
+	}
+
+	return &res.Multisig, nil
+}
@@ -0,0 +1,174 @@
+package multisig
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"com.perkunas/internal/errmsg"
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/pkg/address"
+	"com.perkunas/proto"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// MaxOwners bounds the signatures a spend has to carry.
+const MaxOwners = 16
+
+// Multisig is an M-of-N account. Its address is derived from the threshold
+// and owners, so nobody holds a private key for it and spends need Threshold
+// owner signatures.
+type Multisig struct {
+	Address   string   `json:"address" db:"address"`
+	Threshold int      `json:"threshold" db:"threshold"`
+	Owners    []string `json:"owners" db:"-"`
+}
+
+type MultisigDB struct {
+	Multisig
+	OwnersDB    string `db:"owners"`
+	TxHash      string `db:"tx_hash"`
+	BlockHeight uint64 `db:"block_height"`
+}
+
+// Definition is the data of a multisig creation transaction.
+type Definition struct {
+	Threshold int      `json:"threshold"`
+	Owners    []string `json:"owners"`
+}
+
+// New validates the definition and derives the account address. Owners are
+// normalised and sorted so their order does not change the address.
+func New(threshold int, owners []string) (*Multisig, error) {
+	if len(owners) == 0 || len(owners) > MaxOwners {
+		return nil, fmt.Errorf("%w: need 1 to %d owners", errmsg.ErrInvalidMultisig, MaxOwners)
+	}
+
+	if threshold < 1 || threshold > len(owners) {
+		return nil, fmt.Errorf("%w: threshold must be between 1 and %d", errmsg.ErrInvalidMultisig, len(owners))
+	}
+
+	normalised := make([]string, 0, len(owners))
+	for _, o := range owners {
+		owner, err := address.Parse(o)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid owner: %w", errmsg.ErrInvalidMultisig, err)
+		}
+		normalised = append(normalised, owner)
+	}
+
+	slices.Sort(normalised)
+	if len(slices.Compact(slices.Clone(normalised))) != len(normalised) {
+		return nil, fmt.Errorf("%w: duplicate owner", errmsg.ErrInvalidMultisig)
+	}
+
+	return &Multisig{
+		Address:   deriveAddress(threshold, normalised),
+		Threshold: threshold,
+		Owners:    normalised,
+	}, nil
+}
+
+func deriveAddress(threshold int, owners []string) string {
+	buf := []byte("multisig")
+	buf = binary.BigEndian.AppendUint32(buf, uint32(threshold))
+	for _, o := range owners {
+		buf = append(buf, common.HexToAddress(o).Bytes()...)
+	}
+
+	return common.BytesToAddress(crypto.Keccak256(buf)[12:]).Hex()
+}
+
+// CreateTransaction returns an unsigned transaction creating m, funded with
+// amount from the creator.
+func (m *Multisig) CreateTransaction(creator string, amount, fee int64, nonce uint64) (*transaction.Transaction, error) {
+	data, err := json.Marshal(Definition{Threshold: m.Threshold, Owners: m.Owners})
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &transaction.Transaction{
+		Type:   transaction.TypeMultisigCreate,
+		From:   creator,
+		To:     m.Address,
+		Data:   string(data),
+		Amount: amount,
+		Fee:    fee,
+		Nonce:  nonce,
+	}
+	tx.SetHash()
+
+	return tx, nil
+}
+
+// FromCreateTransaction reads the multisig a creation transaction defines.
+func FromCreateTransaction(tx *transaction.Transaction) (*Multisig, error) {
+	if tx.Type != transaction.TypeMultisigCreate {
+		return nil, fmt.Errorf("%w: not a creation transaction", errmsg.ErrInvalidMultisig)
+	}
+
+	var def Definition
+	if err := json.Unmarshal([]byte(tx.Data), &def); err != nil {
+		return nil, fmt.Errorf("%w: %v", errmsg.ErrInvalidMultisig, err)
+	}
+
+	m, err := New(def.Threshold, def.Owners)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Address != tx.To {
+		return nil, fmt.Errorf("%w: recipient %s is not the derived address %s", errmsg.ErrInvalidMultisig, tx.To, m.Address)
+	}
+
+	return m, nil
+}
+
+// Verify checks that tx spends from m and carries signatures of at least
+// Threshold distinct owners, chainID is passed on to tx.Verify.
+func (m *Multisig) Verify(tx *transaction.Transaction, chainID uint64) error {
+	if tx.From != m.Address {
+		return errmsg.ErrSignatureSenderMismatch
+	}
+
+	if err := tx.Verify(chainID); err != nil {
+		return err
+	}
+
+	signers, err := tx.Signers()
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(signers))
+	for _, s := range signers {
+		if !slices.Contains(m.Owners, s) {
+			return errmsg.ErrNotMultisigOwner
+		}
+		seen[s] = true
+	}
+
+	if len(seen) < m.Threshold {
+		return errmsg.ErrMultisigThreshold
+	}
+
+	return nil
+}
+
+func (m *Multisig) ToProto() *proto.Multisig {
+	return &proto.Multisig{
+		Address:   m.Address,
+		Threshold: uint32(m.Threshold),
+		Owners:    m.Owners,
+	}
+}
+
+func FromProto(in *proto.Multisig) *Multisig {
+	return &Multisig{
+		Address:   in.GetAddress(),
+		Threshold: int(in.GetThreshold()),
+		Owners:    in.GetOwners(),
+	}
+}
@@ -0,0 +1,95 @@
+package multisig
+
+import (
+	"testing"
+
+	"com.perkunas/internal/errmsg"
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/pkg/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func owners(t *testing.T, n int) []*wallet.Wallet {
+	t.Helper()
+
+	res := make([]*wallet.Wallet, n)
+	for i := range res {
+		w, err := wallet.New()
+		assert.NoError(t, err)
+		res[i] = w
+	}
+
+	return res
+}
+
+func TestNew(t *testing.T) {
+	ws := owners(t, 3)
+
+	m, err := New(2, []string{ws[0].Address, ws[1].Address, ws[2].Address})
+	assert.NoError(t, err)
+
+	// owner order does not change the account
+	reordered, err := New(2, []string{ws[2].Address, ws[0].Address, ws[1].Address})
+	assert.NoError(t, err)
+	assert.Equal(t, m.Address, reordered.Address)
+
+	other, err := New(3, m.Owners)
+	assert.NoError(t, err)
+	assert.NotEqual(t, m.Address, other.Address)
+
+	_, err = New(4, m.Owners)
+	assert.ErrorIs(t, err, errmsg.ErrInvalidMultisig)
+	_, err = New(1, []string{ws[0].Address, ws[0].Address})
+	assert.ErrorIs(t, err, errmsg.ErrInvalidMultisig)
+	_, err = New(1, []string{"not an address"})
+	assert.ErrorIs(t, err, errmsg.ErrInvalidMultisig)
+}
+
+func TestFromCreateTransaction(t *testing.T) {
+	ws := owners(t, 2)
+	m, err := New(1, []string{ws[0].Address, ws[1].Address})
+	assert.NoError(t, err)
+
+	tx, err := m.CreateTransaction(ws[0].Address, 100, 1, 1)
+	assert.NoError(t, err)
+	assert.NoError(t, ws[0].SignTransaction(tx))
+	assert.NoError(t, tx.Verify(4242))
+
+	parsed, err := FromCreateTransaction(tx)
+	assert.NoError(t, err)
+	assert.Equal(t, m, parsed)
+
+	// the owners are signed, the creator can not be swapped for another account
+	tx.To = ws[1].Address
+	_, err = FromCreateTransaction(tx)
+	assert.ErrorIs(t, err, errmsg.ErrInvalidMultisig)
+}
+
+func TestVerify(t *testing.T) {
+	ws := owners(t, 4)
+	m, err := New(2, []string{ws[0].Address, ws[1].Address, ws[2].Address})
+	assert.NoError(t, err)
+
+	tx := &transaction.Transaction{From: m.Address, To: ws[3].Address, Amount: 10, Fee: 1, Nonce: 1}
+	tx.SetHash()
+
+	assert.NoError(t, ws[0].CoSign(tx))
+	assert.ErrorIs(t, ws[0].CoSign(tx), wallet.ErrAlreadySigned)
+	assert.ErrorIs(t, m.Verify(tx, 4242), errmsg.ErrMultisigThreshold)
+
+	// a duplicated signature does not count twice
+	dup := *tx
+	dup.Signatures = append(dup.Signatures, tx.Signatures[0])
+	assert.ErrorIs(t, m.Verify(&dup, 4242), errmsg.ErrMultisigThreshold)
+
+	outsider := *tx
+	outsider.Signatures = append(transaction.Signatures{}, tx.Signatures...)
+	assert.NoError(t, ws[3].CoSign(&outsider))
+	assert.ErrorIs(t, m.Verify(&outsider, 4242), errmsg.ErrNotMultisigOwner)
+
+	assert.NoError(t, ws[2].CoSign(tx))
+	assert.NoError(t, m.Verify(tx, 4242))
+
+	tx.Amount = 1000
+	assert.Error(t, m.Verify(tx, 4242))
+}
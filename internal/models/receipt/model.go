@@ -11,10 +11,37 @@ type Model struct {
 	DB *db.DB
 }
 
+func (am *Model) GetByTxHash(ctx context.Context, txHash string) (Receipt, error) {
+	query := `
+		SELECT tx_hash, block_hash, status, gas_used, CAST(COALESCE(logs, '[]') AS BLOB) AS logs, error
+		FROM receipts
+		WHERE tx_hash = ?
+	`
+
+	var res Receipt
+	return res, am.DB.ReadDB.GetContext(ctx, &res, query, txHash)
+}
+
+// GetByBlockHash returns the receipts of the transactions in a block.
+func (am *Model) GetByBlockHash(ctx context.Context, blockHash string) ([]Receipt, error) {
+	query := `
+		SELECT tx_hash, block_hash, status, gas_used, CAST(COALESCE(logs, '[]') AS BLOB) AS logs, error
+		FROM receipts
+		WHERE block_hash = ?
+	`
+
+	var res []Receipt
+	return res, am.DB.ReadDB.SelectContext(ctx, &res, query, blockHash)
+}
+
 func (am *Model) InsertBatch(ctx context.Context, db *sqlx.Tx, in []Receipt) error {
+	if len(in) == 0 {
+		return nil
+	}
+
 	query := `
-		INSERT INTO receipts (tx_hash, block_hash, status, gas_used, logs)
-		VALUES (:tx_hash, :block_hash, :status, :gas_used, :logs)
+		INSERT INTO receipts (tx_hash, block_hash, status, gas_used, logs, error)
+		VALUES (:tx_hash, :block_hash, :status, :gas_used, CAST(:logs AS TEXT), :error)
 		ON CONFLICT (tx_hash) DO NOTHING
 	`
 
@@ -4,29 +4,98 @@ import (
 	"encoding/json"
 	"time"
 
+	"com.perkunas/internal/vm"
 	"com.perkunas/proto"
 )
 
+// Receipt statuses. Every transaction of a block has a receipt, the ones
+// that failed are included with their fee charged and none of their other
+// effects.
+const (
+	// StatusAccepted is a transaction executed successfully
+	StatusAccepted = "ACCEPTED"
+	// StatusReverted is a transaction whose execution failed, a token
+	// transfer without the tokens, a claim of a settled HTLC or a contract
+	// call that reverted. StatusOutOfGas is a contract call that ran out of
+	// gas, it costs the whole fee.
+	StatusReverted = "REVERTED"
+	StatusOutOfGas = "OUT_OF_GAS"
+	// StatusInsufficientFunds is a transaction whose sender can not cover
+	// the amount and fee, it is charged what it has up to the fee
+	StatusInsufficientFunds = "INSUFFICIENT_FUNDS"
+	// StatusBadNonce is a transaction sent out of order, it is dropped
+	// without a charge and its nonce stays free
+	StatusBadNonce = "BAD_NONCE"
+)
+
 type Receipt struct {
 	TxHash    string          `json:"txHash" db:"tx_hash"`
 	BlockHash string          `json:"blockHash" db:"block_hash"`
 	Status    string          `json:"status" db:"status"`
 	GasUsed   int64           `json:"gasUsed" db:"gas_used"`
 	Logs      json.RawMessage `json:"logs" db:"logs"`
+	Error     string          `json:"error" db:"error"`
 	Timestamp time.Time       `json:"timestamp" db:"timestamp"`
 }
 
-func ProtoToReceipts(in []*proto.Transaction, blockHash string) []Receipt {
+// Result is the outcome of executing a transaction. Error says why a
+// transaction failed.
+type Result struct {
+	Status  string
+	GasUsed int64
+	Logs    []vm.Log
+	Error   string
+}
+
+func ProtoToReceipts(in []*proto.Transaction, blockHash string, results map[string]Result) []Receipt {
 	var res []Receipt
 
 	for _, tx := range in {
-		res = append(res, Receipt{
+		r := Receipt{
 			TxHash:    tx.GetHash(),
 			BlockHash: blockHash,
-			Status:    "ACCEPTED",
-			// Logs:      json.RawMessage(`[]`),
-			// GasUsed: tx.GetGasUsed(),
-		})
+			Status:    StatusAccepted,
+			Logs:      json.RawMessage(`[]`),
+		}
+
+		if result, ok := results[tx.GetHash()]; ok {
+			r.Status = result.Status
+			r.GasUsed = result.GasUsed
+			r.Error = result.Error
+			if logs, err := json.Marshal(result.Logs); err == nil && len(result.Logs) > 0 {
+				r.Logs = logs
+			}
+		}
+
+		res = append(res, r)
+	}
+
+	return res
+}
+
+// DecodeLogs returns the logs the transaction emitted.
+func (r *Receipt) DecodeLogs() ([]vm.Log, error) {
+	logs := []vm.Log{}
+	if len(r.Logs) == 0 {
+		return logs, nil
+	}
+
+	return logs, json.Unmarshal(r.Logs, &logs)
+}
+
+func LogsToProto(in []vm.Log) []*proto.Log {
+	res := make([]*proto.Log, 0, len(in))
+	for _, l := range in {
+		res = append(res, &proto.Log{Address: l.Address, Topics: l.Topics, Data: l.Data})
+	}
+
+	return res
+}
+
+func LogsFromProto(in []*proto.Log) []vm.Log {
+	res := make([]vm.Log, 0, len(in))
+	for _, l := range in {
+		res = append(res, vm.Log{Address: l.GetAddress(), Topics: l.GetTopics(), Data: l.GetData()})
 	}
 
 	return res
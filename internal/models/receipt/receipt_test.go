@@ -0,0 +1,35 @@
+package receipt
+
+import (
+	"testing"
+
+	"com.perkunas/internal/vm"
+	"com.perkunas/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtoToReceipts(t *testing.T) {
+	txs := []*proto.Transaction{{Hash: "a"}, {Hash: "b"}, {Hash: "c"}}
+	logs := []vm.Log{{Address: "0x01", Topics: []string{"0x02"}, Data: "0x03"}}
+
+	res := ProtoToReceipts(txs, "block", map[string]Result{
+		"b": {Status: StatusInsufficientFunds, Error: "balance 1 does not cover amount and fee 2"},
+		"c": {Status: StatusAccepted, GasUsed: 42, Logs: logs},
+	})
+	assert.Len(t, res, 3)
+
+	assert.Equal(t, StatusAccepted, res[0].Status)
+	assert.Empty(t, res[0].Error)
+
+	assert.Equal(t, StatusInsufficientFunds, res[1].Status)
+	assert.Equal(t, "balance 1 does not cover amount and fee 2", res[1].Error)
+
+	assert.Equal(t, int64(42), res[2].GasUsed)
+	decoded, err := res[2].DecodeLogs()
+	assert.NoError(t, err)
+	assert.Equal(t, logs, decoded)
+
+	for _, r := range res {
+		assert.Equal(t, "block", r.BlockHash)
+	}
+}
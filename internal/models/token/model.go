@@ -0,0 +1,132 @@
+package token
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"com.perkunas/internal/db"
+	"github.com/jmoiron/sqlx"
+)
+
+type Model struct {
+	DB *db.DB
+}
+
+func (tm *Model) Get(ctx context.Context, address string) (*Token, error) {
+	return get(ctx, tm.DB.ReadDB, address)
+}
+
+func (tm *Model) GetWithTX(ctx context.Context, db *sqlx.Tx, address string) (*Token, error) {
+	return get(ctx, db, address)
+}
+
+func (tm *Model) InsertWithTX(ctx context.Context, db *sqlx.Tx, t *Token, txHash string, height uint64) error {
+	query := `
+		INSERT INTO tokens (address, symbol, name, decimals, supply, creator, tx_hash, block_height)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := db.ExecContext(ctx, query, t.Address, t.Symbol, t.Name, t.Decimals, t.Supply, t.Creator, txHash, height)
+	return err
+}
+
+// Balance returns what address holds of token, zero when it never held any.
+func (tm *Model) Balance(ctx context.Context, token, address string) (Balance, error) {
+	query := `
+		SELECT t.address AS token, ? AS address, COALESCE(b.balance, 0) AS balance, t.symbol, t.decimals
+		FROM tokens t
+		LEFT JOIN token_balances b ON b.token = t.address AND b.address = ?
+		WHERE t.address = ?
+	`
+
+	var res Balance
+	err := tm.DB.ReadDB.GetContext(ctx, &res, query, address, address, token)
+	return res, err
+}
+
+// Balances lists every token address holds.
+func (tm *Model) Balances(ctx context.Context, address string) ([]Balance, error) {
+	query := `
+		SELECT b.token, b.address, b.balance, t.symbol, t.decimals
+		FROM token_balances b
+		JOIN tokens t ON t.address = b.token
+		WHERE b.address = ? AND b.balance > 0
+		ORDER BY t.symbol, b.token
+	`
+
+	res := make([]Balance, 0)
+	if err := tm.DB.ReadDB.SelectContext(ctx, &res, query, address); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (tm *Model) BalanceWithTX(ctx context.Context, db *sqlx.Tx, token, address string) (int64, error) {
+	var balance int64
+	err := db.GetContext(ctx, &balance, `SELECT balance FROM token_balances WHERE token = ? AND address = ?`, token, address)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	return balance, err
+}
+
+func (tm *Model) SetBalanceWithTX(ctx context.Context, db *sqlx.Tx, token, address string, balance int64) error {
+	query := `
+		INSERT INTO token_balances (token, address, balance)
+		VALUES (?, ?, ?)
+		ON CONFLICT (token, address) DO UPDATE SET balance = excluded.balance
+	`
+
+	_, err := db.ExecContext(ctx, query, token, address, balance)
+	return err
+}
+
+// Allowance returns how much of owner's tokens spender may transfer.
+func (tm *Model) Allowance(ctx context.Context, token, owner, spender string) (int64, error) {
+	return allowance(ctx, tm.DB.ReadDB, token, owner, spender)
+}
+
+func (tm *Model) AllowanceWithTX(ctx context.Context, db *sqlx.Tx, token, owner, spender string) (int64, error) {
+	return allowance(ctx, db, token, owner, spender)
+}
+
+func (tm *Model) SetAllowanceWithTX(ctx context.Context, db *sqlx.Tx, token, owner, spender string, amount int64) error {
+	query := `
+		INSERT INTO token_allowances (token, owner, spender, amount)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (token, owner, spender) DO UPDATE SET amount = excluded.amount
+	`
+
+	_, err := db.ExecContext(ctx, query, token, owner, spender, amount)
+	return err
+}
+
+func get(ctx context.Context, q sqlx.QueryerContext, address string) (*Token, error) {
+	query := `
+		SELECT address, symbol, name, decimals, supply, creator, tx_hash, block_height
+		FROM tokens
+		WHERE address = ?
+	`
+
+	var res TokenDB
+	if err := sqlx.GetContext(ctx, q, &res, query, address); err != nil {
+		return nil, err
+	}
+
+	return &res.Token, nil
+}
+
+func allowance(ctx context.Context, q sqlx.QueryerContext, token, owner, spender string) (int64, error) {
+	query := `SELECT amount FROM token_allowances WHERE token = ? AND owner = ? AND spender = ?`
+
+	var amount int64
+	err := sqlx.GetContext(ctx, q, &amount, query, token, owner, spender)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	return amount, err
+}
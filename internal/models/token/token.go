@@ -0,0 +1,298 @@
+package token
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"com.perkunas/internal/errmsg"
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/pkg/address"
+	"com.perkunas/proto"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	MaxDecimals   = 18
+	MaxNameLength = 64
+)
+
+var symbolPattern = regexp.MustCompile(`^[A-Z0-9]{1,12}$`)
+
+// Token is a fungible token issued on-chain. Its address is derived from the
+// creator and the nonce of the creation transaction, the whole supply is
+// credited to the creator.
+type Token struct {
+	Address  string `json:"address" db:"address"`
+	Symbol   string `json:"symbol" db:"symbol"`
+	Name     string `json:"name" db:"name"`
+	Decimals uint8  `json:"decimals" db:"decimals"`
+	Supply   int64  `json:"supply" db:"supply"`
+	Creator  string `json:"creator" db:"creator"`
+}
+
+type TokenDB struct {
+	Token
+	TxHash      string `db:"tx_hash"`
+	BlockHeight uint64 `db:"block_height"`
+}
+
+// Balance is what an address holds of a token, in base units.
+type Balance struct {
+	Token    string `json:"token" db:"token"`
+	Address  string `json:"address" db:"address"`
+	Balance  int64  `json:"balance" db:"balance"`
+	Symbol   string `json:"symbol" db:"symbol"`
+	Decimals uint8  `json:"decimals" db:"decimals"`
+}
+
+// Definition is the data of a token creation transaction.
+type Definition struct {
+	Symbol   string `json:"symbol"`
+	Name     string `json:"name,omitempty"`
+	Decimals uint8  `json:"decimals"`
+	Supply   int64  `json:"supply"`
+}
+
+// Transfer is the data of token transfer and approve transactions. A
+// transfer with Owner set moves tokens of Owner using the allowance Owner
+// gave the sender.
+type Transfer struct {
+	Token  string `json:"token"`
+	Amount int64  `json:"amount"`
+	Owner  string `json:"owner,omitempty"`
+}
+
+// IsTokenTransaction reports whether tx is one of the token transaction types.
+func IsTokenTransaction(tx *transaction.Transaction) bool {
+	switch tx.Type {
+	case transaction.TypeTokenCreate, transaction.TypeTokenTransfer, transaction.TypeTokenApprove:
+		return true
+	}
+
+	return false
+}
+
+// DeriveAddress returns the address of the token created by creator with the
+// transaction of the given nonce.
+func DeriveAddress(creator string, nonce uint64) string {
+	buf := []byte("token")
+	buf = append(buf, common.HexToAddress(creator).Bytes()...)
+	buf = binary.BigEndian.AppendUint64(buf, nonce)
+
+	return common.BytesToAddress(crypto.Keccak256(buf)[12:]).Hex()
+}
+
+// New validates the definition of a token created by creator with the
+// transaction of the given nonce.
+func New(creator string, nonce uint64, def Definition) (*Token, error) {
+	if !symbolPattern.MatchString(def.Symbol) {
+		return nil, fmt.Errorf("%w: symbol must be 1 to 12 upper case letters or digits", errmsg.ErrInvalidToken)
+	}
+
+	if len(def.Name) > MaxNameLength {
+		return nil, fmt.Errorf("%w: name longer than %d characters", errmsg.ErrInvalidToken, MaxNameLength)
+	}
+
+	if def.Decimals > MaxDecimals {
+		return nil, fmt.Errorf("%w: at most %d decimals", errmsg.ErrInvalidToken, MaxDecimals)
+	}
+
+	if def.Supply <= 0 {
+		return nil, fmt.Errorf("%w: supply must be positive", errmsg.ErrInvalidToken)
+	}
+
+	return &Token{
+		Address:  DeriveAddress(creator, nonce),
+		Symbol:   def.Symbol,
+		Name:     def.Name,
+		Decimals: def.Decimals,
+		Supply:   def.Supply,
+		Creator:  creator,
+	}, nil
+}
+
+// CreateTransaction returns an unsigned transaction issuing a token.
+func CreateTransaction(creator string, def Definition, fee int64, nonce uint64) (*transaction.Transaction, error) {
+	t, err := New(creator, nonce, def)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTransaction(transaction.TypeTokenCreate, creator, t.Address, def, fee, nonce)
+}
+
+// TransferTransaction returns an unsigned transaction sending tokens to to.
+func TransferTransaction(from, to string, tr Transfer, fee int64, nonce uint64) (*transaction.Transaction, error) {
+	return newTransaction(transaction.TypeTokenTransfer, from, to, tr, fee, nonce)
+}
+
+// ApproveTransaction returns an unsigned transaction allowing spender to
+// transfer up to tr.Amount of the owner's tokens.
+func ApproveTransaction(owner, spender string, tr Transfer, fee int64, nonce uint64) (*transaction.Transaction, error) {
+	return newTransaction(transaction.TypeTokenApprove, owner, spender, tr, fee, nonce)
+}
+
+func newTransaction(typ, from, to string, data any, fee int64, nonce uint64) (*transaction.Transaction, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &transaction.Transaction{
+		Type:  typ,
+		From:  from,
+		To:    to,
+		Data:  string(b),
+		Fee:   fee,
+		Nonce: nonce,
+	}
+	tx.SetHash()
+
+	return tx, nil
+}
+
+// FromCreateTransaction reads the token a creation transaction issues.
+func FromCreateTransaction(tx *transaction.Transaction) (*Token, error) {
+	if tx.Type != transaction.TypeTokenCreate {
+		return nil, fmt.Errorf("%w: not a creation transaction", errmsg.ErrInvalidToken)
+	}
+
+	if tx.Amount != 0 {
+		return nil, fmt.Errorf("%w: token transactions can not move native funds", errmsg.ErrInvalidToken)
+	}
+
+	var def Definition
+	if err := json.Unmarshal([]byte(tx.Data), &def); err != nil {
+		return nil, fmt.Errorf("%w: %v", errmsg.ErrInvalidToken, err)
+	}
+
+	t, err := New(tx.From, tx.Nonce, def)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Address != tx.To {
+		return nil, fmt.Errorf("%w: recipient %s is not the derived address %s", errmsg.ErrInvalidToken, tx.To, t.Address)
+	}
+
+	return t, nil
+}
+
+// ParseTransfer reads the data of a transfer or approve transaction.
+func ParseTransfer(tx *transaction.Transaction) (*Transfer, error) {
+	if tx.Type != transaction.TypeTokenTransfer && tx.Type != transaction.TypeTokenApprove {
+		return nil, fmt.Errorf("%w: not a transfer or approve transaction", errmsg.ErrInvalidToken)
+	}
+
+	if tx.Amount != 0 {
+		return nil, fmt.Errorf("%w: token transactions can not move native funds", errmsg.ErrInvalidToken)
+	}
+
+	var tr Transfer
+	if err := json.Unmarshal([]byte(tx.Data), &tr); err != nil {
+		return nil, fmt.Errorf("%w: %v", errmsg.ErrInvalidToken, err)
+	}
+
+	if err := address.Validate(tr.Token); err != nil {
+		return nil, fmt.Errorf("%w: token: %w", errmsg.ErrInvalidToken, err)
+	}
+
+	if tr.Amount < 0 || (tr.Amount == 0 && tx.Type == transaction.TypeTokenTransfer) {
+		return nil, fmt.Errorf("%w: amount must be positive", errmsg.ErrInvalidToken)
+	}
+
+	if tr.Owner != "" {
+		if tx.Type != transaction.TypeTokenTransfer {
+			return nil, fmt.Errorf("%w: owner is only allowed on transfers", errmsg.ErrInvalidToken)
+		}
+		if err := address.Validate(tr.Owner); err != nil {
+			return nil, fmt.Errorf("%w: owner: %w", errmsg.ErrInvalidToken, err)
+		}
+	}
+
+	return &tr, nil
+}
+
+// Holder returns the address whose tokens a transfer moves.
+func (tr *Transfer) Holder(tx *transaction.Transaction) string {
+	if tr.Owner != "" {
+		return tr.Owner
+	}
+
+	return tx.From
+}
+
+// ParseAmount converts a decimal amount like "12.5" into base units.
+func ParseAmount(s string, decimals uint8) (int64, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok || r.Sign() < 0 {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)))
+	if !r.IsInt() {
+		return 0, fmt.Errorf("amount %q has more than %d decimals", s, decimals)
+	}
+
+	if !r.Num().IsInt64() {
+		return 0, fmt.Errorf("amount %q is too large", s)
+	}
+
+	return r.Num().Int64(), nil
+}
+
+// FormatAmount renders base units as a decimal amount.
+func FormatAmount(v int64, decimals uint8) string {
+	if decimals == 0 {
+		return fmt.Sprintf("%d", v)
+	}
+
+	r := new(big.Rat).SetFrac(big.NewInt(v), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	return strings.TrimRight(strings.TrimRight(r.FloatString(int(decimals)), "0"), ".")
+}
+
+func (t *Token) ToProto() *proto.Token {
+	return &proto.Token{
+		Address:  t.Address,
+		Symbol:   t.Symbol,
+		Name:     t.Name,
+		Decimals: uint32(t.Decimals),
+		Supply:   t.Supply,
+		Creator:  t.Creator,
+	}
+}
+
+func FromProto(in *proto.Token) *Token {
+	return &Token{
+		Address:  in.GetAddress(),
+		Symbol:   in.GetSymbol(),
+		Name:     in.GetName(),
+		Decimals: uint8(in.GetDecimals()),
+		Supply:   in.GetSupply(),
+		Creator:  in.GetCreator(),
+	}
+}
+
+func (b Balance) ToProto() *proto.TokenBalance {
+	return &proto.TokenBalance{
+		Token:    b.Token,
+		Address:  b.Address,
+		Balance:  b.Balance,
+		Symbol:   b.Symbol,
+		Decimals: uint32(b.Decimals),
+	}
+}
+
+func BalancesToProto(in []Balance) []*proto.TokenBalance {
+	res := make([]*proto.TokenBalance, 0, len(in))
+	for _, b := range in {
+		res = append(res, b.ToProto())
+	}
+
+	return res
+}
@@ -0,0 +1,105 @@
+package token
+
+import (
+	"testing"
+
+	"com.perkunas/internal/errmsg"
+	"com.perkunas/internal/models/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	creator   = "0xE07cD67682C4b43bEF6b399bb7C180D975571aaa"
+	recipient = "0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19b2"
+)
+
+func TestNew(t *testing.T) {
+	tok, err := New(creator, 1, Definition{Symbol: "PTS", Decimals: 2, Supply: 100})
+	assert.NoError(t, err)
+	assert.Equal(t, DeriveAddress(creator, 1), tok.Address)
+	assert.NotEqual(t, DeriveAddress(creator, 2), tok.Address)
+
+	for _, def := range []Definition{
+		{Symbol: "pts", Supply: 1},
+		{Symbol: "", Supply: 1},
+		{Symbol: "PTS", Supply: 0},
+		{Symbol: "PTS", Supply: 1, Decimals: MaxDecimals + 1},
+	} {
+		_, err := New(creator, 1, def)
+		assert.ErrorIs(t, err, errmsg.ErrInvalidToken, def)
+	}
+}
+
+func TestFromCreateTransaction(t *testing.T) {
+	def := Definition{Symbol: "PTS", Name: "Points", Decimals: 2, Supply: 10_000}
+	tx, err := CreateTransaction(creator, def, 1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, transaction.TypeTokenCreate, tx.Type)
+
+	tok, err := FromCreateTransaction(tx)
+	assert.NoError(t, err)
+	assert.Equal(t, tx.To, tok.Address)
+	assert.Equal(t, creator, tok.Creator)
+	assert.Equal(t, int64(10_000), tok.Supply)
+
+	// the address is bound to the creation nonce
+	tx.Nonce = 4
+	_, err = FromCreateTransaction(tx)
+	assert.ErrorIs(t, err, errmsg.ErrInvalidToken)
+
+	tx.Nonce = 3
+	tx.Amount = 5
+	_, err = FromCreateTransaction(tx)
+	assert.ErrorIs(t, err, errmsg.ErrInvalidToken)
+}
+
+func TestParseTransfer(t *testing.T) {
+	tokAddr := DeriveAddress(creator, 1)
+
+	tx, err := TransferTransaction(creator, recipient, Transfer{Token: tokAddr, Amount: 5}, 1, 2)
+	assert.NoError(t, err)
+	tr, err := ParseTransfer(tx)
+	assert.NoError(t, err)
+	assert.Equal(t, creator, tr.Holder(tx))
+
+	tx, err = TransferTransaction(recipient, recipient, Transfer{Token: tokAddr, Amount: 5, Owner: creator}, 1, 2)
+	assert.NoError(t, err)
+	tr, err = ParseTransfer(tx)
+	assert.NoError(t, err)
+	assert.Equal(t, creator, tr.Holder(tx))
+
+	// approving zero revokes an allowance, transferring zero is pointless
+	tx, err = ApproveTransaction(creator, recipient, Transfer{Token: tokAddr}, 1, 2)
+	assert.NoError(t, err)
+	_, err = ParseTransfer(tx)
+	assert.NoError(t, err)
+
+	for _, tr := range []Transfer{
+		{Token: tokAddr},
+		{Token: tokAddr, Amount: -1},
+		{Token: "points", Amount: 1},
+		{Token: tokAddr, Amount: 1, Owner: "someone"},
+	} {
+		tx, err := TransferTransaction(creator, recipient, tr, 1, 2)
+		assert.NoError(t, err)
+		_, err = ParseTransfer(tx)
+		assert.ErrorIs(t, err, errmsg.ErrInvalidToken, tr)
+	}
+}
+
+func TestAmounts(t *testing.T) {
+	v, err := ParseAmount("12.5", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1250), v)
+	assert.Equal(t, "12.5", FormatAmount(v, 2))
+	assert.Equal(t, "12", FormatAmount(1200, 2))
+	assert.Equal(t, "0.01", FormatAmount(1, 2))
+	assert.Equal(t, "7", FormatAmount(7, 0))
+
+	_, err = ParseAmount("0.001", 2)
+	assert.Error(t, err)
+	_, err = ParseAmount("-1", 2)
+	assert.Error(t, err)
+	_, err = ParseAmount("100000000000", 18)
+	assert.Error(t, err)
+}
@@ -0,0 +1,124 @@
+package transaction
+
+import (
+	"fmt"
+	"math/big"
+
+	"com.perkunas/internal/errmsg"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// FromEthereum maps a signed Ethereum transaction onto a transaction. The
+// value is taken as is, the fee is gas times the gas price, so a gas price
+// of 1 keeps the gas limit of contract transactions. Ethereum nonces start
+// at 0 where ours start at 1. A missing recipient deploys the input as a
+// contract, To is left empty then since the contract address is derived
+// from the sender, an input calls the recipient as a contract.
+func FromEthereum(raw string) (*Transaction, error) {
+	etx, err := decodeEthereum(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(etx.ChainId()), etx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errmsg.ErrSignatureRecoveryFailed, err)
+	}
+
+	if !etx.Value().IsInt64() {
+		return nil, fmt.Errorf("%w: value is out of range", errmsg.ErrInvalidRawTx)
+	}
+
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(etx.Gas()), etx.GasFeeCap())
+	if !fee.IsInt64() {
+		return nil, fmt.Errorf("%w: fee is out of range", errmsg.ErrInvalidRawTx)
+	}
+
+	tx := &Transaction{
+		From:   from.Hex(),
+		Amount: etx.Value().Int64(),
+		Fee:    fee.Int64(),
+		Nonce:  etx.Nonce() + 1,
+		Raw:    raw,
+	}
+
+	switch {
+	case etx.To() == nil:
+		tx.Type = TypeContractDeploy
+		tx.Data = hexutil.Encode(etx.Data())
+	case len(etx.Data()) > 0:
+		tx.Type = TypeContractCall
+		tx.To = etx.To().Hex()
+		tx.Data = hexutil.Encode(etx.Data())
+	default:
+		tx.To = etx.To().Hex()
+	}
+	tx.SetHash()
+
+	return tx, nil
+}
+
+// EthereumTx decodes Raw.
+func (t *Transaction) EthereumTx() (*types.Transaction, error) {
+	return decodeEthereum(t.Raw)
+}
+
+func decodeEthereum(raw string) (*types.Transaction, error) {
+	b, err := hexutil.Decode(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errmsg.ErrInvalidRawTx, err)
+	}
+
+	etx := new(types.Transaction)
+	if err := etx.UnmarshalBinary(b); err != nil {
+		return nil, fmt.Errorf("%w: %v", errmsg.ErrInvalidRawTx, err)
+	}
+
+	switch etx.Type() {
+	case types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType:
+	default:
+		return nil, fmt.Errorf("%w: unsupported transaction type %d", errmsg.ErrInvalidRawTx, etx.Type())
+	}
+
+	return etx, nil
+}
+
+// verifyEthereum checks that the Ethereum transaction in Raw is replay
+// protected and signed for chainID, and that the fields match it.
+func (t *Transaction) verifyEthereum(chainID uint64) error {
+	if t.Signature != "" || t.IsMultisig() || t.LockTime != 0 {
+		return fmt.Errorf("%w: signature and lock time come from the raw transaction", errmsg.ErrInvalidRawTx)
+	}
+
+	etx, err := decodeEthereum(t.Raw)
+	if err != nil {
+		return err
+	}
+
+	// unprotected transactions could be replayed on any chain
+	if !etx.Protected() || etx.ChainId().Cmp(new(big.Int).SetUint64(chainID)) != 0 {
+		return fmt.Errorf("%w: expected chain id %d", errmsg.ErrInvalidChainID, chainID)
+	}
+
+	want, err := FromEthereum(t.Raw)
+	if err != nil {
+		return err
+	}
+
+	if want.From != t.From {
+		return errmsg.ErrSignatureSenderMismatch
+	}
+
+	// the address of a deployed contract is checked with the contract
+	if want.Type == TypeContractDeploy {
+		want.To = t.To
+	}
+
+	if want.To != t.To || want.Type != t.Type || want.Data != t.Data ||
+		want.Amount != t.Amount || want.Fee != t.Fee || want.Nonce != t.Nonce {
+		return fmt.Errorf("%w: fields do not match the raw transaction", errmsg.ErrInvalidRawTx)
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package transaction
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"com.perkunas/internal/errmsg"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+const testChainID = 4242
+
+func signedEthereumTx(t *testing.T, inner types.TxData) (string, common.Hash, string) {
+	t.Helper()
+
+	return signedEthereumTxFor(t, types.LatestSignerForChainID(big.NewInt(testChainID)), inner)
+}
+
+func signedEthereumTxFor(t *testing.T, signer types.Signer, inner types.TxData) (string, common.Hash, string) {
+	t.Helper()
+
+	key, err := crypto.HexToECDSA("a6f7fa0885f49b8327376bdcc1da167750ec8004b1331705358c7fb697a74fbb")
+	assert.NoError(t, err)
+
+	etx, err := types.SignNewTx(key, signer, inner)
+	assert.NoError(t, err)
+
+	b, err := etx.MarshalBinary()
+	assert.NoError(t, err)
+
+	return hexutil.Encode(b), etx.Hash(), crypto.PubkeyToAddress(key.PublicKey).Hex()
+}
+
+func TestFromEthereum(t *testing.T) {
+	to := common.HexToAddress("0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19b2")
+
+	raw, hash, from := signedEthereumTx(t, &types.DynamicFeeTx{
+		ChainID:   big.NewInt(testChainID),
+		Nonce:     0,
+		To:        &to,
+		Value:     big.NewInt(500),
+		Gas:       21000,
+		GasFeeCap: big.NewInt(2),
+	})
+
+	tx, err := FromEthereum(raw)
+	assert.NoError(t, err)
+
+	assert.Equal(t, from, tx.From)
+	assert.Equal(t, to.Hex(), tx.To)
+	assert.Equal(t, TypeTransfer, tx.Type)
+	assert.Equal(t, int64(500), tx.Amount)
+	assert.Equal(t, int64(42000), tx.Fee)
+	assert.Equal(t, uint64(1), tx.Nonce)
+	assert.Equal(t, hex.EncodeToString(hash.Bytes()), tx.Hash)
+	assert.NoError(t, tx.Verify(testChainID))
+
+	etx, err := tx.EthereumTx()
+	assert.NoError(t, err)
+	assert.Equal(t, hash, etx.Hash())
+}
+
+func TestFromEthereum_Contracts(t *testing.T) {
+	raw, _, _ := signedEthereumTx(t, &types.LegacyTx{
+		Nonce:    3,
+		Gas:      5000,
+		GasPrice: big.NewInt(1),
+		Data:     []byte{0x60, 0x01},
+	})
+
+	tx, err := FromEthereum(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, TypeContractDeploy, tx.Type)
+	assert.Equal(t, "0x6001", tx.Data)
+	assert.Empty(t, tx.To)
+	assert.Equal(t, uint64(4), tx.Nonce)
+
+	to := common.HexToAddress("0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19b2")
+	raw, _, _ = signedEthereumTx(t, &types.LegacyTx{
+		To:       &to,
+		Gas:      5000,
+		GasPrice: big.NewInt(1),
+		Data:     []byte{0xaa},
+	})
+
+	tx, err = FromEthereum(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, TypeContractCall, tx.Type)
+	assert.Equal(t, "0xaa", tx.Data)
+	assert.Equal(t, to.Hex(), tx.To)
+}
+
+func TestVerify_Ethereum(t *testing.T) {
+	to := common.HexToAddress("0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19b2")
+	raw, _, _ := signedEthereumTx(t, &types.LegacyTx{
+		To:       &to,
+		Value:    big.NewInt(10),
+		Gas:      21000,
+		GasPrice: big.NewInt(1),
+	})
+
+	tx, err := FromEthereum(raw)
+	assert.NoError(t, err)
+
+	tampered := *tx
+	tampered.Amount = 11
+	assert.ErrorIs(t, tampered.Verify(testChainID), errmsg.ErrInvalidRawTx)
+
+	tampered = *tx
+	tampered.From = "0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19b2"
+	assert.ErrorIs(t, tampered.Verify(testChainID), errmsg.ErrSignatureSenderMismatch)
+
+	tampered = *tx
+	tampered.LockTime = 100
+	assert.ErrorIs(t, tampered.Verify(testChainID), errmsg.ErrInvalidRawTx)
+
+	_, err = FromEthereum("0x1234")
+	assert.ErrorIs(t, err, errmsg.ErrInvalidRawTx)
+}
+
+func TestVerify_EthereumChainID(t *testing.T) {
+	to := common.HexToAddress("0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19b2")
+	legacy := &types.LegacyTx{To: &to, Value: big.NewInt(10), Gas: 21000, GasPrice: big.NewInt(1)}
+
+	for name, signer := range map[string]types.Signer{
+		"unprotected": types.HomesteadSigner{},
+		"other chain": types.LatestSignerForChainID(big.NewInt(1)),
+	} {
+		raw, _, _ := signedEthereumTxFor(t, signer, legacy)
+
+		tx, err := FromEthereum(raw)
+		assert.NoError(t, err, name)
+		assert.ErrorIs(t, tx.Verify(testChainID), errmsg.ErrInvalidChainID, name)
+	}
+
+	raw, _, _ := signedEthereumTx(t, legacy)
+	tx, err := FromEthereum(raw)
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Verify(testChainID))
+	assert.ErrorIs(t, tx.Verify(1), errmsg.ErrInvalidChainID)
+}
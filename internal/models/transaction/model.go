@@ -3,10 +3,10 @@ package transaction
 import (
 	"context"
 	"database/sql"
-	"fmt"
-	"strings"
+	"time"
 
 	"com.perkunas/internal/db"
+	"github.com/jmoiron/sqlx"
 )
 
 type Model struct {
@@ -15,64 +15,225 @@ type Model struct {
 
 func (tm *Model) Save(ctx context.Context, tx Transaction) error {
 	query := `
-		INSERT INTO mempool (hash, from_addr, to_addr, signature, fee, amount, nonce, timestamp, expires)
-		VALUES (:hash, :from_addr, :to_addr, :signature, :fee, :amount, :nonce, :timestamp, :expires)
+		INSERT INTO mempool (hash, type, from_addr, to_addr, data, signature, signatures, fee, amount, nonce, timestamp, expires, lock_time, raw)
+		VALUES (:hash, :type, :from_addr, :to_addr, :data, :signature, :signatures, :fee, :amount, :nonce, :timestamp, :expires, :lock_time, :raw)
 	`
 	_, err := tm.DB.WriteDB.NamedExecContext(ctx, query, tx)
 	return err
 }
 
+// DeleteBatch removes the transactions of a mined block. The other
+// transactions of their senders with nonces the block used up can never be
+// mined, they are removed too and recorded as dropped.
 func (tm *Model) DeleteBatch(ctx context.Context, IDs []int64) error {
 	if len(IDs) == 0 {
 		return nil
 	}
 
-	placeholders := make([]string, len(IDs))
-	args := make([]interface{}, len(IDs))
-	for i, id := range IDs {
-		placeholders[i] = "?"
-		args[i] = id
+	dbTx, err := tm.DB.WriteDB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
 	}
+	defer dbTx.Rollback()
 
-	query := fmt.Sprintf(`
+	// a resubmitted copy of a mined transaction is not a drop
+	record, args, err := sqlx.In(`
+		INSERT INTO dropped (hash, reason, dropped_at)
+		SELECT hash, ?, ? FROM (`+supersededQuery+`)
+		WHERE hash NOT IN (SELECT hash FROM mempool WHERE id IN (?))
+		ON CONFLICT (hash) DO UPDATE SET reason = excluded.reason, dropped_at = excluded.dropped_at
+	`, DropReasonSuperseded, time.Now().Unix(), IDs, IDs, IDs)
+	if err != nil {
+		return err
+	}
+
+	if _, err := dbTx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+
+	query, args, err := sqlx.In(`
 		DELETE FROM mempool
-		WHERE id IN (%s)
-	`, strings.Join(placeholders, ","))
+		WHERE id IN (?) OR id IN (SELECT id FROM (`+supersededQuery+`))
+	`, IDs, IDs, IDs)
+	if err != nil {
+		return err
+	}
 
-	_, err := tm.DB.WriteDB.ExecContext(ctx, query, args...)
-	return err
+	if _, err := dbTx.ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
+
+	return dbTx.Commit()
 }
 
-func (tm *Model) Pending(ctx context.Context) ([]*Transaction, error) {
+// supersededQuery selects the transactions not in the batch whose sender
+// has a transaction with the same or a higher nonce in it.
+const supersededQuery = `
+	SELECT m.id, m.hash
+	FROM mempool m
+	JOIN (
+		SELECT from_addr, MAX(nonce) AS nonce
+		FROM mempool
+		WHERE id IN (?)
+		GROUP BY from_addr
+	) mined ON mined.from_addr = m.from_addr AND m.nonce <= mined.nonce
+	WHERE m.id NOT IN (?)
+`
+
+// MaxPending is how many transactions Pending returns at most, the ones
+// with the highest fees.
+const MaxPending = 2000
+
+// pendingFilter keeps the transactions executable at a unix time, 0 keeps
+// all, and the ones sent by an address, an empty one keeps all.
+const pendingFilter = `
+	(? = 0 OR (lock_time <= ? AND expires >= ?))
+	AND (? = '' OR from_addr = ? COLLATE NOCASE)
+`
+
+// Pending returns the transactions waiting for a block. When at is set only
+// the ones that are executable at that unix time are returned, when from is
+// set only the ones that address sent.
+func (tm *Model) Pending(ctx context.Context, at int64, from string) ([]*Transaction, error) {
 	query := `
 		SELECT
 			id,
 			hash,
+			type,
 			from_addr,
 			to_addr,
+			data,
 			signature,
+			signatures,
 			fee,
 			amount,
 			nonce,
 			timestamp,
-			expires
+			expires,
+			lock_time,
+			raw
 		FROM mempool
-		ORDER BY fee DESC LIMIT 2000
+		WHERE ` + pendingFilter + `
+		ORDER BY fee DESC LIMIT ?
 	`
 
 	var res []*Transaction
-	if err := tm.DB.ReadDB.Select(&res, query); err != nil {
+	if err := tm.DB.ReadDB.SelectContext(ctx, &res, query, at, at, at, from, from, MaxPending); err != nil {
 		return nil, err
 	}
 
 	return res, nil
 }
 
+// CountPending returns how many transactions Pending matches, including the
+// ones past MaxPending.
+func (tm *Model) CountPending(ctx context.Context, at int64, from string) (uint64, error) {
+	query := `SELECT COUNT(*) FROM mempool WHERE ` + pendingFilter
+
+	var count uint64
+	if err := tm.DB.ReadDB.GetContext(ctx, &count, query, at, at, at, from, from); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (tm *Model) GetByHash(ctx context.Context, hash string) (Transaction, error) {
+	query := `
+		SELECT
+			id,
+			hash,
+			type,
+			from_addr,
+			to_addr,
+			data,
+			signature,
+			signatures,
+			fee,
+			amount,
+			nonce,
+			timestamp,
+			expires,
+			lock_time,
+			raw
+		FROM mempool
+		WHERE hash = ?
+		LIMIT 1
+	`
+
+	var res Transaction
+	return res, tm.DB.ReadDB.GetContext(ctx, &res, query, hash)
+}
+
+// Reasons recorded for dropped transactions.
+const (
+	// DropReasonExpired is recorded for transactions that expired before
+	// making it into a block.
+	DropReasonExpired = "expired"
+	// DropReasonSuperseded is recorded for transactions whose nonce was used
+	// by another transaction of their sender that was mined.
+	DropReasonSuperseded = "superseded"
+)
+
+// DroppedRetention is how long dropped transactions are remembered.
+const DroppedRetention = 24 * time.Hour
+
+// Dropped is a transaction that left the mempool without being mined.
+type Dropped struct {
+	Hash      string `db:"hash"`
+	Reason    string `db:"reason"`
+	DroppedAt int64  `db:"dropped_at"`
+}
+
+// ClearExpired removes the expired transactions and records them as
+// dropped, forgetting drops older than DroppedRetention.
 func (tm *Model) ClearExpired(ctx context.Context) (sql.Result, error) {
+	now := time.Now().Unix()
+
+	dbTx, err := tm.DB.WriteDB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer dbTx.Rollback()
+
+	record := `
+		INSERT INTO dropped (hash, reason, dropped_at)
+		SELECT hash, ?, ? FROM mempool
+		WHERE expires < ?
+		ON CONFLICT (hash) DO UPDATE SET reason = excluded.reason, dropped_at = excluded.dropped_at
+	`
+	if _, err := dbTx.ExecContext(ctx, record, DropReasonExpired, now, now); err != nil {
+		return nil, err
+	}
+
+	forget := `
+		DELETE FROM dropped
+		WHERE dropped_at < ?
+	`
+	if _, err := dbTx.ExecContext(ctx, forget, now-int64(DroppedRetention.Seconds())); err != nil {
+		return nil, err
+	}
+
 	query := `
 		DELETE FROM mempool
-		WHERE expires < strftime('%s', 'now')
+		WHERE expires < ?
+	`
+	res, err := dbTx.ExecContext(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, dbTx.Commit()
+}
+
+// GetDropped returns why the transaction with hash was dropped.
+func (tm *Model) GetDropped(ctx context.Context, hash string) (Dropped, error) {
+	query := `
+		SELECT hash, reason, dropped_at
+		FROM dropped
+		WHERE hash = ?
 	`
 
-	return tm.DB.WriteDB.ExecContext(ctx, query)
+	var res Dropped
+	return res, tm.DB.ReadDB.GetContext(ctx, &res, query, hash)
 }
@@ -0,0 +1,134 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"com.perkunas/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func testModel(t *testing.T) *Model {
+	t.Helper()
+
+	schema, err := os.ReadFile("../../../cmd/mempool/sql/mempool.sql")
+	assert.NoError(t, err)
+
+	d, err := db.NewDB(context.Background(), filepath.Join(t.TempDir(), "mempool.db"))
+	assert.NoError(t, err)
+	t.Cleanup(func() { d.Close() })
+
+	_, err = d.WriteDB.Exec(string(schema))
+	assert.NoError(t, err)
+
+	return &Model{DB: d}
+}
+
+func saveTx(t *testing.T, m *Model, hash, from string, nonce uint64, expires int64) int64 {
+	t.Helper()
+
+	tx := Transaction{Hash: hash, From: from, To: "receiver", Signature: "sig", Amount: 1, Fee: 1, Nonce: nonce, Timestamp: time.Now().Unix(), Expires: expires}
+	assert.NoError(t, m.Save(context.Background(), tx))
+
+	saved, err := m.GetByHash(context.Background(), hash)
+	assert.NoError(t, err)
+	return saved.ID
+}
+
+func TestClearExpired(t *testing.T) {
+	ctx := context.Background()
+	m := testModel(t)
+	now := time.Now().Unix()
+
+	saveTx(t, m, "expired", "alice", 1, now-10)
+	saveTx(t, m, "live", "bob", 1, now+3600)
+
+	// a drop older than the retention is forgotten
+	_, err := m.DB.WriteDB.Exec(`INSERT INTO dropped (hash, reason, dropped_at) VALUES (?, ?, ?)`, "old", DropReasonExpired, now-int64(DroppedRetention.Seconds())-1)
+	assert.NoError(t, err)
+
+	res, err := m.ClearExpired(ctx)
+	assert.NoError(t, err)
+	affected, _ := res.RowsAffected()
+	assert.Equal(t, int64(1), affected)
+
+	dropped, err := m.GetDropped(ctx, "expired")
+	assert.NoError(t, err)
+	assert.Equal(t, DropReasonExpired, dropped.Reason)
+	assert.GreaterOrEqual(t, dropped.DroppedAt, now)
+
+	_, err = m.GetByHash(ctx, "expired")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = m.GetDropped(ctx, "live")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = m.GetByHash(ctx, "live")
+	assert.NoError(t, err)
+
+	_, err = m.GetDropped(ctx, "old")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDeleteBatch_RecordsSuperseded(t *testing.T) {
+	ctx := context.Background()
+	m := testModel(t)
+	expires := time.Now().Add(time.Hour).Unix()
+
+	mined := saveTx(t, m, "mined", "alice", 2, expires)
+	saveTx(t, m, "same nonce", "alice", 2, expires)
+	saveTx(t, m, "lower nonce", "alice", 1, expires)
+	saveTx(t, m, "next nonce", "alice", 3, expires)
+	saveTx(t, m, "other sender", "bob", 1, expires)
+	saveTx(t, m, "mined", "alice", 2, expires) // resubmitted copy
+
+	assert.NoError(t, m.DeleteBatch(ctx, []int64{mined}))
+
+	for _, hash := range []string{"same nonce", "lower nonce"} {
+		dropped, err := m.GetDropped(ctx, hash)
+		assert.NoError(t, err, hash)
+		assert.Equal(t, DropReasonSuperseded, dropped.Reason, hash)
+	}
+
+	// the copy is gone but the mined transaction was not dropped
+	_, err := m.GetByHash(ctx, "mined")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = m.GetDropped(ctx, "mined")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	pending, err := m.Pending(ctx, 0, "")
+	assert.NoError(t, err)
+	hashes := make([]string, 0, len(pending))
+	for _, tx := range pending {
+		hashes = append(hashes, tx.Hash)
+	}
+	assert.ElementsMatch(t, []string{"next nonce", "other sender"}, hashes)
+}
+
+func TestPending_From(t *testing.T) {
+	ctx := context.Background()
+	m := testModel(t)
+	expires := time.Now().Add(time.Hour).Unix()
+
+	saveTx(t, m, "a1", "0xE07cD67682C4b43bEF6b399bb7C180D975571aaa", 1, expires)
+	saveTx(t, m, "a2", "0xE07cD67682C4b43bEF6b399bb7C180D975571aaa", 2, expires)
+	saveTx(t, m, "b1", "0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19b2", 1, expires)
+
+	pending, err := m.Pending(ctx, 0, "0xe07cd67682c4b43bef6b399bb7c180d975571aaa")
+	assert.NoError(t, err)
+	assert.Len(t, pending, 2)
+	for _, tx := range pending {
+		assert.Equal(t, "0xE07cD67682C4b43bEF6b399bb7C180D975571aaa", tx.From)
+	}
+
+	pending, err = m.Pending(ctx, 0, "")
+	assert.NoError(t, err)
+	assert.Len(t, pending, 3)
+
+	count, err := m.CountPending(ctx, 0, "0xe07cd67682c4b43bef6b399bb7c180d975571aaa")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), count)
+}
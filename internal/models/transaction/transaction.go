@@ -2,29 +2,63 @@ package transaction
 
 import (
 	"crypto/sha256"
+	"database/sql/driver"
 	"encoding/binary"
 	"encoding/hex"
+	"encoding/json"
+	"fmt"
 
 	"com.perkunas/internal/errmsg"
+	"com.perkunas/pkg/address"
 	"com.perkunas/proto"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
+// Transaction types, a plain transfer has no type.
+const (
+	TypeTransfer       = ""
+	TypeMultisigCreate = "multisig_create"
+	TypeTokenCreate    = "token_create"
+	TypeTokenTransfer  = "token_transfer"
+	TypeTokenApprove   = "token_approve"
+	TypeHTLCLock       = "htlc_lock"
+	TypeHTLCClaim      = "htlc_claim"
+	TypeHTLCRefund     = "htlc_refund"
+	TypeContractDeploy = "contract_deploy"
+	TypeContractCall   = "contract_call"
+)
+
 type Transaction struct {
 	ID        int64  `json:"id" db:"id"`
 	Hash      string `json:"hash" db:"hash"`
+	Type      string `json:"type,omitempty" db:"type"`
 	From      string `json:"from_addr" db:"from_addr"` // Sender's public key
 	To        string `json:"to_addr" db:"to_addr"`     // Recipient's public key
-	Data      string `json:"data,omitempty"`
+	Data      string `json:"data,omitempty" db:"data"`
 	Signature string `json:"signature"`
-	Amount    int64  `json:"amount" db:"amount"`
-	Fee       int64  `json:"fee" db:"fee"`
-	Nonce     uint64 `json:"nonce" db:"nonce"`
-	Timestamp int64  `json:"timestamp" db:"timestamp"`
-	Expires   int64  `json:"expires" db:"expires"`
+	// Signatures holds the owner signatures when From is a multisig account
+	Signatures Signatures `json:"signatures,omitempty" db:"signatures"`
+	Amount     int64      `json:"amount" db:"amount"`
+	Fee        int64      `json:"fee" db:"fee"`
+	Nonce      uint64     `json:"nonce" db:"nonce"`
+	Timestamp  int64      `json:"timestamp" db:"timestamp"`
+	Expires    int64      `json:"expires" db:"expires"`
+	// LockTime is the unix time before which the transaction can not be
+	// included in a block, 0 means no lock
+	LockTime int64 `json:"lock_time,omitempty" db:"lock_time"`
+	// Raw is a signed Ethereum transaction, 0x prefixed, the fields above
+	// are mapped from it and it replaces Signature
+	Raw string `json:"raw,omitempty" db:"raw"`
 }
 
 func (t *Transaction) CalculateHash() []byte {
+	// Ethereum transactions keep their own hash, so their tooling finds them
+	if t.Raw != "" {
+		b, _ := hexutil.Decode(t.Raw)
+		return crypto.Keccak256(b)
+	}
+
 	hasher := sha256.New()
 	buf := make([]byte, 8)
 
@@ -38,34 +72,88 @@ func (t *Transaction) CalculateHash() []byte {
 	binary.BigEndian.PutUint64(buf, t.Nonce)
 	hasher.Write(buf)
 
+	// type and data were added later, plain transfers keep their old hash
+	if t.Type != "" || t.Data != "" {
+		binary.Write(hasher, binary.BigEndian, uint32(len(t.Type)))
+		hasher.Write([]byte(t.Type))
+		binary.Write(hasher, binary.BigEndian, uint32(len(t.Data)))
+		hasher.Write([]byte(t.Data))
+	}
+
+	if t.LockTime != 0 {
+		hasher.Write([]byte("lock_time"))
+		binary.Write(hasher, binary.BigEndian, t.LockTime)
+	}
+
 	return hasher.Sum(nil)
 }
 
+// Executable reports whether the transaction may be included in a block with
+// the given timestamp.
+func (t *Transaction) Executable(at int64) bool {
+	return t.LockTime <= at
+}
+
+// SigningHash is the digest signed by the sender or multisig owners.
+func (t *Transaction) SigningHash() []byte {
+	return crypto.Keccak256(t.CalculateHash())
+}
+
+// IsMultisig reports whether the transaction is a spend from a multisig account.
+func (t *Transaction) IsMultisig() bool {
+	return len(t.Signatures) > 0
+}
+
 func (t *Transaction) SetHash() {
 	t.Hash = hex.EncodeToString(t.CalculateHash())
 }
 
-func (t *Transaction) Verify() error {
-	// Verify signature and sender first
-	sigBytes, err := hex.DecodeString(t.Signature)
-	if err != nil {
-		return errmsg.ErrInvalidSignatureFormat
+// ValidateAddresses checks that sender and recipient are checksummed
+// addresses, so funds can not be sent to a string nobody holds the key for.
+func (t *Transaction) ValidateAddresses() error {
+	if err := address.Validate(t.From); err != nil {
+		return fmt.Errorf("invalid from_addr: %w", err)
 	}
 
-	msgHash := crypto.Keccak256Hash(t.CalculateHash())
-	pubKey, err := crypto.Ecrecover(msgHash.Bytes(), sigBytes)
-	if err != nil {
-		return errmsg.ErrSignatureRecoveryFailed
+	if err := address.Validate(t.To); err != nil {
+		return fmt.Errorf("invalid to_addr: %w", err)
 	}
 
-	publicKeyECDSA, err := crypto.UnmarshalPubkey(pubKey)
-	if err != nil {
-		return errmsg.ErrInvalidPublicKeyFormat
+	return nil
+}
+
+// Verify checks the addresses, the hash and that the sender signed the
+// transaction, or the Ethereum transaction it was mapped from, which must
+// be signed for chainID. Multisig spends only get their signatures checked
+// for well-formedness here, whether the signers own the account is checked
+// against the multisig record.
+func (t *Transaction) Verify(chainID uint64) error {
+	if err := t.ValidateAddresses(); err != nil {
+		return err
 	}
 
-	address := crypto.PubkeyToAddress(*publicKeyECDSA).Hex()
-	if address != t.From {
-		return errmsg.ErrSignatureSenderMismatch
+	if t.Raw != "" {
+		if err := t.verifyEthereum(chainID); err != nil {
+			return err
+		}
+	} else if t.IsMultisig() {
+		if t.Signature != "" {
+			return errmsg.ErrInvalidSignatureFormat
+		}
+
+		if _, err := t.Signers(); err != nil {
+			return err
+		}
+	} else {
+		// Verify signature and sender first
+		address, err := recoverSigner(t.SigningHash(), t.Signature)
+		if err != nil {
+			return err
+		}
+
+		if address != t.From {
+			return errmsg.ErrSignatureSenderMismatch
+		}
 	}
 
 	// Then verify hash matches data
@@ -77,20 +165,59 @@ func (t *Transaction) Verify() error {
 	return nil
 }
 
+// Signers recovers the address behind every multisig signature.
+func (t *Transaction) Signers() ([]string, error) {
+	hash := t.SigningHash()
+	signers := make([]string, 0, len(t.Signatures))
+	for _, sig := range t.Signatures {
+		address, err := recoverSigner(hash, sig)
+		if err != nil {
+			return nil, err
+		}
+
+		signers = append(signers, address)
+	}
+
+	return signers, nil
+}
+
+func recoverSigner(hash []byte, signature string) (string, error) {
+	sigBytes, err := hex.DecodeString(signature)
+	if err != nil {
+		return "", errmsg.ErrInvalidSignatureFormat
+	}
+
+	pubKey, err := crypto.Ecrecover(hash, sigBytes)
+	if err != nil {
+		return "", errmsg.ErrSignatureRecoveryFailed
+	}
+
+	publicKeyECDSA, err := crypto.UnmarshalPubkey(pubKey)
+	if err != nil {
+		return "", errmsg.ErrInvalidPublicKeyFormat
+	}
+
+	return crypto.PubkeyToAddress(*publicKeyECDSA).Hex(), nil
+}
+
 func ToProtoTxs(in []*Transaction) (out []*proto.Transaction) {
 	for _, tx := range in {
 		out = append(out, &proto.Transaction{
-			Id:        tx.ID,
-			Hash:      tx.Hash,
-			FromAddr:  tx.From,
-			ToAddr:    tx.To,
-			Signature: tx.Signature,
-			Amount:    tx.Amount,
-			Fee:       tx.Fee,
-			Nonce:     tx.Nonce,
-			Data:      tx.Data,
-			Timestamp: tx.Timestamp,
-			Expires:   tx.Expires,
+			Id:         tx.ID,
+			Hash:       tx.Hash,
+			Type:       tx.Type,
+			FromAddr:   tx.From,
+			ToAddr:     tx.To,
+			Signature:  tx.Signature,
+			Signatures: tx.Signatures,
+			Amount:     tx.Amount,
+			Fee:        tx.Fee,
+			Nonce:      tx.Nonce,
+			Data:       tx.Data,
+			Timestamp:  tx.Timestamp,
+			Expires:    tx.Expires,
+			LockTime:   tx.LockTime,
+			Raw:        tx.Raw,
 		})
 	}
 
@@ -100,17 +227,21 @@ func ToProtoTxs(in []*Transaction) (out []*proto.Transaction) {
 func FromProtoTxs(in []*proto.Transaction) (out []*Transaction) {
 	for _, tx := range in {
 		out = append(out, &Transaction{
-			ID:        tx.Id,
-			Hash:      tx.Hash,
-			From:      tx.FromAddr,
-			To:        tx.ToAddr,
-			Signature: tx.Signature,
-			Amount:    tx.Amount,
-			Fee:       tx.Fee,
-			Nonce:     tx.Nonce,
-			Data:      tx.Data,
-			Timestamp: tx.Timestamp,
-			Expires:   tx.Expires,
+			ID:         tx.Id,
+			Hash:       tx.Hash,
+			Type:       tx.Type,
+			From:       tx.FromAddr,
+			To:         tx.ToAddr,
+			Signature:  tx.Signature,
+			Signatures: tx.Signatures,
+			Amount:     tx.Amount,
+			Fee:        tx.Fee,
+			Nonce:      tx.Nonce,
+			Data:       tx.Data,
+			Timestamp:  tx.Timestamp,
+			Expires:    tx.Expires,
+			LockTime:   tx.LockTime,
+			Raw:        tx.Raw,
 		})
 	}
 
@@ -119,30 +250,73 @@ func FromProtoTxs(in []*proto.Transaction) (out []*Transaction) {
 
 func FromProtoTx(in *proto.Transaction) Transaction {
 	return Transaction{
-		Hash:      in.GetHash(),
-		From:      in.GetFromAddr(),
-		To:        in.GetToAddr(),
-		Signature: in.GetSignature(),
-		Amount:    in.GetAmount(),
-		Fee:       in.GetFee(),
-		Nonce:     in.GetNonce(),
-		Data:      in.GetData(),
-		Timestamp: in.GetTimestamp(),
-		Expires:   in.GetExpires(),
+		Hash:       in.GetHash(),
+		Type:       in.GetType(),
+		From:       in.GetFromAddr(),
+		To:         in.GetToAddr(),
+		Signature:  in.GetSignature(),
+		Signatures: in.GetSignatures(),
+		Amount:     in.GetAmount(),
+		Fee:        in.GetFee(),
+		Nonce:      in.GetNonce(),
+		Data:       in.GetData(),
+		Timestamp:  in.GetTimestamp(),
+		Expires:    in.GetExpires(),
+		LockTime:   in.GetLockTime(),
+		Raw:        in.GetRaw(),
 	}
 }
 
 func ToProtoTx(in Transaction) *proto.Transaction {
 	return &proto.Transaction{
-		Hash:      in.Hash,
-		FromAddr:  in.From,
-		ToAddr:    in.To,
-		Signature: in.Signature,
-		Amount:    in.Amount,
-		Fee:       in.Fee,
-		Nonce:     in.Nonce,
-		Data:      in.Data,
-		Timestamp: in.Timestamp,
-		Expires:   in.Expires,
+		Hash:       in.Hash,
+		Type:       in.Type,
+		FromAddr:   in.From,
+		ToAddr:     in.To,
+		Signature:  in.Signature,
+		Signatures: in.Signatures,
+		Amount:     in.Amount,
+		Fee:        in.Fee,
+		Nonce:      in.Nonce,
+		Data:       in.Data,
+		Timestamp:  in.Timestamp,
+		Expires:    in.Expires,
+		LockTime:   in.LockTime,
+		Raw:        in.Raw,
 	}
 }
+
+// Signatures are stored as a JSON array.
+type Signatures []string
+
+func (s Signatures) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return "[]", nil
+	}
+
+	b, err := json.Marshal([]string(s))
+	return string(b), err
+}
+
+func (s *Signatures) Scan(src any) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("cannot scan %T into signatures", src)
+	}
+
+	var sigs []string
+	if err := json.Unmarshal(data, &sigs); err != nil {
+		return err
+	}
+
+	*s = sigs
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"com.perkunas/pkg/address"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -46,3 +47,61 @@ func TestTransaction_SetHash(t *testing.T) {
 	expected := hex.EncodeToString(tx.CalculateHash())
 	assert.Equal(t, expected, tx.Hash)
 }
+
+func TestTransaction_CalculateHashTypeAndData(t *testing.T) {
+	tx := &Transaction{
+		From:   "0x71C7656EC7ab88b098defB751B7401B5f6d8976F",
+		To:     "0x7217d3eC0A0C357d7Dde4896094B83137c137E42",
+		Amount: 1000,
+		Fee:    10,
+		Nonce:  1,
+	}
+
+	// plain transfers keep the hash they had before type and data were hashed
+	assert.Equal(t, "bcf480c501027c34789b1e353eacaaf737fbae2be2aa886aa710dd78df4d3901", hex.EncodeToString(tx.CalculateHash()))
+
+	plain := tx.CalculateHash()
+	tx.Data = "memo"
+	withData := tx.CalculateHash()
+	assert.NotEqual(t, plain, withData)
+
+	tx.Type = TypeMultisigCreate
+	assert.NotEqual(t, withData, tx.CalculateHash())
+}
+
+func TestTransaction_LockTime(t *testing.T) {
+	tx := &Transaction{
+		From:   "0x71C7656EC7ab88b098defB751B7401B5f6d8976F",
+		To:     "0x7217d3eC0A0C357d7Dde4896094B83137c137E42",
+		Amount: 1000,
+		Fee:    10,
+		Nonce:  1,
+	}
+
+	unlocked := tx.CalculateHash()
+	assert.True(t, tx.Executable(0))
+
+	// the lock time is signed, it can not be stripped to mine the tx early
+	tx.LockTime = 500
+	assert.NotEqual(t, unlocked, tx.CalculateHash())
+	assert.False(t, tx.Executable(499))
+	assert.True(t, tx.Executable(500))
+
+	tx.LockTime = 0
+	assert.Equal(t, unlocked, tx.CalculateHash())
+}
+
+func TestTransaction_ValidateAddresses(t *testing.T) {
+	tx := &Transaction{
+		From: "0xE07cD67682C4b43bEF6b399bb7C180D975571aaa",
+		To:   "0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19b2",
+	}
+	assert.NoError(t, tx.ValidateAddresses())
+
+	tx.To = "recipient"
+	assert.ErrorIs(t, tx.ValidateAddresses(), address.ErrMissingPrefix)
+	assert.ErrorIs(t, tx.Verify(4242), address.ErrMissingPrefix)
+
+	tx.To = "0x76f86614a08683bdfd4a44df1ee24e94bf5c19b2"
+	assert.ErrorIs(t, tx.ValidateAddresses(), address.ErrNotChecksummed)
+}
@@ -0,0 +1,298 @@
+package smt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"sort"
+
+	"com.perkunas/pkg/proof"
+)
+
+// Depth of the tree, one level per bit of the keccak256 key.
+const Depth = proof.StateDepth
+
+// Leaf is the state committed to for a single account. StorageRoot is the
+// hex encoded root of the account's storage tree, empty when the address
+// keeps nothing else.
+type Leaf struct {
+	Address     string
+	Balance     int64
+	Nonce       uint64
+	StorageRoot string
+}
+
+// Item is an entry of an account's storage tree.
+type Item struct {
+	Key   string
+	Value string
+}
+
+// Tree is a sparse Merkle tree over account address -> balance/nonce/storage
+// root, or over storage item key -> value. Keys are keccak256 hashes so the
+// root does not depend on insertion order.
+//
+// Only the nodes where the paths of two keys split are kept, a node stands
+// for the subtree at its depth and the hash of a subtree higher up is
+// extended from it with empty siblings. Trees are never modified, Update
+// returns a new tree sharing the unchanged nodes.
+type Tree struct {
+	root *node
+}
+
+type node struct {
+	depth       int
+	key         []byte // of a leaf below, its first depth bits are the node's path
+	hash        []byte // of the subtree at depth
+	left, right *node
+	leaf        *Leaf
+	item        *Item
+}
+
+// New builds the tree of leaves, a later leaf wins over an earlier one for
+// the same address.
+func New(leaves []Leaf) *Tree {
+	nodes := make([]*node, 0, len(leaves))
+	for _, l := range leaves {
+		nodes = append(nodes, leafNode(l))
+	}
+
+	return build(nodes)
+}
+
+// NewStorage builds the storage tree of items.
+func NewStorage(items []Item) *Tree {
+	nodes := make([]*node, 0, len(items))
+	for _, it := range items {
+		key := proof.StorageKey(it.Key)
+		nodes = append(nodes, &node{depth: Depth, key: key, hash: proof.HashItem(key, []byte(it.Value)), item: &it})
+	}
+
+	return build(nodes)
+}
+
+// StorageRoot returns the hex encoded root of the storage tree of items,
+// empty when there are none.
+func StorageRoot(items []Item) string {
+	if len(items) == 0 {
+		return ""
+	}
+
+	return NewStorage(items).Root()
+}
+
+// Update returns the tree with leaves set, t stays as it is.
+func (t *Tree) Update(leaves ...Leaf) *Tree {
+	root := t.root
+	for _, l := range leaves {
+		root = insert(root, leafNode(l))
+	}
+
+	return &Tree{root: root}
+}
+
+// Root returns the hex encoded root hash of the tree.
+func (t *Tree) Root() string {
+	return hex.EncodeToString(extend(t.root, 0))
+}
+
+// Prove builds an inclusion (or, for unknown addresses, exclusion) proof for
+// address against Root.
+func (t *Tree) Prove(address string) *proof.AccountProof {
+	p := &proof.AccountProof{Address: address, StateRoot: t.Root()}
+
+	var found *node
+	p.Bitmap, p.Siblings, found = t.path(proof.Key(address))
+	if found != nil {
+		p.Exists = true
+		p.Address = found.leaf.Address
+		p.Balance = found.leaf.Balance
+		p.Nonce = found.leaf.Nonce
+		p.StorageRoot = found.leaf.StorageRoot
+	}
+
+	return p
+}
+
+// ProveStorage builds an inclusion or exclusion proof for the storage item
+// key against Root.
+func (t *Tree) ProveStorage(key string) *proof.StorageProof {
+	p := &proof.StorageProof{Key: key}
+
+	var found *node
+	p.Bitmap, p.Siblings, found = t.path(proof.StorageKey(key))
+	if found != nil {
+		p.Exists = true
+		p.Value = found.item.Value
+	}
+
+	return p
+}
+
+// path collects the non-empty siblings from the root down to key, and the
+// leaf at key if there is one.
+func (t *Tree) path(key []byte) (string, []string, *node) {
+	bitmap := make([]byte, Depth/8)
+	siblings := make([]string, 0)
+
+	cur := t.root
+	for d := 0; d < Depth; d++ {
+		var sibling []byte
+		switch {
+		case cur == nil:
+		case cur.depth > d:
+			// the only subtree here leaves the path to key
+			if bit(key, d) != bit(cur.key, d) {
+				sibling = extend(cur, d+1)
+				cur = nil
+			}
+		case bit(key, d):
+			sibling = extend(cur.left, d+1)
+			cur = cur.right
+		default:
+			sibling = extend(cur.right, d+1)
+			cur = cur.left
+		}
+
+		if sibling != nil && !bytes.Equal(sibling, proof.EmptyHash(d+1)) {
+			bitmap[d/8] |= 0x80 >> (d % 8)
+			siblings = append(siblings, hex.EncodeToString(sibling))
+		}
+	}
+
+	return hex.EncodeToString(bitmap), siblings, cur
+}
+
+func leafNode(l Leaf) *node {
+	key := Key(l.Address)
+
+	var storageRoot []byte
+	if l.StorageRoot != "" {
+		storageRoot, _ = hex.DecodeString(l.StorageRoot)
+	}
+
+	return &node{depth: Depth, key: key, hash: HashLeaf(key, l.Balance, l.Nonce, storageRoot), leaf: &l}
+}
+
+// build links the leaf nodes bottom up.
+func build(nodes []*node) *Tree {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return bytes.Compare(nodes[i].key, nodes[j].key) < 0
+	})
+
+	// keep the last node of every key
+	uniq := nodes[:0]
+	for i, n := range nodes {
+		if i+1 < len(nodes) && bytes.Equal(n.key, nodes[i+1].key) {
+			continue
+		}
+		uniq = append(uniq, n)
+	}
+
+	if len(uniq) == 0 {
+		return &Tree{}
+	}
+
+	return &Tree{root: subtree(uniq)}
+}
+
+// subtree links sorted leaves with distinct keys, the node for them sits
+// where the first and last key split.
+func subtree(leaves []*node) *node {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+
+	depth := commonPrefix(leaves[0].key, leaves[len(leaves)-1].key)
+	mid := sort.Search(len(leaves), func(i int) bool { return bit(leaves[i].key, depth) })
+
+	n := &node{depth: depth, key: leaves[0].key, left: subtree(leaves[:mid]), right: subtree(leaves[mid:])}
+	n.rehash()
+	return n
+}
+
+// insert returns n with leaf added or replaced, copying the nodes on the way.
+func insert(n, leaf *node) *node {
+	if n == nil {
+		return leaf
+	}
+
+	depth := min(commonPrefix(n.key, leaf.key), n.depth)
+	if depth == Depth {
+		return leaf
+	}
+
+	if depth == n.depth {
+		c := *n
+		if bit(leaf.key, depth) {
+			c.right = insert(n.right, leaf)
+		} else {
+			c.left = insert(n.left, leaf)
+		}
+		c.rehash()
+		return &c
+	}
+
+	// leaf leaves n's path above n
+	split := &node{depth: depth, key: leaf.key, left: n, right: leaf}
+	if !bit(leaf.key, depth) {
+		split.left, split.right = leaf, n
+	}
+	split.rehash()
+	return split
+}
+
+func (n *node) rehash() {
+	n.hash = HashNode(extend(n.left, n.depth+1), extend(n.right, n.depth+1))
+}
+
+// extend returns the hash of the subtree at depth that holds nothing but n.
+func extend(n *node, depth int) []byte {
+	if n == nil {
+		return proof.EmptyHash(depth)
+	}
+
+	hash := n.hash
+	for d := n.depth - 1; d >= depth; d-- {
+		if bit(n.key, d) {
+			hash = HashNode(proof.EmptyHash(d+1), hash)
+		} else {
+			hash = HashNode(hash, proof.EmptyHash(d+1))
+		}
+	}
+
+	return hash
+}
+
+// commonPrefix returns how many leading bits a and b share.
+func commonPrefix(a, b []byte) int {
+	for i := range a {
+		if x := a[i] ^ b[i]; x != 0 {
+			n := i * 8
+			for x&0x80 == 0 {
+				x <<= 1
+				n++
+			}
+			return n
+		}
+	}
+
+	return len(a) * 8
+}
+
+// Key maps an address to its position in the tree.
+func Key(address string) []byte {
+	return proof.Key(address)
+}
+
+func HashLeaf(key []byte, balance int64, nonce uint64, storageRoot []byte) []byte {
+	return proof.HashLeaf(key, balance, nonce, storageRoot)
+}
+
+func HashNode(left, right []byte) []byte {
+	return proof.HashNode(left, right)
+}
+
+// bit reports whether the i-th most significant bit of key is set.
+func bit(key []byte, i int) bool {
+	return key[i/8]&(0x80>>(i%8)) != 0
+}
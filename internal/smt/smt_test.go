@@ -0,0 +1,187 @@
+package smt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"testing"
+
+	"com.perkunas/pkg/proof"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	addr1 = "0xE07cD67682C4b43bEF6b399bb7C180D975571aaa"
+	addr2 = "0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19b2"
+)
+
+func TestRoot_Empty(t *testing.T) {
+	assert.Equal(t, hex.EncodeToString(proof.EmptyHash(0)), New(nil).Root())
+}
+
+func TestRoot_OrderIndependent(t *testing.T) {
+	a := New([]Leaf{{Address: addr1, Balance: 10, Nonce: 1}, {Address: addr2, Balance: 20}})
+	b := New([]Leaf{{Address: addr2, Balance: 20}, {Address: addr1, Balance: 10, Nonce: 1}})
+
+	assert.Equal(t, a.Root(), b.Root())
+	assert.Len(t, a.Root(), 64)
+}
+
+func TestRoot_ChangesWithState(t *testing.T) {
+	base := New([]Leaf{{Address: addr1, Balance: 10, Nonce: 1}, {Address: addr2, Balance: 20}}).Root()
+
+	balance := New([]Leaf{{Address: addr1, Balance: 11, Nonce: 1}, {Address: addr2, Balance: 20}}).Root()
+	assert.NotEqual(t, base, balance)
+
+	nonce := New([]Leaf{{Address: addr1, Balance: 10, Nonce: 2}, {Address: addr2, Balance: 20}}).Root()
+	assert.NotEqual(t, base, nonce)
+
+	single := New([]Leaf{{Address: addr1, Balance: 10, Nonce: 1}}).Root()
+	assert.NotEqual(t, base, single)
+}
+
+func TestRoot_SingleLeaf(t *testing.T) {
+	key := Key(addr1)
+	hash := HashLeaf(key, 10, 1, nil)
+
+	// walk up from the leaf, every sibling is an empty subtree
+	for d := Depth - 1; d >= 0; d-- {
+		if bit(key, d) {
+			hash = HashNode(proof.EmptyHash(d+1), hash)
+		} else {
+			hash = HashNode(hash, proof.EmptyHash(d+1))
+		}
+	}
+
+	assert.Equal(t, hex.EncodeToString(hash), New([]Leaf{{Address: addr1, Balance: 10, Nonce: 1}}).Root())
+}
+
+func TestProve(t *testing.T) {
+	tree := New([]Leaf{
+		{Address: addr1, Balance: 10, Nonce: 1},
+		{Address: addr2, Balance: 20},
+		{Address: "0x0000000000000000000000000000000000000001", Balance: 5},
+	})
+	root := tree.Root()
+
+	p := tree.Prove(addr2)
+	assert.True(t, p.Exists)
+	assert.Equal(t, int64(20), p.Balance)
+	assert.Equal(t, root, p.StateRoot)
+	assert.NoError(t, proof.VerifyAccount(root, p))
+
+	// claiming a different balance breaks the proof
+	p.Balance = 21
+	assert.ErrorIs(t, proof.VerifyAccount(root, p), proof.ErrRootMismatch)
+
+	absent := tree.Prove("0x00000000000000000000000000000000000000ff")
+	assert.False(t, absent.Exists)
+	assert.NoError(t, proof.VerifyAccount(root, absent))
+}
+
+// naiveRoot hashes every level of the tree, the way the root is defined.
+func naiveRoot(leaves []Leaf) string {
+	sort.Slice(leaves, func(i, j int) bool {
+		return bytes.Compare(Key(leaves[i].Address), Key(leaves[j].Address)) < 0
+	})
+
+	var subtree func(lo, hi, depth int) []byte
+	subtree = func(lo, hi, depth int) []byte {
+		if lo == hi {
+			return proof.EmptyHash(depth)
+		}
+		if depth == Depth {
+			return leafNode(leaves[lo]).hash
+		}
+
+		mid := lo
+		for mid < hi && !bit(Key(leaves[mid].Address), depth) {
+			mid++
+		}
+		return HashNode(subtree(lo, mid, depth+1), subtree(mid, hi, depth+1))
+	}
+
+	return hex.EncodeToString(subtree(0, len(leaves), 0))
+}
+
+func testLeaves(n int) []Leaf {
+	leaves := make([]Leaf, 0, n)
+	for i := 0; i < n; i++ {
+		leaves = append(leaves, Leaf{Address: fmt.Sprintf("0x%040x", i+1), Balance: int64(i), Nonce: uint64(i % 3)})
+	}
+	return leaves
+}
+
+func TestRoot_MatchesDefinition(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 17, 64} {
+		leaves := testLeaves(n)
+		assert.Equal(t, naiveRoot(leaves), New(leaves).Root(), n)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	leaves := testLeaves(40)
+	full := New(leaves)
+
+	// inserting one by one ends in the same tree
+	incremental := New(nil)
+	for _, l := range leaves {
+		incremental = incremental.Update(l)
+	}
+	assert.Equal(t, full.Root(), incremental.Root())
+
+	changed := append([]Leaf{}, leaves...)
+	changed[7].Balance = 1000
+	changed[20].StorageRoot = StorageRoot([]Item{{Key: "code", Value: "00"}})
+	extra := Leaf{Address: addr1, Balance: 5}
+	changed = append(changed, extra)
+
+	updated := full.Update(changed[7], changed[20], extra)
+	assert.Equal(t, New(changed).Root(), updated.Root())
+	assert.Equal(t, naiveRoot(changed), updated.Root())
+
+	// the tree updated from is left alone
+	assert.Equal(t, New(leaves).Root(), full.Root())
+
+	p := updated.Prove(changed[20].Address)
+	assert.True(t, p.Exists)
+	assert.Equal(t, changed[20].StorageRoot, p.StorageRoot)
+	assert.NoError(t, proof.VerifyAccount(updated.Root(), p))
+}
+
+func TestStorage(t *testing.T) {
+	assert.Empty(t, StorageRoot(nil))
+
+	items := []Item{
+		{Key: "token_balance/0x01", Value: "10"},
+		{Key: "storage/00", Value: "ff"},
+		{Key: "code", Value: "6001"},
+	}
+	tree := NewStorage(items)
+	assert.Equal(t, tree.Root(), StorageRoot(items))
+
+	changed := StorageRoot([]Item{items[0], items[1], {Key: "code", Value: "6002"}})
+	assert.NotEqual(t, tree.Root(), changed)
+
+	p := tree.ProveStorage("storage/00")
+	assert.True(t, p.Exists)
+	assert.Equal(t, "ff", p.Value)
+	assert.NoError(t, proof.VerifyStorage(tree.Root(), p))
+
+	p.Value = "fe"
+	assert.ErrorIs(t, proof.VerifyStorage(tree.Root(), p), proof.ErrRootMismatch)
+
+	absent := tree.ProveStorage("storage/01")
+	assert.False(t, absent.Exists)
+	assert.NoError(t, proof.VerifyStorage(tree.Root(), absent))
+
+	// the account proof carries a storage item under its storage root
+	accounts := New([]Leaf{{Address: addr1, Balance: 1, StorageRoot: tree.Root()}, {Address: addr2}})
+	ap := accounts.Prove(addr1)
+	ap.Storage = tree.ProveStorage("code")
+	assert.NoError(t, proof.VerifyAccount(accounts.Root(), ap))
+
+	ap.Storage.Value = "6002"
+	assert.ErrorIs(t, proof.VerifyAccount(accounts.Root(), ap), proof.ErrRootMismatch)
+}
@@ -0,0 +1,163 @@
+package vm
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/holiman/uint256"
+)
+
+// Assemble translates assembly into code. Instructions are their mnemonics
+// separated by white space, ; starts a comment. PUSH takes a decimal or 0x
+// hex value and picks the smallest PUSHn that holds it. "name:" defines a
+// label at a JUMPDEST and "@name" pushes the position of the label:
+//
+//	PUSH 0 SLOAD PUSH 1 ADD   ; counter + 1
+//	DUP1 PUSH 0 SSTORE        ; store it
+//	@done JUMP
+//	done: STOP
+func Assemble(src string) ([]byte, error) {
+	var (
+		code   []byte
+		labels = map[string]int{}
+		// fixups are the positions of label pushes to fill in
+		fixups = map[int]string{}
+	)
+
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+
+		switch {
+		case strings.HasSuffix(tok, ":"):
+			name := strings.TrimSuffix(tok, ":")
+			if _, ok := labels[name]; ok || name == "" {
+				return nil, fmt.Errorf("duplicate or empty label %q", name)
+			}
+			labels[name] = len(code)
+			code = append(code, byte(JUMPDEST))
+
+		case strings.HasPrefix(tok, "@"):
+			fixups[len(code)+1] = tok[1:]
+			code = append(code, byte(PUSH1+1), 0, 0)
+
+		case strings.EqualFold(tok, "PUSH") || isPushN(tok):
+			if i+1 >= len(tokens) {
+				return nil, fmt.Errorf("%s without a value", tok)
+			}
+			i++
+			push, err := assemblePush(strings.ToUpper(tok), tokens[i])
+			if err != nil {
+				return nil, err
+			}
+			code = append(code, push...)
+
+		default:
+			op, ok := opcodes[strings.ToUpper(tok)]
+			if !ok {
+				return nil, fmt.Errorf("unknown instruction %q", tok)
+			}
+			code = append(code, byte(op))
+		}
+	}
+
+	for pos, name := range fixups {
+		dest, ok := labels[name]
+		if !ok {
+			return nil, fmt.Errorf("undefined label %q", name)
+		}
+		if dest > 0xffff {
+			return nil, fmt.Errorf("label %q out of range", name)
+		}
+		code[pos], code[pos+1] = byte(dest>>8), byte(dest)
+	}
+
+	if len(code) > MaxCodeSize {
+		return nil, fmt.Errorf("code is %d bytes, at most %d are allowed", len(code), MaxCodeSize)
+	}
+
+	return code, nil
+}
+
+func tokenize(src string) ([]string, error) {
+	var tokens []string
+
+	sc := bufio.NewScanner(strings.NewReader(src))
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), ";")
+		tokens = append(tokens, strings.Fields(line)...)
+	}
+
+	return tokens, sc.Err()
+}
+
+func isPushN(tok string) bool {
+	op, ok := opcodes[strings.ToUpper(tok)]
+	return ok && op.IsPush()
+}
+
+func assemblePush(mnemonic, value string) ([]byte, error) {
+	v, err := parseWord(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", mnemonic, value, err)
+	}
+
+	n := max(v.ByteLen(), 1)
+	if mnemonic != "PUSH" {
+		size, _ := strconv.Atoi(strings.TrimPrefix(mnemonic, "PUSH"))
+		if n > size {
+			return nil, fmt.Errorf("%s %s: value does not fit", mnemonic, value)
+		}
+		n = size
+	}
+
+	b := v.Bytes32()
+	return append([]byte{byte(PUSH1) + byte(n-1)}, b[32-n:]...), nil
+}
+
+func parseWord(s string) (*uint256.Int, error) {
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return uint256.FromDecimal(s)
+	}
+
+	digits := s[2:]
+	if len(digits)%2 == 1 {
+		digits = "0" + digits
+	}
+
+	b, err := hex.DecodeString(digits)
+	if err != nil || len(b) == 0 || len(b) > 32 {
+		return nil, errors.New("invalid hex word")
+	}
+
+	return new(uint256.Int).SetBytes(b), nil
+}
+
+// Disassemble renders code as one instruction per line, prefixed with its
+// position.
+func Disassemble(code []byte) string {
+	var sb strings.Builder
+
+	for pc := 0; pc < len(code); pc++ {
+		op := OpCode(code[pc])
+		fmt.Fprintf(&sb, "%04x  %s", pc, op)
+
+		if op.IsPush() {
+			n := int(op-PUSH1) + 1
+			end := min(pc+1+n, len(code))
+			fmt.Fprintf(&sb, " 0x%x", code[pc+1:end])
+			pc += n
+		}
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,146 @@
+package vm
+
+import "fmt"
+
+// OpCode is a single VM instruction. The numbering follows the EVM for the
+// instructions both machines share, so bytecode dumps look familiar.
+type OpCode byte
+
+const (
+	STOP OpCode = 0x00
+	ADD  OpCode = 0x01
+	MUL  OpCode = 0x02
+	SUB  OpCode = 0x03
+	DIV  OpCode = 0x04
+	MOD  OpCode = 0x06
+
+	LT     OpCode = 0x10
+	GT     OpCode = 0x11
+	EQ     OpCode = 0x14
+	ISZERO OpCode = 0x15
+	AND    OpCode = 0x16
+	OR     OpCode = 0x17
+	XOR    OpCode = 0x18
+	NOT    OpCode = 0x19
+
+	// KECCAK256 hashes the two words on top of the stack, the usual way to
+	// derive a storage key from a map slot and a key
+	KECCAK256 OpCode = 0x20
+
+	ADDRESS      OpCode = 0x30
+	CALLER       OpCode = 0x33
+	CALLVALUE    OpCode = 0x34
+	CALLDATALOAD OpCode = 0x35
+	CALLDATASIZE OpCode = 0x36
+	TIMESTAMP    OpCode = 0x42
+	NUMBER       OpCode = 0x43
+
+	POP      OpCode = 0x50
+	SLOAD    OpCode = 0x54
+	SSTORE   OpCode = 0x55
+	JUMP     OpCode = 0x56
+	JUMPI    OpCode = 0x57
+	PC       OpCode = 0x58
+	GAS      OpCode = 0x5a
+	JUMPDEST OpCode = 0x5b
+
+	PUSH1  OpCode = 0x60
+	PUSH32 OpCode = 0x7f
+	DUP1   OpCode = 0x80
+	DUP16  OpCode = 0x8f
+	SWAP1  OpCode = 0x90
+	SWAP16 OpCode = 0x9f
+
+	// LOG0 to LOG4 pop one data word and then 0 to 4 topics
+	LOG0 OpCode = 0xa0
+	LOG4 OpCode = 0xa4
+
+	// RETURN stops successfully with the word on top of the stack as output,
+	// REVERT stops, discards all storage writes and logs, and returns the word
+	// on top of the stack as the reason
+	RETURN OpCode = 0xf3
+	REVERT OpCode = 0xfd
+)
+
+// Gas costs
+const (
+	GasQuick     int64 = 1
+	GasFast      int64 = 2
+	GasJump      int64 = 3
+	GasKeccak    int64 = 10
+	GasSload     int64 = 5
+	GasSstore    int64 = 20
+	GasLog       int64 = 10
+	GasLogTopic  int64 = 5
+	GasCall      int64 = 20
+	GasDeploy    int64 = 50
+	GasCodeByte  int64 = 2
+	GasInputByte int64 = 1
+)
+
+var names = map[OpCode]string{
+	STOP: "STOP", ADD: "ADD", MUL: "MUL", SUB: "SUB", DIV: "DIV", MOD: "MOD",
+	LT: "LT", GT: "GT", EQ: "EQ", ISZERO: "ISZERO", AND: "AND", OR: "OR", XOR: "XOR", NOT: "NOT",
+	KECCAK256: "KECCAK256",
+	ADDRESS:   "ADDRESS", CALLER: "CALLER", CALLVALUE: "CALLVALUE", CALLDATALOAD: "CALLDATALOAD",
+	CALLDATASIZE: "CALLDATASIZE", TIMESTAMP: "TIMESTAMP", NUMBER: "NUMBER",
+	POP: "POP", SLOAD: "SLOAD", SSTORE: "SSTORE", JUMP: "JUMP", JUMPI: "JUMPI", PC: "PC", GAS: "GAS",
+	JUMPDEST: "JUMPDEST", RETURN: "RETURN", REVERT: "REVERT",
+}
+
+var opcodes = map[string]OpCode{}
+
+func init() {
+	for op := PUSH1; op <= PUSH32; op++ {
+		names[op] = fmt.Sprintf("PUSH%d", op-PUSH1+1)
+	}
+	for op := DUP1; op <= DUP16; op++ {
+		names[op] = fmt.Sprintf("DUP%d", op-DUP1+1)
+	}
+	for op := SWAP1; op <= SWAP16; op++ {
+		names[op] = fmt.Sprintf("SWAP%d", op-SWAP1+1)
+	}
+	for op := LOG0; op <= LOG4; op++ {
+		names[op] = fmt.Sprintf("LOG%d", op-LOG0)
+	}
+
+	for op, name := range names {
+		opcodes[name] = op
+	}
+}
+
+func (op OpCode) String() string {
+	if name, ok := names[op]; ok {
+		return name
+	}
+	return fmt.Sprintf("INVALID(0x%02x)", byte(op))
+}
+
+// IsPush reports whether op is followed by immediate bytes.
+func (op OpCode) IsPush() bool {
+	return op >= PUSH1 && op <= PUSH32
+}
+
+// gas returns the constant cost of op.
+func (op OpCode) gas() int64 {
+	switch {
+	case op == STOP, op == RETURN, op == REVERT:
+		return 0
+	case op == JUMPDEST:
+		return GasQuick
+	case op == JUMP, op == JUMPI:
+		return GasJump
+	case op == KECCAK256:
+		return GasKeccak
+	case op == SLOAD:
+		return GasSload
+	case op == SSTORE:
+		return GasSstore
+	case op >= LOG0 && op <= LOG4:
+		return GasLog + GasLogTopic*int64(op-LOG0)
+	case op == MUL, op == DIV, op == MOD:
+		return GasFast
+	default:
+		return GasQuick
+	}
+}
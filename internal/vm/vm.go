@@ -0,0 +1,445 @@
+// Package vm is a small gas metered stack machine running contract code in
+// process. Words are 256 bit unsigned integers, contracts keep state in a
+// word to word storage and emit logs, there is no memory and no calls
+// between contracts.
+package vm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+const (
+	// MaxStack is the maximum number of words on the stack.
+	MaxStack = 1024
+	// MaxCodeSize is the maximum size of contract code in bytes.
+	MaxCodeSize = 24576
+)
+
+// Execution errors, all of them discard the storage writes and logs of the
+// execution.
+var (
+	ErrReverted       = errors.New("execution reverted")
+	ErrOutOfGas       = errors.New("out of gas")
+	ErrInvalidOpcode  = errors.New("invalid opcode")
+	ErrStackUnderflow = errors.New("stack underflow")
+	ErrStackOverflow  = errors.New("stack overflow")
+	ErrInvalidJump    = errors.New("invalid jump destination")
+)
+
+// Context is what the running code can see of the transaction and block.
+type Context struct {
+	Address   common.Address
+	Caller    common.Address
+	Value     int64
+	Input     []byte
+	Timestamp int64
+	Height    uint64
+}
+
+// Storage reads the persisted storage of the running contract.
+type Storage interface {
+	Load(key common.Hash) (common.Hash, error)
+}
+
+// Log is an event emitted by a contract.
+type Log struct {
+	Address string   `json:"address"`
+	Topics  []string `json:"topics"`
+	Data    string   `json:"data"`
+}
+
+// Result is the outcome of an execution. Err is nil when the code stopped
+// normally, only then Storage and Logs are to be applied.
+type Result struct {
+	GasUsed int64
+	Output  common.Hash
+	Storage map[common.Hash]common.Hash
+	Logs    []Log
+	Err     error
+}
+
+type machine struct {
+	code    []byte
+	ctx     Context
+	storage Storage
+	dests   []bool
+	stack   []uint256.Int
+	gas     int64
+	res     *Result
+}
+
+// Run executes code with gas as the limit. The returned error is a failure
+// to read storage, execution failures are reported in Result.Err.
+func Run(code []byte, ctx Context, storage Storage, gas int64) (*Result, error) {
+	m := &machine{
+		code:    code,
+		ctx:     ctx,
+		storage: storage,
+		dests:   jumpDests(code),
+		stack:   make([]uint256.Int, 0, 16),
+		gas:     gas,
+		res:     &Result{Storage: map[common.Hash]common.Hash{}, Logs: []Log{}},
+	}
+
+	err := m.run()
+
+	var storageErr *storageError
+	if errors.As(err, &storageErr) {
+		return nil, storageErr.err
+	}
+
+	m.res.GasUsed = gas - m.gas
+	if err != nil {
+		m.res.Err = err
+		m.res.Storage = map[common.Hash]common.Hash{}
+		m.res.Logs = []Log{}
+
+		// only a revert gives the unused gas back
+		if !errors.Is(err, ErrReverted) {
+			m.res.GasUsed = gas
+		}
+	}
+
+	return m.res, nil
+}
+
+type storageError struct {
+	err error
+}
+
+func (e *storageError) Error() string {
+	return fmt.Sprintf("failed to load storage: %v", e.err)
+}
+
+// pushImmediate reads the n byte immediate of the PUSH at pc. Bytes past the
+// end of the code read as zero, a cut off immediate is padded on the right.
+func pushImmediate(code []byte, pc, n int) *uint256.Int {
+	imm := make([]byte, n)
+	copy(imm, code[pc+1:min(pc+1+n, len(code))])
+	return new(uint256.Int).SetBytes(imm)
+}
+
+func (m *machine) run() error {
+	for pc := 0; pc < len(m.code); pc++ {
+		op := OpCode(m.code[pc])
+
+		if _, ok := names[op]; !ok {
+			return fmt.Errorf("%w 0x%02x at %d", ErrInvalidOpcode, byte(op), pc)
+		}
+
+		cost := op.gas()
+		if cost > m.gas {
+			m.gas = 0
+			return ErrOutOfGas
+		}
+		m.gas -= cost
+
+		switch {
+		case op.IsPush():
+			n := int(op-PUSH1) + 1
+			if err := m.push(pushImmediate(m.code, pc, n)); err != nil {
+				return err
+			}
+			pc += n
+			continue
+
+		case op >= DUP1 && op <= DUP16:
+			n := int(op-DUP1) + 1
+			if len(m.stack) < n {
+				return ErrStackUnderflow
+			}
+			if err := m.push(&m.stack[len(m.stack)-n]); err != nil {
+				return err
+			}
+			continue
+
+		case op >= SWAP1 && op <= SWAP16:
+			n := int(op-SWAP1) + 1
+			if len(m.stack) < n+1 {
+				return ErrStackUnderflow
+			}
+			top := len(m.stack) - 1
+			m.stack[top], m.stack[top-n] = m.stack[top-n], m.stack[top]
+			continue
+
+		case op >= LOG0 && op <= LOG4:
+			if err := m.log(int(op - LOG0)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch op {
+		case STOP:
+			return nil
+
+		case ADD, MUL, SUB, DIV, MOD, LT, GT, EQ, AND, OR, XOR, KECCAK256:
+			a, b, err := m.pop2()
+			if err != nil {
+				return err
+			}
+			if err := m.push(binary(op, &a, &b)); err != nil {
+				return err
+			}
+
+		case ISZERO, NOT:
+			a, err := m.pop()
+			if err != nil {
+				return err
+			}
+			r := new(uint256.Int)
+			if op == NOT {
+				r.Not(&a)
+			} else if a.IsZero() {
+				r.SetOne()
+			}
+			if err := m.push(r); err != nil {
+				return err
+			}
+
+		case ADDRESS, CALLER:
+			addr := m.ctx.Address
+			if op == CALLER {
+				addr = m.ctx.Caller
+			}
+			if err := m.push(new(uint256.Int).SetBytes(addr.Bytes())); err != nil {
+				return err
+			}
+
+		case CALLVALUE, TIMESTAMP:
+			v := m.ctx.Value
+			if op == TIMESTAMP {
+				v = m.ctx.Timestamp
+			}
+			if err := m.push(new(uint256.Int).SetUint64(uint64(max(v, 0)))); err != nil {
+				return err
+			}
+
+		case NUMBER:
+			if err := m.push(new(uint256.Int).SetUint64(m.ctx.Height)); err != nil {
+				return err
+			}
+
+		case CALLDATASIZE:
+			if err := m.push(new(uint256.Int).SetUint64(uint64(len(m.ctx.Input)))); err != nil {
+				return err
+			}
+
+		case CALLDATALOAD:
+			off, err := m.pop()
+			if err != nil {
+				return err
+			}
+			var buf [32]byte
+			if off.IsUint64() && off.Uint64() < uint64(len(m.ctx.Input)) {
+				copy(buf[:], m.ctx.Input[off.Uint64():])
+			}
+			if err := m.push(new(uint256.Int).SetBytes(buf[:])); err != nil {
+				return err
+			}
+
+		case POP:
+			if _, err := m.pop(); err != nil {
+				return err
+			}
+
+		case SLOAD:
+			key, err := m.pop()
+			if err != nil {
+				return err
+			}
+			v, err := m.load(common.Hash(key.Bytes32()))
+			if err != nil {
+				return err
+			}
+			if err := m.push(new(uint256.Int).SetBytes(v.Bytes())); err != nil {
+				return err
+			}
+
+		case SSTORE:
+			key, v, err := m.pop2()
+			if err != nil {
+				return err
+			}
+			m.res.Storage[common.Hash(key.Bytes32())] = common.Hash(v.Bytes32())
+
+		case JUMP:
+			dest, err := m.pop()
+			if err != nil {
+				return err
+			}
+			if pc, err = m.jump(&dest); err != nil {
+				return err
+			}
+
+		case JUMPI:
+			dest, cond, err := m.pop2()
+			if err != nil {
+				return err
+			}
+			if !cond.IsZero() {
+				if pc, err = m.jump(&dest); err != nil {
+					return err
+				}
+			}
+
+		case PC:
+			if err := m.push(new(uint256.Int).SetUint64(uint64(pc))); err != nil {
+				return err
+			}
+
+		case GAS:
+			if err := m.push(new(uint256.Int).SetUint64(uint64(m.gas))); err != nil {
+				return err
+			}
+
+		case JUMPDEST:
+
+		case RETURN, REVERT:
+			v, err := m.pop()
+			if err != nil {
+				return err
+			}
+			m.res.Output = common.Hash(v.Bytes32())
+			if op == REVERT {
+				return ErrReverted
+			}
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// binary applies a two word instruction, a is the top of the stack.
+func binary(op OpCode, a, b *uint256.Int) *uint256.Int {
+	r := new(uint256.Int)
+	switch op {
+	case ADD:
+		r.Add(a, b)
+	case MUL:
+		r.Mul(a, b)
+	case SUB:
+		r.Sub(a, b)
+	case DIV:
+		r.Div(a, b)
+	case MOD:
+		r.Mod(a, b)
+	case LT:
+		if a.Lt(b) {
+			r.SetOne()
+		}
+	case GT:
+		if a.Gt(b) {
+			r.SetOne()
+		}
+	case EQ:
+		if a.Eq(b) {
+			r.SetOne()
+		}
+	case AND:
+		r.And(a, b)
+	case OR:
+		r.Or(a, b)
+	case XOR:
+		r.Xor(a, b)
+	case KECCAK256:
+		ab, bb := a.Bytes32(), b.Bytes32()
+		r.SetBytes(crypto.Keccak256(ab[:], bb[:]))
+	}
+
+	return r
+}
+
+func (m *machine) log(topics int) error {
+	data, err := m.pop()
+	if err != nil {
+		return err
+	}
+
+	l := Log{Address: m.ctx.Address.Hex(), Topics: make([]string, 0, topics), Data: common.Hash(data.Bytes32()).Hex()}
+	for range topics {
+		t, err := m.pop()
+		if err != nil {
+			return err
+		}
+		l.Topics = append(l.Topics, common.Hash(t.Bytes32()).Hex())
+	}
+
+	m.res.Logs = append(m.res.Logs, l)
+	return nil
+}
+
+func (m *machine) load(key common.Hash) (common.Hash, error) {
+	if v, ok := m.res.Storage[key]; ok {
+		return v, nil
+	}
+
+	v, err := m.storage.Load(key)
+	if err != nil {
+		return common.Hash{}, &storageError{err: err}
+	}
+
+	return v, nil
+}
+
+// jump returns the pc to continue at, the loop increments it past the
+// JUMPDEST.
+func (m *machine) jump(dest *uint256.Int) (int, error) {
+	if !dest.IsUint64() || dest.Uint64() >= uint64(len(m.code)) || !m.dests[dest.Uint64()] {
+		return 0, ErrInvalidJump
+	}
+
+	return int(dest.Uint64()), nil
+}
+
+func (m *machine) push(v *uint256.Int) error {
+	if len(m.stack) >= MaxStack {
+		return ErrStackOverflow
+	}
+
+	m.stack = append(m.stack, *v)
+	return nil
+}
+
+func (m *machine) pop() (uint256.Int, error) {
+	if len(m.stack) == 0 {
+		return uint256.Int{}, ErrStackUnderflow
+	}
+
+	v := m.stack[len(m.stack)-1]
+	m.stack = m.stack[:len(m.stack)-1]
+	return v, nil
+}
+
+func (m *machine) pop2() (a, b uint256.Int, err error) {
+	if len(m.stack) < 2 {
+		return a, b, ErrStackUnderflow
+	}
+
+	a, _ = m.pop()
+	b, _ = m.pop()
+	return a, b, nil
+}
+
+// jumpDests marks the JUMPDEST instructions of code, bytes pushed as
+// immediates are not instructions.
+func jumpDests(code []byte) []bool {
+	dests := make([]bool, len(code))
+	for pc := 0; pc < len(code); pc++ {
+		op := OpCode(code[pc])
+		if op == JUMPDEST {
+			dests[pc] = true
+		}
+		if op.IsPush() {
+			pc += int(op-PUSH1) + 1
+		}
+	}
+
+	return dests
+}
@@ -0,0 +1,146 @@
+package vm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type memStorage map[common.Hash]common.Hash
+
+func (s memStorage) Load(key common.Hash) (common.Hash, error) {
+	return s[key], nil
+}
+
+type failingStorage struct{}
+
+func (failingStorage) Load(common.Hash) (common.Hash, error) {
+	return common.Hash{}, errors.New("disk on fire")
+}
+
+const counter = `
+	PUSH 0 SLOAD PUSH 1 ADD   ; counter + 1
+	DUP1 PUSH 0 SSTORE
+	PUSH 0xc0ffee DUP2 LOG1   ; topic, then the new value as data
+	RETURN
+`
+
+func run(t *testing.T, src string, ctx Context, storage Storage, gas int64) *Result {
+	code, err := Assemble(src)
+	assert.NoError(t, err)
+
+	res, err := Run(code, ctx, storage, gas)
+	assert.NoError(t, err)
+	return res
+}
+
+func TestRun_Counter(t *testing.T) {
+	storage := memStorage{common.Hash{}: common.BigToHash(common.Big1)}
+	ctx := Context{Address: common.HexToAddress("0x01")}
+
+	res := run(t, counter, ctx, storage, 1000)
+	assert.NoError(t, res.Err)
+	assert.Equal(t, common.BigToHash(common.Big2), res.Output)
+	assert.Equal(t, common.BigToHash(common.Big2), res.Storage[common.Hash{}])
+	assert.Len(t, res.Logs, 1)
+	assert.Equal(t, ctx.Address.Hex(), res.Logs[0].Address)
+	assert.Equal(t, []string{common.HexToHash("0xc0ffee").Hex()}, res.Logs[0].Topics)
+	assert.Equal(t, common.BigToHash(common.Big2).Hex(), res.Logs[0].Data)
+	assert.Positive(t, res.GasUsed)
+	assert.Less(t, res.GasUsed, int64(1000))
+
+	// the storage passed in is only read, the caller applies the writes
+	assert.Equal(t, common.BigToHash(common.Big1), storage[common.Hash{}])
+}
+
+func TestRun_OutOfGas(t *testing.T) {
+	res := run(t, counter, Context{}, memStorage{}, 10)
+	assert.ErrorIs(t, res.Err, ErrOutOfGas)
+	assert.Equal(t, int64(10), res.GasUsed)
+	assert.Empty(t, res.Storage)
+	assert.Empty(t, res.Logs)
+}
+
+func TestRun_Revert(t *testing.T) {
+	src := `
+		PUSH 7 PUSH 0 SSTORE
+		CALLVALUE ISZERO @free JUMPI
+		PUSH 42 REVERT
+		free: STOP
+	`
+
+	res := run(t, src, Context{Value: 5}, memStorage{}, 1000)
+	assert.ErrorIs(t, res.Err, ErrReverted)
+	assert.Equal(t, common.BigToHash(big.NewInt(42)), res.Output)
+	assert.Empty(t, res.Storage)
+	assert.Less(t, res.GasUsed, int64(1000), "a revert keeps the unused gas")
+
+	res = run(t, src, Context{}, memStorage{}, 1000)
+	assert.NoError(t, res.Err)
+	assert.Len(t, res.Storage, 1)
+}
+
+func TestRun_Failures(t *testing.T) {
+	for src, want := range map[string]error{
+		"ADD":                      ErrStackUnderflow,
+		"PUSH 3 JUMP":              ErrInvalidJump,
+		"PUSH2 0x5b00 PUSH 1 JUMP": ErrInvalidJump, // pushed bytes are not instructions
+		"loop: @loop JUMP":         nil,
+	} {
+		res := run(t, src, Context{}, memStorage{}, 100)
+		if want == nil {
+			assert.ErrorIs(t, res.Err, ErrOutOfGas, src)
+			continue
+		}
+		assert.ErrorIs(t, res.Err, want, src)
+		assert.Equal(t, int64(100), res.GasUsed, src)
+	}
+
+	res, err := Run([]byte{0xfe}, Context{}, memStorage{}, 100)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, res.Err, ErrInvalidOpcode)
+
+	_, err = Run([]byte{byte(PUSH1), 0, byte(SLOAD)}, Context{}, failingStorage{}, 100)
+	assert.Error(t, err)
+}
+
+func TestPushImmediate(t *testing.T) {
+	code := []byte{byte(PUSH1 + 1), 0xab, 0xcd, byte(PUSH1 + 1), 0xab}
+	assert.Equal(t, uint64(0xabcd), pushImmediate(code, 0, 2).Uint64())
+
+	// cut off by the end of the code, the missing byte is zero
+	assert.Equal(t, uint64(0xab00), pushImmediate(code, 3, 2).Uint64())
+
+	full := pushImmediate([]byte{byte(PUSH32), 0x01}, 0, 32)
+	assert.Equal(t, new(big.Int).Lsh(big.NewInt(1), 248), full.ToBig())
+
+	assert.True(t, pushImmediate([]byte{byte(PUSH1)}, 0, 1).IsZero())
+}
+
+func TestRun_Context(t *testing.T) {
+	caller := common.HexToAddress("0xE07cD67682C4b43bEF6b399bb7C180D975571aaa")
+	input := common.BigToHash(big.NewInt(9)).Bytes()
+
+	res := run(t, "PUSH 0 CALLDATALOAD CALLER ADD RETURN", Context{Caller: caller, Input: input}, memStorage{}, 100)
+	assert.NoError(t, res.Err)
+	assert.Equal(t, common.BigToHash(new(big.Int).Add(caller.Big(), big.NewInt(9))), res.Output)
+
+	res = run(t, "CALLDATASIZE PUSH 64 CALLDATALOAD ADD RETURN", Context{Input: input}, memStorage{}, 100)
+	assert.NoError(t, res.Err)
+	assert.Equal(t, common.BigToHash(big.NewInt(32)), res.Output)
+}
+
+func TestAssemble(t *testing.T) {
+	code, err := Assemble("PUSH 0 PUSH 0x0100 PUSH4 1 start: @start")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x60, 0x00, 0x61, 0x01, 0x00, 0x63, 0, 0, 0, 1, 0x5b, 0x61, 0x00, 0x0a}, code)
+	assert.Contains(t, Disassemble(code), "000a  JUMPDEST")
+
+	for _, src := range []string{"FOO", "PUSH", "PUSH1 256", "@nowhere", "a: a:"} {
+		_, err := Assemble(src)
+		assert.Error(t, err, src)
+	}
+}
@@ -0,0 +1,85 @@
+// Package address validates account addresses: 0x followed by 20 hex encoded
+// bytes, with the EIP-55 mixed case checksum.
+package address
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Length is the length of an address including the 0x prefix.
+const Length = 2 + 2*common.AddressLength
+
+var (
+	ErrMissingPrefix   = errors.New("address must start with 0x")
+	ErrInvalidLength   = fmt.Errorf("address must be %d characters long", Length)
+	ErrInvalidHex      = errors.New("address must be hex encoded")
+	ErrInvalidChecksum = errors.New("address checksum does not match, check for typos")
+	ErrNotChecksummed  = errors.New("address must be in its EIP-55 checksummed form")
+	ErrZeroAddress     = errors.New("zero address is not allowed")
+	ErrEmpty           = errors.New("address is empty")
+)
+
+// Parse checks user input and returns the checksummed form of the address.
+// All lower or all upper case addresses carry no checksum and are accepted,
+// mixed case ones must have a valid checksum.
+func Parse(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", ErrEmpty
+	}
+
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return "", fmt.Errorf("%w: %q", ErrMissingPrefix, s)
+	}
+
+	if len(s) != Length {
+		return "", fmt.Errorf("%w: %q", ErrInvalidLength, s)
+	}
+
+	if _, err := hex.DecodeString(s[2:]); err != nil {
+		return "", fmt.Errorf("%w: %q", ErrInvalidHex, s)
+	}
+
+	checksummed := Checksum(s)
+	body := s[2:]
+	if body != strings.ToLower(body) && body != strings.ToUpper(body) && "0x"+body != checksummed {
+		return "", fmt.Errorf("%w: %q", ErrInvalidChecksum, s)
+	}
+
+	return checksummed, nil
+}
+
+// Validate checks an address as it appears on-chain. Accounts are keyed by
+// the exact address string, so only the checksummed form is accepted, any
+// other spelling would credit an account nobody can spend from.
+func Validate(s string) error {
+	checksummed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+
+	if s != checksummed {
+		return fmt.Errorf("%w: %q, use %s", ErrNotChecksummed, s, checksummed)
+	}
+
+	if checksummed == (common.Address{}).Hex() {
+		return ErrZeroAddress
+	}
+
+	return nil
+}
+
+// IsValid reports whether Validate accepts s.
+func IsValid(s string) bool {
+	return Validate(s) == nil
+}
+
+// Checksum returns the EIP-55 form of a hex address without validating it.
+func Checksum(s string) string {
+	return common.HexToAddress(s).Hex()
+}
@@ -0,0 +1,44 @@
+package address
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	const want = "0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19b2"
+
+	for _, in := range []string{
+		want,
+		"0x76f86614a08683bdfd4a44df1ee24e94bf5c19b2",
+		"0X76F86614A08683BDFD4A44DF1EE24E94BF5C19B2",
+		" " + want + "\n",
+	} {
+		got, err := Parse(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, got)
+	}
+
+	tests := map[string]error{
+		"":          ErrEmpty,
+		"recipient": ErrMissingPrefix,
+		"0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19":   ErrInvalidLength,
+		"0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19zz": ErrInvalidHex,
+		"0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19B2": ErrInvalidChecksum,
+	}
+	for in, wantErr := range tests {
+		_, err := Parse(in)
+		assert.ErrorIs(t, err, wantErr, in)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate("0xE07cD67682C4b43bEF6b399bb7C180D975571aaa"))
+	assert.True(t, IsValid("0x9858EfFD232B4033E47d90003D41EC34EcaEda94"))
+
+	assert.ErrorIs(t, Validate("0xe07cd67682c4b43bef6b399bb7c180d975571aaa"), ErrNotChecksummed)
+	assert.ErrorIs(t, Validate("0xE07cD67682C4b43bEF6b399bb7C180D975571aAa"), ErrInvalidChecksum)
+	assert.ErrorIs(t, Validate("0x0000000000000000000000000000000000000000"), ErrZeroAddress)
+	assert.False(t, IsValid("recipient"))
+}
@@ -0,0 +1,366 @@
+// Package client is a Go client for the node's REST API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"com.perkunas/internal/models/account"
+	"com.perkunas/internal/models/block"
+	"com.perkunas/internal/models/chainconfig"
+	"com.perkunas/internal/models/contract"
+	"com.perkunas/internal/models/eventlog"
+	"com.perkunas/internal/models/htlc"
+	"com.perkunas/internal/models/multisig"
+	"com.perkunas/internal/models/token"
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/internal/vm"
+)
+
+// StatusPending is reported for transactions that are still in the mempool.
+const StatusPending = "PENDING"
+
+var ErrNotFound = errors.New("not found")
+
+// ErrConflict is returned when the request no longer fits the node's state,
+// e.g. a transaction whose nonce is not the next one of the sender.
+var ErrConflict = errors.New("conflicts with the node's state")
+
+// ErrDropped is returned while waiting for a transaction that left the
+// mempool without being mined.
+var ErrDropped = errors.New("transaction dropped")
+
+// Lifecycle stages reported by TransactionStatus.
+const (
+	LifecyclePending = "pending"
+	LifecycleMined   = "mined"
+	LifecycleDropped = "dropped"
+)
+
+type Client struct {
+	NodeURL string
+	HTTP    *http.Client
+}
+
+// TxResult is a transaction and where it was included. BlockHash and Height
+// are empty while the transaction is pending, GasUsed and Logs are only set
+// for contract transactions and Error for failed ones.
+type TxResult struct {
+	Transaction transaction.Transaction `json:"transaction"`
+	BlockHash   string                  `json:"block_hash"`
+	Height      uint64                  `json:"height"`
+	Status      string                  `json:"status"`
+	GasUsed     int64                   `json:"gas_used"`
+	Logs        []vm.Log                `json:"logs"`
+	Error       string                  `json:"error"`
+}
+
+func New(nodeURL string) *Client {
+	return &Client{
+		NodeURL: strings.TrimRight(nodeURL, "/"),
+		HTTP:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Status returns the latest block header.
+func (c *Client) Status(ctx context.Context) (*block.Block, error) {
+	var res struct {
+		Block *block.Block `json:"block"`
+	}
+
+	if err := c.do(ctx, http.MethodGet, "/status", nil, &res); err != nil {
+		return nil, err
+	}
+
+	if res.Block == nil {
+		return nil, ErrNotFound
+	}
+
+	return res.Block, nil
+}
+
+// ChainConfig returns the genesis config of the node's chain.
+func (c *Client) ChainConfig(ctx context.Context) (*chainconfig.ChainConfig, error) {
+	var res chainconfig.ChainConfig
+	return &res, c.do(ctx, http.MethodGet, "/config", nil, &res)
+}
+
+// Block returns the block at height with its transactions.
+func (c *Client) Block(ctx context.Context, height uint64) (*block.Block, error) {
+	var b block.Block
+	return &b, c.do(ctx, http.MethodGet, fmt.Sprintf("/blocks/%d", height), nil, &b)
+}
+
+// Account returns the confirmed state of address. Addresses the chain has
+// never seen have a zero balance and nonce.
+func (c *Client) Account(ctx context.Context, address string) (*account.Account, error) {
+	var acc account.Account
+	err := c.do(ctx, http.MethodGet, "/accounts/"+url.PathEscape(address), nil, &acc)
+	if errors.Is(err, ErrNotFound) {
+		return &account.Account{Address: address}, nil
+	}
+
+	return &acc, err
+}
+
+func (c *Client) Transaction(ctx context.Context, hash string) (*TxResult, error) {
+	var res TxResult
+	return &res, c.do(ctx, http.MethodGet, "/transactions/"+url.PathEscape(hash), nil, &res)
+}
+
+// Multisig returns the owners and threshold of a multisig account.
+func (c *Client) Multisig(ctx context.Context, address string) (*multisig.Multisig, error) {
+	var m multisig.Multisig
+	return &m, c.do(ctx, http.MethodGet, "/multisig/"+url.PathEscape(address), nil, &m)
+}
+
+// Token returns a token's definition.
+func (c *Client) Token(ctx context.Context, address string) (*token.Token, error) {
+	var t token.Token
+	return &t, c.do(ctx, http.MethodGet, "/tokens/"+url.PathEscape(address), nil, &t)
+}
+
+// TokenBalance returns what owner holds of a token.
+func (c *Client) TokenBalance(ctx context.Context, tokenAddr, owner string) (*token.Balance, error) {
+	var b token.Balance
+	return &b, c.do(ctx, http.MethodGet, "/tokens/"+url.PathEscape(tokenAddr)+"/balances/"+url.PathEscape(owner), nil, &b)
+}
+
+// TokenBalances lists every token address holds.
+func (c *Client) TokenBalances(ctx context.Context, address string) ([]token.Balance, error) {
+	var res struct {
+		Balances []token.Balance `json:"balances"`
+	}
+
+	return res.Balances, c.do(ctx, http.MethodGet, "/accounts/"+url.PathEscape(address)+"/tokens", nil, &res)
+}
+
+// TokenAllowance returns how much of owner's tokens spender may transfer.
+func (c *Client) TokenAllowance(ctx context.Context, tokenAddr, owner, spender string) (int64, error) {
+	var res struct {
+		Amount int64 `json:"amount"`
+	}
+
+	path := "/tokens/" + url.PathEscape(tokenAddr) + "/allowances/" + url.PathEscape(owner) + "/" + url.PathEscape(spender)
+	return res.Amount, c.do(ctx, http.MethodGet, path, nil, &res)
+}
+
+// HTLC returns a hash time-locked contract.
+func (c *Client) HTLC(ctx context.Context, address string) (*htlc.HTLC, error) {
+	var h htlc.HTLC
+	return &h, c.do(ctx, http.MethodGet, "/htlc/"+url.PathEscape(address), nil, &h)
+}
+
+// Contract returns a deployed contract.
+func (c *Client) Contract(ctx context.Context, address string) (*contract.Contract, error) {
+	var ct contract.Contract
+	return &ct, c.do(ctx, http.MethodGet, "/contracts/"+url.PathEscape(address), nil, &ct)
+}
+
+// ContractStorage returns the word a contract stores at key, a decimal or 0x
+// prefixed hex word.
+func (c *Client) ContractStorage(ctx context.Context, address, key string) (string, error) {
+	var res struct {
+		Value string `json:"value"`
+	}
+
+	path := "/contracts/" + url.PathEscape(address) + "/storage/" + url.PathEscape(key)
+	return res.Value, c.do(ctx, http.MethodGet, path, nil, &res)
+}
+
+// Logs returns the contract logs matching f, see the node's /logs endpoint.
+func (c *Client) Logs(ctx context.Context, f eventlog.Filter) ([]eventlog.Log, error) {
+	q := url.Values{"address": f.Addresses}
+	for i, values := range f.Topics {
+		if len(values) > 0 {
+			q.Set(fmt.Sprintf("topic%d", i), strings.Join(values, ","))
+		}
+	}
+	if f.FromHeight > 0 {
+		q.Set("from", strconv.FormatUint(f.FromHeight, 10))
+	}
+	if f.ToHeight > 0 {
+		q.Set("to", strconv.FormatUint(f.ToHeight, 10))
+	}
+	if f.Limit > 0 {
+		q.Set("limit", strconv.Itoa(f.Limit))
+	}
+
+	var res struct {
+		Logs []eventlog.Log `json:"logs"`
+	}
+
+	return res.Logs, c.do(ctx, http.MethodGet, "/logs?"+q.Encode(), nil, &res)
+}
+
+// Mempool lists pending transactions, only those sent by from when it is set.
+func (c *Client) Mempool(ctx context.Context, from string) ([]*transaction.Transaction, error) {
+	path := "/mempool"
+	if from != "" {
+		path += "?from=" + url.QueryEscape(from)
+	}
+
+	var res struct {
+		Transactions []*transaction.Transaction `json:"transactions"`
+	}
+
+	return res.Transactions, c.do(ctx, http.MethodGet, path, nil, &res)
+}
+
+// ConfirmedNonce implements wallet.NonceSource.
+func (c *Client) ConfirmedNonce(ctx context.Context, address string) (uint64, error) {
+	acc, err := c.Account(ctx, address)
+	if err != nil {
+		return 0, err
+	}
+
+	return acc.Nonce, nil
+}
+
+// PendingNonces implements wallet.NonceSource.
+func (c *Client) PendingNonces(ctx context.Context, address string) ([]uint64, error) {
+	txs, err := c.Mempool(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	nonces := make([]uint64, 0, len(txs))
+	for _, tx := range txs {
+		nonces = append(nonces, tx.Nonce)
+	}
+
+	return nonces, nil
+}
+
+// FeeEstimate is the node's fee suggestion for a transaction to be mined
+// within TargetBlocks, Low may take the whole time and High makes the next
+// block.
+type FeeEstimate struct {
+	TargetBlocks        uint64 `json:"target_blocks"`
+	Low                 int64  `json:"low"`
+	Medium              int64  `json:"medium"`
+	High                int64  `json:"high"`
+	PendingTransactions uint64 `json:"pending_transactions"`
+	SampledBlocks       uint64 `json:"sampled_blocks"`
+	BlockCapacity       uint64 `json:"block_capacity"`
+}
+
+// EstimateFee asks the node for fees to be mined within targetBlocks, 0
+// lets the node pick.
+func (c *Client) EstimateFee(ctx context.Context, targetBlocks uint64) (*FeeEstimate, error) {
+	path := "/fees/estimate"
+	if targetBlocks > 0 {
+		path += fmt.Sprintf("?blocks=%d", targetBlocks)
+	}
+
+	var res FeeEstimate
+	return &res, c.do(ctx, http.MethodGet, path, nil, &res)
+}
+
+// TxStatus is where a submitted transaction is. Mined transactions carry
+// their receipt status and confirmations, dropped ones the reason they left
+// the mempool.
+type TxStatus struct {
+	Hash          string `json:"hash"`
+	Status        string `json:"status"`
+	ReceiptStatus string `json:"receipt_status"`
+	BlockHeight   uint64 `json:"block_height"`
+	BlockHash     string `json:"block_hash"`
+	Confirmations uint64 `json:"confirmations"`
+	Reason        string `json:"reason"`
+	DroppedAt     int64  `json:"dropped_at"`
+}
+
+// TransactionStatus returns the lifecycle stage of a submitted transaction.
+func (c *Client) TransactionStatus(ctx context.Context, hash string) (*TxStatus, error) {
+	var res TxStatus
+	return &res, c.do(ctx, http.MethodGet, "/transactions/"+url.PathEscape(hash)+"/status", nil, &res)
+}
+
+// SendTransaction submits a signed transaction and returns its hash.
+func (c *Client) SendTransaction(ctx context.Context, tx *transaction.Transaction) (string, error) {
+	body, err := json.Marshal(tx)
+	if err != nil {
+		return "", err
+	}
+
+	var res struct {
+		Hash string `json:"hash"`
+	}
+
+	return res.Hash, c.do(ctx, http.MethodPost, "/transactions", body, &res)
+}
+
+// WaitForTransaction polls until the transaction is mined or ctx is done,
+// failing with ErrDropped when it expired out of the mempool or another
+// transaction used its nonce.
+func (c *Client) WaitForTransaction(ctx context.Context, hash string, interval time.Duration) (*TxResult, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		res, err := c.Transaction(ctx, hash)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		if errors.Is(err, ErrNotFound) {
+			st, err := c.TransactionStatus(ctx, hash)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return nil, err
+			}
+			if err == nil && st.Status == LifecycleDropped {
+				return nil, fmt.Errorf("%w: %s", ErrDropped, st.Reason)
+			}
+		}
+
+		if err == nil && res.Status != StatusPending {
+			return res, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body []byte, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.NodeURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrNotFound, path)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		if res.StatusCode == http.StatusConflict {
+			return fmt.Errorf("%w: %s %s: %s", ErrConflict, method, path, strings.TrimSpace(string(msg)))
+		}
+		return fmt.Errorf("node responded %s to %s %s: %s", res.Status, method, path, strings.TrimSpace(string(msg)))
+	}
+
+	return json.NewDecoder(res.Body).Decode(out)
+}
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"com.perkunas/internal/models/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_AccountUnknown(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "could not get account", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	acc, err := New(srv.URL).Account(context.Background(), "0xabc")
+	assert.NoError(t, err)
+	assert.Equal(t, "0xabc", acc.Address)
+	assert.Zero(t, acc.Nonce)
+}
+
+func TestClient_SendTransactionConflict(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid nonce, expected 3", http.StatusConflict)
+	}))
+	defer srv.Close()
+
+	_, err := New(srv.URL).SendTransaction(context.Background(), &transaction.Transaction{Nonce: 5})
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.ErrorContains(t, err, "expected 3")
+}
+
+func TestClient_WaitForTransaction(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/transactions/abc", r.URL.Path)
+		if calls.Add(1) < 3 {
+			w.Write([]byte(`{"transaction":{"hash":"abc"},"status":"PENDING"}`))
+			return
+		}
+		w.Write([]byte(`{"transaction":{"hash":"abc"},"block_hash":"ff","height":4,"status":"ACCEPTED"}`))
+	}))
+	defer srv.Close()
+
+	res, err := New(srv.URL).WaitForTransaction(context.Background(), "abc", time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), res.Height)
+	assert.Equal(t, "abc", res.Transaction.Hash)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls.Store(0)
+	_, err = New(srv.URL).WaitForTransaction(ctx, "abc", time.Millisecond)
+	assert.Error(t, err)
+}
+
+func TestClient_ChainConfig(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/config", r.URL.Path)
+		w.Write([]byte(`{"chain_id":77,"max_tx_per_block":500,"consensus":"poa"}`))
+	}))
+	defer srv.Close()
+
+	cfg, err := New(srv.URL).ChainConfig(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(77), cfg.ChainID)
+	assert.Equal(t, uint64(500), cfg.MaxTxPerBlock)
+}
+
+func TestClient_EstimateFee(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/fees/estimate", r.URL.Path)
+		assert.Equal(t, "2", r.URL.Query().Get("blocks"))
+		w.Write([]byte(`{"target_blocks":2,"low":3,"medium":5,"high":9,"pending_transactions":7}`))
+	}))
+	defer srv.Close()
+
+	e, err := New(srv.URL).EstimateFee(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), e.TargetBlocks)
+	assert.Equal(t, int64(5), e.Medium)
+	assert.Equal(t, int64(9), e.High)
+	assert.Equal(t, uint64(7), e.PendingTransactions)
+}
+
+func TestClient_TransactionStatusEscapesHash(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/transactions/a%2Fb/status", r.URL.EscapedPath())
+		w.Write([]byte(`{"hash":"a/b","status":"pending"}`))
+	}))
+	defer srv.Close()
+
+	res, err := New(srv.URL).TransactionStatus(context.Background(), "a/b")
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", res.Status)
+}
+
+func TestClient_WaitForTransactionDropped(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/transactions/abc/status" {
+			w.Write([]byte(`{"hash":"abc","status":"dropped","reason":"expired","dropped_at":100}`))
+			return
+		}
+		http.Error(w, "could not get transaction", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	st, err := New(srv.URL).TransactionStatus(context.Background(), "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, LifecycleDropped, st.Status)
+	assert.Equal(t, int64(100), st.DroppedAt)
+
+	_, err = New(srv.URL).WaitForTransaction(context.Background(), "abc", time.Millisecond)
+	assert.ErrorIs(t, err, ErrDropped)
+	assert.ErrorContains(t, err, "expired")
+}
@@ -0,0 +1,301 @@
+// Package lightclient follows the chain by block headers only. It checks that
+// every header extends the previous one and is correctly sealed, and uses the
+// roots of verified headers to check transaction and account proofs served by
+// an untrusted node.
+package lightclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"com.perkunas/pkg/proof"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	ConsensusPoW = "pow"
+	ConsensusPoA = "poa"
+)
+
+var (
+	ErrUnknownHeader     = errors.New("header is not known to the light client")
+	ErrInvalidLinkage    = errors.New("header does not extend the known chain")
+	ErrInvalidHeaderHash = errors.New("header hash does not match its contents")
+	ErrInsufficientWork  = errors.New("header does not satisfy proof of work")
+	ErrInvalidSigner     = errors.New("header is not signed by the scheduled authority")
+	ErrProofMismatch     = errors.New("proof does not belong to requested item")
+)
+
+// Header is the part of a block a light client keeps.
+type Header struct {
+	Hash       string `json:"hash"`
+	PrevHash   string `json:"prev_hash"`
+	MerkleRoot string `json:"merkle_root"`
+	StateRoot  string `json:"state_root"`
+	Timestamp  int64  `json:"timestamp"`
+	Height     uint64 `json:"height"`
+	Nonce      uint64 `json:"nonce"`
+	Difficulty uint64 `json:"difficulty"`
+	Miner      string `json:"miner"`
+	Signature  string `json:"signature"`
+}
+
+// CalculateHash hashes the header fields the same way full nodes hash blocks.
+func (h *Header) CalculateHash() string {
+	hasher := sha256.New()
+	hasher.Write([]byte(h.PrevHash))
+	binary.Write(hasher, binary.LittleEndian, h.Timestamp)
+	binary.Write(hasher, binary.LittleEndian, h.Height)
+	binary.Write(hasher, binary.LittleEndian, h.Nonce)
+	hasher.Write([]byte(h.MerkleRoot))
+	hasher.Write([]byte(h.Miner))
+	hasher.Write([]byte(h.StateRoot))
+
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// Config holds the consensus rules the client enforces. Field names match the
+// node's GET /config response.
+type Config struct {
+	Consensus  string   `json:"consensus"`
+	Difficulty uint64   `json:"initial_difficulty"`
+	BlockTime  uint64   `json:"block_time"`
+	Signers    []string `json:"signers"`
+}
+
+// Source serves headers and proofs, typically a node (see HTTPSource).
+type Source interface {
+	LatestHeader(ctx context.Context) (*Header, error)
+	Header(ctx context.Context, height uint64) (*Header, error)
+	TransactionProof(ctx context.Context, txHash string) (*proof.TxProof, error)
+	AccountProof(ctx context.Context, address string, height uint64) (*proof.AccountProof, error)
+	StorageProof(ctx context.Context, address, key string, height uint64) (*proof.AccountProof, error)
+}
+
+type Client struct {
+	cfg     Config
+	src     Source
+	mu      sync.RWMutex
+	headers map[uint64]*Header
+	tip     *Header
+}
+
+// New starts a client from a trusted header, usually genesis or a checkpoint.
+func New(cfg Config, trusted *Header, src Source) *Client {
+	return &Client{
+		cfg:     cfg,
+		src:     src,
+		headers: map[uint64]*Header{trusted.Height: trusted},
+		tip:     trusted,
+	}
+}
+
+// Tip returns the highest verified header.
+func (c *Client) Tip() *Header {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tip
+}
+
+// Header returns the verified header at height.
+func (c *Client) Header(height uint64) (*Header, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	h, ok := c.headers[height]
+	if !ok {
+		return nil, ErrUnknownHeader
+	}
+
+	return h, nil
+}
+
+// Confirmations returns how many verified headers sit on top of height,
+// counting the block itself.
+func (c *Client) Confirmations(height uint64) uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if height > c.tip.Height {
+		return 0
+	}
+
+	return c.tip.Height - height + 1
+}
+
+// AddHeader verifies h extends the current tip and appends it.
+func (c *Client) AddHeader(h *Header) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.verify(c.tip, h); err != nil {
+		return fmt.Errorf("header %d: %w", h.Height, err)
+	}
+
+	c.headers[h.Height] = h
+	c.tip = h
+	return nil
+}
+
+// Sync downloads and verifies headers from the tip up to the source's latest.
+func (c *Client) Sync(ctx context.Context) error {
+	latest, err := c.src.LatestHeader(ctx)
+	if err != nil {
+		return fmt.Errorf("failed getting latest header %w", err)
+	}
+
+	for height := c.Tip().Height + 1; height <= latest.Height; height++ {
+		h, err := c.src.Header(ctx, height)
+		if err != nil {
+			return fmt.Errorf("failed getting header %d %w", height, err)
+		}
+
+		if err := c.AddHeader(h); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// VerifyTransaction fetches the inclusion proof of txHash and checks it
+// against the merkle root of the verified header it claims to be in.
+func (c *Client) VerifyTransaction(ctx context.Context, txHash string) (*proof.TxProof, error) {
+	p, err := c.src.TransactionProof(ctx, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed getting transaction proof %w", err)
+	}
+
+	if p.TxHash != txHash {
+		return nil, ErrProofMismatch
+	}
+
+	h, err := c.Header(p.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.Hash != p.BlockHash {
+		return nil, ErrProofMismatch
+	}
+
+	if err := proof.VerifyTransaction(h.MerkleRoot, p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// VerifyAccount fetches the state proof of address at height and checks it
+// against the state root of the verified header.
+func (c *Client) VerifyAccount(ctx context.Context, address string, height uint64) (*proof.AccountProof, error) {
+	h, err := c.Header(height)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := c.src.AccountProof(ctx, address, height)
+	if err != nil {
+		return nil, fmt.Errorf("failed getting account proof %w", err)
+	}
+
+	if err := verifyAccount(h, p, address); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// VerifyStorage fetches the state proof of address at height together with
+// the storage item key, such as token_balance/<token>, and checks both
+// against the state root of the verified header.
+func (c *Client) VerifyStorage(ctx context.Context, address, key string, height uint64) (*proof.AccountProof, error) {
+	h, err := c.Header(height)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := c.src.StorageProof(ctx, address, key, height)
+	if err != nil {
+		return nil, fmt.Errorf("failed getting storage proof %w", err)
+	}
+
+	if p.Storage == nil || p.Storage.Key != key {
+		return nil, ErrProofMismatch
+	}
+
+	if err := verifyAccount(h, p, address); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func verifyAccount(h *Header, p *proof.AccountProof, address string) error {
+	if !strings.EqualFold(p.Address, address) || p.Height != h.Height {
+		return ErrProofMismatch
+	}
+
+	return proof.VerifyAccount(h.StateRoot, p)
+}
+
+func (c *Client) verify(parent, h *Header) error {
+	if h.PrevHash != parent.Hash || h.Height != parent.Height+1 || h.Timestamp < parent.Timestamp {
+		return ErrInvalidLinkage
+	}
+
+	if h.CalculateHash() != h.Hash {
+		return ErrInvalidHeaderHash
+	}
+
+	switch c.cfg.Consensus {
+	case ConsensusPoA:
+		return c.verifyAuthority(parent, h)
+	default:
+		return c.verifyWork(h)
+	}
+}
+
+func (c *Client) verifyWork(h *Header) error {
+	if h.Difficulty < c.cfg.Difficulty || !strings.HasPrefix(h.Hash, strings.Repeat("0", int(h.Difficulty))) {
+		return ErrInsufficientWork
+	}
+
+	return nil
+}
+
+func (c *Client) verifyAuthority(parent, h *Header) error {
+	if len(c.cfg.Signers) == 0 || h.Timestamp < parent.Timestamp+int64(c.cfg.BlockTime) {
+		return ErrInvalidSigner
+	}
+
+	digest, err := hex.DecodeString(h.Hash)
+	if err != nil {
+		return ErrInvalidHeaderHash
+	}
+
+	sig, err := hex.DecodeString(h.Signature)
+	if err != nil {
+		return ErrInvalidSigner
+	}
+
+	pubKey, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		return ErrInvalidSigner
+	}
+
+	signer := crypto.PubkeyToAddress(*pubKey)
+	inTurn := common.HexToAddress(c.cfg.Signers[h.Height%uint64(len(c.cfg.Signers))])
+	if signer != inTurn || signer != common.HexToAddress(h.Miner) {
+		return ErrInvalidSigner
+	}
+
+	return nil
+}
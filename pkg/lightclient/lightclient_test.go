@@ -0,0 +1,163 @@
+package lightclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"com.perkunas/internal/consensus"
+	"com.perkunas/internal/models/block"
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/internal/smt"
+	"com.perkunas/pkg/proof"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSource struct {
+	blocks  []*block.Block
+	state   map[uint64]*smt.Tree
+	storage *smt.Tree
+}
+
+func (f *fakeSource) LatestHeader(ctx context.Context) (*Header, error) {
+	return toHeader(f.blocks[len(f.blocks)-1]), nil
+}
+
+func (f *fakeSource) Header(ctx context.Context, height uint64) (*Header, error) {
+	return toHeader(f.blocks[height]), nil
+}
+
+func (f *fakeSource) TransactionProof(ctx context.Context, txHash string) (*proof.TxProof, error) {
+	for _, b := range f.blocks {
+		if p, err := b.MerkleProof(txHash); err == nil {
+			return p, nil
+		}
+	}
+
+	return nil, ErrUnknownHeader
+}
+
+func (f *fakeSource) AccountProof(ctx context.Context, address string, height uint64) (*proof.AccountProof, error) {
+	p := f.state[height].Prove(address)
+	p.Height = height
+	return p, nil
+}
+
+func (f *fakeSource) StorageProof(ctx context.Context, address, key string, height uint64) (*proof.AccountProof, error) {
+	p, _ := f.AccountProof(ctx, address, height)
+	p.Storage = f.storage.ProveStorage(key)
+	return p, nil
+}
+
+func toHeader(b *block.Block) *Header {
+	return &Header{
+		Hash:       b.Hash,
+		PrevHash:   b.PrevHash,
+		MerkleRoot: b.MerkleRoot,
+		StateRoot:  b.StateRoot,
+		Timestamp:  b.Timestamp,
+		Height:     b.Height,
+		Nonce:      b.Nonce,
+		Difficulty: b.Difficulty,
+		Miner:      b.Miner,
+		Signature:  b.Signature,
+	}
+}
+
+const (
+	addr  = "0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19b2"
+	token = "0xE07cD67682C4b43bEF6b399bb7C180D975571aaa"
+)
+
+func testChain(t *testing.T, length int) *fakeSource {
+	t.Helper()
+
+	engine := consensus.NewPoW(2, nil)
+	src := &fakeSource{state: map[uint64]*smt.Tree{}}
+	src.storage = smt.NewStorage([]smt.Item{{Key: "token_balance/" + token, Value: "7"}})
+
+	genesisState := smt.New([]smt.Leaf{{Address: addr, Balance: 100}})
+	genesis := &block.Block{Timestamp: time.Now().Unix(), StateRoot: genesisState.Root()}
+	genesis.Hash, _ = genesis.CalculateHash()
+	src.blocks = append(src.blocks, genesis)
+	src.state[0] = genesisState
+
+	for i := 1; i < length; i++ {
+		parent := src.blocks[i-1]
+		tx := &transaction.Transaction{From: "sender", To: addr, Amount: int64(i), Nonce: uint64(i)}
+		tx.SetHash()
+
+		state := smt.New([]smt.Leaf{{Address: addr, Balance: 100 + int64(i), StorageRoot: src.storage.Root()}})
+		b := &block.Block{
+			PrevHash:     parent.Hash,
+			Height:       parent.Height + 1,
+			Timestamp:    parent.Timestamp + 1,
+			StateRoot:    state.Root(),
+			Transactions: []*transaction.Transaction{tx},
+		}
+
+		assert.NoError(t, engine.Prepare(parent, b))
+		assert.NoError(t, engine.Seal(context.Background(), b))
+		src.blocks = append(src.blocks, b)
+		src.state[b.Height] = state
+	}
+
+	return src
+}
+
+func TestHeader_CalculateHashMatchesBlock(t *testing.T) {
+	src := testChain(t, 3)
+	for _, b := range src.blocks {
+		assert.Equal(t, b.Hash, toHeader(b).CalculateHash())
+	}
+}
+
+func TestClient_SyncAndVerify(t *testing.T) {
+	src := testChain(t, 4)
+	c := New(Config{Consensus: ConsensusPoW, Difficulty: 2}, toHeader(src.blocks[0]), src)
+
+	assert.NoError(t, c.Sync(context.Background()))
+	assert.Equal(t, uint64(3), c.Tip().Height)
+	assert.Equal(t, uint64(2), c.Confirmations(2))
+
+	txHash := src.blocks[2].Transactions[0].Hash
+	p, err := c.VerifyTransaction(context.Background(), txHash)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), p.Height)
+
+	acc, err := c.VerifyAccount(context.Background(), addr, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(103), acc.Balance)
+
+	acc, err = c.VerifyStorage(context.Background(), addr, "token_balance/"+token, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "7", acc.Storage.Value)
+
+	// genesis committed no storage for addr
+	_, err = c.VerifyStorage(context.Background(), addr, "token_balance/"+token, 0)
+	assert.ErrorIs(t, err, proof.ErrRootMismatch)
+}
+
+func TestClient_RejectsBadHeaders(t *testing.T) {
+	src := testChain(t, 3)
+	c := New(Config{Consensus: ConsensusPoW, Difficulty: 2}, toHeader(src.blocks[0]), src)
+
+	skipped := toHeader(src.blocks[2])
+	assert.ErrorIs(t, c.AddHeader(skipped), ErrInvalidLinkage)
+
+	tampered := toHeader(src.blocks[1])
+	tampered.StateRoot = "00"
+	assert.ErrorIs(t, c.AddHeader(tampered), ErrInvalidHeaderHash)
+
+	// a node can not lower the difficulty the client enforces
+	strict := New(Config{Consensus: ConsensusPoW, Difficulty: 3}, toHeader(src.blocks[0]), src)
+	assert.ErrorIs(t, strict.AddHeader(toHeader(src.blocks[1])), ErrInsufficientWork)
+}
+
+func TestClient_RejectsProofForUnknownHeader(t *testing.T) {
+	src := testChain(t, 3)
+	c := New(Config{Consensus: ConsensusPoW, Difficulty: 2}, toHeader(src.blocks[0]), src)
+
+	_, err := c.VerifyTransaction(context.Background(), src.blocks[1].Transactions[0].Hash)
+	assert.ErrorIs(t, err, ErrUnknownHeader)
+}
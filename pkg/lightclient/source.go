@@ -0,0 +1,88 @@
+package lightclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"com.perkunas/pkg/proof"
+)
+
+// HTTPSource reads headers and proofs from a node's REST API.
+type HTTPSource struct {
+	NodeURL string
+	Client  *http.Client
+}
+
+func NewHTTPSource(nodeURL string) *HTTPSource {
+	return &HTTPSource{
+		NodeURL: strings.TrimRight(nodeURL, "/"),
+		Client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Config fetches the chain's consensus rules. Callers that do not trust the
+// node should pin a Config instead.
+func (s *HTTPSource) Config(ctx context.Context) (Config, error) {
+	var cfg Config
+	return cfg, s.get(ctx, "/config", &cfg)
+}
+
+func (s *HTTPSource) LatestHeader(ctx context.Context) (*Header, error) {
+	var res struct {
+		Block *Header `json:"block"`
+	}
+
+	if err := s.get(ctx, "/status", &res); err != nil {
+		return nil, err
+	}
+
+	if res.Block == nil {
+		return nil, ErrUnknownHeader
+	}
+
+	return res.Block, nil
+}
+
+func (s *HTTPSource) Header(ctx context.Context, height uint64) (*Header, error) {
+	var h Header
+	return &h, s.get(ctx, fmt.Sprintf("/headers/%d", height), &h)
+}
+
+func (s *HTTPSource) TransactionProof(ctx context.Context, txHash string) (*proof.TxProof, error) {
+	var p proof.TxProof
+	return &p, s.get(ctx, "/proofs/transactions/"+url.PathEscape(txHash), &p)
+}
+
+func (s *HTTPSource) AccountProof(ctx context.Context, address string, height uint64) (*proof.AccountProof, error) {
+	var p proof.AccountProof
+	return &p, s.get(ctx, fmt.Sprintf("/proofs/accounts/%s?height=%d", url.PathEscape(address), height), &p)
+}
+
+func (s *HTTPSource) StorageProof(ctx context.Context, address, key string, height uint64) (*proof.AccountProof, error) {
+	var p proof.AccountProof
+	return &p, s.get(ctx, fmt.Sprintf("/proofs/accounts/%s?height=%d&storage=%s", url.PathEscape(address), height, url.QueryEscape(key)), &p)
+}
+
+func (s *HTTPSource) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.NodeURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("node responded %s to %s", res.Status, path)
+	}
+
+	return json.NewDecoder(res.Body).Decode(out)
+}
@@ -0,0 +1,237 @@
+// Package proof verifies that a transaction is included in a block and that an
+// account has a given balance/nonce, or keeps a given item such as a token
+// balance, in a block's state, using only the roots from a trusted block
+// header.
+package proof
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// StateDepth is the depth of the sparse Merkle tree behind block state roots.
+const StateDepth = 256
+
+var (
+	ErrInvalidProof = errors.New("invalid proof encoding")
+	ErrRootMismatch = errors.New("proof does not match root")
+
+	leafPrefix = []byte{0x00}
+	nodePrefix = []byte{0x01}
+
+	// defaults[d] is the hash of an empty state subtree rooted at depth d
+	defaults = func() [][]byte {
+		res := make([][]byte, StateDepth+1)
+		res[StateDepth] = make([]byte, 32)
+		for d := StateDepth - 1; d >= 0; d-- {
+			res[d] = HashNode(res[d+1], res[d+1])
+		}
+		return res
+	}()
+)
+
+// TxProof is the path from a transaction hash to a block's merkle root.
+type TxProof struct {
+	TxHash     string   `json:"tx_hash"`
+	BlockHash  string   `json:"block_hash"`
+	Height     uint64   `json:"height"`
+	MerkleRoot string   `json:"merkle_root"`
+	Index      uint64   `json:"index"`
+	Siblings   []string `json:"siblings"`
+}
+
+// AccountProof is the path from an account's state to a block's state root.
+// Only siblings that are not empty subtrees are included, Bitmap marks at
+// which depths they sit. StorageRoot commits to everything else kept at the
+// address, empty when there is nothing, and Storage proves one item of it.
+type AccountProof struct {
+	Address     string        `json:"address"`
+	Balance     int64         `json:"balance"`
+	Nonce       uint64        `json:"nonce"`
+	StorageRoot string        `json:"storage_root"`
+	Exists      bool          `json:"exists"`
+	Height      uint64        `json:"height"`
+	StateRoot   string        `json:"state_root"`
+	Bitmap      string        `json:"bitmap"`
+	Siblings    []string      `json:"siblings"`
+	Storage     *StorageProof `json:"storage,omitempty"`
+}
+
+// StorageProof is the path from one item kept at an address, such as a token
+// balance or a contract storage slot, to the account's storage root. Keys
+// are described with the state service that commits them.
+type StorageProof struct {
+	Key      string   `json:"key"`
+	Value    string   `json:"value"`
+	Exists   bool     `json:"exists"`
+	Bitmap   string   `json:"bitmap"`
+	Siblings []string `json:"siblings"`
+}
+
+// VerifyTransaction checks that p links p.TxHash to merkleRoot.
+func VerifyTransaction(merkleRoot string, p *TxProof) error {
+	cur := p.TxHash
+	idx := p.Index
+	for _, sibling := range p.Siblings {
+		if idx%2 == 0 {
+			cur = HashPair(cur, sibling)
+		} else {
+			cur = HashPair(sibling, cur)
+		}
+		idx /= 2
+	}
+
+	if idx != 0 || cur != merkleRoot {
+		return ErrRootMismatch
+	}
+
+	return nil
+}
+
+// VerifyAccount checks that p links the account state it claims to stateRoot,
+// and the storage item it carries to the account's storage root. A proof
+// with Exists false proves the account is absent from the state.
+func VerifyAccount(stateRoot string, p *AccountProof) error {
+	storageRoot, err := decodeRoot(p.StorageRoot)
+	if err != nil {
+		return err
+	}
+	if !p.Exists && storageRoot != nil {
+		// an absent account keeps nothing, the root would go unchecked
+		return ErrInvalidProof
+	}
+
+	key := Key(p.Address)
+	leaf := defaults[StateDepth]
+	if p.Exists {
+		leaf = HashLeaf(key, p.Balance, p.Nonce, storageRoot)
+	}
+
+	if err := verifyPath(stateRoot, key, leaf, p.Bitmap, p.Siblings); err != nil {
+		return err
+	}
+
+	if p.Storage == nil {
+		return nil
+	}
+
+	if storageRoot == nil {
+		storageRoot = defaults[0]
+	}
+	return VerifyStorage(hex.EncodeToString(storageRoot), p.Storage)
+}
+
+// VerifyStorage checks that p links the storage item it claims to
+// storageRoot. A proof with Exists false proves the key is not set.
+func VerifyStorage(storageRoot string, p *StorageProof) error {
+	key := StorageKey(p.Key)
+	leaf := defaults[StateDepth]
+	if p.Exists {
+		leaf = HashItem(key, []byte(p.Value))
+	}
+
+	return verifyPath(storageRoot, key, leaf, p.Bitmap, p.Siblings)
+}
+
+// verifyPath hashes leaf up to the root along key, taking the siblings the
+// bitmap marks from siblings and empty subtrees for the rest.
+func verifyPath(root string, key, leaf []byte, encodedBitmap string, siblings []string) error {
+	bitmap, err := hex.DecodeString(encodedBitmap)
+	if err != nil || len(bitmap) != StateDepth/8 {
+		return ErrInvalidProof
+	}
+
+	cur := leaf
+	next := len(siblings) - 1
+	for d := StateDepth - 1; d >= 0; d-- {
+		sibling := defaults[d+1]
+		if bit(bitmap, d) {
+			if next < 0 {
+				return ErrInvalidProof
+			}
+
+			sibling, err = hex.DecodeString(siblings[next])
+			if err != nil {
+				return ErrInvalidProof
+			}
+			next--
+		}
+
+		if bit(key, d) {
+			cur = HashNode(sibling, cur)
+		} else {
+			cur = HashNode(cur, sibling)
+		}
+	}
+
+	if next != -1 || hex.EncodeToString(cur) != root {
+		return ErrRootMismatch
+	}
+
+	return nil
+}
+
+func decodeRoot(root string) ([]byte, error) {
+	if root == "" {
+		return nil, nil
+	}
+
+	b, err := hex.DecodeString(root)
+	if err != nil || len(b) != 32 {
+		return nil, ErrInvalidProof
+	}
+
+	return b, nil
+}
+
+// HashPair combines two hex encoded nodes of a block's transaction tree.
+func HashPair(left, right string) string {
+	hasher := sha256.New()
+	hasher.Write([]byte(left))
+	hasher.Write([]byte(right))
+	hash := hasher.Sum(nil)
+	return hex.EncodeToString(hash)
+}
+
+// Key maps an address to its position in the state tree.
+func Key(address string) []byte {
+	return crypto.Keccak256(common.HexToAddress(address).Bytes())
+}
+
+// StorageKey maps a storage item key to its position in a storage tree.
+func StorageKey(key string) []byte {
+	return crypto.Keccak256([]byte(key))
+}
+
+// HashLeaf hashes an account's state. Accounts without a storage root hash
+// only their balance and nonce.
+func HashLeaf(key []byte, balance int64, nonce uint64, storageRoot []byte) []byte {
+	buf := make([]byte, 16)
+	binary.BigEndian.PutUint64(buf[:8], uint64(balance))
+	binary.BigEndian.PutUint64(buf[8:], nonce)
+	return crypto.Keccak256(leafPrefix, key, buf, storageRoot)
+}
+
+// HashItem hashes a storage item.
+func HashItem(key, value []byte) []byte {
+	return crypto.Keccak256(leafPrefix, key, crypto.Keccak256(value))
+}
+
+func HashNode(left, right []byte) []byte {
+	return crypto.Keccak256(nodePrefix, left, right)
+}
+
+// EmptyHash returns the hash of an empty state subtree rooted at depth.
+func EmptyHash(depth int) []byte {
+	return defaults[depth]
+}
+
+// bit reports whether the i-th most significant bit of b is set.
+func bit(b []byte, i int) bool {
+	return b[i/8]&(0x80>>(i%8)) != 0
+}
@@ -0,0 +1,46 @@
+package proof
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyTransaction(t *testing.T) {
+	leaves := []string{"aa", "bb", "cc", "cc"}
+	left := HashPair(leaves[0], leaves[1])
+	right := HashPair(leaves[2], leaves[3])
+	root := HashPair(left, right)
+
+	p := &TxProof{TxHash: "cc", Index: 2, Siblings: []string{"cc", left}}
+	assert.NoError(t, VerifyTransaction(root, p))
+
+	p.Index = 3
+	assert.NoError(t, VerifyTransaction(root, p))
+
+	p.Index = 0
+	assert.ErrorIs(t, VerifyTransaction(root, p), ErrRootMismatch)
+
+	p = &TxProof{TxHash: "bb", Index: 1, Siblings: []string{"aa", right}}
+	assert.NoError(t, VerifyTransaction(root, p))
+	assert.ErrorIs(t, VerifyTransaction(left, p), ErrRootMismatch)
+}
+
+func TestVerifyAccount_InvalidEncoding(t *testing.T) {
+	p := &AccountProof{Address: "0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19b2", Bitmap: "zz"}
+	assert.ErrorIs(t, VerifyAccount("", p), ErrInvalidProof)
+
+	// bitmap claims a sibling the proof does not carry
+	p.Bitmap = "80" + strings.Repeat("00", StateDepth/8-1)
+	assert.ErrorIs(t, VerifyAccount("", p), ErrInvalidProof)
+}
+
+func TestVerifyAccount_AbsentWithStorage(t *testing.T) {
+	p := &AccountProof{
+		Address:     "0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19b2",
+		StorageRoot: strings.Repeat("ab", 32),
+		Bitmap:      strings.Repeat("00", StateDepth/8),
+	}
+	assert.ErrorIs(t, VerifyAccount("", p), ErrInvalidProof)
+}
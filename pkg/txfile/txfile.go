@@ -0,0 +1,133 @@
+// Package txfile is the file format used to move transactions between the
+// machine that builds them, air-gapped signers and the machine that
+// broadcasts them.
+package txfile
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"com.perkunas/internal/models/transaction"
+)
+
+// Version is bumped whenever a reader of the previous version would
+// misinterpret a file. Readers reject versions they do not know.
+const Version = 1
+
+var (
+	ErrUnsupportedVersion = errors.New("unsupported transaction file version")
+	ErrHashMismatch       = errors.New("transaction hash does not match its contents")
+)
+
+// File wraps a transaction with the information the builder got from the
+// node. Meta is informational, only the transaction is signed.
+type File struct {
+	Version     int                     `json:"version"`
+	Transaction transaction.Transaction `json:"transaction"`
+	Meta        *Meta                   `json:"meta,omitempty"`
+}
+
+// Meta records what the nonce and fee were derived from when the file was built.
+type Meta struct {
+	Node           string `json:"node,omitempty"`
+	BuiltAt        int64  `json:"built_at,omitempty"`
+	ConfirmedNonce uint64 `json:"confirmed_nonce"`
+	PendingTxs     int    `json:"pending_txs"`
+	SuggestedFee   int64  `json:"suggested_fee"`
+}
+
+func New(tx *transaction.Transaction, meta *Meta) *File {
+	return &File{Version: Version, Transaction: *tx, Meta: meta}
+}
+
+// Marshal encodes f with a fixed field order and indentation so files diff
+// cleanly between signers.
+func Marshal(f *File) ([]byte, error) {
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(b, '\n'), nil
+}
+
+// Unmarshal decodes a transaction file. A bare transaction, as printed by
+// sign-tx, is accepted as well.
+func Unmarshal(data []byte) (*File, error) {
+	var probe struct {
+		Version *int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+
+	if probe.Version == nil {
+		var tx transaction.Transaction
+		if err := strictUnmarshal(data, &tx); err != nil {
+			return nil, err
+		}
+		return New(&tx, nil), nil
+	}
+
+	if *probe.Version != Version {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, *probe.Version)
+	}
+
+	var f File
+	if err := strictUnmarshal(data, &f); err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+// Check verifies that the hash in the file matches the transaction, so a
+// signer never signs something other than what the file claims.
+func (f *File) Check() error {
+	if f.Transaction.Hash != hex.EncodeToString(f.Transaction.CalculateHash()) {
+		return ErrHashMismatch
+	}
+
+	return nil
+}
+
+// Signed reports whether the transaction carries a sender signature or, for
+// multisig spends, at least one owner signature.
+func (f *File) Signed() bool {
+	return f.Transaction.Signature != "" || f.Transaction.IsMultisig()
+}
+
+func Read(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return f, nil
+}
+
+func Write(path string, f *File) error {
+	data, err := Marshal(f)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+// strictUnmarshal rejects unknown fields, a file written by a newer tool
+// must not be signed with parts silently dropped.
+func strictUnmarshal(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
@@ -0,0 +1,73 @@
+package txfile
+
+import (
+	"path/filepath"
+	"testing"
+
+	"com.perkunas/internal/models/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+func testTx() *transaction.Transaction {
+	tx := &transaction.Transaction{
+		From:      "0xE07cD67682C4b43bEF6b399bb7C180D975571aaa",
+		To:        "0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19b2",
+		Amount:    999,
+		Fee:       5,
+		Nonce:     1,
+		Timestamp: 1700000000,
+		Expires:   1700086400,
+	}
+	tx.SetHash()
+
+	return tx
+}
+
+func TestWriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tx.json")
+	f := New(testTx(), &Meta{ConfirmedNonce: 0, SuggestedFee: 5})
+
+	assert.NoError(t, Write(path, f))
+
+	read, err := Read(path)
+	assert.NoError(t, err)
+	assert.Equal(t, f, read)
+	assert.NoError(t, read.Check())
+	assert.False(t, read.Signed())
+}
+
+func TestMarshal_Stable(t *testing.T) {
+	data, err := Marshal(New(testTx(), nil))
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "version": 1,
+  "transaction": {
+    "id": 0,
+    "hash": "53383375632170df16eba5d0659c2d19d06b6cfaca225cb22cd9b6615b59241a",
+    "from_addr": "0xE07cD67682C4b43bEF6b399bb7C180D975571aaa",
+    "to_addr": "0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19b2",
+    "signature": "",
+    "amount": 999,
+    "fee": 5,
+    "nonce": 1,
+    "timestamp": 1700000000,
+    "expires": 1700086400
+  }
+}
+`, string(data))
+}
+
+func TestUnmarshal(t *testing.T) {
+	// bare transactions printed by sign-tx
+	f, err := Unmarshal([]byte(`{"hash": "abc", "from_addr": "0x1", "amount": 3}`))
+	assert.NoError(t, err)
+	assert.Equal(t, Version, f.Version)
+	assert.Equal(t, int64(3), f.Transaction.Amount)
+	assert.ErrorIs(t, f.Check(), ErrHashMismatch)
+
+	_, err = Unmarshal([]byte(`{"version": 2, "transaction": {}}`))
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+
+	_, err = Unmarshal([]byte(`{"version": 1, "transaction": {"gas": 1}}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,202 @@
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// Wallets are derived following BIP-32 from a BIP-39 seed. The BIP-44 path
+// uses Ethereum's coin type so a phrase restores the same addresses in
+// other Ethereum wallets.
+const (
+	// DefaultBasePath is the BIP-44 account path, addresses are its children.
+	DefaultBasePath = "m/44'/60'/0'/0"
+	// HardenedOffset marks a path index as hardened.
+	HardenedOffset uint32 = 0x80000000
+
+	mnemonicEntropyBits = 256
+)
+
+var (
+	ErrInvalidMnemonic = errors.New("invalid mnemonic")
+	ErrInvalidPath     = errors.New("invalid derivation path")
+	ErrInvalidChildKey = errors.New("derived key is invalid, use the next index")
+)
+
+var masterKeySalt = []byte("Bitcoin seed")
+
+// HDKey is an extended private key, a node in a BIP-32 tree.
+type HDKey struct {
+	key       []byte
+	chainCode []byte
+}
+
+// NewMnemonic generates a random 24 word BIP-39 phrase.
+func NewMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(mnemonicEntropyBits)
+	if err != nil {
+		return "", err
+	}
+
+	return bip39.NewMnemonic(entropy)
+}
+
+// ValidateMnemonic reports whether the phrase has valid words and checksum.
+func ValidateMnemonic(mnemonic string) bool {
+	return bip39.IsMnemonicValid(normalizeMnemonic(mnemonic))
+}
+
+// NewMasterKey restores the root of the tree from a mnemonic and an optional
+// passphrase.
+func NewMasterKey(mnemonic, passphrase string) (*HDKey, error) {
+	mnemonic = normalizeMnemonic(mnemonic)
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, ErrInvalidMnemonic
+	}
+
+	return NewMasterKeyFromSeed(bip39.NewSeed(mnemonic, passphrase))
+}
+
+// NewMasterKeyFromSeed creates the root of the tree from a raw seed.
+func NewMasterKeyFromSeed(seed []byte) (*HDKey, error) {
+	mac := hmac.New(sha512.New, masterKeySalt)
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	if !validKey(sum[:32]) {
+		return nil, ErrInvalidChildKey
+	}
+
+	return &HDKey{key: sum[:32], chainCode: sum[32:]}, nil
+}
+
+// Child derives the child at index, hardened when index >= HardenedOffset.
+func (k *HDKey) Child(index uint32) (*HDKey, error) {
+	data := make([]byte, 0, 37)
+	if index >= HardenedOffset {
+		data = append(data, 0x00)
+		data = append(data, k.key...)
+	} else {
+		priv, err := crypto.ToECDSA(k.key)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, crypto.CompressPubkey(&priv.PublicKey)...)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	if !validKey(sum[:32]) {
+		return nil, ErrInvalidChildKey
+	}
+
+	n := crypto.S256().Params().N
+	child := new(big.Int).SetBytes(sum[:32])
+	child.Add(child, new(big.Int).SetBytes(k.key))
+	child.Mod(child, n)
+	if child.Sign() == 0 {
+		return nil, ErrInvalidChildKey
+	}
+
+	return &HDKey{key: child.FillBytes(make([]byte, 32)), chainCode: sum[32:]}, nil
+}
+
+// Derive walks path, e.g. "m/44'/60'/0'/0/1", from k.
+func (k *HDKey) Derive(path string) (*HDKey, error) {
+	indexes, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key := k
+	for _, i := range indexes {
+		if key, err = key.Child(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return key, nil
+}
+
+// PrivateKey returns the key of this node.
+func (k *HDKey) PrivateKey() (*ecdsa.PrivateKey, error) {
+	return crypto.ToECDSA(k.key)
+}
+
+// Wallet returns a wallet for the key of this node.
+func (k *HDKey) Wallet() (*Wallet, error) {
+	priv, err := k.PrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return fromECDSA(priv), nil
+}
+
+// FromMnemonic restores the wallet at index under DefaultBasePath.
+func FromMnemonic(mnemonic, passphrase string, index uint32) (*Wallet, error) {
+	master, err := NewMasterKey(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := master.Derive(AccountPath(index))
+	if err != nil {
+		return nil, err
+	}
+
+	return key.Wallet()
+}
+
+// AccountPath is the derivation path of the address at index.
+func AccountPath(index uint32) string {
+	return fmt.Sprintf("%s/%d", DefaultBasePath, index)
+}
+
+// ParsePath parses a BIP-32 path. Hardened indexes are marked with ' or h.
+func ParsePath(path string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if len(parts) == 0 || parts[0] != "m" {
+		return nil, fmt.Errorf("%w: %q must start at m", ErrInvalidPath, path)
+	}
+
+	indexes := make([]uint32, 0, len(parts)-1)
+	for _, p := range parts[1:] {
+		offset := uint32(0)
+		if strings.HasSuffix(p, "'") || strings.HasSuffix(p, "h") {
+			offset = HardenedOffset
+			p = p[:len(p)-1]
+		}
+
+		i, err := strconv.ParseUint(p, 10, 32)
+		if err != nil || uint32(i) >= HardenedOffset {
+			return nil, fmt.Errorf("%w: bad index %q in %q", ErrInvalidPath, p, path)
+		}
+
+		indexes = append(indexes, uint32(i)+offset)
+	}
+
+	return indexes, nil
+}
+
+func validKey(key []byte) bool {
+	k := new(big.Int).SetBytes(key)
+	return k.Sign() > 0 && k.Cmp(crypto.S256().Params().N) < 0
+}
+
+func normalizeMnemonic(mnemonic string) string {
+	return strings.Join(strings.Fields(strings.ToLower(mnemonic)), " ")
+}
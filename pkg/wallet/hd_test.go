@@ -0,0 +1,58 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHDKey_BIP32Vector(t *testing.T) {
+	// test vector 1 from BIP-32
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := NewMasterKeyFromSeed(seed)
+	assert.NoError(t, err)
+	assert.Equal(t, "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35", hex.EncodeToString(master.key))
+
+	key, err := master.Derive("m/0'/1/2'")
+	assert.NoError(t, err)
+	assert.Equal(t, "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca", hex.EncodeToString(key.key))
+}
+
+func TestFromMnemonic(t *testing.T) {
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+	w, err := FromMnemonic(mnemonic, "", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x9858EfFD232B4033E47d90003D41EC34EcaEda94", w.Address)
+
+	next, err := FromMnemonic(mnemonic, "", 1)
+	assert.NoError(t, err)
+	assert.NotEqual(t, w.Address, next.Address)
+
+	withPassphrase, err := FromMnemonic(mnemonic, "secret", 0)
+	assert.NoError(t, err)
+	assert.NotEqual(t, w.Address, withPassphrase.Address)
+
+	_, err = FromMnemonic("abandon abandon abandon", "", 0)
+	assert.ErrorIs(t, err, ErrInvalidMnemonic)
+}
+
+func TestNewMnemonic(t *testing.T) {
+	mnemonic, err := NewMnemonic()
+	assert.NoError(t, err)
+	assert.True(t, ValidateMnemonic(mnemonic))
+	assert.Len(t, strings.Fields(mnemonic), 24)
+}
+
+func TestParsePath(t *testing.T) {
+	indexes, err := ParsePath("m/44'/60h/0'/0/7")
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{44 + HardenedOffset, 60 + HardenedOffset, HardenedOffset, 0, 7}, indexes)
+
+	for _, path := range []string{"44'/60'", "m/x", "m/2147483648"} {
+		_, err := ParsePath(path)
+		assert.ErrorIs(t, err, ErrInvalidPath, path)
+	}
+}
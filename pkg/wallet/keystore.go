@@ -0,0 +1,313 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Keys are stored in the Web3 Secret Storage (v3) format so they can be used
+// with other Ethereum tooling. The format encrypts with aes-128-ctr and
+// authenticates with a keccak256 MAC over the ciphertext.
+const keystoreVersion = 3
+
+var (
+	ErrDecrypt          = errors.New("could not decrypt key with given password")
+	ErrKeystoreFormat   = errors.New("unsupported keystore format")
+	ErrKeystoreNotFound = errors.New("no key for address in keystore")
+)
+
+// KDFParams are the scrypt cost parameters used to derive the encryption key.
+type KDFParams struct {
+	N int
+	P int
+}
+
+var (
+	// StandardKDF takes about a second and 256MB of memory per key.
+	StandardKDF = KDFParams{N: 1 << 18, P: 1}
+	// LightKDF is for low powered devices and tests.
+	LightKDF = KDFParams{N: 1 << 12, P: 6}
+)
+
+const (
+	scryptR      = 8
+	scryptDKLen  = 32
+	keystoreMode = 0o600
+
+	// keystore files come from anywhere, decrypting one may cost at most
+	// the memory of StandardKDF
+	maxScryptMemory = 128 * scryptR * (1 << 18)
+	maxScryptP      = 16
+)
+
+type keyJSON struct {
+	Address string     `json:"address"`
+	Crypto  cryptoJSON `json:"crypto"`
+	ID      string     `json:"id"`
+	Version int        `json:"version"`
+}
+
+type cryptoJSON struct {
+	Cipher       string       `json:"cipher"`
+	CipherText   string       `json:"ciphertext"`
+	CipherParams cipherParams `json:"cipherparams"`
+	KDF          string       `json:"kdf"`
+	KDFParams    scryptParams `json:"kdfparams"`
+	MAC          string       `json:"mac"`
+}
+
+type cipherParams struct {
+	IV string `json:"iv"`
+}
+
+type scryptParams struct {
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+}
+
+// validate rejects key lengths the cipher and MAC can not use and scrypt
+// costs above maxScryptMemory and maxScryptP.
+func (p scryptParams) validate() error {
+	if p.DKLen != scryptDKLen {
+		return fmt.Errorf("%w: dklen must be %d", ErrKeystoreFormat, scryptDKLen)
+	}
+
+	if p.N <= 1 || p.R < 1 || p.P < 1 || p.P > maxScryptP || p.N > maxScryptMemory/128/p.R {
+		return fmt.Errorf("%w: scrypt parameters out of range", ErrKeystoreFormat)
+	}
+
+	return nil
+}
+
+// KeyFile is a key found in a keystore directory.
+type KeyFile struct {
+	Address string
+	Path    string
+}
+
+// Encrypt serialises the wallet's private key as password protected keystore JSON.
+func (w *Wallet) Encrypt(password string, kdf KDFParams) ([]byte, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	derivedKey, err := scrypt.Key([]byte(password), salt, kdf.N, scryptR, kdf.P, scryptDKLen)
+	if err != nil {
+		return nil, err
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+
+	cipherText, err := aesCTR(derivedKey[:16], iv, crypto.FromECDSA(w.PrivateKey))
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(keyJSON{
+		Address: strings.ToLower(strings.TrimPrefix(w.Address, "0x")),
+		Crypto: cryptoJSON{
+			Cipher:       "aes-128-ctr",
+			CipherText:   hex.EncodeToString(cipherText),
+			CipherParams: cipherParams{IV: hex.EncodeToString(iv)},
+			KDF:          "scrypt",
+			KDFParams: scryptParams{
+				N:     kdf.N,
+				R:     scryptR,
+				P:     kdf.P,
+				DKLen: scryptDKLen,
+				Salt:  hex.EncodeToString(salt),
+			},
+			MAC: hex.EncodeToString(crypto.Keccak256(derivedKey[16:32], cipherText)),
+		},
+		ID:      id.String(),
+		Version: keystoreVersion,
+	})
+}
+
+// Decrypt restores a wallet from keystore JSON.
+func Decrypt(data []byte, password string) (*Wallet, error) {
+	var k keyJSON
+	if err := json.Unmarshal(data, &k); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeystoreFormat, err)
+	}
+
+	if k.Version != keystoreVersion || k.Crypto.Cipher != "aes-128-ctr" || k.Crypto.KDF != "scrypt" {
+		return nil, ErrKeystoreFormat
+	}
+
+	salt, err := hex.DecodeString(k.Crypto.KDFParams.Salt)
+	if err != nil {
+		return nil, ErrKeystoreFormat
+	}
+
+	iv, err := hex.DecodeString(k.Crypto.CipherParams.IV)
+	if err != nil {
+		return nil, ErrKeystoreFormat
+	}
+
+	cipherText, err := hex.DecodeString(k.Crypto.CipherText)
+	if err != nil {
+		return nil, ErrKeystoreFormat
+	}
+
+	mac, err := hex.DecodeString(k.Crypto.MAC)
+	if err != nil {
+		return nil, ErrKeystoreFormat
+	}
+
+	if len(iv) != aes.BlockSize {
+		return nil, ErrKeystoreFormat
+	}
+
+	p := k.Crypto.KDFParams
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	derivedKey, err := scrypt.Key([]byte(password), salt, p.N, p.R, p.P, p.DKLen)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(crypto.Keccak256(derivedKey[16:32], cipherText), mac) {
+		return nil, ErrDecrypt
+	}
+
+	keyBytes, err := aesCTR(derivedKey[:16], iv, cipherText)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := FromPrivateKey(hex.EncodeToString(keyBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	if k.Address != "" && common.HexToAddress(k.Address).Hex() != w.Address {
+		return nil, ErrDecrypt
+	}
+
+	return w, nil
+}
+
+// Save encrypts the wallet into a new file in dir and returns its path.
+func Save(dir string, w *Wallet, password string, kdf KDFParams) (string, error) {
+	data, err := w.Encrypt(password, kdf)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf(
+		"UTC--%s--%s",
+		time.Now().UTC().Format("2006-01-02T15-04-05.000000000Z"),
+		strings.ToLower(strings.TrimPrefix(w.Address, "0x")),
+	)
+	path := filepath.Join(dir, name)
+
+	if err := os.WriteFile(path, data, keystoreMode); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// Load decrypts the keystore file at path.
+func Load(path, password string) (*Wallet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Decrypt(data, password)
+}
+
+// List returns the keys stored in dir, skipping files that are not keystore JSON.
+func List(dir string) ([]KeyFile, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]KeyFile, 0)
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(dir, e.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var k keyJSON
+		if err := json.Unmarshal(data, &k); err != nil || k.Address == "" {
+			continue
+		}
+
+		res = append(res, KeyFile{Address: common.HexToAddress(k.Address).Hex(), Path: path})
+	}
+
+	return res, nil
+}
+
+// Find returns the path of the key for address in dir.
+func Find(dir, address string) (string, error) {
+	keys, err := List(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for _, k := range keys {
+		if strings.EqualFold(k.Address, address) {
+			return k.Path, nil
+		}
+	}
+
+	return "", ErrKeystoreNotFound
+}
+
+func aesCTR(key, iv, in []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, len(in))
+	cipher.NewCTR(block, iv).XORKeyStream(out, in)
+	return out, nil
+}
@@ -0,0 +1,113 @@
+package wallet
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	w, err := New()
+	assert.NoError(t, err)
+
+	data, err := w.Encrypt("secret", LightKDF)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), w.GetPrivateKeyHex())
+
+	restored, err := Decrypt(data, "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, w.Address, restored.Address)
+	assert.Equal(t, w.GetPrivateKeyHex(), restored.GetPrivateKeyHex())
+
+	_, err = Decrypt(data, "wrong")
+	assert.ErrorIs(t, err, ErrDecrypt)
+}
+
+func TestDecrypt_Web3SecretStorageVector(t *testing.T) {
+	// test vector from the Web3 Secret Storage definition
+	data := []byte(`{
+		"crypto": {
+			"cipher": "aes-128-ctr",
+			"cipherparams": {"iv": "83dbcc02d8ccb40e466191a123791e0e"},
+			"ciphertext": "d172bf743a674da9cdad04534d56926ef8358534d458fffccd4e6ad2fbde479c",
+			"kdf": "scrypt",
+			"kdfparams": {"dklen": 32, "n": 262144, "p": 8, "r": 1, "salt": "ab0c7876052600dd703518d6fc3fe8984592145b591fc8fb5c6d43190334ba19"},
+			"mac": "2103ac29920d71da29f15d75b4a16dbe95cfd7ff8faea1056c33131d846e3097"
+		},
+		"id": "3198bc9c-6672-5ab3-d995-4942343ae5b6",
+		"version": 3
+	}`)
+
+	w, err := Decrypt(data, "testpassword")
+	assert.NoError(t, err)
+	assert.Equal(t, "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d", w.GetPrivateKeyHex())
+}
+
+func TestDecrypt_Malformed(t *testing.T) {
+	w, err := New()
+	assert.NoError(t, err)
+
+	data, err := w.Encrypt("secret", LightKDF)
+	assert.NoError(t, err)
+
+	tests := map[string]func(c map[string]any){
+		"short dklen": func(c map[string]any) { c["kdfparams"].(map[string]any)["dklen"] = 16 },
+		"long dklen":  func(c map[string]any) { c["kdfparams"].(map[string]any)["dklen"] = 64 },
+		"huge n":      func(c map[string]any) { c["kdfparams"].(map[string]any)["n"] = 1 << 30 },
+		"zero r":      func(c map[string]any) { c["kdfparams"].(map[string]any)["r"] = 0 },
+		"huge p":      func(c map[string]any) { c["kdfparams"].(map[string]any)["p"] = 1 << 20 },
+		"short iv":    func(c map[string]any) { c["cipherparams"].(map[string]any)["iv"] = "00" },
+		"huge n times r": func(c map[string]any) {
+			c["kdfparams"].(map[string]any)["n"] = 1 << 18
+			c["kdfparams"].(map[string]any)["r"] = 16
+		},
+	}
+
+	for name, mutate := range tests {
+		var k map[string]any
+		assert.NoError(t, json.Unmarshal(data, &k))
+		mutate(k["crypto"].(map[string]any))
+
+		malformed, err := json.Marshal(k)
+		assert.NoError(t, err)
+
+		_, err = Decrypt(malformed, "secret")
+		assert.ErrorIs(t, err, ErrKeystoreFormat, name)
+	}
+}
+
+func TestSaveLoadList(t *testing.T) {
+	dir := t.TempDir()
+
+	w, err := New()
+	assert.NoError(t, err)
+
+	path, err := Save(dir, w, "secret", LightKDF)
+	assert.NoError(t, err)
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	var k keyJSON
+	data, _ := os.ReadFile(path)
+	assert.NoError(t, json.Unmarshal(data, &k))
+	assert.Equal(t, 3, k.Version)
+
+	loaded, err := Load(path, "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, w.Address, loaded.Address)
+
+	keys, err := List(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []KeyFile{{Address: w.Address, Path: path}}, keys)
+
+	found, err := Find(dir, w.Address)
+	assert.NoError(t, err)
+	assert.Equal(t, path, found)
+
+	_, err = Find(dir, "0x76F86614A08683bDFd4a44Df1Ee24E94Bf5c19b2")
+	assert.ErrorIs(t, err, ErrKeystoreNotFound)
+}
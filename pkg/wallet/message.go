@@ -0,0 +1,67 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"com.perkunas/internal/errmsg"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// messagePrefix is prepended to signed messages so that a message signature
+// can never be replayed as a transaction signature, which signs a bare hash.
+const messagePrefix = "\x19Perkunas Signed Message:\n"
+
+// MessageHash is the digest signed by SignMessage.
+func MessageHash(msg []byte) []byte {
+	return crypto.Keccak256([]byte(fmt.Sprintf("%s%d", messagePrefix, len(msg))), msg)
+}
+
+// SignMessage signs msg and returns the hex encoded 65 byte signature.
+func (w *Wallet) SignMessage(msg []byte) (string, error) {
+	if w.PrivateKey == nil {
+		return "", errmsg.ErrSigningError
+	}
+
+	signature, err := crypto.Sign(MessageHash(msg), w.PrivateKey)
+	if err != nil {
+		return "", errmsg.ErrSigningError
+	}
+
+	return hex.EncodeToString(signature), nil
+}
+
+// RecoverMessageSigner returns the address that signed msg. Signatures with
+// an Ethereum style recovery id of 27/28 are accepted as well.
+func RecoverMessageSigner(msg []byte, signatureHex string) (string, error) {
+	signature, err := hex.DecodeString(strings.TrimPrefix(signatureHex, "0x"))
+	if err != nil || len(signature) != crypto.SignatureLength {
+		return "", errmsg.ErrInvalidSignatureFormat
+	}
+
+	if signature[crypto.RecoveryIDOffset] >= 27 {
+		signature[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pubKey, err := crypto.SigToPub(MessageHash(msg), signature)
+	if err != nil {
+		return "", errmsg.ErrSignatureRecoveryFailed
+	}
+
+	return crypto.PubkeyToAddress(*pubKey).Hex(), nil
+}
+
+// VerifyMessage checks that msg was signed by the key of address.
+func VerifyMessage(address string, msg []byte, signatureHex string) error {
+	signer, err := RecoverMessageSigner(msg, signatureHex)
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(signer, address) {
+		return errmsg.ErrSignatureSenderMismatch
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"com.perkunas/internal/errmsg"
+	"com.perkunas/internal/models/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignVerifyMessage(t *testing.T) {
+	w, err := FromPrivateKey("a6f7fa0885f49b8327376bdcc1da167750ec8004b1331705358c7fb697a74fbb")
+	assert.NoError(t, err)
+
+	msg := []byte("login to perkunas at 2026-01-01T00:00:00Z")
+	sig, err := w.SignMessage(msg)
+	assert.NoError(t, err)
+
+	signer, err := RecoverMessageSigner(msg, sig)
+	assert.NoError(t, err)
+	assert.Equal(t, w.Address, signer)
+	assert.NoError(t, VerifyMessage(w.Address, msg, "0x"+sig))
+
+	// recovery id in the 27/28 form used by Ethereum wallets
+	raw, _ := hex.DecodeString(sig)
+	raw[64] += 27
+	assert.NoError(t, VerifyMessage(w.Address, msg, hex.EncodeToString(raw)))
+
+	assert.ErrorIs(t, VerifyMessage(w.Address, []byte("other message"), sig), errmsg.ErrSignatureSenderMismatch)
+	assert.ErrorIs(t, VerifyMessage(w.Address, msg, "zz"), errmsg.ErrInvalidSignatureFormat)
+}
+
+func TestSignMessage_NotATransactionSignature(t *testing.T) {
+	w, err := New()
+	assert.NoError(t, err)
+
+	tx := &transaction.Transaction{From: w.Address, To: w.Address, Amount: 1, Nonce: 1}
+	tx.SetHash()
+
+	// signing the transaction hash as a message must not authorise the transaction
+	tx.Signature, err = w.SignMessage(tx.CalculateHash())
+	assert.NoError(t, err)
+	assert.Error(t, tx.Verify(4242))
+}
@@ -0,0 +1,119 @@
+package wallet
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// NonceSource reports what the chain knows about an account's nonces.
+// pkg/client.Client implements it against a node.
+type NonceSource interface {
+	// ConfirmedNonce is the nonce of the account's last mined transaction.
+	ConfirmedNonce(ctx context.Context, address string) (uint64, error)
+	// PendingNonces are the nonces of the account's transactions in the mempool.
+	PendingNonces(ctx context.Context, address string) ([]uint64, error)
+}
+
+// NonceManager hands out nonces for sending several transactions in a row
+// without waiting for each to be mined. It starts after the confirmed nonce
+// and the sender's pending transactions, and remembers the nonces it handed
+// out that the node has not seen yet. Safe for concurrent use.
+type NonceManager struct {
+	src      NonceSource
+	mu       sync.Mutex
+	accounts map[string]*nonceState
+}
+
+type nonceState struct {
+	next     uint64
+	released []uint64
+}
+
+func NewNonceManager(src NonceSource) *NonceManager {
+	return &NonceManager{src: src, accounts: make(map[string]*nonceState)}
+}
+
+// Next returns the nonce to use for the next transaction of address.
+// Nonces given back with Release are reused first so no gap is left.
+func (m *NonceManager) Next(ctx context.Context, address string) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	confirmed, err := m.src.ConfirmedNonce(ctx, address)
+	if err != nil {
+		return 0, err
+	}
+
+	pending, err := m.src.PendingNonces(ctx, address)
+	if err != nil {
+		return 0, err
+	}
+
+	st := m.state(address)
+	st.next = max(st.next, NextNonce(confirmed, pending))
+
+	// drop released nonces the chain has used meanwhile
+	st.released = slices.DeleteFunc(st.released, func(n uint64) bool {
+		return n <= confirmed || slices.Contains(pending, n)
+	})
+
+	if len(st.released) > 0 {
+		n := st.released[0]
+		st.released = st.released[1:]
+		return n, nil
+	}
+
+	n := st.next
+	st.next++
+	return n, nil
+}
+
+// Release gives back a nonce whose transaction was not accepted by the node,
+// so the next transaction fills the gap instead of getting stuck behind it.
+func (m *NonceManager) Release(address string, nonce uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st := m.state(address)
+	if nonce+1 == st.next {
+		st.next--
+		return
+	}
+
+	if nonce < st.next && !slices.Contains(st.released, nonce) {
+		st.released = append(st.released, nonce)
+		slices.Sort(st.released)
+	}
+}
+
+// Reset forgets what was handed out for address, so the next call starts
+// from the chain's view again. Use it when the node rejects a nonce.
+func (m *NonceManager) Reset(address string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.accounts, strings.ToLower(address))
+}
+
+func (m *NonceManager) state(address string) *nonceState {
+	key := strings.ToLower(address)
+	st, ok := m.accounts[key]
+	if !ok {
+		st = &nonceState{}
+		m.accounts[key] = st
+	}
+
+	return st
+}
+
+// NextNonce is the first nonce after confirmed that no pending transaction uses.
+func NextNonce(confirmed uint64, pending []uint64) uint64 {
+	next := confirmed + 1
+	for slices.Contains(pending, next) {
+		next++
+	}
+
+	return next
+}
@@ -0,0 +1,73 @@
+package wallet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeNonceSource struct {
+	confirmed uint64
+	pending   []uint64
+}
+
+func (f *fakeNonceSource) ConfirmedNonce(ctx context.Context, address string) (uint64, error) {
+	return f.confirmed, nil
+}
+
+func (f *fakeNonceSource) PendingNonces(ctx context.Context, address string) ([]uint64, error) {
+	return f.pending, nil
+}
+
+const nonceAddr = "0xE07cD67682C4b43bEF6b399bb7C180D975571aaa"
+
+func TestNonceManager_Next(t *testing.T) {
+	ctx := context.Background()
+	src := &fakeNonceSource{confirmed: 4, pending: []uint64{5, 6}}
+	m := NewNonceManager(src)
+
+	for _, want := range []uint64{7, 8, 9} {
+		n, err := m.Next(ctx, nonceAddr)
+		assert.NoError(t, err)
+		assert.Equal(t, want, n)
+	}
+
+	// the chain catching up does not hand out used nonces again
+	src.confirmed, src.pending = 8, []uint64{9}
+	n, _ := m.Next(ctx, nonceAddr)
+	assert.Equal(t, uint64(10), n)
+}
+
+func TestNonceManager_ReleaseAndReset(t *testing.T) {
+	ctx := context.Background()
+	src := &fakeNonceSource{confirmed: 0}
+	m := NewNonceManager(src)
+
+	for range 3 {
+		m.Next(ctx, nonceAddr)
+	}
+
+	// the last nonce is simply taken back
+	m.Release(nonceAddr, 3)
+	n, _ := m.Next(ctx, nonceAddr)
+	assert.Equal(t, uint64(3), n)
+
+	// a gap is filled before moving on
+	m.Release(nonceAddr, 2)
+	n, _ = m.Next(ctx, nonceAddr)
+	assert.Equal(t, uint64(2), n)
+	n, _ = m.Next(ctx, nonceAddr)
+	assert.Equal(t, uint64(4), n)
+
+	// nothing reached the node, start over from the chain
+	m.Reset(nonceAddr)
+	n, _ = m.Next(ctx, nonceAddr)
+	assert.Equal(t, uint64(1), n)
+}
+
+func TestNextNonce(t *testing.T) {
+	assert.Equal(t, uint64(1), NextNonce(0, nil))
+	assert.Equal(t, uint64(4), NextNonce(1, []uint64{3, 2}))
+	assert.Equal(t, uint64(2), NextNonce(1, []uint64{3}))
+}
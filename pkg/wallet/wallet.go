@@ -3,13 +3,17 @@ package wallet
 import (
 	"crypto/ecdsa"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"slices"
 
 	"com.perkunas/internal/errmsg"
 	"com.perkunas/internal/models/transaction"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
+var ErrAlreadySigned = errors.New("transaction already signed by this key")
+
 type Wallet struct {
 	PrivateKey *ecdsa.PrivateKey
 	PublicKey  *ecdsa.PublicKey
@@ -22,14 +26,7 @@ func New() (*Wallet, error) {
 		return nil, err
 	}
 
-	publicKey := &privateKey.PublicKey
-	address := crypto.PubkeyToAddress(*publicKey).Hex()
-
-	return &Wallet{
-		PrivateKey: privateKey,
-		PublicKey:  publicKey,
-		Address:    address,
-	}, nil
+	return fromECDSA(privateKey), nil
 }
 
 func FromPrivateKey(privateKeyHex string) (*Wallet, error) {
@@ -43,6 +40,10 @@ func FromPrivateKey(privateKeyHex string) (*Wallet, error) {
 		return nil, fmt.Errorf("invalid private key: %w", err)
 	}
 
+	return fromECDSA(privateKey), nil
+}
+
+func fromECDSA(privateKey *ecdsa.PrivateKey) *Wallet {
 	publicKey := &privateKey.PublicKey
 	address := crypto.PubkeyToAddress(*publicKey).Hex()
 
@@ -50,7 +51,7 @@ func FromPrivateKey(privateKeyHex string) (*Wallet, error) {
 		PrivateKey: privateKey,
 		PublicKey:  publicKey,
 		Address:    address,
-	}, nil
+	}
 }
 
 func (w *Wallet) GetPublicKeyHex() string {
@@ -66,6 +67,10 @@ func (w *Wallet) SignTransaction(tx *transaction.Transaction) error {
 		return errmsg.ErrSigningError
 	}
 
+	if err := tx.ValidateAddresses(); err != nil {
+		return err
+	}
+
 	senderAddr := crypto.PubkeyToAddress(w.PrivateKey.PublicKey).Hex()
 	if tx.From != senderAddr {
 		return errmsg.ErrSignatureSenderMismatch
@@ -80,3 +85,36 @@ func (w *Wallet) SignTransaction(tx *transaction.Transaction) error {
 	tx.Signature = hex.EncodeToString(signature)
 	return nil
 }
+
+// CoSign adds the wallet's signature to a spend from a multisig account the
+// wallet is an owner of.
+func (w *Wallet) CoSign(tx *transaction.Transaction) error {
+	if w.PrivateKey == nil {
+		return errmsg.ErrSigningError
+	}
+
+	if tx.Signature != "" {
+		return errmsg.ErrInvalidSignatureFormat
+	}
+
+	if err := tx.ValidateAddresses(); err != nil {
+		return err
+	}
+
+	signers, err := tx.Signers()
+	if err != nil {
+		return err
+	}
+
+	if slices.Contains(signers, w.Address) {
+		return ErrAlreadySigned
+	}
+
+	signature, err := crypto.Sign(tx.SigningHash(), w.PrivateKey)
+	if err != nil {
+		return errmsg.ErrSigningError
+	}
+
+	tx.Signatures = append(tx.Signatures, hex.EncodeToString(signature))
+	return nil
+}
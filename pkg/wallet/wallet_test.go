@@ -3,6 +3,9 @@ package wallet
 import (
 	"testing"
 
+	"com.perkunas/internal/models/transaction"
+	"com.perkunas/pkg/address"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,3 +31,14 @@ func TestNewWallet(t *testing.T) {
 	// ECDSA private key is 32 bytes (64 hex chars)
 	assert.Len(t, privHex, 64)
 }
+
+func TestSignTransaction_InvalidRecipient(t *testing.T) {
+	wallet, err := New()
+	assert.NoError(t, err)
+
+	tx := &transaction.Transaction{From: wallet.Address, To: "recipient", Amount: 1, Nonce: 1}
+	tx.SetHash()
+
+	assert.ErrorIs(t, wallet.SignTransaction(tx), address.ErrMissingPrefix)
+	assert.Empty(t, tx.Signature)
+}
@@ -19,9 +19,12 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	MempoolService_CreateMempool_FullMethodName       = "/mempool.MempoolService/CreateMempool"
-	MempoolService_DeleteMempoolBatch_FullMethodName  = "/mempool.MempoolService/DeleteMempoolBatch"
-	MempoolService_PendingTransactions_FullMethodName = "/mempool.MempoolService/PendingTransactions"
+	MempoolService_CreateMempool_FullMethodName         = "/mempool.MempoolService/CreateMempool"
+	MempoolService_DeleteMempoolBatch_FullMethodName    = "/mempool.MempoolService/DeleteMempoolBatch"
+	MempoolService_PendingTransactions_FullMethodName   = "/mempool.MempoolService/PendingTransactions"
+	MempoolService_GetTransaction_FullMethodName        = "/mempool.MempoolService/GetTransaction"
+	MempoolService_SubscribeTransactions_FullMethodName = "/mempool.MempoolService/SubscribeTransactions"
+	MempoolService_GetDropped_FullMethodName            = "/mempool.MempoolService/GetDropped"
 )
 
 // MempoolServiceClient is the client API for MempoolService service.
@@ -31,6 +34,9 @@ type MempoolServiceClient interface {
 	CreateMempool(ctx context.Context, in *CreateMempoolRequest, opts ...grpc.CallOption) (*CreateMempoolResponse, error)
 	DeleteMempoolBatch(ctx context.Context, in *DeleteMempoolBatchRequest, opts ...grpc.CallOption) (*DeleteMempoolBatchResponse, error)
 	PendingTransactions(ctx context.Context, in *PendingTransactionsRequest, opts ...grpc.CallOption) (*PendingTransactionsResponse, error)
+	GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*GetTransactionResponse, error)
+	SubscribeTransactions(ctx context.Context, in *SubscribeTransactionsRequest, opts ...grpc.CallOption) (MempoolService_SubscribeTransactionsClient, error)
+	GetDropped(ctx context.Context, in *GetDroppedRequest, opts ...grpc.CallOption) (*DroppedTransaction, error)
 }
 
 type mempoolServiceClient struct {
@@ -68,6 +74,56 @@ func (c *mempoolServiceClient) PendingTransactions(ctx context.Context, in *Pend
 	return out, nil
 }
 
+func (c *mempoolServiceClient) GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*GetTransactionResponse, error) {
+	out := new(GetTransactionResponse)
+	err := c.cc.Invoke(ctx, MempoolService_GetTransaction_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mempoolServiceClient) SubscribeTransactions(ctx context.Context, in *SubscribeTransactionsRequest, opts ...grpc.CallOption) (MempoolService_SubscribeTransactionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &MempoolService_ServiceDesc.Streams[0], MempoolService_SubscribeTransactions_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &mempoolServiceSubscribeTransactionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MempoolService_SubscribeTransactionsClient interface {
+	Recv() (*Transaction, error)
+	grpc.ClientStream
+}
+
+type mempoolServiceSubscribeTransactionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *mempoolServiceSubscribeTransactionsClient) Recv() (*Transaction, error) {
+	m := new(Transaction)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *mempoolServiceClient) GetDropped(ctx context.Context, in *GetDroppedRequest, opts ...grpc.CallOption) (*DroppedTransaction, error) {
+	out := new(DroppedTransaction)
+	err := c.cc.Invoke(ctx, MempoolService_GetDropped_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MempoolServiceServer is the server API for MempoolService service.
 // All implementations must embed UnimplementedMempoolServiceServer
 // for forward compatibility
@@ -75,6 +131,9 @@ type MempoolServiceServer interface {
 	CreateMempool(context.Context, *CreateMempoolRequest) (*CreateMempoolResponse, error)
 	DeleteMempoolBatch(context.Context, *DeleteMempoolBatchRequest) (*DeleteMempoolBatchResponse, error)
 	PendingTransactions(context.Context, *PendingTransactionsRequest) (*PendingTransactionsResponse, error)
+	GetTransaction(context.Context, *GetTransactionRequest) (*GetTransactionResponse, error)
+	SubscribeTransactions(*SubscribeTransactionsRequest, MempoolService_SubscribeTransactionsServer) error
+	GetDropped(context.Context, *GetDroppedRequest) (*DroppedTransaction, error)
 	mustEmbedUnimplementedMempoolServiceServer()
 }
 
@@ -91,6 +150,15 @@ func (UnimplementedMempoolServiceServer) DeleteMempoolBatch(context.Context, *De
 func (UnimplementedMempoolServiceServer) PendingTransactions(context.Context, *PendingTransactionsRequest) (*PendingTransactionsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PendingTransactions not implemented")
 }
+func (UnimplementedMempoolServiceServer) GetTransaction(context.Context, *GetTransactionRequest) (*GetTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransaction not implemented")
+}
+func (UnimplementedMempoolServiceServer) SubscribeTransactions(*SubscribeTransactionsRequest, MempoolService_SubscribeTransactionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeTransactions not implemented")
+}
+func (UnimplementedMempoolServiceServer) GetDropped(context.Context, *GetDroppedRequest) (*DroppedTransaction, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDropped not implemented")
+}
 func (UnimplementedMempoolServiceServer) mustEmbedUnimplementedMempoolServiceServer() {}
 
 // UnsafeMempoolServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -158,6 +226,63 @@ func _MempoolService_PendingTransactions_Handler(srv interface{}, ctx context.Co
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MempoolService_GetTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MempoolServiceServer).GetTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MempoolService_GetTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MempoolServiceServer).GetTransaction(ctx, req.(*GetTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MempoolService_SubscribeTransactions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeTransactionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MempoolServiceServer).SubscribeTransactions(m, &mempoolServiceSubscribeTransactionsServer{stream})
+}
+
+type MempoolService_SubscribeTransactionsServer interface {
+	Send(*Transaction) error
+	grpc.ServerStream
+}
+
+type mempoolServiceSubscribeTransactionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *mempoolServiceSubscribeTransactionsServer) Send(m *Transaction) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _MempoolService_GetDropped_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDroppedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MempoolServiceServer).GetDropped(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MempoolService_GetDropped_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MempoolServiceServer).GetDropped(ctx, req.(*GetDroppedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MempoolService_ServiceDesc is the grpc.ServiceDesc for MempoolService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -177,7 +302,21 @@ var MempoolService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "PendingTransactions",
 			Handler:    _MempoolService_PendingTransactions_Handler,
 		},
+		{
+			MethodName: "GetTransaction",
+			Handler:    _MempoolService_GetTransaction_Handler,
+		},
+		{
+			MethodName: "GetDropped",
+			Handler:    _MempoolService_GetDropped_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeTransactions",
+			Handler:       _MempoolService_SubscribeTransactions_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "mempool.proto",
 }
@@ -19,9 +19,26 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	StateService_CreateBlock_FullMethodName         = "/state.StateService/CreateBlock"
-	StateService_GetAccountByAddress_FullMethodName = "/state.StateService/GetAccountByAddress"
-	StateService_GetLatestBlock_FullMethodName      = "/state.StateService/GetLatestBlock"
+	StateService_CreateBlock_FullMethodName            = "/state.StateService/CreateBlock"
+	StateService_PreviewStateRoot_FullMethodName       = "/state.StateService/PreviewStateRoot"
+	StateService_GetAccountByAddress_FullMethodName    = "/state.StateService/GetAccountByAddress"
+	StateService_GetLatestBlock_FullMethodName         = "/state.StateService/GetLatestBlock"
+	StateService_GetBlockByHeight_FullMethodName       = "/state.StateService/GetBlockByHeight"
+	StateService_GetTransactionProof_FullMethodName    = "/state.StateService/GetTransactionProof"
+	StateService_GetAccountProof_FullMethodName        = "/state.StateService/GetAccountProof"
+	StateService_GetTransaction_FullMethodName         = "/state.StateService/GetTransaction"
+	StateService_GetAccountTransactions_FullMethodName = "/state.StateService/GetAccountTransactions"
+	StateService_GetMultisig_FullMethodName            = "/state.StateService/GetMultisig"
+	StateService_GetToken_FullMethodName               = "/state.StateService/GetToken"
+	StateService_GetTokenBalance_FullMethodName        = "/state.StateService/GetTokenBalance"
+	StateService_GetTokenBalances_FullMethodName       = "/state.StateService/GetTokenBalances"
+	StateService_GetTokenAllowance_FullMethodName      = "/state.StateService/GetTokenAllowance"
+	StateService_GetHTLC_FullMethodName                = "/state.StateService/GetHTLC"
+	StateService_GetContract_FullMethodName            = "/state.StateService/GetContract"
+	StateService_GetStorage_FullMethodName             = "/state.StateService/GetStorage"
+	StateService_GetLogs_FullMethodName                = "/state.StateService/GetLogs"
+	StateService_SubscribeLogs_FullMethodName          = "/state.StateService/SubscribeLogs"
+	StateService_SubscribeBlocks_FullMethodName        = "/state.StateService/SubscribeBlocks"
 )
 
 // StateServiceClient is the client API for StateService service.
@@ -29,8 +46,25 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type StateServiceClient interface {
 	CreateBlock(ctx context.Context, in *CreateBlockReq, opts ...grpc.CallOption) (*CreateBlockRes, error)
+	PreviewStateRoot(ctx context.Context, in *CreateBlockReq, opts ...grpc.CallOption) (*StateRootRes, error)
 	GetAccountByAddress(ctx context.Context, in *AccountByAddressReq, opts ...grpc.CallOption) (*AccountByAddressRes, error)
 	GetLatestBlock(ctx context.Context, in *LastBlockReq, opts ...grpc.CallOption) (*LastBlockRes, error)
+	GetBlockByHeight(ctx context.Context, in *BlockByHeightReq, opts ...grpc.CallOption) (*BlockByHeightRes, error)
+	GetTransactionProof(ctx context.Context, in *TxProofReq, opts ...grpc.CallOption) (*TxProofRes, error)
+	GetAccountProof(ctx context.Context, in *AccountProofReq, opts ...grpc.CallOption) (*AccountProofRes, error)
+	GetTransaction(ctx context.Context, in *TransactionReq, opts ...grpc.CallOption) (*TransactionRes, error)
+	GetAccountTransactions(ctx context.Context, in *AccountTransactionsReq, opts ...grpc.CallOption) (*AccountTransactionsRes, error)
+	GetMultisig(ctx context.Context, in *MultisigReq, opts ...grpc.CallOption) (*MultisigRes, error)
+	GetToken(ctx context.Context, in *TokenReq, opts ...grpc.CallOption) (*TokenRes, error)
+	GetTokenBalance(ctx context.Context, in *TokenBalanceReq, opts ...grpc.CallOption) (*TokenBalanceRes, error)
+	GetTokenBalances(ctx context.Context, in *TokenBalancesReq, opts ...grpc.CallOption) (*TokenBalancesRes, error)
+	GetTokenAllowance(ctx context.Context, in *TokenAllowanceReq, opts ...grpc.CallOption) (*TokenAllowanceRes, error)
+	GetHTLC(ctx context.Context, in *HTLCReq, opts ...grpc.CallOption) (*HTLCRes, error)
+	GetContract(ctx context.Context, in *ContractReq, opts ...grpc.CallOption) (*ContractRes, error)
+	GetStorage(ctx context.Context, in *StorageReq, opts ...grpc.CallOption) (*StorageRes, error)
+	GetLogs(ctx context.Context, in *LogsReq, opts ...grpc.CallOption) (*LogsRes, error)
+	SubscribeLogs(ctx context.Context, in *LogsReq, opts ...grpc.CallOption) (StateService_SubscribeLogsClient, error)
+	SubscribeBlocks(ctx context.Context, in *SubscribeBlocksReq, opts ...grpc.CallOption) (StateService_SubscribeBlocksClient, error)
 }
 
 type stateServiceClient struct {
@@ -50,6 +84,15 @@ func (c *stateServiceClient) CreateBlock(ctx context.Context, in *CreateBlockReq
 	return out, nil
 }
 
+func (c *stateServiceClient) PreviewStateRoot(ctx context.Context, in *CreateBlockReq, opts ...grpc.CallOption) (*StateRootRes, error) {
+	out := new(StateRootRes)
+	err := c.cc.Invoke(ctx, StateService_PreviewStateRoot_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *stateServiceClient) GetAccountByAddress(ctx context.Context, in *AccountByAddressReq, opts ...grpc.CallOption) (*AccountByAddressRes, error) {
 	out := new(AccountByAddressRes)
 	err := c.cc.Invoke(ctx, StateService_GetAccountByAddress_FullMethodName, in, out, opts...)
@@ -68,13 +111,220 @@ func (c *stateServiceClient) GetLatestBlock(ctx context.Context, in *LastBlockRe
 	return out, nil
 }
 
+func (c *stateServiceClient) GetBlockByHeight(ctx context.Context, in *BlockByHeightReq, opts ...grpc.CallOption) (*BlockByHeightRes, error) {
+	out := new(BlockByHeightRes)
+	err := c.cc.Invoke(ctx, StateService_GetBlockByHeight_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stateServiceClient) GetTransactionProof(ctx context.Context, in *TxProofReq, opts ...grpc.CallOption) (*TxProofRes, error) {
+	out := new(TxProofRes)
+	err := c.cc.Invoke(ctx, StateService_GetTransactionProof_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stateServiceClient) GetAccountProof(ctx context.Context, in *AccountProofReq, opts ...grpc.CallOption) (*AccountProofRes, error) {
+	out := new(AccountProofRes)
+	err := c.cc.Invoke(ctx, StateService_GetAccountProof_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stateServiceClient) GetTransaction(ctx context.Context, in *TransactionReq, opts ...grpc.CallOption) (*TransactionRes, error) {
+	out := new(TransactionRes)
+	err := c.cc.Invoke(ctx, StateService_GetTransaction_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stateServiceClient) GetAccountTransactions(ctx context.Context, in *AccountTransactionsReq, opts ...grpc.CallOption) (*AccountTransactionsRes, error) {
+	out := new(AccountTransactionsRes)
+	err := c.cc.Invoke(ctx, StateService_GetAccountTransactions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stateServiceClient) GetMultisig(ctx context.Context, in *MultisigReq, opts ...grpc.CallOption) (*MultisigRes, error) {
+	out := new(MultisigRes)
+	err := c.cc.Invoke(ctx, StateService_GetMultisig_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stateServiceClient) GetToken(ctx context.Context, in *TokenReq, opts ...grpc.CallOption) (*TokenRes, error) {
+	out := new(TokenRes)
+	err := c.cc.Invoke(ctx, StateService_GetToken_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stateServiceClient) GetTokenBalance(ctx context.Context, in *TokenBalanceReq, opts ...grpc.CallOption) (*TokenBalanceRes, error) {
+	out := new(TokenBalanceRes)
+	err := c.cc.Invoke(ctx, StateService_GetTokenBalance_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stateServiceClient) GetTokenBalances(ctx context.Context, in *TokenBalancesReq, opts ...grpc.CallOption) (*TokenBalancesRes, error) {
+	out := new(TokenBalancesRes)
+	err := c.cc.Invoke(ctx, StateService_GetTokenBalances_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stateServiceClient) GetTokenAllowance(ctx context.Context, in *TokenAllowanceReq, opts ...grpc.CallOption) (*TokenAllowanceRes, error) {
+	out := new(TokenAllowanceRes)
+	err := c.cc.Invoke(ctx, StateService_GetTokenAllowance_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stateServiceClient) GetHTLC(ctx context.Context, in *HTLCReq, opts ...grpc.CallOption) (*HTLCRes, error) {
+	out := new(HTLCRes)
+	err := c.cc.Invoke(ctx, StateService_GetHTLC_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stateServiceClient) GetContract(ctx context.Context, in *ContractReq, opts ...grpc.CallOption) (*ContractRes, error) {
+	out := new(ContractRes)
+	err := c.cc.Invoke(ctx, StateService_GetContract_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stateServiceClient) GetStorage(ctx context.Context, in *StorageReq, opts ...grpc.CallOption) (*StorageRes, error) {
+	out := new(StorageRes)
+	err := c.cc.Invoke(ctx, StateService_GetStorage_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stateServiceClient) GetLogs(ctx context.Context, in *LogsReq, opts ...grpc.CallOption) (*LogsRes, error) {
+	out := new(LogsRes)
+	err := c.cc.Invoke(ctx, StateService_GetLogs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stateServiceClient) SubscribeLogs(ctx context.Context, in *LogsReq, opts ...grpc.CallOption) (StateService_SubscribeLogsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &StateService_ServiceDesc.Streams[0], StateService_SubscribeLogs_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &stateServiceSubscribeLogsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type StateService_SubscribeLogsClient interface {
+	Recv() (*IndexedLog, error)
+	grpc.ClientStream
+}
+
+type stateServiceSubscribeLogsClient struct {
+	grpc.ClientStream
+}
+
+func (x *stateServiceSubscribeLogsClient) Recv() (*IndexedLog, error) {
+	m := new(IndexedLog)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *stateServiceClient) SubscribeBlocks(ctx context.Context, in *SubscribeBlocksReq, opts ...grpc.CallOption) (StateService_SubscribeBlocksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &StateService_ServiceDesc.Streams[1], StateService_SubscribeBlocks_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &stateServiceSubscribeBlocksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type StateService_SubscribeBlocksClient interface {
+	Recv() (*BlockEvent, error)
+	grpc.ClientStream
+}
+
+type stateServiceSubscribeBlocksClient struct {
+	grpc.ClientStream
+}
+
+func (x *stateServiceSubscribeBlocksClient) Recv() (*BlockEvent, error) {
+	m := new(BlockEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // StateServiceServer is the server API for StateService service.
 // All implementations must embed UnimplementedStateServiceServer
 // for forward compatibility
 type StateServiceServer interface {
 	CreateBlock(context.Context, *CreateBlockReq) (*CreateBlockRes, error)
+	PreviewStateRoot(context.Context, *CreateBlockReq) (*StateRootRes, error)
 	GetAccountByAddress(context.Context, *AccountByAddressReq) (*AccountByAddressRes, error)
 	GetLatestBlock(context.Context, *LastBlockReq) (*LastBlockRes, error)
+	GetBlockByHeight(context.Context, *BlockByHeightReq) (*BlockByHeightRes, error)
+	GetTransactionProof(context.Context, *TxProofReq) (*TxProofRes, error)
+	GetAccountProof(context.Context, *AccountProofReq) (*AccountProofRes, error)
+	GetTransaction(context.Context, *TransactionReq) (*TransactionRes, error)
+	GetAccountTransactions(context.Context, *AccountTransactionsReq) (*AccountTransactionsRes, error)
+	GetMultisig(context.Context, *MultisigReq) (*MultisigRes, error)
+	GetToken(context.Context, *TokenReq) (*TokenRes, error)
+	GetTokenBalance(context.Context, *TokenBalanceReq) (*TokenBalanceRes, error)
+	GetTokenBalances(context.Context, *TokenBalancesReq) (*TokenBalancesRes, error)
+	GetTokenAllowance(context.Context, *TokenAllowanceReq) (*TokenAllowanceRes, error)
+	GetHTLC(context.Context, *HTLCReq) (*HTLCRes, error)
+	GetContract(context.Context, *ContractReq) (*ContractRes, error)
+	GetStorage(context.Context, *StorageReq) (*StorageRes, error)
+	GetLogs(context.Context, *LogsReq) (*LogsRes, error)
+	SubscribeLogs(*LogsReq, StateService_SubscribeLogsServer) error
+	SubscribeBlocks(*SubscribeBlocksReq, StateService_SubscribeBlocksServer) error
 	mustEmbedUnimplementedStateServiceServer()
 }
 
@@ -85,12 +335,63 @@ type UnimplementedStateServiceServer struct {
 func (UnimplementedStateServiceServer) CreateBlock(context.Context, *CreateBlockReq) (*CreateBlockRes, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateBlock not implemented")
 }
+func (UnimplementedStateServiceServer) PreviewStateRoot(context.Context, *CreateBlockReq) (*StateRootRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PreviewStateRoot not implemented")
+}
 func (UnimplementedStateServiceServer) GetAccountByAddress(context.Context, *AccountByAddressReq) (*AccountByAddressRes, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAccountByAddress not implemented")
 }
 func (UnimplementedStateServiceServer) GetLatestBlock(context.Context, *LastBlockReq) (*LastBlockRes, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetLatestBlock not implemented")
 }
+func (UnimplementedStateServiceServer) GetBlockByHeight(context.Context, *BlockByHeightReq) (*BlockByHeightRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlockByHeight not implemented")
+}
+func (UnimplementedStateServiceServer) GetTransactionProof(context.Context, *TxProofReq) (*TxProofRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransactionProof not implemented")
+}
+func (UnimplementedStateServiceServer) GetAccountProof(context.Context, *AccountProofReq) (*AccountProofRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccountProof not implemented")
+}
+func (UnimplementedStateServiceServer) GetTransaction(context.Context, *TransactionReq) (*TransactionRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransaction not implemented")
+}
+func (UnimplementedStateServiceServer) GetAccountTransactions(context.Context, *AccountTransactionsReq) (*AccountTransactionsRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccountTransactions not implemented")
+}
+func (UnimplementedStateServiceServer) GetMultisig(context.Context, *MultisigReq) (*MultisigRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMultisig not implemented")
+}
+func (UnimplementedStateServiceServer) GetToken(context.Context, *TokenReq) (*TokenRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetToken not implemented")
+}
+func (UnimplementedStateServiceServer) GetTokenBalance(context.Context, *TokenBalanceReq) (*TokenBalanceRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTokenBalance not implemented")
+}
+func (UnimplementedStateServiceServer) GetTokenBalances(context.Context, *TokenBalancesReq) (*TokenBalancesRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTokenBalances not implemented")
+}
+func (UnimplementedStateServiceServer) GetTokenAllowance(context.Context, *TokenAllowanceReq) (*TokenAllowanceRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTokenAllowance not implemented")
+}
+func (UnimplementedStateServiceServer) GetHTLC(context.Context, *HTLCReq) (*HTLCRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHTLC not implemented")
+}
+func (UnimplementedStateServiceServer) GetContract(context.Context, *ContractReq) (*ContractRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetContract not implemented")
+}
+func (UnimplementedStateServiceServer) GetStorage(context.Context, *StorageReq) (*StorageRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStorage not implemented")
+}
+func (UnimplementedStateServiceServer) GetLogs(context.Context, *LogsReq) (*LogsRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLogs not implemented")
+}
+func (UnimplementedStateServiceServer) SubscribeLogs(*LogsReq, StateService_SubscribeLogsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeLogs not implemented")
+}
+func (UnimplementedStateServiceServer) SubscribeBlocks(*SubscribeBlocksReq, StateService_SubscribeBlocksServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeBlocks not implemented")
+}
 func (UnimplementedStateServiceServer) mustEmbedUnimplementedStateServiceServer() {}
 
 // UnsafeStateServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -122,6 +423,24 @@ func _StateService_CreateBlock_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StateService_PreviewStateRoot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBlockReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateServiceServer).PreviewStateRoot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StateService_PreviewStateRoot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateServiceServer).PreviewStateRoot(ctx, req.(*CreateBlockReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _StateService_GetAccountByAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(AccountByAddressReq)
 	if err := dec(in); err != nil {
@@ -158,6 +477,300 @@ func _StateService_GetLatestBlock_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StateService_GetBlockByHeight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockByHeightReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateServiceServer).GetBlockByHeight(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StateService_GetBlockByHeight_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateServiceServer).GetBlockByHeight(ctx, req.(*BlockByHeightReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StateService_GetTransactionProof_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxProofReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateServiceServer).GetTransactionProof(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StateService_GetTransactionProof_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateServiceServer).GetTransactionProof(ctx, req.(*TxProofReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StateService_GetAccountProof_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountProofReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateServiceServer).GetAccountProof(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StateService_GetAccountProof_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateServiceServer).GetAccountProof(ctx, req.(*AccountProofReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StateService_GetTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransactionReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateServiceServer).GetTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StateService_GetTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateServiceServer).GetTransaction(ctx, req.(*TransactionReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StateService_GetAccountTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountTransactionsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateServiceServer).GetAccountTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StateService_GetAccountTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateServiceServer).GetAccountTransactions(ctx, req.(*AccountTransactionsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StateService_GetMultisig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MultisigReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateServiceServer).GetMultisig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StateService_GetMultisig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateServiceServer).GetMultisig(ctx, req.(*MultisigReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StateService_GetToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TokenReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateServiceServer).GetToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StateService_GetToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateServiceServer).GetToken(ctx, req.(*TokenReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StateService_GetTokenBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TokenBalanceReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateServiceServer).GetTokenBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StateService_GetTokenBalance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateServiceServer).GetTokenBalance(ctx, req.(*TokenBalanceReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StateService_GetTokenBalances_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TokenBalancesReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateServiceServer).GetTokenBalances(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StateService_GetTokenBalances_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateServiceServer).GetTokenBalances(ctx, req.(*TokenBalancesReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StateService_GetTokenAllowance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TokenAllowanceReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateServiceServer).GetTokenAllowance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StateService_GetTokenAllowance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateServiceServer).GetTokenAllowance(ctx, req.(*TokenAllowanceReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StateService_GetHTLC_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HTLCReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateServiceServer).GetHTLC(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StateService_GetHTLC_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateServiceServer).GetHTLC(ctx, req.(*HTLCReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StateService_GetContract_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContractReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateServiceServer).GetContract(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StateService_GetContract_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateServiceServer).GetContract(ctx, req.(*ContractReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StateService_GetStorage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StorageReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateServiceServer).GetStorage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StateService_GetStorage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateServiceServer).GetStorage(ctx, req.(*StorageReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StateService_GetLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateServiceServer).GetLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StateService_GetLogs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateServiceServer).GetLogs(ctx, req.(*LogsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StateService_SubscribeLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LogsReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StateServiceServer).SubscribeLogs(m, &stateServiceSubscribeLogsServer{stream})
+}
+
+type StateService_SubscribeLogsServer interface {
+	Send(*IndexedLog) error
+	grpc.ServerStream
+}
+
+type stateServiceSubscribeLogsServer struct {
+	grpc.ServerStream
+}
+
+func (x *stateServiceSubscribeLogsServer) Send(m *IndexedLog) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _StateService_SubscribeBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeBlocksReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StateServiceServer).SubscribeBlocks(m, &stateServiceSubscribeBlocksServer{stream})
+}
+
+type StateService_SubscribeBlocksServer interface {
+	Send(*BlockEvent) error
+	grpc.ServerStream
+}
+
+type stateServiceSubscribeBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (x *stateServiceSubscribeBlocksServer) Send(m *BlockEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // StateService_ServiceDesc is the grpc.ServiceDesc for StateService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -169,6 +782,10 @@ var StateService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateBlock",
 			Handler:    _StateService_CreateBlock_Handler,
 		},
+		{
+			MethodName: "PreviewStateRoot",
+			Handler:    _StateService_PreviewStateRoot_Handler,
+		},
 		{
 			MethodName: "GetAccountByAddress",
 			Handler:    _StateService_GetAccountByAddress_Handler,
@@ -177,7 +794,74 @@ var StateService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetLatestBlock",
 			Handler:    _StateService_GetLatestBlock_Handler,
 		},
+		{
+			MethodName: "GetBlockByHeight",
+			Handler:    _StateService_GetBlockByHeight_Handler,
+		},
+		{
+			MethodName: "GetTransactionProof",
+			Handler:    _StateService_GetTransactionProof_Handler,
+		},
+		{
+			MethodName: "GetAccountProof",
+			Handler:    _StateService_GetAccountProof_Handler,
+		},
+		{
+			MethodName: "GetTransaction",
+			Handler:    _StateService_GetTransaction_Handler,
+		},
+		{
+			MethodName: "GetAccountTransactions",
+			Handler:    _StateService_GetAccountTransactions_Handler,
+		},
+		{
+			MethodName: "GetMultisig",
+			Handler:    _StateService_GetMultisig_Handler,
+		},
+		{
+			MethodName: "GetToken",
+			Handler:    _StateService_GetToken_Handler,
+		},
+		{
+			MethodName: "GetTokenBalance",
+			Handler:    _StateService_GetTokenBalance_Handler,
+		},
+		{
+			MethodName: "GetTokenBalances",
+			Handler:    _StateService_GetTokenBalances_Handler,
+		},
+		{
+			MethodName: "GetTokenAllowance",
+			Handler:    _StateService_GetTokenAllowance_Handler,
+		},
+		{
+			MethodName: "GetHTLC",
+			Handler:    _StateService_GetHTLC_Handler,
+		},
+		{
+			MethodName: "GetContract",
+			Handler:    _StateService_GetContract_Handler,
+		},
+		{
+			MethodName: "GetStorage",
+			Handler:    _StateService_GetStorage_Handler,
+		},
+		{
+			MethodName: "GetLogs",
+			Handler:    _StateService_GetLogs_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeLogs",
+			Handler:       _StateService_SubscribeLogs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeBlocks",
+			Handler:       _StateService_SubscribeBlocks_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "state.proto",
 }